@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/winnerx0/jille/internal/application"
+)
+
+// AuthMiddleware accepts either a Bearer JWT or a personal API key, sent as
+// "Authorization: Bearer jille_..." or in the X-API-Key header. Requests made
+// with an API key carry its scopes in the "scopes" local.
+func AuthMiddleware(c fiber.Ctx, jwtservice application.JwtService, apikeyservice application.APIKeyService) error {
+
+	apiKey := c.Get("X-API-Key")
+
+	if apiKey == "" {
+		if token, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(token, application.APIKeyPrefix) {
+			apiKey = token
+		}
+	}
+
+	if apiKey == "" {
+		return JWTMiddleware(c, jwtservice)
+	}
+
+	principal, err := apikeyservice.Authenticate(c.RequestCtx(), apiKey)
+
+	if err != nil {
+		c.Response().SetStatusCode(401)
+		return c.JSON(fiber.Map{"message": "Invalid API key provided"})
+	}
+
+	c.Locals("userID", principal.UserID.String())
+	c.Locals("scopes", principal.Scopes)
+
+	return c.Next()
+}
+
+// RequireScope rejects API key requests whose key lacks scope. Requests
+// authenticated with a JWT act with the user's full rights.
+func RequireScope(scope string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+
+		scopes, ok := c.Locals("scopes").([]string)
+
+		if ok && !slices.Contains(scopes, scope) {
+			c.Response().SetStatusCode(403)
+			return c.JSON(fiber.Map{"message": "API key is missing the " + scope + " scope"})
+		}
+
+		return c.Next()
+	}
+}
@@ -44,3 +44,14 @@ func JWTMiddleware(c fiber.Ctx, jwtservice application.JwtService) error {
 
 	return c.Next()
 }
+
+// QueryToken lets clients that cannot set headers, such as the browser's
+// EventSource, send their access token in the access_token query parameter.
+func QueryToken(c fiber.Ctx) error {
+
+	if token := c.Query("access_token"); token != "" && c.Get("Authorization") == "" {
+		c.Request().Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.Next()
+}
@@ -1,19 +1,30 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/cors"
+	"github.com/gofiber/fiber/v3/middleware/static"
 	"gorm.io/gorm"
 
 	"github.com/winnerx0/jille/api/middleware"
 	"github.com/winnerx0/jille/config"
+	"github.com/winnerx0/jille/infra/chart"
 	"github.com/winnerx0/jille/infra/database"
+	"github.com/winnerx0/jille/infra/oidc"
 	"github.com/winnerx0/jille/infra/persistence"
+	"github.com/winnerx0/jille/infra/slack"
+	"github.com/winnerx0/jille/infra/storage"
+	"github.com/winnerx0/jille/infra/stream"
+	"github.com/winnerx0/jille/infra/webhook"
 	"github.com/winnerx0/jille/internal/application"
 	"github.com/winnerx0/jille/internal/delivery/web"
+	"github.com/winnerx0/jille/internal/domain"
 	"github.com/winnerx0/jille/internal/utils"
 )
 
@@ -30,7 +41,16 @@ func New(cfg *config.Config) (*App, error) {
 		Validator: utils.Validate,
 	}
 
-	r := fiber.New()
+	// c.IP() only reads the client address from ProxyHeader on requests
+	// from a trusted proxy, so clients cannot pick the address sign-in
+	// attempts are counted against
+	r := fiber.New(fiber.Config{
+		TrustProxy: len(cfg.TrustedProxies) > 0,
+		TrustProxyConfig: fiber.TrustProxyConfig{
+			Proxies: cfg.TrustedProxies,
+		},
+		ProxyHeader: cfg.ProxyHeader,
+	})
 
 	dbConfig := &database.DBConfig{
 		Host:     cfg.DBConfig.Host,
@@ -47,9 +67,19 @@ func New(cfg *config.Config) (*App, error) {
 		log.Fatal("Error connecting to database", err.Error())
 	}
 
-	// if err := db.AutoMigrate(database.Models...); err != nil {
-	// 		log.Fatal("Error migrating dataabse tables", err.Error())
-	// }
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatal("Error loading database migrations", err.Error())
+	}
+
+	// the schema is managed by the migrate command, the server only checks
+	// that it is current
+	if err := migrator.CheckCurrent(context.Background()); err != nil {
+		if cfg.RequireCurrentSchema || !errors.Is(err, utils.OutdatedSchemaError) {
+			log.Fatal("Error checking database schema ", err.Error())
+		}
+		fmt.Println("warning:", err.Error(), "- run the migrate command")
+	}
 
 	app := &App{
 		Config: cfg,
@@ -60,7 +90,10 @@ func New(cfg *config.Config) (*App, error) {
 	app.Router.Use(cors.New(cors.Config{
 		AllowOrigins: []string{"http://localhost:3000", "https://jille.vercel.app"},
 		AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders: []string{"Content-Type", "Authorization"},
+		AllowHeaders: []string{"Content-Type", "Authorization", "X-API-Key"},
+		// the identity provider callback carries the cookie set when the
+		// login started
+		AllowCredentials: true,
 	}))
 
 	userRepo := persistence.NewUserReposiory(db)
@@ -71,26 +104,206 @@ func New(cfg *config.Config) (*App, error) {
 
 	voteRepo := persistence.NewVoteRepository(db)
 
-	pollService := application.NewPollService(pollRepo, optionRepo, voteRepo)
+	organizationRepo := persistence.NewOrganizationRepository(db)
+
+	pollInviteRepo := persistence.NewPollInviteRepository(db)
+
+	pollPermissionRepo := persistence.NewPollPermissionRepository(db)
+
+	unitOfWork := persistence.NewUnitOfWork(db)
+
+	pollPolicy := application.NewPollPolicy(organizationRepo, pollPermissionRepo, voteRepo)
 
-	userService := application.NewUserService(userRepo, pollService)
+	pollService := application.NewPollService(pollRepo, optionRepo, voteRepo, organizationRepo, unitOfWork, pollPolicy)
+
+	fileStorage := storage.NewLocalStorage(cfg.UploadDir, cfg.PublicURL+"/uploads")
+
+	userService := application.NewUserService(userRepo, pollService, fileStorage)
 
 	userHandler := web.NewUserHandler(userService)
 
+	organizationHandler := web.NewOrganizationHandler(application.NewOrganizationService(organizationRepo, userService), *validator)
+
 	authRepo := persistence.NewAuthRepository(db)
 
+	userTokenRepo := persistence.NewUserTokenRepository(db)
+
+	mailer, err := cfg.MailConfig.New()
+	if err != nil {
+		log.Fatal("Error configuring mailer", err.Error())
+	}
+
 	jwtService := application.NewJwtService(cfg.JWT_ACCESS_TOKEN_SECRET, cfg.JWT_REFRESH_TOKEN_SECRET)
 
-	authService := application.NewAuthService(authRepo, userService, jwtService)
+	twoFactorRepo := persistence.NewTwoFactorRepository(db)
+
+	loginGuard := application.NewLoginGuard(persistence.NewLoginThrottleRepository(db))
+
+	twoFactorService := application.NewTwoFactorService(twoFactorRepo, userService, loginGuard)
+
+	twoFactorHandler := web.NewTwoFactorHandler(twoFactorService, *validator)
+
+	apiKeyService := application.NewAPIKeyService(persistence.NewAPIKeyRepository(db))
+
+	apiKeyHandler := web.NewAPIKeyHandler(apiKeyService, *validator)
+
+	authService := application.NewAuthService(authRepo, userTokenRepo, userService, jwtService, twoFactorService, loginGuard, mailer, cfg.AppURL)
 
 	authHandler := web.NewAuthHandler(authService, *validator)
 
-	pollHandler := web.NewPollHandler(pollService, *validator)
+	accountHandler := web.NewAccountHandler(userService, authService, *validator)
+
+	// exports are kept apart from public uploads and only served through
+	// their download links
+	exportStorage := storage.NewLocalStorage(cfg.ExportDir, "")
+
+	dataExportService := application.NewDataExportService(persistence.NewDataExportRepository(db), userRepo, pollRepo, voteRepo, authRepo, persistence.NewAPIKeyRepository(db), persistence.NewIdentityRepository(db), organizationRepo, persistence.NewWebhookRepository(db), persistence.NewNotificationRepository(db), exportStorage, mailer, cfg.PublicURL+"/api/v1")
+
+	dataExportHandler := web.NewDataExportHandler(dataExportService)
+
+	identityRepo := persistence.NewIdentityRepository(db)
+
+	var identityProviders []application.IdentityProvider
+
+	for _, providerConfig := range cfg.OIDCProviders {
+		identityProviders = append(identityProviders, oidc.NewProvider(providerConfig))
+	}
+
+	oidcService := application.NewOIDCService(identityRepo, userService, authService, identityProviders...)
+
+	oidcHandler := web.NewOIDCHandler(oidcService, *validator)
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := dataExportService.PurgeExpired(context.Background()); err != nil {
+				fmt.Println("error purging expired data exports", err.Error())
+			}
+			if err := oidcService.PurgeExpiredLoginStates(context.Background()); err != nil {
+				fmt.Println("error purging expired login states", err.Error())
+			}
+		}
+	}()
+
+	webhookService := application.NewWebhookService(persistence.NewWebhookRepository(db), persistence.NewWebhookDeliveryRepository(db), pollRepo, pollPolicy, webhook.NewHTTPSender(10*time.Second))
+
+	webhookHandler := web.NewWebhookHandler(webhookService, *validator)
+
+	// deliveries are queued in the database, so the workers pick up where
+	// they left off after a restart
+	go func() {
+		for range time.Tick(5 * time.Second) {
+			if err := webhookService.DeliverDue(context.Background()); err != nil {
+				fmt.Println("error delivering webhooks", err.Error())
+			}
+		}
+	}()
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := webhookService.PurgeDeliveries(context.Background()); err != nil {
+				fmt.Println("error purging webhook deliveries", err.Error())
+			}
+		}
+	}()
 
 	broker := utils.NewBroker()
 	broker.Start()
 
-	voteservice := application.NewVoteService(voteRepo, pollRepo, optionRepo)
+	// the outbox hands an event to one instance, which passes it on to the
+	// streams open in every instance
+	streamPublisher := stream.NewPostgresPublisher(db)
+	go stream.Listen(context.Background(), db, broker)
+
+	notificationService := application.NewNotificationService(persistence.NewNotificationRepository(db), pollRepo, voteRepo, pollInviteRepo, userRepo, mailer, streamPublisher, cfg.AppURL)
+
+	notificationHandler := web.NewNotificationHandler(notificationService, *validator)
+
+	outboxRelay := application.NewOutboxRelay(persistence.NewOutboxRepository(db), map[domain.OutboxConsumer]application.OutboxHandler{
+		domain.OutboxConsumerBroker:        application.NewBrokerOutboxHandler(streamPublisher),
+		domain.OutboxConsumerWebhooks:      webhookService,
+		domain.OutboxConsumerNotifications: notificationService,
+	})
+
+	// domain events are committed with the change that caused them and
+	// handed to each consumer from here, so none is lost when a request or
+	// the server dies half way. A consumer may be handed one twice, which
+	// it makes have no further effect.
+	go func() {
+		for range time.Tick(time.Second) {
+			if err := outboxRelay.Relay(context.Background()); err != nil {
+				fmt.Println("error relaying outbox events", err.Error())
+			}
+		}
+	}()
+
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := pollService.CloseExpiredPolls(context.Background()); err != nil {
+				fmt.Println("error closing expired polls", err.Error())
+			}
+		}
+	}()
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := outboxRelay.Purge(context.Background()); err != nil {
+				fmt.Println("error purging outbox events", err.Error())
+			}
+		}
+	}()
+
+	// emails are queued like webhook deliveries so they survive a restart
+	go func() {
+		for range time.Tick(10 * time.Second) {
+			if err := notificationService.SendDue(context.Background()); err != nil {
+				fmt.Println("error sending notification emails", err.Error())
+			}
+		}
+	}()
+
+	go func() {
+		for range time.Tick(5 * time.Minute) {
+			if err := notificationService.RemindExpiring(context.Background()); err != nil {
+				fmt.Println("error recording expiring polls", err.Error())
+			}
+			if err := notificationService.SendDigests(context.Background()); err != nil {
+				fmt.Println("error sending notification digests", err.Error())
+			}
+		}
+	}()
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := notificationService.Purge(context.Background()); err != nil {
+				fmt.Println("error purging notifications", err.Error())
+			}
+		}
+	}()
+
+	pollHandler := web.NewPollHandler(pollService, *validator)
+
+	chartRenderer, err := chart.NewRenderer()
+	if err != nil {
+		log.Fatal("Error loading chart renderer", err.Error())
+	}
+
+	pollChartHandler := web.NewPollChartHandler(pollService, chartRenderer)
+
+	embedHandler := web.NewEmbedHandler(pollService, application.NewEmbedService(pollRepo, cfg.AppURL, cfg.PublicURL), cfg.PublicURL, cfg.AppURL, cfg.EmbedFrameAncestors)
+
+	pollPreviewHandler := web.NewPollPreviewHandler(pollService, chartRenderer, cfg.PublicURL, cfg.AppURL)
+
+	pollCollaboratorHandler := web.NewPollCollaboratorHandler(application.NewPollCollaboratorService(pollPermissionRepo, pollRepo, userService, pollPolicy), *validator)
+
+	pollInviteHandler := web.NewPollInviteHandler(application.NewPollInviteService(pollInviteRepo, pollRepo, voteRepo, userRepo, pollPolicy), *validator)
+
+	voteIngester := application.NewVoteIngester(voteRepo, pollInviteRepo, unitOfWork)
+
+	// votes are written in batches by these workers, so a crowd voting at
+	// once costs a handful of transactions
+	go voteIngester.Run(context.Background())
+
+	voteservice := application.NewVoteService(voteIngester, pollRepo, optionRepo, pollPolicy, pollInviteRepo, userRepo)
 
 	voteHandler := web.NewVoteHandler(voteservice)
 
@@ -100,40 +313,213 @@ func New(cfg *config.Config) (*App, error) {
 
 	apiRouter.Post("/auth/login", authHandler.LoginUser)
 
+	apiRouter.Post("/auth/login/mfa", authHandler.LoginMFA)
+
 	apiRouter.Post("/auth/refresh", authHandler.RefreshToken)
 
-	// user routers
+	apiRouter.Post("/auth/verify-email", authHandler.VerifyEmail)
+
+	apiRouter.Post("/auth/verify-email/resend", authHandler.ResendVerification)
+
+	apiRouter.Post("/auth/password-reset", authHandler.RequestPasswordReset)
+
+	apiRouter.Post("/auth/password-reset/confirm", authHandler.ResetPassword)
 
-	userRouter := apiRouter.Group("/user", func(c fiber.Ctx) error {
+	apiRouter.Post("/auth/email-change/confirm", accountHandler.ConfirmEmailChange)
+
+	apiRouter.Get("/exports/:exportID/download", dataExportHandler.Download)
+
+	apiRouter.Get("/auth/oidc/:provider/login", oidcHandler.StartLogin)
+
+	apiRouter.Post("/auth/oidc/:provider/callback", oidcHandler.Callback)
+
+	apiRouter.Get("/oembed", embedHandler.OEmbed)
+
+	// two-factor routers
+
+	twoFactorRouter := apiRouter.Group("/auth/2fa", func(c fiber.Ctx) error {
 		return middleware.JWTMiddleware(c, jwtService)
 	})
 
-	userRouter.Get("/:userID", userHandler.GetUser)
+	twoFactorRouter.Post("/enroll", twoFactorHandler.Enroll)
 
-	// poll routers
+	twoFactorRouter.Post("/confirm", twoFactorHandler.Confirm)
+
+	twoFactorRouter.Post("/disable", twoFactorHandler.Disable)
+
+	twoFactorRouter.Post("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+	// api key routers, JWT only so a key can never mint or revoke keys
+
+	apiKeyRouter := apiRouter.Group("/api-keys", func(c fiber.Ctx) error {
+		return middleware.JWTMiddleware(c, jwtService)
+	})
+
+	apiKeyRouter.Post("/", apiKeyHandler.CreateKey)
+
+	apiKeyRouter.Get("/", apiKeyHandler.GetKeys)
+
+	apiKeyRouter.Delete("/:keyID", apiKeyHandler.RevokeKey)
+
+	// account routers, JWT only so a leaked API key cannot take over the account
 
-	pollRouter := apiRouter.Group("/poll", func(c fiber.Ctx) error {
+	accountRouter := apiRouter.Group("/account", func(c fiber.Ctx) error {
 		return middleware.JWTMiddleware(c, jwtService)
 	})
 
-	pollRouter.Post("/create", pollHandler.CreatePoll)
+	accountRouter.Get("/", accountHandler.GetAccount)
 
-	pollRouter.Post("/:pollID", pollHandler.DeletePoll)
+	accountRouter.Put("/username", accountHandler.UpdateUsername)
 
-	pollRouter.Get("/all", pollHandler.GetAllPolls)
+	accountRouter.Put("/email", accountHandler.ChangeEmail)
 
-	pollRouter.Get("/view/:pollID", pollHandler.GetPollView)
+	accountRouter.Put("/password", accountHandler.ChangePassword)
 
-	pollRouter.Get("/:pollID", pollHandler.GetPoll)
+	accountRouter.Put("/avatar", accountHandler.UploadProfilePicture)
+
+	accountRouter.Delete("/", accountHandler.DeleteAccount)
+
+	accountRouter.Get("/notification-preferences", notificationHandler.GetPreferences)
+
+	accountRouter.Put("/notification-preferences", notificationHandler.UpdatePreferences)
+
+	accountRouter.Post("/exports", dataExportHandler.RequestExport)
+
+	accountRouter.Get("/exports/:exportID", dataExportHandler.GetExport)
+
+	// organization routers, membership is managed with a signed in session
+
+	organizationRouter := apiRouter.Group("/organizations", func(c fiber.Ctx) error {
+		return middleware.JWTMiddleware(c, jwtService)
+	})
+
+	organizationRouter.Post("/", organizationHandler.CreateOrganization)
+
+	organizationRouter.Get("/", organizationHandler.GetOrganizations)
+
+	organizationRouter.Get("/:organizationID", organizationHandler.GetOrganization)
+
+	organizationRouter.Post("/:organizationID/members", organizationHandler.AddMember)
+
+	organizationRouter.Put("/:organizationID/members/:userID", organizationHandler.UpdateMemberRole)
+
+	organizationRouter.Delete("/:organizationID/members/:userID", organizationHandler.RemoveMember)
+
+	authMiddleware := func(c fiber.Ctx) error {
+		return middleware.AuthMiddleware(c, jwtService, apiKeyService)
+	}
+
+	// user routers
+
+	userRouter := apiRouter.Group("/user", authMiddleware)
+
+	userRouter.Get("/:userID", userHandler.GetUser)
+
+	// poll routers
+
+	pollRouter := apiRouter.Group("/poll", authMiddleware)
+
+	pollRouter.Post("/create", middleware.RequireScope(domain.ScopePollsWrite), pollHandler.CreatePoll)
+
+	pollRouter.Post("/:pollID", middleware.RequireScope(domain.ScopePollsWrite), pollHandler.DeletePoll)
+
+	pollRouter.Get("/all", middleware.RequireScope(domain.ScopePollsRead), pollHandler.GetAllPolls)
+
+	pollRouter.Get("/organization/:organizationID", middleware.RequireScope(domain.ScopePollsRead), pollHandler.GetOrganizationPolls)
+
+	pollRouter.Get("/view/:pollID", middleware.RequireScope(domain.ScopeVotesRead), pollHandler.GetPollView)
+
+	pollRouter.Get("/:pollID", middleware.RequireScope(domain.ScopePollsRead), pollHandler.GetPoll)
+
+	pollRouter.Get("/:pollID/export", middleware.RequireScope(domain.ScopeVotesRead), pollHandler.ExportResults)
+
+	pollRouter.Get("/:pollID/votes", middleware.RequireScope(domain.ScopeVotesRead), pollHandler.GetPollVotes)
+
+	pollRouter.Post("/:pollID/invites", middleware.RequireScope(domain.ScopePollsWrite), pollInviteHandler.CreateInvites)
+
+	pollRouter.Get("/:pollID/invites", middleware.RequireScope(domain.ScopePollsRead), pollInviteHandler.GetInvites)
+
+	pollRouter.Delete("/:pollID/invites/:inviteID", middleware.RequireScope(domain.ScopePollsWrite), pollInviteHandler.RevokeInvite)
+
+	pollRouter.Post("/:pollID/collaborators", middleware.RequireScope(domain.ScopePollsWrite), pollCollaboratorHandler.AddCollaborator)
+
+	pollRouter.Get("/:pollID/collaborators", middleware.RequireScope(domain.ScopePollsRead), pollCollaboratorHandler.GetCollaborators)
+
+	pollRouter.Put("/:pollID/collaborators/:userID", middleware.RequireScope(domain.ScopePollsWrite), pollCollaboratorHandler.UpdateCollaboratorRole)
+
+	pollRouter.Delete("/:pollID/collaborators/:userID", middleware.RequireScope(domain.ScopePollsWrite), pollCollaboratorHandler.RemoveCollaborator)
+
+	// webhook routers
+
+	webhookRouter := apiRouter.Group("/webhooks", authMiddleware)
+
+	webhookRouter.Post("/", middleware.RequireScope(domain.ScopePollsWrite), webhookHandler.CreateWebhook)
+
+	webhookRouter.Get("/", middleware.RequireScope(domain.ScopePollsRead), webhookHandler.GetWebhooks)
+
+	webhookRouter.Delete("/:webhookID", middleware.RequireScope(domain.ScopePollsWrite), webhookHandler.DeleteWebhook)
+
+	webhookRouter.Get("/:webhookID/deliveries", middleware.RequireScope(domain.ScopePollsRead), webhookHandler.GetDeliveries)
+
+	webhookRouter.Post("/:webhookID/deliveries/:deliveryID/redeliver", middleware.RequireScope(domain.ScopePollsWrite), webhookHandler.Redeliver)
 
 	// vote routers
-	voteRouter := apiRouter.Group("/vote", func(c fiber.Ctx) error {
+	voteRouter := apiRouter.Group("/vote", authMiddleware)
+
+	voteRouter.Post("/", middleware.RequireScope(domain.ScopeVotesWrite), voteHandler.VotePoll)
+
+	apiRouter.Get("/chart/:pollID", middleware.QueryToken, authMiddleware, middleware.RequireScope(domain.ScopeVotesRead), pollChartHandler.GetChart)
+
+	apiRouter.Get("/sse/:pollID", middleware.QueryToken, authMiddleware, middleware.RequireScope(domain.ScopeVotesRead), pollHandler.StreamResults(broker))
+
+	// the stream comes before the group so EventSource clients, which cannot
+	// set headers, can pass their token in the query
+	apiRouter.Get("/notifications/stream", middleware.QueryToken, func(c fiber.Ctx) error {
+		return middleware.JWTMiddleware(c, jwtService)
+	}, notificationHandler.Stream(broker))
+
+	notificationRouter := apiRouter.Group("/notifications", func(c fiber.Ctx) error {
 		return middleware.JWTMiddleware(c, jwtService)
 	})
 
-	voteRouter.Post("/", voteHandler.VotePoll(*broker))
+	notificationRouter.Get("/", notificationHandler.GetNotifications)
+
+	notificationRouter.Get("/unread-count", notificationHandler.CountUnread)
+
+	notificationRouter.Post("/read", notificationHandler.MarkAllRead)
+
+	notificationRouter.Post("/:notificationID/read", notificationHandler.MarkRead)
+
+	apiRouter.Get("/embed/polls/:pollID", authMiddleware, middleware.RequireScope(domain.ScopePollsRead), embedHandler.GetWidgetPoll)
+
+	// slack routers, authenticated by Slack's request signature
+
+	if cfg.SlackSigningSecret != "" {
+
+		slackService := application.NewSlackService(identityRepo, pollRepo, userService, pollService, voteservice, slack.NewResponder(5*time.Second), cfg.SlackSigningSecret, cfg.AppURL)
+
+		slackHandler := web.NewSlackHandler(slackService)
+
+		slackRouter := apiRouter.Group("/slack", slackHandler.VerifySignature)
+
+		slackRouter.Post("/commands", slackHandler.Command)
+
+		slackRouter.Post("/interactions", slackHandler.Interaction)
+	}
+
+	app.Router.Get("/uploads/*", static.New(cfg.UploadDir))
+
+	// embeddable poll widget, framed by other sites
+
+	app.Router.Get("/embed/assets/*", static.New("", static.Config{FS: web.EmbedAssets}))
+
+	app.Router.Get("/embed/polls/:pollID", embedHandler.GetWidget)
+
+	// shared poll links, unfurled by chat apps
+
+	app.Router.Get("/polls/:pollID", pollPreviewHandler.GetPreview)
 
-	apiRouter.Get("/sse", web.SseHandler(broker))
+	app.Router.Get("/polls/:pollID/preview.png", pollPreviewHandler.GetPreviewImage)
 
 	return app, err
 }
@@ -43,12 +43,12 @@ export function PollCard({ id: pollId, title, options, createdAt, expires_at, cr
     }
   })
 
-  const totalVotes = options.reduce((sum: number, opt: any) => sum + opt.votes.length, 0)
+  const totalVotes = options.reduce((sum: number, opt: any) => sum + opt.votes, 0)
   const isExpired = expires_at ? new Date(expires_at).getTime() < Date.now() : false
 
   const optionsWithPercentage = options.map(opt => ({
     ...opt,
-    percentage: totalVotes > 0 ? Math.round((opt.votes.length / totalVotes) * 100) : 0
+    percentage: totalVotes > 0 ? Math.round((opt.votes / totalVotes) * 100) : 0
   }))
 
   return (
@@ -100,12 +100,19 @@ export interface Vote {
   id: string
   user_id: string
   poll_id: string
+  option_id: string
+  voted_at: string
 }
 
 export interface PollOption {
   id: string
   name: string
+  votes: number
+}
+
+export interface PollVotesResponse {
   votes: Array<Vote>
+  next_cursor?: string
 }
 
 export interface PollViewResponse {
@@ -116,6 +123,7 @@ export interface PollViewResponse {
   expires_at: string
   creator_id: string
   voted: boolean
+  voters_visible: boolean
 }
 
 export interface VoteRequest {
@@ -171,6 +179,10 @@ export const userAPI = {
 
 // Poll APIs
 export const pollAPI = {
+  // shareLink points at the backend, which shows link previews to chat
+  // apps and sends everyone else on to the poll
+  shareLink: (pollId: string) => `${api.defaults.baseURL}/polls/${pollId}`,
+
   createPoll: async (data: CreatePollRequest) => {
     const response = await api.post<{ message: string }>(
       '/api/v1/poll/create',
@@ -193,6 +205,14 @@ export const pollAPI = {
     return response.data
   },
 
+  getPollVotes: async (pollId: string, cursor?: string) => {
+    const response = await api.get<PollVotesResponse>(
+      `/api/v1/poll/${pollId}/votes`,
+      { params: cursor ? { cursor } : undefined },
+    )
+    return response.data
+  },
+
   getAllPolls: async () => {
     const response = await api.get<{
       message: string
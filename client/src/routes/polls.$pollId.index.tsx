@@ -49,7 +49,7 @@ function PollDetailsPage() {
   })()
 
   const handleShare = () => {
-    navigator.clipboard.writeText(window.location.href)
+    navigator.clipboard.writeText(pollAPI.shareLink(pollId))
     toast.success('Link copied to clipboard!')
   }
 
@@ -48,12 +48,15 @@ function PollResultsPage() {
 
   const isCreator = poll?.creator_id === currentUserId
 
-  // SSE for real-time updates (accessible to creator)
+  // SSE for real-time updates (accessible to whoever may see the results)
   useEffect(() => {
-    if (!isCreator) return
+    if (!poll) return
+
+    const token = localStorage.getItem('access_token')
+    if (!token) return
 
     const backendUrl = import.meta.env.VITE_BACKEND_URL || 'http://localhost:9000'
-    const eventSource = new EventSource(`${backendUrl}/api/v1/sse`)
+    const eventSource = new EventSource(`${backendUrl}/api/v1/sse/${pollId}?access_token=${encodeURIComponent(token)}`)
 
     eventSource.onmessage = (event) => {
       try {
@@ -69,10 +72,10 @@ function PollResultsPage() {
     return () => {
       eventSource.close()
     }
-  }, [pollId, queryClient, isCreator])
+  }, [pollId, queryClient, !!poll])
 
   const handleShare = () => {
-    navigator.clipboard.writeText(window.location.origin + `/polls/${pollId}`)
+    navigator.clipboard.writeText(pollAPI.shareLink(pollId))
     toast.success('Voting link copied to clipboard!')
   }
 
@@ -94,11 +97,11 @@ function PollResultsPage() {
         <div className="bg-card p-10 text-center max-w-md w-full relative overflow-hidden">
 
           <h1 className="text-3xl font-black mb-3 tracking-tight">
-            {isUnauthorised ? 'Creator Access Only' : 'Poll Not Found'}
+            {isUnauthorised ? 'Results Not Available' : 'Poll Not Found'}
           </h1>
           <p className="text-muted-foreground mb-10 leading-relaxed font-medium">
             {isUnauthorised
-              ? "This live view is strictly for the poll creator. Please return to the public voting page to participate."
+              ? "The creator has not made these results visible to you yet. Please return to the public voting page to participate."
               : "Oops! The poll you're looking for might have expired, been deleted, or never existed."}
           </p>
           <div className="space-y-3">
@@ -115,8 +118,8 @@ function PollResultsPage() {
     )
   }
 
-  const totalVotes = poll.options.reduce((sum, opt) => sum + opt.votes.length, 0)
-  const maxVotes = Math.max(...poll.options.map(o => o.votes.length))
+  const totalVotes = poll.options.reduce((sum, opt) => sum + opt.votes, 0)
+  const maxVotes = Math.max(...poll.options.map(o => o.votes))
   const isPollActive = new Date(poll.expires_at).getTime() > Date.now()
 
   return (
@@ -134,16 +137,18 @@ function PollResultsPage() {
             <span className="font-bold tracking-tight">Dashboard</span>
           </button>
 
-          <div className="flex gap-4">
-            <Button
-              variant="destructive"
-              size="lg"
-              onClick={() => setShowDeleteConfirm(true)}
-              className="rounded-2xl px-6 group"
-            >
-              <Trash2 className="w-4 h-4 group-hover:animate-bounce" />
-            </Button>
-          </div>
+          {isCreator && (
+            <div className="flex gap-4">
+              <Button
+                variant="destructive"
+                size="lg"
+                onClick={() => setShowDeleteConfirm(true)}
+                className="rounded-2xl px-6 group"
+              >
+                <Trash2 className="w-4 h-4 group-hover:animate-bounce" />
+              </Button>
+            </div>
+          )}
         </div>
 
         {/* Delete Confirmation Overlay */}
@@ -207,9 +212,9 @@ function PollResultsPage() {
             <div className="grid gap-6">
               {poll.options.map((option) => {
                 const percentage = totalVotes > 0
-                  ? Math.round((option.votes.length / totalVotes) * 100)
+                  ? Math.round((option.votes / totalVotes) * 100)
                   : 0
-                const isWinner = maxVotes > 0 && option.votes.length === maxVotes
+                const isWinner = maxVotes > 0 && option.votes === maxVotes
 
                 return (
                   <div
@@ -240,7 +245,7 @@ function PollResultsPage() {
                         </div>
                         <div className="flex items-center gap-2">
                            <span className="text-sm font-bold text-muted-foreground uppercase tracking-widest">
-                             {option.votes.length.toLocaleString()} votes
+                             {option.votes.toLocaleString()} votes
                            </span>
                         </div>
                       </div>
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/winnerx0/jille/config"
+	"github.com/winnerx0/jille/infra/database"
+	"github.com/winnerx0/jille/infra/persistence"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const usage = `usage: loadtest [flags]
+
+Casts votes in a new poll through the same vote pipeline as the server, as
+many voters at once as -concurrency, then checks that every voter counted
+once and that the option counts match the votes. It creates its own users
+and poll and removes them afterwards, so point it at a local database
+(DB_* as for the server) that no server is relaying events from.
+
+flags:`
+
+// result is the outcome of a single vote.
+type result struct {
+	latency time.Duration
+	err     error
+}
+
+func main() {
+
+	godotenv.Load(".env")
+
+	voters := flag.Int("voters", 10000, "number of voters")
+	concurrency := flag.Int("concurrency", 1000, "voters voting at the same time")
+	options := flag.Int("options", 4, "options in the poll")
+	repeats := flag.Float64("repeat", 0.05, "share of voters who vote a second time")
+	keep := flag.Bool("keep", false, "keep the poll and its voters afterwards")
+
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *voters < 1 || *concurrency < 1 || *options < 2 || *repeats < 0 || *repeats > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*voters, *concurrency, *options, *repeats, *keep); err != nil {
+		fmt.Println("FAIL:", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println("ok: every voter counted once and the option counts match the votes")
+}
+
+func run(voters int, concurrency int, options int, repeats float64, keep bool) error {
+
+	dbConfig, err := config.LoadDBConfig()
+
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	db, err := dbConfig.New()
+
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	// slow queries are expected under load, the report covers them
+	db.Logger = logger.Discard
+
+	migrator, err := database.NewMigrator(db)
+
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	if err := migrator.CheckCurrent(ctx); err != nil {
+		return fmt.Errorf("%w - run the migrate command first", err)
+	}
+
+	poll, voterIDs, err := seed(ctx, db, voters, options)
+
+	if err != nil {
+		return fmt.Errorf("creating the poll: %w", err)
+	}
+
+	if !keep {
+		defer cleanUp(db, poll.ID)
+	}
+
+	fmt.Printf("poll %s: %d voters, %d options\n", poll.ID, len(voterIDs), len(poll.Options))
+
+	voteRepo := persistence.NewVoteRepository(db)
+	pollInviteRepo := persistence.NewPollInviteRepository(db)
+	pollPolicy := application.NewPollPolicy(persistence.NewOrganizationRepository(db), persistence.NewPollPermissionRepository(db), voteRepo)
+
+	ingesterCtx, stopIngester := context.WithCancel(ctx)
+	defer stopIngester()
+
+	voteIngester := application.NewVoteIngester(voteRepo, pollInviteRepo, persistence.NewUnitOfWork(db))
+	go voteIngester.Run(ingesterCtx)
+
+	voteService := application.NewVoteService(voteIngester, persistence.NewPollRepository(db), persistence.NewOptionRepository(db), pollPolicy, pollInviteRepo, persistence.NewUserReposiory(db))
+
+	// every voter votes once, and some of them try again, in no particular
+	// order
+	requests := make([]dto.VoteRequest, 0, len(voterIDs))
+	userIDs := make([]uuid.UUID, 0, len(voterIDs))
+
+	for i, voterID := range voterIDs {
+		requests = append(requests, dto.VoteRequest{PollID: poll.ID.String(), OptionID: poll.Options[i%len(poll.Options)].ID.String()})
+		userIDs = append(userIDs, voterID)
+	}
+
+	for i := range int(float64(len(voterIDs)) * repeats) {
+		requests = append(requests, dto.VoteRequest{PollID: poll.ID.String(), OptionID: poll.Options[(i+1)%len(poll.Options)].ID.String()})
+		userIDs = append(userIDs, voterIDs[i])
+	}
+
+	order := rand.Perm(len(requests))
+	results := make([]result, len(requests))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+
+	started := time.Now()
+
+	for range concurrency {
+		wg.Go(func() {
+			for i := range next {
+				voteCtx := context.WithValue(ctx, "userID", userIDs[i].String())
+				voted := time.Now()
+				_, err := voteService.VotePoll(voteCtx, requests[i])
+				results[i] = result{latency: time.Since(voted), err: err}
+			}
+		})
+	}
+
+	for _, i := range order {
+		next <- i
+	}
+
+	close(next)
+	wg.Wait()
+
+	report(results, time.Since(started))
+
+	return verify(ctx, db, poll.ID, len(voterIDs))
+}
+
+// seed creates the voters, and a poll run by one more user.
+func seed(ctx context.Context, db *gorm.DB, voters int, options int) (*domain.Poll, []uuid.UUID, error) {
+
+	pollID := uuid.New()
+	prefix := "loadtest-" + pollID.String() + "-"
+
+	err := db.WithContext(ctx).Exec(`
+		INSERT INTO users (id, username, email, password, joined_at, created_at, updated_at)
+		SELECT gen_random_uuid(), ? || g, ? || g || '@example.com', '', now(), now(), now()
+		FROM generate_series(0, ?) AS g`, prefix, prefix, voters).Error
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var users []domain.User
+
+	if err := db.WithContext(ctx).Select("id", "email").Where("email LIKE ?", prefix+"%").Order("email").Find(&users).Error; err != nil {
+		return nil, nil, err
+	}
+
+	poll := &domain.Poll{
+		ID:                pollID,
+		Title:             "Load test",
+		UserID:            users[0].ID,
+		ExpiresAt:         time.Now().Add(time.Hour),
+		ResultsVisibility: domain.ResultsVisibilityAlways,
+	}
+
+	for i := range options {
+		poll.Options = append(poll.Options, domain.Option{ID: uuid.New(), Name: fmt.Sprintf("Option %d", i+1)})
+	}
+
+	if err := db.WithContext(ctx).Create(poll).Error; err != nil {
+		return nil, nil, err
+	}
+
+	voterIDs := make([]uuid.UUID, 0, voters)
+
+	for _, user := range users[1:] {
+		voterIDs = append(voterIDs, user.ID)
+	}
+
+	return poll, voterIDs, nil
+}
+
+func report(results []result, elapsed time.Duration) {
+
+	latencies := make([]time.Duration, len(results))
+	counted, repeated := 0, 0
+	failures := map[string]int{}
+
+	for i, result := range results {
+
+		latencies[i] = result.latency
+
+		switch {
+		case result.err == nil:
+			counted++
+		case errors.Is(result.err, utils.VoteAlreadyExistsError):
+			repeated++
+		default:
+			failures[result.err.Error()]++
+		}
+	}
+
+	slices.Sort(latencies)
+
+	percentile := func(p float64) time.Duration {
+		return latencies[int(float64(len(latencies)-1)*p)]
+	}
+
+	fmt.Printf("%d votes in %s: %.0f votes/s\n", len(results), elapsed.Round(time.Millisecond), float64(len(results))/elapsed.Seconds())
+	fmt.Printf("latency p50 %s, p95 %s, p99 %s, max %s\n", percentile(0.5), percentile(0.95), percentile(0.99), latencies[len(latencies)-1])
+	fmt.Printf("counted %d, turned away as repeats %d\n", counted, repeated)
+
+	for message, count := range failures {
+		fmt.Printf("failed %d: %s\n", count, message)
+	}
+}
+
+// verify checks the poll's votes against its voters and its option counts.
+func verify(ctx context.Context, db *gorm.DB, pollID uuid.UUID, voters int) error {
+
+	var votes struct {
+		Votes  int
+		Voters int
+	}
+
+	err := db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) AS votes, COUNT(DISTINCT user_id) AS voters
+		FROM votes
+		WHERE poll_id = ? AND deleted_at IS NULL`, pollID).Scan(&votes).Error
+
+	if err != nil {
+		return err
+	}
+
+	if votes.Votes != voters || votes.Voters != voters {
+		return fmt.Errorf("%d votes from %d voters, want one from each of %d", votes.Votes, votes.Voters, voters)
+	}
+
+	var mismatched []struct {
+		Name      string
+		VoteCount int
+		Votes     int
+	}
+
+	err = db.WithContext(ctx).Raw(`
+		SELECT options.name, options.vote_count, COUNT(votes.id) AS votes
+		FROM options
+		LEFT JOIN votes ON votes.option_id = options.id AND votes.deleted_at IS NULL
+		WHERE options.poll_id = ?
+		GROUP BY options.id
+		HAVING options.vote_count <> COUNT(votes.id)`, pollID).Scan(&mismatched).Error
+
+	if err != nil {
+		return err
+	}
+
+	if len(mismatched) > 0 {
+		option := mismatched[0]
+		return fmt.Errorf("%s counts %d votes but has %d", option.Name, option.VoteCount, option.Votes)
+	}
+
+	return nil
+}
+
+// cleanUp removes the poll, with its votes and events, and its voters.
+func cleanUp(db *gorm.DB, pollID uuid.UUID) {
+
+	prefix := "loadtest-" + pollID.String() + "-"
+
+	if err := db.Exec("DELETE FROM outbox_events WHERE poll_id = ?", pollID).Error; err != nil {
+		fmt.Println("error removing the poll's events", err.Error())
+	}
+
+	if err := db.Exec("DELETE FROM polls WHERE id = ?", pollID).Error; err != nil {
+		fmt.Println("error removing the poll", err.Error())
+	}
+
+	if err := db.Exec("DELETE FROM users WHERE email LIKE ?", prefix+"%").Error; err != nil {
+		fmt.Println("error removing the voters", err.Error())
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/winnerx0/jille/config"
+	"github.com/winnerx0/jille/infra/database"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up        apply all pending migrations
+  down [n]  revert the last n applied migrations (default 1)
+  status    list migrations and when they were applied`
+
+func main() {
+
+	godotenv.Load(".env")
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	dbConfig, err := config.LoadDBConfig()
+
+	if err != nil {
+		log.Fatal("Failed to load config ", err.Error())
+	}
+
+	db, err := dbConfig.New()
+
+	if err != nil {
+		log.Fatal("Error connecting to database ", err.Error())
+	}
+
+	migrator, err := database.NewMigrator(db)
+
+	if err != nil {
+		log.Fatal("Error loading migrations ", err.Error())
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+
+	case "up":
+
+		applied, err := migrator.Up(ctx)
+
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+
+	case "down":
+
+		steps := 1
+
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+
+			if err != nil || steps < 1 {
+				log.Fatal("down takes a positive number of migrations to revert")
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+	case "status":
+
+		statuses, err := migrator.Status(ctx)
+
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+
+		for _, status := range statuses {
+
+			appliedAt := "pending"
+
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		w.Flush()
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
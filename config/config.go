@@ -3,8 +3,11 @@ package config
 import (
 	"errors"
 	"os"
+	"strings"
 
 	"github.com/winnerx0/jille/infra/database"
+	"github.com/winnerx0/jille/infra/mail"
+	"github.com/winnerx0/jille/infra/oidc"
 )
 
 type Config struct {
@@ -12,6 +15,17 @@ type Config struct {
 	JWT_ACCESS_TOKEN_SECRET  string
 	JWT_REFRESH_TOKEN_SECRET string
 	DBConfig                 database.DBConfig
+	MailConfig               mail.MailConfig
+	AppURL                   string
+	PublicURL                string
+	UploadDir                string
+	ExportDir                string
+	EmbedFrameAncestors      string
+	TrustedProxies           []string
+	ProxyHeader              string
+	SlackSigningSecret       string
+	RequireCurrentSchema     bool
+	OIDCProviders            []oidc.ProviderConfig
 }
 
 func Load() (*Config, error) {
@@ -34,6 +48,147 @@ func Load() (*Config, error) {
 		return nil, errors.New("JWT Refresh Token Secret Required")
 	}
 
+	dbConfig, err := LoadDBConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// refuse to start until pending migrations have been applied, rather
+	// than only warning about them
+	requireCurrentSchema := os.Getenv("DB_REQUIRE_CURRENT_SCHEMA") == "true"
+
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+
+	uploadDir := os.Getenv("UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "uploads"
+	}
+
+	exportDir := os.Getenv("EXPORT_DIR")
+	if exportDir == "" {
+		exportDir = "exports"
+	}
+
+	// sites allowed to put the poll widget in an iframe, as CSP
+	// frame-ancestors sources
+	embedFrameAncestors := os.Getenv("EMBED_FRAME_ANCESTORS")
+	if embedFrameAncestors == "" {
+		embedFrameAncestors = "*"
+	}
+
+	// addresses or CIDR ranges of the reverse proxies in front of the
+	// server; only requests from them may set the client IP in ProxyHeader
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
+	proxyHeader := os.Getenv("PROXY_HEADER")
+	if proxyHeader == "" {
+		proxyHeader = "X-Real-IP"
+	}
+
+	// the Slack integration is only served when the app's signing secret
+	// is set
+	slackSigningSecret := os.Getenv("SLACK_SIGNING_SECRET")
+
+	mailDriver := os.Getenv("MAIL_DRIVER")
+	if mailDriver == "" {
+		mailDriver = "log"
+	}
+
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "no-reply@jille.app"
+	}
+
+	smtpHost := os.Getenv("SMTP_HOST")
+	if mailDriver == "smtp" && smtpHost == "" {
+		return nil, errors.New("SMTP Host Required")
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort == "" {
+		smtpPort = "587"
+	}
+
+	mailLogFile := os.Getenv("MAIL_LOG_FILE")
+	if mailLogFile == "" {
+		mailLogFile = "mail.log"
+	}
+
+	var oidcProviders []oidc.ProviderConfig
+
+	if issuerURL := os.Getenv("OIDC_ISSUER_URL"); issuerURL != "" {
+
+		clientID := os.Getenv("OIDC_CLIENT_ID")
+		if clientID == "" {
+			return nil, errors.New("OIDC Client ID Required")
+		}
+
+		providerName := os.Getenv("OIDC_PROVIDER_NAME")
+		if providerName == "" {
+			providerName = "oidc"
+		}
+
+		redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+		if redirectURL == "" {
+			redirectURL = appURL + "/auth/oidc/" + providerName + "/callback"
+		}
+
+		oidcProviders = append(oidcProviders, oidc.ProviderConfig{
+			Name:         providerName,
+			IssuerURL:    issuerURL,
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  redirectURL,
+		})
+	}
+
+	cfg := &Config{
+		Port:                     port,
+		JWT_ACCESS_TOKEN_SECRET:  jwt_access_token_secret,
+		JWT_REFRESH_TOKEN_SECRET: jwt_refresh_token_secret,
+		DBConfig:                 *dbConfig,
+		MailConfig: mail.MailConfig{
+			Driver:   mailDriver,
+			Host:     smtpHost,
+			Port:     smtpPort,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     mailFrom,
+			LogFile:  mailLogFile,
+		},
+		AppURL:        appURL,
+		PublicURL:     publicURL,
+		UploadDir:     uploadDir,
+		ExportDir:     exportDir,
+		OIDCProviders: oidcProviders,
+
+		EmbedFrameAncestors:  embedFrameAncestors,
+		TrustedProxies:       trustedProxies,
+		ProxyHeader:          proxyHeader,
+		SlackSigningSecret:   slackSigningSecret,
+		RequireCurrentSchema: requireCurrentSchema,
+	}
+
+	return cfg, nil
+}
+
+// LoadDBConfig reads the database settings on their own, for commands that
+// only need the database.
+func LoadDBConfig() (*database.DBConfig, error) {
+
 	db_host := os.Getenv("DB_HOST")
 	if db_host == "" {
 		return nil, errors.New("DB Host Required")
@@ -69,20 +224,13 @@ func Load() (*Config, error) {
 		timeZone = "UTC"
 	}
 
-	cfg := &Config{
-		Port:                     port,
-		JWT_ACCESS_TOKEN_SECRET:  jwt_access_token_secret,
-		JWT_REFRESH_TOKEN_SECRET: jwt_refresh_token_secret,
-		DBConfig: database.DBConfig{
-			Host:     db_host,
-			Port:     db_port,
-			User:     db_user,
-			Password: db_password,
-			Name:     db_name,
-			SSLMode:  sslMode,
-			TimeZone: timeZone,
-		},
-	}
-
-	return cfg, nil
+	return &database.DBConfig{
+		Host:     db_host,
+		Port:     db_port,
+		User:     db_user,
+		Password: db_password,
+		Name:     db_name,
+		SSLMode:  sslMode,
+		TimeZone: timeZone,
+	}, nil
 }
@@ -0,0 +1,335 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/winnerx0/jille/internal/application"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// renderer lays charts out once as shapes and then either writes them as SVG
+// or rasterizes them, so both formats look the same.
+type renderer struct {
+	font *opentype.Font
+}
+
+func NewRenderer() (application.ChartRenderer, error) {
+
+	f, err := opentype.Parse(goregular.TTF)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &renderer{
+		font: f,
+	}, nil
+}
+
+// typesetter measures and draws text for a single render. Font faces keep
+// internal buffers, so they are never shared between renders.
+type typesetter struct {
+	font  *opentype.Font
+	faces map[float64]font.Face
+}
+
+func (r *renderer) typesetter() *typesetter {
+	return &typesetter{
+		font:  r.font,
+		faces: make(map[float64]font.Face),
+	}
+}
+
+type rgb struct {
+	r, g, b uint8
+}
+
+func (c rgb) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
+
+type theme struct {
+	background rgb
+	text       rgb
+	muted      rgb
+	track      rgb
+}
+
+var themes = map[application.ChartTheme]theme{
+	application.ChartThemeLight: {rgb{255, 255, 255}, rgb{17, 24, 39}, rgb{107, 114, 128}, rgb{229, 231, 235}},
+	application.ChartThemeDark:  {rgb{17, 24, 39}, rgb{249, 250, 251}, rgb{156, 163, 175}, rgb{55, 65, 81}},
+}
+
+var palette = []rgb{
+	{99, 102, 241},
+	{236, 72, 153},
+	{16, 185, 129},
+	{245, 158, 11},
+	{59, 130, 246},
+	{239, 68, 68},
+	{139, 92, 246},
+	{20, 184, 166},
+}
+
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorEnd
+)
+
+type rect struct {
+	x, y, w, h float64
+	color      rgb
+}
+
+type wedge struct {
+	cx, cy, r  float64
+	start, end float64
+	color      rgb
+}
+
+type text struct {
+	x, y    float64
+	size    float64
+	color   rgb
+	anchor  anchor
+	content string
+}
+
+type layout struct {
+	width, height int
+	background    rgb
+	rects         []rect
+	wedges        []wedge
+	texts         []text
+}
+
+const padding = 24.0
+
+func (t *typesetter) layout(chart application.Chart) layout {
+
+	th, ok := themes[chart.Theme]
+
+	if !ok {
+		th = themes[application.ChartThemeLight]
+	}
+
+	l := layout{
+		width:      chart.Width,
+		height:     chart.Height,
+		background: th.background,
+	}
+
+	width := float64(chart.Width)
+	height := float64(chart.Height)
+
+	titleSize := math.Max(14, math.Min(40, width/28))
+
+	l.texts = append(l.texts, text{
+		x:       padding,
+		y:       padding + titleSize,
+		size:    titleSize,
+		color:   th.text,
+		content: t.truncate(chart.Title, titleSize, width-2*padding),
+	})
+
+	total := 0
+
+	for _, option := range chart.Options {
+		total += option.Votes
+	}
+
+	if chart.HideResults {
+		total = 0
+	}
+
+	top := padding + titleSize + 16
+	area := height - top - padding
+
+	if len(chart.Options) == 0 || area <= 0 {
+		return l
+	}
+
+	if chart.Kind == application.ChartKindPie {
+		t.layoutPie(&l, chart, th, total, top, area)
+	} else {
+		t.layoutBars(&l, chart, th, total, top, area)
+	}
+
+	return l
+}
+
+// layoutBars draws one horizontal bar per option with its name above and
+// the votes beside it.
+func (t *typesetter) layoutBars(l *layout, chart application.Chart, th theme, total int, top float64, area float64) {
+
+	width := float64(l.width)
+	row := area / float64(len(chart.Options))
+	labelSize := math.Max(9, math.Min(maxLabelSize(width), row*0.35))
+
+	// bars stay about twice as tall as their label, with the rows centred
+	// when there is room to spare
+	row = math.Min(row, 3*labelSize+14)
+	top += (area - row*float64(len(chart.Options))) / 2
+
+	barHeight := math.Max(2, row-labelSize-10)
+	track := width - 2*padding
+
+	if !chart.HideResults {
+		track -= t.measure("000 (100%)", labelSize) + 8
+	}
+
+	for i, option := range chart.Options {
+
+		y := top + float64(i)*row
+		color := palette[i%len(palette)]
+
+		l.texts = append(l.texts, text{
+			x:       padding,
+			y:       y + labelSize,
+			size:    labelSize,
+			color:   th.text,
+			content: t.truncate(option.Name, labelSize, width-2*padding),
+		})
+
+		barY := y + labelSize + 4
+
+		l.rects = append(l.rects, rect{padding, barY, track, barHeight, th.track})
+
+		if total > 0 && option.Votes > 0 {
+			l.rects = append(l.rects, rect{padding, barY, track * float64(option.Votes) / float64(total), barHeight, color})
+		}
+
+		if chart.HideResults {
+			continue
+		}
+
+		l.texts = append(l.texts, text{
+			x:       width - padding,
+			y:       barY + barHeight/2 + labelSize/3,
+			size:    labelSize,
+			color:   th.muted,
+			anchor:  anchorEnd,
+			content: voteLabel(option.Votes, total),
+		})
+	}
+}
+
+// layoutPie draws the pie on the left and a legend on the right.
+func (t *typesetter) layoutPie(l *layout, chart application.Chart, th theme, total int, top float64, area float64) {
+
+	width := float64(l.width)
+	radius := math.Min(area, width/2-padding) / 2
+	cx := padding + radius
+	cy := top + area/2
+
+	if total == 0 {
+		l.wedges = append(l.wedges, wedge{cx, cy, radius, 0, 2 * math.Pi, th.track})
+	}
+
+	angle := -math.Pi / 2
+
+	for i, option := range chart.Options {
+
+		if total == 0 || option.Votes == 0 {
+			continue
+		}
+
+		sweep := 2 * math.Pi * float64(option.Votes) / float64(total)
+
+		l.wedges = append(l.wedges, wedge{cx, cy, radius, angle, angle + sweep, palette[i%len(palette)]})
+
+		angle += sweep
+	}
+
+	legendX := cx + radius + padding
+	row := math.Min(2*maxLabelSize(width), area/float64(len(chart.Options)))
+	labelSize := math.Max(9, math.Min(maxLabelSize(width), row*0.55))
+	legendTop := cy - row*float64(len(chart.Options))/2
+
+	for i, option := range chart.Options {
+
+		y := legendTop + float64(i)*row
+		swatch := labelSize
+		label := option.Name
+
+		if !chart.HideResults {
+			label = fmt.Sprintf("%s · %s", option.Name, voteLabel(option.Votes, total))
+		}
+
+		l.rects = append(l.rects, rect{legendX, y + (row-swatch)/2, swatch, swatch, palette[i%len(palette)]})
+
+		l.texts = append(l.texts, text{
+			x:       legendX + swatch + 8,
+			y:       y + row/2 + labelSize/3,
+			size:    labelSize,
+			color:   th.text,
+			content: t.truncate(label, labelSize, width-legendX-swatch-8-padding),
+		})
+	}
+}
+
+// maxLabelSize grows labels with wide charts, such as link previews.
+func maxLabelSize(width float64) float64 {
+	return math.Max(14, width/48)
+}
+
+func voteLabel(votes int, total int) string {
+
+	if total == 0 {
+		return "0 (0%)"
+	}
+
+	return fmt.Sprintf("%d (%.0f%%)", votes, 100*float64(votes)/float64(total))
+}
+
+func (t *typesetter) face(size float64) font.Face {
+
+	if face, ok := t.faces[size]; ok {
+		return face
+	}
+
+	face, err := opentype.NewFace(t.font, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+
+	if err != nil {
+		// only fails for invalid sizes, which layout never produces
+		panic(err)
+	}
+
+	t.faces[size] = face
+
+	return face
+}
+
+func (t *typesetter) measure(s string, size float64) float64 {
+	return fixedToFloat(font.MeasureString(t.face(size), s))
+}
+
+// truncate shortens s with an ellipsis until it fits in max pixels.
+func (t *typesetter) truncate(s string, size float64, max float64) string {
+
+	if t.measure(s, size) <= max {
+		return s
+	}
+
+	runes := []rune(s)
+
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+
+		if candidate := string(runes) + "…"; t.measure(candidate, size) <= max {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
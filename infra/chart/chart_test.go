@@ -0,0 +1,88 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/winnerx0/jille/internal/application"
+)
+
+func testChart(kind application.ChartKind) application.Chart {
+	return application.Chart{
+		Title: "Lunch <today> & tomorrow",
+		Options: []application.ChartOption{
+			{Name: "Pizza", Votes: 3},
+			{Name: "Sushi", Votes: 1},
+			{Name: "A very long option name that will not fit next to the bar at this width", Votes: 0},
+		},
+		Kind:   kind,
+		Theme:  application.ChartThemeDark,
+		Width:  400,
+		Height: 240,
+	}
+}
+
+func TestSVG(t *testing.T) {
+	r, err := NewRenderer()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, kind := range []application.ChartKind{application.ChartKindBar, application.ChartKindPie} {
+		var buf bytes.Buffer
+
+		assert.NoError(t, r.SVG(&buf, testChart(kind)))
+
+		svg := buf.String()
+		assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="400" height="240"`))
+		assert.Contains(t, svg, "Lunch &lt;today&gt; &amp; tomorrow")
+		assert.Contains(t, svg, "3 (75%)")
+		assert.Contains(t, svg, "…")
+	}
+}
+
+func TestSVG_PieWithSingleOptionIsCircle(t *testing.T) {
+	r, _ := NewRenderer()
+
+	chart := testChart(application.ChartKindPie)
+	chart.Options = []application.ChartOption{{Name: "Pizza", Votes: 2}}
+
+	var buf bytes.Buffer
+
+	assert.NoError(t, r.SVG(&buf, chart))
+	assert.Contains(t, buf.String(), "<circle")
+}
+
+func TestPNG(t *testing.T) {
+	r, err := NewRenderer()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	assert.NoError(t, r.PNG(&buf, testChart(application.ChartKindPie)))
+
+	img, err := png.Decode(&buf)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 400, img.Bounds().Dx())
+		assert.Equal(t, 240, img.Bounds().Dy())
+	}
+}
+
+func TestSVG_HideResults(t *testing.T) {
+	r, _ := NewRenderer()
+
+	chart := testChart(application.ChartKindBar)
+	chart.HideResults = true
+
+	var buf bytes.Buffer
+
+	assert.NoError(t, r.SVG(&buf, chart))
+	assert.Contains(t, buf.String(), "Pizza")
+	assert.NotContains(t, buf.String(), "%)")
+}
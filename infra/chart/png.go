@@ -0,0 +1,80 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/winnerx0/jille/internal/application"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+func (r *renderer) PNG(w io.Writer, chart application.Chart) error {
+
+	ts := r.typesetter()
+	l := ts.layout(chart)
+
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+
+	draw.Draw(img, img.Bounds(), image.NewUniform(l.background.rgba()), image.Point{}, draw.Src)
+
+	for _, w := range l.wedges {
+
+		z := vector.NewRasterizer(l.width, l.height)
+
+		// approximate the arc with short segments, about one per pixel of
+		// circumference
+		steps := int(math.Max(8, (w.end-w.start)*w.r))
+
+		z.MoveTo(float32(w.cx), float32(w.cy))
+
+		for i := 0; i <= steps; i++ {
+			angle := w.start + (w.end-w.start)*float64(i)/float64(steps)
+			z.LineTo(float32(w.cx+w.r*math.Cos(angle)), float32(w.cy+w.r*math.Sin(angle)))
+		}
+
+		z.ClosePath()
+		z.Draw(img, img.Bounds(), image.NewUniform(w.color.rgba()), image.Point{})
+	}
+
+	for _, rc := range l.rects {
+
+		z := vector.NewRasterizer(l.width, l.height)
+
+		z.MoveTo(float32(rc.x), float32(rc.y))
+		z.LineTo(float32(rc.x+rc.w), float32(rc.y))
+		z.LineTo(float32(rc.x+rc.w), float32(rc.y+rc.h))
+		z.LineTo(float32(rc.x), float32(rc.y+rc.h))
+		z.ClosePath()
+		z.Draw(img, img.Bounds(), image.NewUniform(rc.color.rgba()), image.Point{})
+	}
+
+	for _, t := range l.texts {
+
+		x := t.x
+
+		if t.anchor == anchorEnd {
+			x -= ts.measure(t.content, t.size)
+		}
+
+		d := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(t.color.rgba()),
+			Face: ts.face(t.size),
+			Dot:  fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(t.y * 64)},
+		}
+
+		d.DrawString(t.content)
+	}
+
+	return png.Encode(w, img)
+}
+
+func (c rgb) rgba() color.RGBA {
+	return color.RGBA{c.r, c.g, c.b, 255}
+}
@@ -0,0 +1,64 @@
+package chart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/winnerx0/jille/internal/application"
+)
+
+func (r *renderer) SVG(w io.Writer, chart application.Chart) error {
+
+	l := r.typesetter().layout(chart)
+
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Go, Helvetica, Arial, sans-serif">`, l.width, l.height, l.width, l.height)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`, l.background.hex())
+
+	for _, w := range l.wedges {
+
+		if w.end-w.start >= 2*math.Pi-1e-9 {
+			fmt.Fprintf(b, `<circle cx="%.2f" cy="%.2f" r="%.2f" fill="%s"/>`, w.cx, w.cy, w.r, w.color.hex())
+			continue
+		}
+
+		large := 0
+
+		if w.end-w.start > math.Pi {
+			large = 1
+		}
+
+		fmt.Fprintf(b, `<path d="M%.2f %.2fL%.2f %.2fA%.2f %.2f 0 %d 1 %.2f %.2fZ" fill="%s"/>`,
+			w.cx, w.cy,
+			w.cx+w.r*math.Cos(w.start), w.cy+w.r*math.Sin(w.start),
+			w.r, w.r, large,
+			w.cx+w.r*math.Cos(w.end), w.cy+w.r*math.Sin(w.end),
+			w.color.hex(),
+		)
+	}
+
+	for _, rc := range l.rects {
+		fmt.Fprintf(b, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`, rc.x, rc.y, rc.w, rc.h, rc.color.hex())
+	}
+
+	for _, t := range l.texts {
+
+		textAnchor := "start"
+
+		if t.anchor == anchorEnd {
+			textAnchor = "end"
+		}
+
+		fmt.Fprintf(b, `<text x="%.2f" y="%.2f" font-size="%.2f" fill="%s" text-anchor="%s">`, t.x, t.y, t.size, t.color.hex(), textAnchor)
+		xml.EscapeText(b, []byte(t.content))
+		b.WriteString(`</text>`)
+	}
+
+	b.WriteString(`</svg>`)
+
+	return b.Flush()
+}
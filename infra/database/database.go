@@ -3,7 +3,6 @@ package database
 import (
 	"fmt"
 
-	"github.com/winnerx0/jille/internal/domain"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -31,7 +30,9 @@ func (db *DBConfig) New() (*gorm.DB, error) {
 		db.TimeZone,
 	)
 
-	database, err := gorm.Open(postgres.Open(dsn))
+	// TranslateError maps unique violations to gorm.ErrDuplicatedKey, which
+	// repositories rely on to report duplicates
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 
 	if err != nil {
 		return nil, err
@@ -39,11 +40,3 @@ func (db *DBConfig) New() (*gorm.DB, error) {
 	
 	return database, nil
 }
-
-var Models = []any{
-	&domain.User{},
-	&domain.RefreshToken{},
-	&domain.Poll{},
-	&domain.Option{},
-	&domain.Vote{},
-}
\ No newline at end of file
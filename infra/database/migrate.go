@@ -0,0 +1,338 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migrationLock is the advisory lock held while migrating, so two migrate
+// runs against the same database wait for each other.
+const migrationLock = 4_311_150_047
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change, read from a pair of
+// <version>_<name>.up.sql and .down.sql files.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of the up script. A migration whose script
+	// changed after it was applied is reported rather than run again.
+	Checksum string
+}
+
+// AppliedMigration is a row of the schema_migrations table.
+type AppliedMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	Checksum  string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (AppliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus tells whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// LoadMigrations reads the migrations in dir of fsys, oldest first.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+
+	entries, err := fs.ReadDir(fsys, dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+
+		match := migrationFile.FindStringSubmatch(entry.Name())
+
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		script, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			sum := sha256.Sum256(script)
+			migration.Up = string(script)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+// migrationStatus matches the applied migrations against the known ones. It
+// returns utils.OutdatedSchemaError if an applied migration is unknown to
+// this build or was changed after it was applied, since the schema is then
+// not what the code expects.
+func migrationStatus(migrations []Migration, applied []AppliedMigration) ([]MigrationStatus, error) {
+
+	appliedByVersion := make(map[int64]AppliedMigration, len(applied))
+
+	for _, migration := range applied {
+		appliedByVersion[migration.Version] = migration
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+
+	for _, migration := range migrations {
+
+		status := MigrationStatus{Migration: migration}
+
+		if row, ok := appliedByVersion[migration.Version]; ok {
+
+			if row.Checksum != migration.Checksum {
+				return nil, fmt.Errorf("%w: migration %d_%s was changed after it was applied", utils.OutdatedSchemaError, migration.Version, migration.Name)
+			}
+
+			status.AppliedAt = &row.AppliedAt
+			delete(appliedByVersion, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for version, row := range appliedByVersion {
+		return nil, fmt.Errorf("%w: migration %d_%s is applied but unknown to this build", utils.OutdatedSchemaError, version, row.Name)
+	}
+
+	return statuses, nil
+}
+
+// Migrator applies the migrations embedded in the binary.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+
+	migrations, err := LoadMigrations(migrationFS, "migrations")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Status lists every migration and when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	return m.status(ctx, m.db)
+}
+
+func (m *Migrator) status(ctx context.Context, db *gorm.DB) ([]MigrationStatus, error) {
+
+	var applied []AppliedMigration
+
+	// nothing has been applied to a database that was never migrated
+	if db.WithContext(ctx).Migrator().HasTable(&AppliedMigration{}) {
+
+		var err error
+
+		applied, err = gorm.G[AppliedMigration](db).Order("version").Find(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return migrationStatus(m.migrations, applied)
+}
+
+// Up applies the pending migrations, each in a transaction of its own,
+// and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+
+	var applied []Migration
+
+	for {
+		var next *Migration
+
+		err := m.locked(ctx, func(tx *gorm.DB) error {
+
+			statuses, err := m.status(ctx, tx)
+
+			if err != nil {
+				return err
+			}
+
+			for _, status := range statuses {
+
+				if status.AppliedAt != nil {
+					continue
+				}
+
+				if err := tx.Exec(status.Up).Error; err != nil {
+					return fmt.Errorf("migration %d_%s: %w", status.Version, status.Name, err)
+				}
+
+				next = &status.Migration
+
+				return gorm.G[AppliedMigration](tx).Create(ctx, &AppliedMigration{
+					Version:   status.Version,
+					Name:      status.Name,
+					Checksum:  status.Checksum,
+					AppliedAt: time.Now(),
+				})
+			}
+
+			return nil
+		})
+
+		if err != nil || next == nil {
+			return applied, err
+		}
+
+		applied = append(applied, *next)
+	}
+}
+
+// Down reverts the latest steps applied migrations, newest first, and
+// returns the ones it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+
+	var reverted []Migration
+
+	for range steps {
+		var last *Migration
+
+		err := m.locked(ctx, func(tx *gorm.DB) error {
+
+			statuses, err := m.status(ctx, tx)
+
+			if err != nil {
+				return err
+			}
+
+			for i := len(statuses) - 1; i >= 0; i-- {
+
+				if statuses[i].AppliedAt == nil {
+					continue
+				}
+
+				if err := tx.Exec(statuses[i].Down).Error; err != nil {
+					return fmt.Errorf("migration %d_%s: %w", statuses[i].Version, statuses[i].Name, err)
+				}
+
+				last = &statuses[i].Migration
+
+				_, err := gorm.G[AppliedMigration](tx).Where("version = ?", statuses[i].Version).Delete(ctx)
+
+				return err
+			}
+
+			return nil
+		})
+
+		if err != nil || last == nil {
+			return reverted, err
+		}
+
+		reverted = append(reverted, *last)
+	}
+
+	return reverted, nil
+}
+
+// CheckCurrent returns utils.OutdatedSchemaError when a migration has not
+// been applied yet, or when the applied ones do not match this build.
+func (m *Migrator) CheckCurrent(ctx context.Context) error {
+
+	statuses, err := m.Status(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			return fmt.Errorf("%w: migration %d_%s is pending", utils.OutdatedSchemaError, status.Version, status.Name)
+		}
+	}
+
+	return nil
+}
+
+// locked runs fn in a transaction holding the migration lock, creating the
+// schema_migrations table first if needed.
+func (m *Migrator) locked(ctx context.Context, fn func(tx *gorm.DB) error) error {
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLock).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Migrator().AutoMigrate(&AppliedMigration{}); err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFS, "migrations")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for i, migration := range migrations {
+		assert.NotEmpty(t, migration.Checksum)
+		if i > 0 {
+			assert.Greater(t, migration.Version, migrations[i-1].Version)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0010_add_index.up.sql":   {Data: []byte("CREATE INDEX a ON b (c);")},
+		"m/0010_add_index.down.sql": {Data: []byte("DROP INDEX a;")},
+		"m/0002_create.up.sql":      {Data: []byte("CREATE TABLE b (c int);")},
+		"m/0002_create.down.sql":    {Data: []byte("DROP TABLE b;")},
+		"m/README.md":               {Data: []byte("not a migration")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "m")
+
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, int64(2), migrations[0].Version)
+	assert.Equal(t, "create", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE b;", migrations[0].Down)
+	assert.Equal(t, int64(10), migrations[1].Version)
+
+	fsys["m/0011_no_down.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+	_, err = LoadMigrations(fsys, "m")
+	assert.ErrorContains(t, err, "11_no_down needs both an up and a down script")
+}
+
+func TestMigrationStatus(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "initial", Checksum: "aaa"},
+		{Version: 2, Name: "indexes", Checksum: "bbb"},
+	}
+	appliedAt := time.Now()
+
+	statuses, err := migrationStatus(migrations, []AppliedMigration{{Version: 1, Name: "initial", Checksum: "aaa", AppliedAt: appliedAt}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &appliedAt, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	tests := []struct {
+		name    string
+		applied []AppliedMigration
+		message string
+	}{
+		{"changed after applying", []AppliedMigration{{Version: 1, Name: "initial", Checksum: "edited"}}, "1_initial was changed"},
+		{"applied by a newer build", []AppliedMigration{{Version: 3, Name: "later", Checksum: "ccc"}}, "3_later is applied but unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := migrationStatus(migrations, tt.applied)
+
+			assert.ErrorIs(t, err, utils.OutdatedSchemaError)
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+}
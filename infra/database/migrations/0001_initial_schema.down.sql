@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS email_notifications;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS outbox_deliveries;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS poll_permissions;
+DROP TABLE IF EXISTS poll_invites;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS data_exports;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS login_throttles;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS two_factors;
+DROP TABLE IF EXISTS o_id_c_login_states;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS user_tokens;
+DROP TABLE IF EXISTS votes;
+DROP TABLE IF EXISTS options;
+DROP TABLE IF EXISTS polls;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,362 @@
+-- The schema as AutoMigrate created it. Tables and indexes that already
+-- exist are left alone, and the columns added to users, user_tokens and
+-- polls after they were first created are added where they are missing, so
+-- a database set up by any earlier version, before migrations were
+-- introduced, can be brought under them by running this once.
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid,
+    username text NOT NULL,
+    email text NOT NULL,
+    password text NOT NULL,
+    email_verified boolean NOT NULL DEFAULT false,
+    profile_picture text,
+    joined_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS profile_picture text;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid,
+    token text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    user_id uuid NOT NULL,
+    revoked boolean NOT NULL DEFAULT false,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_refresh_tokens FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+
+CREATE TABLE IF NOT EXISTS polls (
+    id uuid,
+    title text NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    organization_id uuid,
+    members_only boolean NOT NULL DEFAULT false,
+    invite_only boolean NOT NULL DEFAULT false,
+    results_visibility text NOT NULL DEFAULT 'creator_only',
+    closed_at timestamptz,
+    expiry_reminded_at timestamptz,
+    expires_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_polls FOREIGN KEY (user_id) REFERENCES users(id)
+);
+ALTER TABLE polls
+    ADD COLUMN IF NOT EXISTS organization_id uuid,
+    ADD COLUMN IF NOT EXISTS members_only boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS invite_only boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS results_visibility text NOT NULL DEFAULT 'creator_only',
+    ADD COLUMN IF NOT EXISTS closed_at timestamptz,
+    ADD COLUMN IF NOT EXISTS expiry_reminded_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_polls_closed_at ON polls (closed_at);
+CREATE INDEX IF NOT EXISTS idx_polls_organization_id ON polls (organization_id);
+CREATE INDEX IF NOT EXISTS idx_polls_deleted_at ON polls (deleted_at);
+
+CREATE TABLE IF NOT EXISTS options (
+    id uuid,
+    name text NOT NULL,
+    poll_id uuid NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_polls_options FOREIGN KEY (poll_id) REFERENCES polls(id)
+);
+CREATE INDEX IF NOT EXISTS idx_options_deleted_at ON options (deleted_at);
+
+CREATE TABLE IF NOT EXISTS votes (
+    id uuid,
+    user_id uuid NOT NULL,
+    poll_id uuid NOT NULL,
+    option_id uuid NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_options_votes FOREIGN KEY (option_id) REFERENCES options(id),
+    CONSTRAINT fk_users_votes FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_votes_deleted_at ON votes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_poll ON votes (user_id,poll_id);
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id uuid,
+    user_id uuid NOT NULL,
+    purpose text NOT NULL,
+    token_hash text NOT NULL,
+    payload text,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+ALTER TABLE user_tokens ADD COLUMN IF NOT EXISTS payload text;
+CREATE INDEX IF NOT EXISTS idx_user_tokens_deleted_at ON user_tokens (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tokens_token_hash ON user_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id uuid,
+    user_id uuid NOT NULL,
+    provider text NOT NULL,
+    subject text NOT NULL,
+    email text,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_identities_deleted_at ON user_identities (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_subject ON user_identities (provider,subject);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+
+CREATE TABLE IF NOT EXISTS o_id_c_login_states (
+    id uuid,
+    provider text NOT NULL,
+    state_hash text NOT NULL,
+    nonce text NOT NULL,
+    code_verifier text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_o_id_c_login_states_state_hash ON o_id_c_login_states (state_hash);
+
+CREATE TABLE IF NOT EXISTS two_factors (
+    id uuid,
+    user_id uuid NOT NULL,
+    secret text NOT NULL,
+    enabled boolean NOT NULL DEFAULT false,
+    last_used_step bigint NOT NULL DEFAULT 0,
+    enabled_at timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_two_factors_user_id ON two_factors (user_id);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id uuid,
+    user_id uuid NOT NULL,
+    code_hash text NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key text,
+    failures bigint NOT NULL DEFAULT 0,
+    last_failure_at timestamptz NOT NULL,
+    locked_until timestamptz NOT NULL,
+    PRIMARY KEY (key)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    prefix text NOT NULL,
+    key_hash text NOT NULL,
+    scopes text NOT NULL,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_deleted_at ON api_keys (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS data_exports (
+    id uuid,
+    user_id uuid NOT NULL,
+    status text NOT NULL,
+    token_hash text NOT NULL,
+    file_name text,
+    completed_at timestamptz,
+    expires_at timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_data_exports_deleted_at ON data_exports (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports (expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_token_hash ON data_exports (token_hash);
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports (user_id);
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id uuid,
+    name text NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id uuid,
+    organization_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    role text NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_organization_members_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT fk_organizations_members FOREIGN KEY (organization_id) REFERENCES organizations(id)
+);
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_member ON organization_members (organization_id,user_id);
+
+CREATE TABLE IF NOT EXISTS poll_invites (
+    id uuid,
+    poll_id uuid NOT NULL,
+    email text NOT NULL,
+    user_id uuid,
+    invited_by uuid NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_poll_invites_user_id ON poll_invites (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poll_invite_email ON poll_invites (poll_id,email);
+
+CREATE TABLE IF NOT EXISTS poll_permissions (
+    id uuid,
+    poll_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    role text NOT NULL,
+    granted_by uuid NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_poll_permissions_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_poll_permissions_user_id ON poll_permissions (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poll_permission ON poll_permissions (poll_id,user_id);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id uuid,
+    user_id uuid NOT NULL,
+    poll_id uuid,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_deleted_at ON webhooks (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_webhooks_poll_id ON webhooks (poll_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid,
+    webhook_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event text NOT NULL,
+    poll_id uuid NOT NULL,
+    payload jsonb NOT NULL,
+    status text NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_attempt_at timestamptz,
+    response_status bigint,
+    response_body text,
+    error text,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries (created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_due ON webhook_deliveries (status,next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id uuid,
+    type text NOT NULL,
+    poll_id uuid NOT NULL,
+    payload jsonb NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    event_id uuid,
+    consumer text,
+    locked_until timestamptz,
+    processed_at timestamptz,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (event_id,consumer)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_delivery_due ON outbox_deliveries (consumer,processed_at);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id uuid,
+    kind text,
+    delivery text NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (user_id,kind)
+);
+
+CREATE TABLE IF NOT EXISTS email_notifications (
+    id uuid,
+    event_id uuid NOT NULL,
+    email text NOT NULL,
+    user_id uuid,
+    kind text NOT NULL,
+    poll_id uuid NOT NULL,
+    subject text NOT NULL,
+    body text NOT NULL,
+    summary text NOT NULL,
+    digest boolean NOT NULL DEFAULT false,
+    status text NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    send_after timestamptz NOT NULL,
+    sent_at timestamptz,
+    error text,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_email_notifications_created_at ON email_notifications (created_at);
+CREATE INDEX IF NOT EXISTS idx_email_notification_due ON email_notifications (status,send_after);
+CREATE INDEX IF NOT EXISTS idx_email_notifications_user_id ON email_notifications (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_notification_event ON email_notifications (event_id,email);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id uuid,
+    user_id uuid NOT NULL,
+    kind text NOT NULL,
+    poll_id uuid NOT NULL,
+    event_id uuid,
+    message text NOT NULL,
+    read_at timestamptz,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_event ON notifications (event_id,user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_inbox ON notifications (user_id,created_at);
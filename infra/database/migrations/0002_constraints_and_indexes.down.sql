@@ -0,0 +1,89 @@
+DROP INDEX IF EXISTS idx_notifications_read_at;
+DROP INDEX IF EXISTS idx_notification_unread;
+
+DROP INDEX IF EXISTS idx_outbox_delivery_due;
+CREATE INDEX idx_outbox_delivery_due ON outbox_deliveries (consumer, processed_at);
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_id;
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+
+DROP INDEX IF EXISTS idx_votes_user;
+DROP INDEX IF EXISTS idx_votes_poll_id;
+DROP INDEX IF EXISTS idx_votes_option;
+DROP INDEX IF EXISTS idx_options_poll_id;
+
+DROP INDEX IF EXISTS idx_polls_open;
+DROP INDEX IF EXISTS idx_polls_organization_id;
+CREATE INDEX idx_polls_organization_id ON polls (organization_id);
+DROP INDEX IF EXISTS idx_polls_user;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_user;
+
+DROP INDEX IF EXISTS idx_provider_subject;
+CREATE UNIQUE INDEX idx_provider_subject ON user_identities (provider, subject);
+
+DROP INDEX IF EXISTS idx_data_exports_token_hash;
+CREATE UNIQUE INDEX idx_data_exports_token_hash ON data_exports (token_hash);
+
+DROP INDEX IF EXISTS idx_api_keys_key_hash;
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
+
+DROP INDEX IF EXISTS idx_user_tokens_token_hash;
+CREATE UNIQUE INDEX idx_user_tokens_token_hash ON user_tokens (token_hash);
+
+DROP INDEX IF EXISTS idx_user_poll;
+CREATE UNIQUE INDEX idx_user_poll ON votes (user_id, poll_id);
+
+DROP INDEX IF EXISTS idx_users_email;
+ALTER TABLE users ADD CONSTRAINT uni_users_email UNIQUE (email);
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS fk_notifications_user;
+ALTER TABLE email_notifications DROP CONSTRAINT IF EXISTS fk_email_notifications_user;
+ALTER TABLE notification_preferences DROP CONSTRAINT IF EXISTS fk_notification_preferences_user;
+
+ALTER TABLE outbox_deliveries DROP CONSTRAINT IF EXISTS fk_outbox_deliveries_event;
+
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS fk_webhook_deliveries_webhook;
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS fk_webhooks_poll;
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS fk_webhooks_user;
+
+ALTER TABLE data_exports DROP CONSTRAINT IF EXISTS fk_data_exports_user;
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS fk_api_keys_user;
+ALTER TABLE recovery_codes DROP CONSTRAINT IF EXISTS fk_recovery_codes_user;
+ALTER TABLE two_factors DROP CONSTRAINT IF EXISTS fk_two_factors_user;
+ALTER TABLE user_identities DROP CONSTRAINT IF EXISTS fk_user_identities_user;
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS fk_user_tokens_user;
+
+ALTER TABLE poll_invites DROP CONSTRAINT IF EXISTS fk_poll_invites_user;
+ALTER TABLE poll_invites DROP CONSTRAINT IF EXISTS fk_poll_invites_poll;
+
+ALTER TABLE poll_permissions DROP CONSTRAINT IF EXISTS fk_poll_permissions_poll;
+ALTER TABLE poll_permissions DROP CONSTRAINT IF EXISTS fk_poll_permissions_user;
+ALTER TABLE poll_permissions ADD CONSTRAINT fk_poll_permissions_user FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE organization_members DROP CONSTRAINT IF EXISTS fk_organizations_members;
+ALTER TABLE organization_members ADD CONSTRAINT fk_organizations_members FOREIGN KEY (organization_id) REFERENCES organizations (id);
+ALTER TABLE organization_members DROP CONSTRAINT IF EXISTS fk_organization_members_user;
+ALTER TABLE organization_members ADD CONSTRAINT fk_organization_members_user FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS fk_users_votes;
+ALTER TABLE votes ADD CONSTRAINT fk_users_votes FOREIGN KEY (user_id) REFERENCES users (id);
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS fk_options_votes;
+ALTER TABLE votes ADD CONSTRAINT fk_options_votes FOREIGN KEY (option_id) REFERENCES options (id);
+
+ALTER TABLE options DROP CONSTRAINT IF EXISTS uni_options_id_poll_id;
+ALTER TABLE options DROP CONSTRAINT IF EXISTS fk_polls_options;
+ALTER TABLE options ADD CONSTRAINT fk_polls_options FOREIGN KEY (poll_id) REFERENCES polls (id);
+
+ALTER TABLE polls DROP CONSTRAINT IF EXISTS fk_organizations_polls;
+ALTER TABLE polls DROP CONSTRAINT IF EXISTS fk_users_polls;
+ALTER TABLE polls ADD CONSTRAINT fk_users_polls FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_users_refresh_tokens;
+ALTER TABLE refresh_tokens ADD CONSTRAINT fk_users_refresh_tokens FOREIGN KEY (user_id) REFERENCES users (id);
+
+-- Plaintext tokens cannot be recovered from their hashes, so every session
+-- ends and users sign in again.
+DROP INDEX IF EXISTS idx_refresh_tokens_token_hash;
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,132 @@
+-- Enforce the domain rules in the schema and index every query path.
+
+-- Refresh tokens are looked up by their SHA-256 like every other token, so
+-- a leaked table does not hand out sessions.
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+UPDATE refresh_tokens SET token_hash = encode(sha256(convert_to(token_hash, 'UTF8')), 'hex');
+CREATE INDEX idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+-- Rows whose parent is gone were never reachable through the application;
+-- remove them so the foreign keys below can be added. Polls are not
+-- touched: a poll whose organization is missing stops the migration for
+-- someone to look at.
+DELETE FROM options WHERE NOT EXISTS (SELECT 1 FROM polls WHERE polls.id = options.poll_id);
+DELETE FROM votes WHERE NOT EXISTS (
+    SELECT 1 FROM options WHERE options.id = votes.option_id AND options.poll_id = votes.poll_id
+);
+DELETE FROM votes WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = votes.user_id);
+DELETE FROM poll_invites WHERE NOT EXISTS (SELECT 1 FROM polls WHERE polls.id = poll_invites.poll_id);
+DELETE FROM poll_permissions WHERE NOT EXISTS (SELECT 1 FROM polls WHERE polls.id = poll_permissions.poll_id);
+DELETE FROM webhooks WHERE poll_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM polls WHERE polls.id = webhooks.poll_id);
+DELETE FROM webhook_deliveries WHERE NOT EXISTS (SELECT 1 FROM webhooks WHERE webhooks.id = webhook_deliveries.webhook_id);
+DELETE FROM outbox_deliveries WHERE NOT EXISTS (SELECT 1 FROM outbox_events WHERE outbox_events.id = outbox_deliveries.event_id);
+DELETE FROM user_tokens WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = user_tokens.user_id);
+DELETE FROM user_identities WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = user_identities.user_id);
+DELETE FROM two_factors WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = two_factors.user_id);
+DELETE FROM recovery_codes WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = recovery_codes.user_id);
+DELETE FROM api_keys WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = api_keys.user_id);
+DELETE FROM data_exports WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = data_exports.user_id);
+DELETE FROM webhooks WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = webhooks.user_id);
+DELETE FROM notification_preferences WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = notification_preferences.user_id);
+DELETE FROM notifications WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = notifications.user_id);
+UPDATE poll_invites SET user_id = NULL WHERE user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = poll_invites.user_id);
+UPDATE email_notifications SET user_id = NULL WHERE user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = email_notifications.user_id);
+
+-- Foreign keys. Deleting a row for good takes everything that only exists
+-- for it along; invites and queued emails addressed to someone outlive
+-- their account. Polls and users are normally soft deleted and the
+-- application removes what belongs to them itself, so these mostly guard
+-- against hard deletes by hand.
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_users_refresh_tokens;
+ALTER TABLE refresh_tokens ADD CONSTRAINT fk_users_refresh_tokens FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE polls DROP CONSTRAINT IF EXISTS fk_users_polls;
+ALTER TABLE polls ADD CONSTRAINT fk_users_polls FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+-- an organization's polls go with it rather than becoming public
+ALTER TABLE polls DROP CONSTRAINT IF EXISTS fk_organizations_polls;
+ALTER TABLE polls ADD CONSTRAINT fk_organizations_polls FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE;
+
+ALTER TABLE options DROP CONSTRAINT IF EXISTS fk_polls_options;
+ALTER TABLE options ADD CONSTRAINT fk_polls_options FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE;
+-- lets a vote reference an option together with the option's poll
+ALTER TABLE options ADD CONSTRAINT uni_options_id_poll_id UNIQUE (id, poll_id);
+
+-- A vote's option belongs to the poll the vote is for.
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS fk_options_votes;
+ALTER TABLE votes ADD CONSTRAINT fk_options_votes FOREIGN KEY (option_id, poll_id) REFERENCES options (id, poll_id) ON DELETE CASCADE;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS fk_users_votes;
+ALTER TABLE votes ADD CONSTRAINT fk_users_votes FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE organization_members DROP CONSTRAINT IF EXISTS fk_organization_members_user;
+ALTER TABLE organization_members ADD CONSTRAINT fk_organization_members_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE organization_members DROP CONSTRAINT IF EXISTS fk_organizations_members;
+ALTER TABLE organization_members ADD CONSTRAINT fk_organizations_members FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE;
+
+ALTER TABLE poll_permissions DROP CONSTRAINT IF EXISTS fk_poll_permissions_user;
+ALTER TABLE poll_permissions ADD CONSTRAINT fk_poll_permissions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE poll_permissions ADD CONSTRAINT fk_poll_permissions_poll FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE;
+
+ALTER TABLE poll_invites ADD CONSTRAINT fk_poll_invites_poll FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE;
+ALTER TABLE poll_invites ADD CONSTRAINT fk_poll_invites_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE user_tokens ADD CONSTRAINT fk_user_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE user_identities ADD CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE two_factors ADD CONSTRAINT fk_two_factors_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE recovery_codes ADD CONSTRAINT fk_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE api_keys ADD CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE data_exports ADD CONSTRAINT fk_data_exports_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE webhooks ADD CONSTRAINT fk_webhooks_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE webhooks ADD CONSTRAINT fk_webhooks_poll FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE;
+ALTER TABLE webhook_deliveries ADD CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE;
+
+ALTER TABLE outbox_deliveries ADD CONSTRAINT fk_outbox_deliveries_event FOREIGN KEY (event_id) REFERENCES outbox_events (id) ON DELETE CASCADE;
+
+ALTER TABLE notification_preferences ADD CONSTRAINT fk_notification_preferences_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE email_notifications ADD CONSTRAINT fk_email_notifications_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE notifications ADD CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+-- Uniqueness only applies to rows that have not been soft deleted, so a
+-- deleted row does not block its replacement.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_user_poll;
+CREATE UNIQUE INDEX idx_user_poll ON votes (user_id, poll_id) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_user_tokens_token_hash;
+CREATE UNIQUE INDEX idx_user_tokens_token_hash ON user_tokens (token_hash) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_api_keys_key_hash;
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_data_exports_token_hash;
+CREATE UNIQUE INDEX idx_data_exports_token_hash ON data_exports (token_hash) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_provider_subject;
+CREATE UNIQUE INDEX idx_provider_subject ON user_identities (provider, subject) WHERE deleted_at IS NULL;
+
+-- Query paths.
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id, created_at);
+
+CREATE INDEX idx_polls_user ON polls (user_id, created_at);
+DROP INDEX IF EXISTS idx_polls_organization_id;
+CREATE INDEX idx_polls_organization_id ON polls (organization_id, created_at);
+-- polls still to close or to remind invitees about
+CREATE INDEX idx_polls_open ON polls (expires_at) WHERE closed_at IS NULL AND deleted_at IS NULL;
+
+CREATE INDEX idx_options_poll_id ON options (poll_id);
+
+CREATE INDEX idx_votes_option ON votes (option_id, poll_id);
+CREATE INDEX idx_votes_poll_id ON votes (poll_id);
+CREATE INDEX idx_votes_user ON votes (user_id, created_at);
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_id;
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at);
+
+DROP INDEX IF EXISTS idx_outbox_delivery_due;
+CREATE INDEX idx_outbox_delivery_due ON outbox_deliveries (consumer, created_at) WHERE processed_at IS NULL;
+
+CREATE INDEX idx_notification_unread ON notifications (user_id) WHERE read_at IS NULL;
+CREATE INDEX idx_notifications_read_at ON notifications (read_at);
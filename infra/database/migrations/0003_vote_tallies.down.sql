@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_votes_page;
+DROP INDEX IF EXISTS idx_votes_tally;
+CREATE INDEX idx_votes_poll_id ON votes (poll_id);
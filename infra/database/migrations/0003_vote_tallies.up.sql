@@ -0,0 +1,5 @@
+-- Tallies count a poll's votes per option from the index alone, and voter
+-- lists page through a poll's votes in the order they were cast.
+DROP INDEX IF EXISTS idx_votes_poll_id;
+CREATE INDEX idx_votes_tally ON votes (poll_id, option_id, created_at) WHERE deleted_at IS NULL;
+CREATE INDEX idx_votes_page ON votes (poll_id, created_at, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS votes_count_delete ON votes;
+DROP TRIGGER IF EXISTS votes_count_update ON votes;
+DROP TRIGGER IF EXISTS votes_count_insert ON votes;
+DROP FUNCTION IF EXISTS count_deleted_votes();
+DROP FUNCTION IF EXISTS count_updated_votes();
+DROP FUNCTION IF EXISTS count_inserted_votes();
+ALTER TABLE options DROP COLUMN IF EXISTS last_vote_at;
+ALTER TABLE options DROP COLUMN IF EXISTS vote_count;
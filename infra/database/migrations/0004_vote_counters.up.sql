@@ -0,0 +1,109 @@
+-- Options keep a count of their votes, and when the last one was cast, so
+-- reading a poll never counts votes. Statement triggers keep them current in
+-- the transaction that changes the votes, so a batch of votes updates each
+-- of its options once.
+ALTER TABLE options ADD COLUMN vote_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE options ADD COLUMN last_vote_at timestamptz;
+
+UPDATE options
+SET vote_count = tally.votes, last_vote_at = tally.last_vote_at
+FROM (
+    SELECT option_id, COUNT(*) AS votes, MAX(created_at) AS last_vote_at
+    FROM votes
+    WHERE deleted_at IS NULL
+    GROUP BY option_id
+) tally
+WHERE options.id = tally.option_id;
+
+-- Every statement locks the options it counts in the same order, so
+-- concurrent batches wait for each other rather than deadlock.
+CREATE FUNCTION count_inserted_votes() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM 1 FROM options
+    WHERE id IN (SELECT option_id FROM new_votes)
+    ORDER BY id
+    FOR NO KEY UPDATE;
+
+    UPDATE options
+    SET vote_count = options.vote_count + tally.votes,
+        last_vote_at = GREATEST(options.last_vote_at, tally.last_vote_at)
+    FROM (
+        SELECT option_id, COUNT(*) AS votes, MAX(created_at) AS last_vote_at
+        FROM new_votes
+        WHERE deleted_at IS NULL
+        GROUP BY option_id
+    ) tally
+    WHERE options.id = tally.option_id;
+
+    RETURN NULL;
+END
+$$;
+
+-- Updates move votes in and out of the count when they are soft deleted or
+-- restored. The latest vote is looked up again for the options that lost
+-- or gained votes, which only happens when votes are removed.
+CREATE FUNCTION count_updated_votes() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM 1 FROM options
+    WHERE id IN (SELECT option_id FROM old_votes UNION SELECT option_id FROM new_votes)
+    ORDER BY id
+    FOR NO KEY UPDATE;
+
+    UPDATE options
+    SET vote_count = options.vote_count + changes.votes,
+        last_vote_at = (
+            SELECT MAX(votes.created_at) FROM votes
+            WHERE votes.poll_id = changes.poll_id AND votes.option_id = changes.option_id AND votes.deleted_at IS NULL
+        )
+    FROM (
+        SELECT option_id, poll_id, SUM(change) AS votes
+        FROM (
+            SELECT option_id, poll_id, -1 AS change FROM old_votes WHERE deleted_at IS NULL
+            UNION ALL
+            SELECT option_id, poll_id, 1 FROM new_votes WHERE deleted_at IS NULL
+        ) moved
+        GROUP BY option_id, poll_id
+        HAVING SUM(change) <> 0
+    ) changes
+    WHERE options.id = changes.option_id;
+
+    RETURN NULL;
+END
+$$;
+
+CREATE FUNCTION count_deleted_votes() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM 1 FROM options
+    WHERE id IN (SELECT option_id FROM old_votes)
+    ORDER BY id
+    FOR NO KEY UPDATE;
+
+    UPDATE options
+    SET vote_count = options.vote_count - tally.votes,
+        last_vote_at = (
+            SELECT MAX(votes.created_at) FROM votes
+            WHERE votes.poll_id = tally.poll_id AND votes.option_id = tally.option_id AND votes.deleted_at IS NULL
+        )
+    FROM (
+        SELECT option_id, poll_id, COUNT(*) AS votes
+        FROM old_votes
+        WHERE deleted_at IS NULL
+        GROUP BY option_id, poll_id
+    ) tally
+    WHERE options.id = tally.option_id;
+
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER votes_count_insert AFTER INSERT ON votes
+    REFERENCING NEW TABLE AS new_votes
+    FOR EACH STATEMENT EXECUTE FUNCTION count_inserted_votes();
+
+CREATE TRIGGER votes_count_update AFTER UPDATE ON votes
+    REFERENCING OLD TABLE AS old_votes NEW TABLE AS new_votes
+    FOR EACH STATEMENT EXECUTE FUNCTION count_updated_votes();
+
+CREATE TRIGGER votes_count_delete AFTER DELETE ON votes
+    REFERENCING OLD TABLE AS old_votes
+    FOR EACH STATEMENT EXECUTE FUNCTION count_deleted_votes();
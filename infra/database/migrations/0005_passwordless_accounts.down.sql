@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS provider_sign_in_at;
+ALTER TABLE users DROP COLUMN IF EXISTS passwordless;
@@ -0,0 +1,8 @@
+-- Accounts created through an identity provider or from Slack get a random
+-- password nobody knows. They are marked so that changes to the account are
+-- confirmed by a recent sign-in through the provider instead. Nothing
+-- recorded so far tells such accounts apart from those whose owner linked a
+-- provider later, so only accounts created from now on are marked; the
+-- owners of older ones choose a password with a reset link first.
+ALTER TABLE users ADD COLUMN passwordless boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN provider_sign_in_at timestamptz;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox_deliveries DROP COLUMN IF EXISTS failed_at;
+ALTER TABLE outbox_deliveries DROP COLUMN IF EXISTS last_error;
+ALTER TABLE outbox_deliveries DROP COLUMN IF EXISTS attempts;
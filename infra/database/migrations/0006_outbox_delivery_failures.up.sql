@@ -0,0 +1,6 @@
+-- A consumer that fails an event is handed it again after the lease of the
+-- attempt runs out, and the event is set aside once it has failed too often,
+-- so one event that always fails no longer holds up the ones after it.
+ALTER TABLE outbox_deliveries ADD COLUMN attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE outbox_deliveries ADD COLUMN last_error text NOT NULL DEFAULT '';
+ALTER TABLE outbox_deliveries ADD COLUMN failed_at timestamptz;
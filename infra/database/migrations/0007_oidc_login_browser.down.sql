@@ -0,0 +1 @@
+ALTER TABLE o_id_c_login_states DROP COLUMN IF EXISTS browser_hash;
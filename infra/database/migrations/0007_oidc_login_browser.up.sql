@@ -0,0 +1,3 @@
+-- A login with an identity provider is tied to the browser that started it.
+-- Logins started before have no key and can no longer be finished.
+ALTER TABLE o_id_c_login_states ADD COLUMN browser_hash text NOT NULL DEFAULT '';
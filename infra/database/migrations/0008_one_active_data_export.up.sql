@@ -0,0 +1,12 @@
+-- A user has at most one export being built. Two requests arriving together
+-- could both find none and start one each; the index turns the second away.
+UPDATE data_exports
+SET status = 'failed'
+WHERE status IN ('pending', 'processing') AND deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM data_exports newer
+    WHERE newer.user_id = data_exports.user_id
+    AND newer.status IN ('pending', 'processing') AND newer.deleted_at IS NULL
+    AND (newer.created_at, newer.id) > (data_exports.created_at, data_exports.id)
+);
+
+CREATE UNIQUE INDEX idx_data_exports_active ON data_exports (user_id) WHERE status IN ('pending', 'processing') AND deleted_at IS NULL;
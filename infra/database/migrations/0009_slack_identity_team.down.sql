@@ -0,0 +1,3 @@
+UPDATE user_identities
+SET subject = split_part(subject, ':', 2)
+WHERE provider = 'slack' AND position(':' IN subject) > 0;
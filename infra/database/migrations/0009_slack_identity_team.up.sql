@@ -0,0 +1,12 @@
+-- Slack user IDs are only unique within a workspace, so identities are
+-- keyed by "<team ID>:<user ID>". Existing guests were created with the
+-- address "<user ID>.<team ID>@slack.invalid", which names their workspace;
+-- Slack IDs are upper case, so the lower cased address gives them back.
+UPDATE user_identities
+SET subject = upper(split_part(split_part(users.email, '@', 1), '.', 2)) || ':' || user_identities.subject
+FROM users
+WHERE users.id = user_identities.user_id
+AND user_identities.provider = 'slack'
+AND position(':' IN user_identities.subject) = 0
+AND users.email LIKE '%@slack.invalid'
+AND split_part(split_part(users.email, '@', 1), '.', 1) = lower(user_identities.subject);
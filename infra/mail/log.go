@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/winnerx0/jille/internal/application"
+)
+
+// logMailer writes every message to a writer instead of delivering it, which
+// is handy for local development and tests.
+type logMailer struct {
+	mu     sync.Mutex
+	from   string
+	writer io.Writer
+}
+
+func NewLogMailer(from string, writer io.Writer) application.Mailer {
+	return &logMailer{
+		from:   from,
+		writer: writer,
+	}
+}
+
+// NewFileMailer appends messages to the file at path, creating it if needed.
+func NewFileMailer(from string, path string) (application.Mailer, error) {
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLogMailer(from, file), nil
+}
+
+func (m *logMailer) Send(ctx context.Context, message application.MailMessage) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.writer, "----- %s -----\n%s\n", time.Now().Format(time.RFC3339), buildMessage(m.from, message))
+
+	return err
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/winnerx0/jille/internal/application"
+)
+
+type MailConfig struct {
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	LogFile  string
+}
+
+func (cfg *MailConfig) New() (application.Mailer, error) {
+
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(*cfg), nil
+	case "file":
+		return NewFileMailer(cfg.From, cfg.LogFile)
+	case "log", "":
+		return NewLogMailer(cfg.From, os.Stdout), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
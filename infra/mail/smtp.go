@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/winnerx0/jille/internal/application"
+)
+
+type smtpMailer struct {
+	config MailConfig
+}
+
+func NewSMTPMailer(config MailConfig) application.Mailer {
+	return &smtpMailer{
+		config: config,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message application.MailMessage) error {
+
+	var auth smtp.Auth
+
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.config.Host, m.config.Port), auth, m.config.From, []string{message.To}, buildMessage(m.config.From, message))
+}
+
+func buildMessage(from string, message application.MailMessage) []byte {
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
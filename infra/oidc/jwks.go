@@ -0,0 +1,136 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type keySet struct {
+	keys map[string]any
+}
+
+func (set jsonWebKeySet) parse() (*keySet, error) {
+
+	keys := make(map[string]any, len(set.Keys))
+
+	for _, key := range set.Keys {
+
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := key.publicKey()
+
+		if err != nil {
+			return nil, fmt.Errorf("parsing key %q: %w", key.Kid, err)
+		}
+
+		// unsupported key types are skipped rather than failing the whole set
+		if publicKey != nil {
+			keys[key.Kid] = publicKey
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("key set contains no usable signing keys")
+	}
+
+	return &keySet{keys: keys}, nil
+}
+
+// find returns the key with the given id. Tokens without a kid are accepted
+// only when the set holds a single key.
+func (s *keySet) find(kid string) (any, bool) {
+
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+
+	key, ok := s.keys[kid]
+
+	return key, ok
+}
+
+func (key jsonWebKey) publicKey() (any, error) {
+
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(key.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+
+		switch key.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", key.Crv)
+		}
+
+		x, err := decodeBigInt(key.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(key.Y)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+
+	b, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,306 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/winnerx0/jille/internal/application"
+)
+
+type ProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+
+	Nonce             string `json:"nonce"`
+	AuthorizedParty   string `json:"azp"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+
+	// SlackTeamID is set by Sign in with Slack, whose user IDs are only
+	// unique within a workspace
+	SlackTeamID string `json:"https://slack.com/team_id"`
+}
+
+// provider is an OpenID Connect relying party for a single identity
+// provider. The discovery document and signing keys are fetched lazily and
+// cached.
+type provider struct {
+	config ProviderConfig
+
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *keySet
+}
+
+func NewProvider(config ProviderConfig) application.IdentityProvider {
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &provider{
+		config: config,
+		client: &http.Client{Timeout: time.Second * 10},
+	}
+}
+
+func (p *provider) Name() string {
+	return p.config.Name
+}
+
+func (p *provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+
+	discovery, err := p.discover(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+
+	if err != nil {
+		return "", err
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+func (p *provider) Exchange(ctx context.Context, code string, codeVerifier string) (*application.ExternalIdentity, error) {
+
+	discovery, err := p.discover(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	var token tokenResponse
+
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("decoding token response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", res.StatusCode, token.Error, token.ErrorDescription)
+	}
+
+	if token.IDToken == "" {
+		return nil, errors.New("token response did not include an id_token")
+	}
+
+	return p.verifyIDToken(ctx, discovery, token.IDToken)
+}
+
+func (p *provider) verifyIDToken(ctx context.Context, discovery *discoveryDocument, rawIDToken string) (*application.ExternalIdentity, error) {
+
+	var claims idTokenClaims
+
+	_, err := jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, errors.New("invalid id token: authorized party does not match client")
+	}
+
+	subject := claims.Subject
+
+	// matches the subject the Slack integration gives its users, so an
+	// account linked through Slack votes as itself from Slack
+	if claims.SlackTeamID != "" {
+		subject = claims.SlackTeamID + ":" + subject
+	}
+
+	return &application.ExternalIdentity{
+		Subject:           subject,
+		Email:             claims.Email,
+		EmailVerified:     emailVerified(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+		Nonce:             claims.Nonce,
+	}, nil
+}
+
+func (p *provider) discover(ctx context.Context) (*discoveryDocument, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery discoveryDocument
+
+	wellKnown := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	if err := p.getJSON(ctx, wellKnown, &discovery); err != nil {
+		return nil, fmt.Errorf("fetching discovery document: %w", err)
+	}
+
+	if discovery.Issuer != strings.TrimSuffix(p.config.IssuerURL, "/") && discovery.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", discovery.Issuer, p.config.IssuerURL)
+	}
+
+	p.discovery = &discovery
+
+	return p.discovery, nil
+}
+
+// signingKey looks up a key by id, refetching the key set once when the id is
+// unknown so that provider key rotation is picked up.
+func (p *provider) signingKey(ctx context.Context, discovery *discoveryDocument, kid string) (any, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil {
+		if key, ok := p.keys.find(kid); ok {
+			return key, nil
+		}
+	}
+
+	var keys jsonWebKeySet
+
+	if err := p.getJSON(ctx, discovery.JwksURI, &keys); err != nil {
+		return nil, fmt.Errorf("fetching signing keys: %w", err)
+	}
+
+	keySet, err := keys.parse()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.keys = keySet
+
+	key, ok := p.keys.find(kid)
+
+	if !ok {
+		return nil, fmt.Errorf("no signing key found for kid %q", kid)
+	}
+
+	return key, nil
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, v any) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// emailVerified accepts both the boolean and the string form some providers
+// send.
+func emailVerified(value any) bool {
+
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
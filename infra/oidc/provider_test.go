@@ -0,0 +1,107 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/winnerx0/jille/infra/oidc/oidctest"
+)
+
+func newTestProvider(idp *oidctest.Server) *provider {
+	return NewProvider(ProviderConfig{
+		Name:        "test",
+		IssuerURL:   idp.URL,
+		ClientID:    idp.ClientID,
+		RedirectURL: "http://localhost:3000/auth/oidc/callback",
+	}).(*provider)
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewServer("jille")
+	defer idp.Close()
+
+	p := newTestProvider(idp)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state123", "nonce123", challenge("verifier-verifier-verifier-verifier-verifier"))
+	assert.NoError(t, err)
+
+	code, state, err := idp.Authorize(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "state123", state)
+
+	identity, err := p.Exchange(ctx, code, "verifier-verifier-verifier-verifier-verifier")
+
+	assert.NoError(t, err)
+	assert.Equal(t, idp.User.Subject, identity.Subject)
+	assert.Equal(t, idp.User.Email, identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "nonce123", identity.Nonce)
+}
+
+func TestProvider_SlackSubjectIncludesTeam(t *testing.T) {
+	idp := oidctest.NewServer("jille")
+	defer idp.Close()
+
+	idp.User.SlackTeamID = "T1DC2JH3J"
+
+	p := newTestProvider(idp)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state123", "nonce123", challenge("verifier-verifier-verifier-verifier-verifier"))
+	assert.NoError(t, err)
+
+	code, _, err := idp.Authorize(authURL)
+	assert.NoError(t, err)
+
+	identity, err := p.Exchange(ctx, code, "verifier-verifier-verifier-verifier-verifier")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "T1DC2JH3J:"+idp.User.Subject, identity.Subject)
+}
+
+func TestProvider_RejectsWrongCodeVerifier(t *testing.T) {
+	idp := oidctest.NewServer("jille")
+	defer idp.Close()
+
+	p := newTestProvider(idp)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state123", "nonce123", challenge("verifier-verifier-verifier-verifier-verifier"))
+	assert.NoError(t, err)
+
+	code, _, err := idp.Authorize(authURL)
+	assert.NoError(t, err)
+
+	_, err = p.Exchange(ctx, code, "another-verifier-another-verifier-another")
+
+	assert.Error(t, err)
+}
+
+func TestProvider_RejectsWrongAudience(t *testing.T) {
+	idp := oidctest.NewServer("jille")
+	defer idp.Close()
+
+	idp.Audience = "someone-else"
+
+	p := newTestProvider(idp)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state123", "nonce123", challenge("verifier-verifier-verifier-verifier-verifier"))
+	assert.NoError(t, err)
+
+	code, _, err := idp.Authorize(authURL)
+	assert.NoError(t, err)
+
+	_, err = p.Exchange(ctx, code, "verifier-verifier-verifier-verifier-verifier")
+
+	assert.ErrorContains(t, err, "invalid id token")
+}
@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+// lastUsedResolution limits how often a busy key writes its last-used time.
+const lastUsedResolution = time.Minute
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) repository.APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+func (repo *apiKeyRepository) Save(ctx context.Context, key *domain.APIKey) error {
+
+	return gorm.G[domain.APIKey](repo.db).Create(ctx, key)
+}
+
+func (repo *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+
+	key, err := gorm.G[domain.APIKey](repo.db).Where("key_hash = ?", keyHash).First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.APIKeyNotFoundError
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (repo *apiKeyRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+
+	return gorm.G[domain.APIKey](repo.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(ctx)
+}
+
+func (repo *apiKeyRepository) Revoke(ctx context.Context, keyID uuid.UUID, userID uuid.UUID) error {
+
+	rows, err := gorm.G[domain.APIKey](repo.db).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update(ctx, "revoked_at", time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.APIKeyNotFoundError
+	}
+
+	return nil
+}
+
+func (repo *apiKeyRepository) TouchLastUsed(ctx context.Context, keyID uuid.UUID) error {
+
+	now := time.Now()
+
+	_, err := gorm.G[domain.APIKey](repo.db).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, now.Add(-lastUsedResolution)).
+		Update(ctx, "last_used_at", now)
+
+	return err
+}
@@ -35,10 +35,17 @@ func (repo authRepository) SaveToken(ctx context.Context, token *domain.RefreshT
 	return err
 }
 
-func (repo authRepository) FindByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+func (repo authRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
 
-	refreshToken, err := gorm.G[domain.RefreshToken](repo.db).Where("token = ?", token).First(ctx)
+	refreshToken, err := gorm.G[domain.RefreshToken](repo.db).Where("token_hash = ? AND revoked = false", tokenHash).First(ctx)
 
 	return &refreshToken, err
 
-}
\ No newline at end of file
+}
+func (repo authRepository) FindTokensByUserID(ctx context.Context, userID uuid.UUID) ([]domain.RefreshToken, error) {
+
+	return gorm.G[domain.RefreshToken](repo.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(ctx)
+}
@@ -0,0 +1,94 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) repository.DataExportRepository {
+	return &dataExportRepository{
+		db: db,
+	}
+}
+
+func (repo *dataExportRepository) Save(ctx context.Context, export *domain.DataExport) error {
+
+	err := gorm.G[domain.DataExport](repo.db).Create(ctx, export)
+
+	// another request started an export for the user first
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return utils.DataExportInProgressError
+	}
+
+	return err
+}
+
+func (repo *dataExportRepository) FindByID(ctx context.Context, exportID uuid.UUID) (*domain.DataExport, error) {
+
+	export, err := gorm.G[domain.DataExport](repo.db).Where("id = ?", exportID).First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.DataExportNotFoundError
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (repo *dataExportRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID) (*domain.DataExport, error) {
+
+	export, err := gorm.G[domain.DataExport](repo.db).
+		Where("user_id = ? AND status IN ?", userID, []domain.DataExportStatus{domain.DataExportPending, domain.DataExportProcessing}).
+		Order("created_at DESC").
+		First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.DataExportNotFoundError
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (repo *dataExportRepository) UpdateStatus(ctx context.Context, exportID uuid.UUID, status domain.DataExportStatus) error {
+
+	_, err := gorm.G[domain.DataExport](repo.db).Where("id = ?", exportID).Update(ctx, "status", status)
+
+	return err
+}
+
+func (repo *dataExportRepository) MarkReady(ctx context.Context, exportID uuid.UUID, fileName string, expiresAt time.Time) error {
+
+	now := time.Now()
+
+	_, err := gorm.G[domain.DataExport](repo.db).Where("id = ?", exportID).Updates(ctx, domain.DataExport{
+		Status:      domain.DataExportReady,
+		FileName:    fileName,
+		CompletedAt: &now,
+		ExpiresAt:   &expiresAt,
+	})
+
+	return err
+}
+
+func (repo *dataExportRepository) FindExpired(ctx context.Context, now time.Time) ([]domain.DataExport, error) {
+
+	return gorm.G[domain.DataExport](repo.db).
+		Where("status = ? AND expires_at < ?", domain.DataExportReady, now).
+		Find(ctx)
+}
@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) repository.IdentityRepository {
+	return &identityRepository{
+		db: db,
+	}
+}
+
+func (repo *identityRepository) Save(ctx context.Context, identity *domain.UserIdentity) error {
+
+	return gorm.G[domain.UserIdentity](repo.db).Create(ctx, identity)
+}
+
+func (repo *identityRepository) FindByProviderAndSubject(ctx context.Context, provider string, subject string) (*domain.UserIdentity, error) {
+
+	identity, err := gorm.G[domain.UserIdentity](repo.db).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (repo *identityRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.UserIdentity, error) {
+
+	return gorm.G[domain.UserIdentity](repo.db).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(ctx)
+}
+
+func (repo *identityRepository) SaveLoginState(ctx context.Context, state *domain.OIDCLoginState) error {
+
+	return gorm.G[domain.OIDCLoginState](repo.db).Create(ctx, state)
+}
+
+func (repo *identityRepository) ConsumeLoginState(ctx context.Context, provider string, stateHash string) (*domain.OIDCLoginState, error) {
+
+	state, err := gorm.G[domain.OIDCLoginState](repo.db).
+		Where("provider = ? AND state_hash = ?", provider, stateHash).
+		First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.InvalidLoginStateError
+		}
+		return nil, err
+	}
+
+	rows, err := gorm.G[domain.OIDCLoginState](repo.db).Where("id = ?", state.ID).Delete(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// another callback already consumed it
+	if rows == 0 {
+		return nil, utils.InvalidLoginStateError
+	}
+
+	return &state, nil
+}
+
+func (repo *identityRepository) DeleteExpiredLoginStates(ctx context.Context, before time.Time) error {
+
+	_, err := gorm.G[domain.OIDCLoginState](repo.db).Where("expires_at < ?", before).Delete(ctx)
+
+	return err
+}
@@ -0,0 +1,62 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"gorm.io/gorm"
+)
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) repository.LoginThrottleRepository {
+	return &loginThrottleRepository{
+		db: db,
+	}
+}
+
+func (repo *loginThrottleRepository) FindByKeys(ctx context.Context, keys []string) ([]domain.LoginThrottle, error) {
+
+	return gorm.G[domain.LoginThrottle](repo.db).Where("key IN ?", keys).Find(ctx)
+}
+
+func (repo *loginThrottleRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+
+	now := time.Now()
+
+	var failures int
+
+	// a single upsert keeps concurrent failures from losing increments
+	err := repo.db.WithContext(ctx).Raw(`
+		INSERT INTO login_throttles (key, failures, last_failure_at, locked_until)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_throttles.last_failure_at < ? THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures`,
+		key, now, time.Time{}, now.Add(-window),
+	).Scan(&failures).Error
+
+	return failures, err
+}
+
+func (repo *loginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+
+	_, err := gorm.G[domain.LoginThrottle](repo.db).Where("key = ?", key).Update(ctx, "locked_until", until)
+
+	return err
+}
+
+func (repo *loginThrottleRepository) Reset(ctx context.Context, key string) error {
+
+	_, err := gorm.G[domain.LoginThrottle](repo.db).Where("key = ?", key).Delete(ctx)
+
+	return err
+}
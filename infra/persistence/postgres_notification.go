@@ -0,0 +1,193 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) repository.NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+func (repo *notificationRepository) FindPreferences(ctx context.Context, userIDs []uuid.UUID) ([]domain.NotificationPreference, error) {
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	return gorm.G[domain.NotificationPreference](repo.db).Where("user_id IN ?", userIDs).Find(ctx)
+}
+
+func (repo *notificationRepository) SavePreferences(ctx context.Context, preferences []domain.NotificationPreference) error {
+
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	return repo.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}},
+			DoUpdates: clause.AssignmentColumns([]string{"delivery", "updated_at"}),
+		}).
+		Create(&preferences).Error
+}
+
+func (repo *notificationRepository) SaveEmails(ctx context.Context, emails []domain.EmailNotification) error {
+
+	if len(emails) == 0 {
+		return nil
+	}
+
+	// an event processed again after a crash queues nothing new
+	return repo.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&emails, 100).Error
+}
+
+func (repo *notificationRepository) ClaimDueEmails(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.EmailNotification, error) {
+
+	var emails []domain.EmailNotification
+
+	err := repo.db.WithContext(ctx).Raw(`
+		UPDATE email_notifications SET send_after = ?
+		WHERE id IN (
+			SELECT id FROM email_notifications
+			WHERE status = ? AND NOT digest AND send_after <= ?
+			ORDER BY send_after
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), domain.EmailNotificationPending, now, limit).Scan(&emails).Error
+
+	return emails, err
+}
+
+func (repo *notificationRepository) ClaimDueDigests(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.EmailNotification, error) {
+
+	var emails []domain.EmailNotification
+
+	// whole recipients are claimed so each gets a single digest
+	err := repo.db.WithContext(ctx).Raw(`
+		UPDATE email_notifications SET send_after = ?
+		WHERE id IN (
+			SELECT id FROM email_notifications
+			WHERE status = ? AND digest AND send_after <= ? AND email IN (
+				SELECT DISTINCT email FROM email_notifications
+				WHERE status = ? AND digest AND send_after <= ?
+				LIMIT ?
+			)
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), domain.EmailNotificationPending, now, domain.EmailNotificationPending, now, limit).Scan(&emails).Error
+
+	return emails, err
+}
+
+func (repo *notificationRepository) UpdateEmails(ctx context.Context, emails []domain.EmailNotification) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		for _, email := range emails {
+
+			_, err := gorm.G[domain.EmailNotification](tx).
+				Where("id = ?", email.ID).
+				Select("status", "attempts", "send_after", "sent_at", "error").
+				Updates(ctx, email)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (repo *notificationRepository) DeleteEmailsBefore(ctx context.Context, before time.Time) error {
+
+	_, err := gorm.G[domain.EmailNotification](repo.db).
+		Where("created_at < ? AND status <> ?", before, domain.EmailNotificationPending).
+		Delete(ctx)
+
+	return err
+}
+
+func (repo *notificationRepository) SaveNotifications(ctx context.Context, notifications []domain.Notification) error {
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	return repo.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&notifications, 100).Error
+}
+
+func (repo *notificationRepository) FindNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]domain.Notification, error) {
+
+	query := gorm.G[domain.Notification](repo.db).Where("user_id = ?", userID)
+
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	query = query.Order("created_at DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	return query.Find(ctx)
+}
+
+func (repo *notificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+
+	return gorm.G[domain.Notification](repo.db).Where("user_id = ? AND read_at IS NULL", userID).Count(ctx, "*")
+}
+
+func (repo *notificationRepository) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID, readAt time.Time) error {
+
+	notification, err := gorm.G[domain.Notification](repo.db).Where("id = ? AND user_id = ?", notificationID, userID).First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return utils.NotificationNotFoundError
+		}
+		return err
+	}
+
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	_, err = gorm.G[domain.Notification](repo.db).Where("id = ?", notificationID).Update(ctx, "read_at", readAt)
+
+	return err
+}
+
+func (repo *notificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID, readAt time.Time) error {
+
+	_, err := gorm.G[domain.Notification](repo.db).Where("user_id = ? AND read_at IS NULL", userID).Update(ctx, "read_at", readAt)
+
+	return err
+}
+
+func (repo *notificationRepository) DeleteReadBefore(ctx context.Context, before time.Time) error {
+
+	_, err := gorm.G[domain.Notification](repo.db).Where("read_at < ?", before).Delete(ctx)
+
+	return err
+}
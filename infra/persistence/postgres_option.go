@@ -21,12 +21,12 @@ func NewOptionRepository(db *gorm.DB) repository.OptionRepository {
 
 func (repo optionRepository) Save(ctx context.Context, options *[]domain.Option) error {
 
-	return gorm.G[[]domain.Option](repo.db).Create(ctx, options)
+	return gorm.G[[]domain.Option](conn(ctx, repo.db)).Create(ctx, options)
 }
 
 func (v *optionRepository) FindOptionsByPollID(ctx context.Context, pollID uuid.UUID) (*[]domain.Option, error) {
 
-	options, err := gorm.G[domain.Option](v.db).Preload("Votes", nil).Where("poll_id = ?", pollID).Find(ctx)
+	options, err := gorm.G[domain.Option](conn(ctx, v.db)).Where("poll_id = ?", pollID).Find(ctx)
 
 	if err != nil {
 		return &[]domain.Option{}, err
@@ -34,3 +34,10 @@ func (v *optionRepository) FindOptionsByPollID(ctx context.Context, pollID uuid.
 
 	return &options, nil
 }
+
+func (repo *optionRepository) DeleteByPollID(ctx context.Context, pollID uuid.UUID) error {
+
+	_, err := gorm.G[domain.Option](conn(ctx, repo.db)).Where("poll_id = ?", pollID).Delete(ctx)
+
+	return err
+}
@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) repository.OrganizationRepository {
+	return &organizationRepository{
+		db: db,
+	}
+}
+
+func (repo *organizationRepository) Create(ctx context.Context, organization *domain.Organization, ownerID uuid.UUID) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if err := gorm.G[domain.Organization](tx).Create(ctx, organization); err != nil {
+			return err
+		}
+
+		return gorm.G[domain.OrganizationMember](tx).Create(ctx, &domain.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         ownerID,
+			Role:           domain.OrganizationRoleOwner,
+		})
+	})
+}
+
+func (repo *organizationRepository) FindByID(ctx context.Context, organizationID uuid.UUID) (*domain.Organization, error) {
+
+	organization, err := gorm.G[domain.Organization](repo.db).
+		Preload("Members.User", nil).
+		Where("id = ?", organizationID).
+		First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.OrganizationNotFoundError
+		}
+		return nil, err
+	}
+
+	return &organization, nil
+}
+
+func (repo *organizationRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Organization, error) {
+
+	return gorm.G[domain.Organization](repo.db).
+		Preload("Members", func(db gorm.PreloadBuilder) error {
+			db.Where("user_id = ?", userID)
+			return nil
+		}).
+		Where("id IN (?)", repo.db.Model(&domain.OrganizationMember{}).Select("organization_id").Where("user_id = ?", userID)).
+		Order("name").
+		Find(ctx)
+}
+
+func (repo *organizationRepository) FindMember(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) (*domain.OrganizationMember, error) {
+
+	member, err := gorm.G[domain.OrganizationMember](repo.db).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.MemberNotFoundError
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (repo *organizationRepository) AddMember(ctx context.Context, member *domain.OrganizationMember) error {
+
+	err := gorm.G[domain.OrganizationMember](repo.db).Create(ctx, member)
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return utils.MemberExistsError
+	}
+
+	return err
+}
+
+func (repo *organizationRepository) UpdateMemberRole(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID, role domain.OrganizationRole) error {
+
+	rows, err := gorm.G[domain.OrganizationMember](repo.db).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Update(ctx, "role", role)
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.MemberNotFoundError
+	}
+
+	return nil
+}
+
+func (repo *organizationRepository) RemoveMember(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) error {
+
+	rows, err := gorm.G[domain.OrganizationMember](repo.db).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.MemberNotFoundError
+	}
+
+	return nil
+}
+
+func (repo *organizationRepository) CountOwners(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+
+	return gorm.G[domain.OrganizationMember](repo.db).
+		Where("organization_id = ? AND role = ?", organizationID, domain.OrganizationRoleOwner).
+		Count(ctx, "*")
+}
@@ -0,0 +1,124 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"gorm.io/gorm"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+// saveOutboxEvents records the events, and a delivery of each to every
+// consumer, on tx, which must be the transaction making the change they
+// describe.
+func saveOutboxEvents(ctx context.Context, tx *gorm.DB, events []domain.OutboxEvent) error {
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	deliveries := make([]domain.OutboxDelivery, 0, len(events)*len(domain.OutboxConsumers))
+
+	for i := range events {
+
+		if events[i].ID == uuid.Nil {
+			events[i].ID = uuid.New()
+		}
+
+		events[i].CreatedAt = now
+
+		for _, consumer := range domain.OutboxConsumers {
+			deliveries = append(deliveries, domain.OutboxDelivery{
+				EventID:   events[i].ID,
+				Consumer:  consumer,
+				CreatedAt: now,
+			})
+		}
+	}
+
+	if err := gorm.G[domain.OutboxEvent](tx).CreateInBatches(ctx, &events, 100); err != nil {
+		return err
+	}
+
+	return gorm.G[domain.OutboxDelivery](tx).CreateInBatches(ctx, &deliveries, 100)
+}
+
+// saveOutboxEvent records a single event on tx, see saveOutboxEvents.
+func saveOutboxEvent(ctx context.Context, tx *gorm.DB, eventType domain.OutboxEventType, pollID uuid.UUID, payload any) error {
+
+	event, err := domain.NewOutboxEvent(eventType, pollID, payload)
+
+	if err != nil {
+		return err
+	}
+
+	return saveOutboxEvents(ctx, tx, []domain.OutboxEvent{event})
+}
+
+func (repo *outboxRepository) ClaimUnprocessed(ctx context.Context, consumer domain.OutboxConsumer, now time.Time, lease time.Duration, limit int) ([]domain.OutboxEvent, error) {
+
+	var events []domain.OutboxEvent
+
+	err := repo.db.WithContext(ctx).Raw(`
+		WITH claimed AS (
+			UPDATE outbox_deliveries SET locked_until = ?
+			WHERE consumer = ? AND event_id IN (
+				SELECT event_id FROM outbox_deliveries
+				WHERE consumer = ? AND processed_at IS NULL AND failed_at IS NULL AND (locked_until IS NULL OR locked_until <= ?)
+				ORDER BY created_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING event_id
+		)
+		SELECT outbox_events.* FROM outbox_events
+		JOIN claimed ON claimed.event_id = outbox_events.id
+		ORDER BY outbox_events.created_at`, now.Add(lease), consumer, consumer, now, limit).Scan(&events).Error
+
+	return events, err
+}
+
+func (repo *outboxRepository) MarkProcessed(ctx context.Context, consumer domain.OutboxConsumer, eventID uuid.UUID, processedAt time.Time) error {
+
+	_, err := gorm.G[domain.OutboxDelivery](repo.db).
+		Where("event_id = ? AND consumer = ?", eventID, consumer).
+		Update(ctx, "processed_at", processedAt)
+
+	return err
+}
+
+func (repo *outboxRepository) RecordFailure(ctx context.Context, consumer domain.OutboxConsumer, eventID uuid.UUID, lastError string, now time.Time, maxAttempts int) error {
+
+	return repo.db.WithContext(ctx).Exec(`
+		UPDATE outbox_deliveries
+		SET attempts = attempts + 1,
+			last_error = ?,
+			failed_at = CASE WHEN attempts + 1 >= ? THEN ?::timestamptz END
+		WHERE event_id = ? AND consumer = ?`, lastError, maxAttempts, now, eventID, consumer).Error
+}
+
+func (repo *outboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) error {
+
+	// the deliveries go with their event
+	return repo.db.WithContext(ctx).Exec(`
+		DELETE FROM outbox_events
+		WHERE created_at < ? AND NOT EXISTS (
+			SELECT 1 FROM outbox_deliveries
+			WHERE outbox_deliveries.event_id = outbox_events.id
+			AND outbox_deliveries.processed_at IS NULL AND outbox_deliveries.failed_at IS NULL
+		)`, before).Error
+}
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/application/repository"
@@ -23,7 +24,7 @@ func NewPollRepository(db *gorm.DB) repository.PollRepository {
 func (repo *pollRepository) FindUserPollCount(ctx context.Context, userID uuid.UUID) (int, error) {
 
 	var pollCount int
-	err := repo.db.
+	err := conn(ctx, repo.db).
 		Raw("SELECT COUNT(*) FROM polls WHERE user_id = ?", userID).
 		Scan(&pollCount).Error
 	if err != nil {
@@ -34,12 +35,20 @@ func (repo *pollRepository) FindUserPollCount(ctx context.Context, userID uuid.U
 }
 
 func (repo *pollRepository) Save(ctx context.Context, poll *domain.Poll) error {
-	return gorm.G[domain.Poll](repo.db).Create(ctx, poll)
+
+	return conn(ctx, repo.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if err := gorm.G[domain.Poll](tx).Create(ctx, poll); err != nil {
+			return err
+		}
+
+		return saveOutboxEvent(ctx, tx, domain.OutboxPollCreated, poll.ID, struct{}{})
+	})
 }
 
 func (repo *pollRepository) FindPollByID(ctx context.Context, pollID uuid.UUID) (*domain.Poll, error) {
 
-	poll, err := gorm.G[domain.Poll](repo.db).Preload("Options.Votes", nil).Where("id = ?", pollID).First(ctx)
+	poll, err := gorm.G[domain.Poll](conn(ctx, repo.db)).Preload("Options", nil).Where("id = ?", pollID).First(ctx)
 
 	if poll.Title == "" {
 		return nil, utils.PollNotFoundError
@@ -47,29 +56,66 @@ func (repo *pollRepository) FindPollByID(ctx context.Context, pollID uuid.UUID)
 	if err != nil {
 		return nil, err
 	}
+
 	return &poll, nil
 }
 
 func (repo *pollRepository) Delete(ctx context.Context, pollID uuid.UUID) error {
-	rows, err := gorm.G[domain.Poll](repo.db).Where("id = ?", pollID).Delete(ctx)
 
-	if err != nil {
-		return err
+	return conn(ctx, repo.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		poll, err := gorm.G[domain.Poll](tx).Where("id = ?", pollID).First(ctx)
+
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return utils.PollNotFoundError
+			}
+			return err
+		}
+
+		return deletePolls(ctx, tx, []domain.Poll{poll})
+	})
+}
+
+// deletePolls soft-deletes the polls on tx and records a poll.deleted event
+// for each, so their consumers learn they are gone.
+func deletePolls(ctx context.Context, tx *gorm.DB, polls []domain.Poll) error {
+
+	if len(polls) == 0 {
+		return nil
 	}
 
-	if rows == 0 {
-		return utils.PollNotFoundError
+	pollIDs := make([]uuid.UUID, len(polls))
+	events := make([]domain.OutboxEvent, len(polls))
+
+	for i, poll := range polls {
+
+		event, err := domain.NewOutboxEvent(domain.OutboxPollDeleted, poll.ID, domain.PollDeletedPayload{
+			UserID: poll.UserID,
+			Title:  poll.Title,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		pollIDs[i] = poll.ID
+		events[i] = event
+	}
+
+	if _, err := gorm.G[domain.Poll](tx).Where("id IN ?", pollIDs).Delete(ctx); err != nil {
+		return err
 	}
 
-	return nil
+	return saveOutboxEvents(ctx, tx, events)
 }
 
 func (repo *pollRepository) FindAllPolls(ctx context.Context) ([]domain.Poll, error) {
 
 	userID := uuid.MustParse(ctx.Value("userID").(string))
 
-	polls, err := gorm.G[domain.Poll](repo.db).
-		Preload("Options.Votes", nil).
+	polls, err := gorm.G[domain.Poll](conn(ctx, repo.db)).
+		Preload("Options", nil).
 		Where("user_id = ?", userID).
 		Find(ctx)
 
@@ -79,3 +125,89 @@ func (repo *pollRepository) FindAllPolls(ctx context.Context) ([]domain.Poll, er
 
 	return polls, nil
 }
+
+func (repo *pollRepository) FindPollsByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Poll, error) {
+
+	return gorm.G[domain.Poll](conn(ctx, repo.db)).
+		Preload("Options", nil).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(ctx)
+}
+
+func (repo *pollRepository) FindPollsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]domain.Poll, error) {
+
+	return gorm.G[domain.Poll](conn(ctx, repo.db)).
+		Preload("Options", nil).
+		Where("organization_id = ?", organizationID).
+		Order("created_at DESC").
+		Find(ctx)
+}
+
+func (repo *pollRepository) CloseExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+
+	var pollIDs []uuid.UUID
+
+	err := conn(ctx, repo.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// claiming the polls in a single statement keeps two servers from
+		// closing the same poll
+		err := tx.Raw(`
+			UPDATE polls SET closed_at = ?
+			WHERE id IN (
+				SELECT id FROM polls
+				WHERE closed_at IS NULL AND expires_at <= ? AND deleted_at IS NULL
+				ORDER BY expires_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id`, now, now, limit).Scan(&pollIDs).Error
+
+		if err != nil {
+			return err
+		}
+
+		return saveOutboxEvents(ctx, tx, pollEvents(domain.OutboxPollClosed, pollIDs))
+	})
+
+	return len(pollIDs), err
+}
+
+func (repo *pollRepository) MarkExpiring(ctx context.Context, now time.Time, window time.Duration, limit int) (int, error) {
+
+	var pollIDs []uuid.UUID
+
+	err := conn(ctx, repo.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Raw(`
+			UPDATE polls SET expiry_reminded_at = ?
+			WHERE id IN (
+				SELECT id FROM polls
+				WHERE expiry_reminded_at IS NULL AND closed_at IS NULL AND deleted_at IS NULL
+					AND expires_at > ? AND expires_at <= ? AND created_at <= ?
+				ORDER BY expires_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id`, now, now, now.Add(window), now.Add(-window), limit).Scan(&pollIDs).Error
+
+		if err != nil {
+			return err
+		}
+
+		return saveOutboxEvents(ctx, tx, pollEvents(domain.OutboxPollExpiring, pollIDs))
+	})
+
+	return len(pollIDs), err
+}
+
+func pollEvents(eventType domain.OutboxEventType, pollIDs []uuid.UUID) []domain.OutboxEvent {
+
+	events := make([]domain.OutboxEvent, 0, len(pollIDs))
+
+	for _, pollID := range pollIDs {
+		events = append(events, domain.OutboxEvent{Type: eventType, PollID: pollID, Payload: "{}"})
+	}
+
+	return events
+}
@@ -0,0 +1,123 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pollInviteRepository struct {
+	db *gorm.DB
+}
+
+func NewPollInviteRepository(db *gorm.DB) repository.PollInviteRepository {
+	return &pollInviteRepository{
+		db: db,
+	}
+}
+
+func (repo *pollInviteRepository) SaveAll(ctx context.Context, invites []domain.PollInvite) error {
+
+	if len(invites) == 0 {
+		return nil
+	}
+
+	return conn(ctx, repo.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&invites, 100).Error; err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(invites))
+
+		for _, invite := range invites {
+			ids = append(ids, invite.ID)
+		}
+
+		// invites skipped as duplicates were never stored, so they are
+		// not announced either
+		stored, err := gorm.G[domain.PollInvite](tx).Where("id IN ?", ids).Find(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		events := make([]domain.OutboxEvent, 0, len(stored))
+
+		for _, invite := range stored {
+
+			event, err := domain.NewOutboxEvent(domain.OutboxPollInvited, invite.PollID, domain.PollInvitedPayload{
+				InviteID: invite.ID,
+				Email:    invite.Email,
+				UserID:   invite.UserID,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			events = append(events, event)
+		}
+
+		return saveOutboxEvents(ctx, tx, events)
+	})
+}
+
+func (repo *pollInviteRepository) FindByPollID(ctx context.Context, pollID uuid.UUID) ([]domain.PollInvite, error) {
+
+	return gorm.G[domain.PollInvite](conn(ctx, repo.db)).
+		Where("poll_id = ?", pollID).
+		Order("created_at").
+		Find(ctx)
+}
+
+func (repo *pollInviteRepository) FindForUser(ctx context.Context, pollID uuid.UUID, userID uuid.UUID, email string) (*domain.PollInvite, error) {
+
+	query := gorm.G[domain.PollInvite](conn(ctx, repo.db)).Where("poll_id = ? AND user_id = ?", pollID, userID)
+
+	if email != "" {
+		query = gorm.G[domain.PollInvite](conn(ctx, repo.db)).Where("poll_id = ? AND (user_id = ? OR (user_id IS NULL AND email = ?))", pollID, userID, email)
+	}
+
+	invite, err := query.First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.InviteNotFoundError
+		}
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (repo *pollInviteRepository) Claim(ctx context.Context, inviteID uuid.UUID, userID uuid.UUID) error {
+
+	_, err := gorm.G[domain.PollInvite](conn(ctx, repo.db)).
+		Where("id = ? AND user_id IS NULL", inviteID).
+		Update(ctx, "user_id", userID)
+
+	return err
+}
+
+func (repo *pollInviteRepository) Delete(ctx context.Context, pollID uuid.UUID, inviteID uuid.UUID) error {
+
+	rows, err := gorm.G[domain.PollInvite](conn(ctx, repo.db)).
+		Where("id = ? AND poll_id = ?", inviteID, pollID).
+		Delete(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.InviteNotFoundError
+	}
+
+	return nil
+}
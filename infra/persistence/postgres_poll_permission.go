@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type pollPermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPollPermissionRepository(db *gorm.DB) repository.PollPermissionRepository {
+	return &pollPermissionRepository{
+		db: db,
+	}
+}
+
+func (repo *pollPermissionRepository) Add(ctx context.Context, permission *domain.PollPermission) error {
+
+	err := gorm.G[domain.PollPermission](repo.db).Create(ctx, permission)
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return utils.CollaboratorExistsError
+	}
+
+	return err
+}
+
+func (repo *pollPermissionRepository) FindByPollID(ctx context.Context, pollID uuid.UUID) ([]domain.PollPermission, error) {
+
+	return gorm.G[domain.PollPermission](repo.db).
+		Preload("User", nil).
+		Where("poll_id = ?", pollID).
+		Order("created_at").
+		Find(ctx)
+}
+
+func (repo *pollPermissionRepository) Find(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) (*domain.PollPermission, error) {
+
+	permission, err := gorm.G[domain.PollPermission](repo.db).
+		Where("poll_id = ? AND user_id = ?", pollID, userID).
+		First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.CollaboratorNotFoundError
+		}
+		return nil, err
+	}
+
+	return &permission, nil
+}
+
+func (repo *pollPermissionRepository) UpdateRole(ctx context.Context, pollID uuid.UUID, userID uuid.UUID, role domain.PollRole) error {
+
+	rows, err := gorm.G[domain.PollPermission](repo.db).
+		Where("poll_id = ? AND user_id = ?", pollID, userID).
+		Update(ctx, "role", role)
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.CollaboratorNotFoundError
+	}
+
+	return nil
+}
+
+func (repo *pollPermissionRepository) Remove(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) error {
+
+	rows, err := gorm.G[domain.PollPermission](repo.db).
+		Where("poll_id = ? AND user_id = ?", pollID, userID).
+		Delete(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.CollaboratorNotFoundError
+	}
+
+	return nil
+}
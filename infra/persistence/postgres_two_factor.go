@@ -0,0 +1,107 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) repository.TwoFactorRepository {
+	return &twoFactorRepository{
+		db: db,
+	}
+}
+
+func (repo *twoFactorRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.TwoFactor, error) {
+
+	twoFactor, err := gorm.G[domain.TwoFactor](repo.db).Where("user_id = ?", userID).First(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &twoFactor, nil
+}
+
+func (repo *twoFactorRepository) Save(ctx context.Context, twoFactor *domain.TwoFactor) error {
+
+	return repo.db.WithContext(ctx).Save(twoFactor).Error
+}
+
+func (repo *twoFactorRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if _, err := gorm.G[domain.RecoveryCode](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		_, err := gorm.G[domain.TwoFactor](tx).Where("user_id = ?", userID).Delete(ctx)
+
+		return err
+	})
+}
+
+func (repo *twoFactorRepository) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+
+	rows, err := gorm.G[domain.TwoFactor](repo.db).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update(ctx, "last_used_step", step)
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.InvalidTwoFactorCodeError
+	}
+
+	return nil
+}
+
+func (repo *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+
+	codes := make([]domain.RecoveryCode, len(codeHashes))
+
+	for i, hash := range codeHashes {
+		codes[i] = domain.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hash,
+		}
+	}
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if _, err := gorm.G[domain.RecoveryCode](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		return gorm.G[domain.RecoveryCode](tx).CreateInBatches(ctx, &codes, len(codes))
+	})
+}
+
+func (repo *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+
+	rows, err := gorm.G[domain.RecoveryCode](repo.db).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update(ctx, "used_at", time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.InvalidTwoFactorCodeError
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/winnerx0/jille/internal/application/repository"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) repository.UnitOfWork {
+	return &unitOfWork{
+		db: db,
+	}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+
+	// a unit of work started inside another becomes a savepoint of it
+	return conn(ctx, u.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction of the unit of work ctx belongs to, or db
+// outside of one.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+
+	return db
+}
@@ -2,10 +2,12 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/application/repository"
 	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
 	"gorm.io/gorm"
 )
 
@@ -23,12 +25,25 @@ func (repo *userRepository) FindById(ctx context.Context, userId uuid.UUID) (dom
 
 	user, err := gorm.G[domain.User](repo.db).Where("id = ?", userId).First(ctx)
 
+	if err == gorm.ErrRecordNotFound {
+		return user, utils.UserNotFoundError
+	}
+
 	return user, err
 }
 
+func (repo *userRepository) FindByIDs(ctx context.Context, userIDs []uuid.UUID) ([]domain.User, error) {
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	return gorm.G[domain.User](repo.db).Where("id IN ?", userIDs).Find(ctx)
+}
+
 func (repo *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 
-	exists, err := gorm.G[bool](repo.db).Raw("SELECT COUNT(u) > 0 FROM users u WHERE u.email = ?", email).First(ctx)
+	exists, err := gorm.G[bool](repo.db).Raw("SELECT COUNT(u) > 0 FROM users u WHERE u.email = ? AND u.deleted_at IS NULL", email).First(ctx)
 
 	return exists, err
 }
@@ -46,3 +61,215 @@ func (repo *userRepository) Save(ctx context.Context, user *domain.User) error {
 
 	return err
 }
+
+func (repo *userRepository) RecordProviderSignIn(ctx context.Context, userID uuid.UUID, at time.Time) error {
+
+	_, err := gorm.G[domain.User](repo.db).Where("id = ?", userID).Update(ctx, "provider_sign_in_at", at)
+
+	return err
+}
+
+func (repo *userRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+
+	_, err := gorm.G[domain.User](repo.db).Where("id = ?", userID).Update(ctx, "email_verified", true)
+
+	return err
+}
+
+func (repo *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+
+	rows, err := gorm.G[domain.User](repo.db).Where("id = ?", userID).Select("password", "passwordless").Updates(ctx, domain.User{
+		Password:     password,
+		Passwordless: false,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.UserNotFoundError
+	}
+
+	return nil
+}
+
+func (repo *userRepository) UpdateUsername(ctx context.Context, userID uuid.UUID, username string) error {
+
+	return repo.updateColumn(ctx, userID, "username", username)
+}
+
+func (repo *userRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+
+	rows, err := gorm.G[domain.User](repo.db).Where("id = ?", userID).Updates(ctx, domain.User{
+		Email:         email,
+		EmailVerified: true,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.UserNotFoundError
+	}
+
+	return nil
+}
+
+func (repo *userRepository) UpdateProfilePicture(ctx context.Context, userID uuid.UUID, profilePicture string) error {
+
+	return repo.updateColumn(ctx, userID, "profile_picture", profilePicture)
+}
+
+func (repo *userRepository) DeleteAccount(ctx context.Context, userID uuid.UUID, mode domain.AccountDeletionMode) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if mode == domain.AccountDeletionRemove {
+
+			userPolls := tx.Model(&domain.Poll{}).Select("id").Where("user_id = ?", userID)
+
+			if _, err := gorm.G[domain.Vote](tx).Where("user_id = ? OR poll_id IN (?)", userID, userPolls).Delete(ctx); err != nil {
+				return err
+			}
+
+			if _, err := gorm.G[domain.Option](tx).Where("poll_id IN (?)", userPolls).Delete(ctx); err != nil {
+				return err
+			}
+
+			if _, err := gorm.G[domain.PollInvite](tx).Where("poll_id IN (?)", userPolls).Delete(ctx); err != nil {
+				return err
+			}
+
+			if _, err := gorm.G[domain.PollPermission](tx).Where("poll_id IN (?)", userPolls).Delete(ctx); err != nil {
+				return err
+			}
+
+			pollWebhooks := tx.Model(&domain.Webhook{}).Select("id").Where("poll_id IN (?)", userPolls)
+
+			if _, err := gorm.G[domain.WebhookDelivery](tx).Where("webhook_id IN (?)", pollWebhooks).Delete(ctx); err != nil {
+				return err
+			}
+
+			if _, err := gorm.G[domain.Webhook](tx).Where("poll_id IN (?)", userPolls).Delete(ctx); err != nil {
+				return err
+			}
+
+			polls, err := gorm.G[domain.Poll](tx).Where("user_id = ?", userID).Find(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			if err := deletePolls(ctx, tx, polls); err != nil {
+				return err
+			}
+		}
+
+		// end every session and credential tied to the account
+		if _, err := gorm.G[domain.RefreshToken](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.UserToken](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.APIKey](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		// stop sending the account's poll events anywhere
+		userWebhooks := tx.Model(&domain.Webhook{}).Select("id").Where("user_id = ?", userID)
+
+		if _, err := gorm.G[domain.WebhookDelivery](tx).Where("webhook_id IN (?)", userWebhooks).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.Webhook](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.NotificationPreference](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.EmailNotification](tx).Where("user_id = ? AND status = ?", userID, domain.EmailNotificationPending).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.Notification](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.RecoveryCode](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.TwoFactor](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		// expire finished data exports so their links stop working and the
+		// archives are purged
+		if _, err := gorm.G[domain.DataExport](tx).Where("user_id = ? AND status = ?", userID, domain.DataExportReady).Update(ctx, "expires_at", time.Now()); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.OrganizationMember](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		if _, err := gorm.G[domain.PollPermission](tx).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		// invites carry the person's address, so they go before it is scrubbed
+		userEmail := tx.Model(&domain.User{}).Select("LOWER(email)").Where("id = ?", userID)
+
+		if _, err := gorm.G[domain.PollInvite](tx).Where("user_id = ? OR email = (?)", userID, userEmail).Delete(ctx); err != nil {
+			return err
+		}
+
+		// identities are removed for good so the external account can sign
+		// up again
+		if _, err := gorm.G[domain.UserIdentity](tx.Unscoped()).Where("user_id = ?", userID).Delete(ctx); err != nil {
+			return err
+		}
+
+		// the soft-deleted row keeps its id for anonymized polls and votes,
+		// but nothing that identifies the person behind it
+		rows, err := gorm.G[domain.User](tx).Where("id = ?", userID).Select("username", "email", "password", "email_verified", "profile_picture").Updates(ctx, domain.User{
+			Username: "Deleted user",
+			Email:    "deleted-" + userID.String() + "@users.invalid",
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return utils.UserNotFoundError
+		}
+
+		_, err = gorm.G[domain.User](tx).Where("id = ?", userID).Delete(ctx)
+
+		return err
+	})
+}
+
+func (repo *userRepository) updateColumn(ctx context.Context, userID uuid.UUID, column string, value any) error {
+
+	rows, err := gorm.G[domain.User](repo.db).Where("id = ?", userID).Update(ctx, column, value)
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.UserNotFoundError
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) repository.UserTokenRepository {
+	return &userTokenRepository{
+		db: db,
+	}
+}
+
+func (repo *userTokenRepository) Save(ctx context.Context, token *domain.UserToken) error {
+
+	return gorm.G[domain.UserToken](repo.db).Create(ctx, token)
+}
+
+func (repo *userTokenRepository) FindByHash(ctx context.Context, purpose domain.TokenPurpose, tokenHash string) (*domain.UserToken, error) {
+
+	token, err := gorm.G[domain.UserToken](repo.db).
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.TokenNotFoundError
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (repo *userTokenRepository) MarkUsed(ctx context.Context, tokenID uuid.UUID) error {
+
+	// the used_at guard makes consuming a token atomic, so two concurrent
+	// requests can never both redeem it
+	rows, err := gorm.G[domain.UserToken](repo.db).
+		Where("id = ? AND used_at IS NULL", tokenID).
+		Update(ctx, "used_at", time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return utils.TokenAlreadyUsedError
+	}
+
+	return nil
+}
+
+func (repo *userTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose) error {
+
+	_, err := gorm.G[domain.UserToken](repo.db).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Delete(ctx)
+
+	return err
+}
@@ -2,6 +2,8 @@ package persistence
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/application/repository"
@@ -19,30 +21,206 @@ func NewVoteRepository(db *gorm.DB) repository.VoteRepository {
 	return &votereposutory{db: db}
 }
 
-func (v *votereposutory) Vote(ctx context.Context, pollID uuid.UUID, optionID uuid.UUID, userID uuid.UUID) error {
+func (v *votereposutory) SaveVotes(ctx context.Context, ballots []domain.Ballot) ([]error, error) {
 
+	results := make([]error, len(ballots))
 
-	err := gorm.G[domain.Vote](v.db).Create(ctx, &domain.Vote{
-		PollID: pollID,
-		UserID: userID,
-		OptionID: optionID,
+	if len(ballots) == 0 {
+		return results, nil
+	}
+
+	voteIDs := make([]uuid.UUID, len(ballots))
+
+	for i := range ballots {
+		voteIDs[i] = uuid.New()
+	}
+
+	query, args := insertBallots(ballots, voteIDs, time.Now())
+
+	err := conn(ctx, v.db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		var recorded []uuid.UUID
+
+		err := tx.Raw(query, args...).Scan(&recorded).Error
+
+		if err != nil {
+			return err
+		}
+
+		saved := make(map[uuid.UUID]bool, len(recorded))
+
+		for _, voteID := range recorded {
+			saved[voteID] = true
+		}
+
+		events := make([]domain.OutboxEvent, 0, len(recorded))
+		var rejected [][]any
+
+		for i, ballot := range ballots {
+
+			if !saved[voteIDs[i]] {
+				rejected = append(rejected, []any{ballot.UserID, ballot.PollID})
+				continue
+			}
+
+			event, err := domain.NewOutboxEvent(domain.OutboxVoteCast, ballot.PollID, domain.VoteCastPayload{
+				OptionID: ballot.OptionID,
+				UserID:   ballot.UserID,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			events = append(events, event)
+		}
+
+		if err := saveOutboxEvents(ctx, tx, events); err != nil {
+			return err
+		}
+
+		if len(rejected) == 0 {
+			return nil
+		}
+
+		// a ballot that was skipped lost to a vote of the same user, or was
+		// cast in a poll that can no longer take votes
+		var voted []domain.Vote
+
+		err = tx.Model(&domain.Vote{}).
+			Select("user_id", "poll_id").
+			Where("(user_id, poll_id) IN ?", rejected).
+			Find(&voted).Error
+
+		if err != nil {
+			return err
+		}
+
+		hasVote := make(map[[2]uuid.UUID]bool, len(voted))
+
+		for _, vote := range voted {
+			hasVote[[2]uuid.UUID{vote.UserID, vote.PollID}] = true
+		}
+
+		for i, ballot := range ballots {
+
+			if saved[voteIDs[i]] {
+				continue
+			}
+
+			if hasVote[[2]uuid.UUID{ballot.UserID, ballot.PollID}] {
+				results[i] = utils.VoteAlreadyExistsError
+			} else {
+				results[i] = utils.PollExpiredError
+			}
+		}
+
+		return nil
 	})
 
-	if err == gorm.ErrDuplicatedKey {
-		return utils.VoteAlreadyExistsError
+	if err != nil {
+		return nil, err
 	}
 
-	return err
+	return results, nil
+}
+
+// insertBallots returns the statement that inserts the ballots as the votes
+// voteIDs, returning the ids of those recorded, with its arguments in the
+// order of its placeholders. The poll is checked again here, since the
+// ballots were validated against a copy that may be stale. Rows are inserted
+// in key order so concurrent batches wait on each other's votes rather than
+// deadlock, and a user's second ballot is skipped by the unique index.
+func insertBallots(ballots []domain.Ballot, voteIDs []uuid.UUID, now time.Time) (string, []any) {
+
+	rows := make([]string, len(ballots))
+	args := make([]any, 0, len(ballots)*4+3)
+
+	args = append(args, now, now)
+
+	for i, ballot := range ballots {
+		rows[i] = "(?::uuid, ?::uuid, ?::uuid, ?::uuid)"
+		args = append(args, voteIDs[i], ballot.UserID, ballot.PollID, ballot.OptionID)
+	}
+
+	args = append(args, now)
+
+	return `
+		INSERT INTO votes (id, user_id, poll_id, option_id, created_at, updated_at)
+		SELECT ballot.id, ballot.user_id, ballot.poll_id, ballot.option_id, ?::timestamptz, ?::timestamptz
+		FROM (VALUES ` + strings.Join(rows, ", ") + `) AS ballot (id, user_id, poll_id, option_id)
+		WHERE EXISTS (
+			SELECT 1 FROM polls
+			WHERE polls.id = ballot.poll_id AND polls.deleted_at IS NULL AND polls.expires_at > ?
+		) AND EXISTS (
+			SELECT 1 FROM options
+			WHERE options.id = ballot.option_id AND options.poll_id = ballot.poll_id AND options.deleted_at IS NULL
+		)
+		ORDER BY ballot.poll_id, ballot.user_id
+		ON CONFLICT (user_id, poll_id) WHERE deleted_at IS NULL DO NOTHING
+		RETURNING id`, args
 }
 
 func (v *votereposutory) ExistsByPollIDAndAndUserID(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) (bool, error) {
 
 
-	votes, err := gorm.G[domain.Vote](v.db).Where("poll_id = ? AND user_id = ?", pollID, userID).Find(ctx)
+	votes, err := gorm.G[domain.Vote](conn(ctx, v.db)).Where("poll_id = ? AND user_id = ?", pollID, userID).Find(ctx)
 
 	if err == gorm.ErrDuplicatedKey {
 		return false, err
 	}
 
 	return len(votes) > 0, nil
-}
\ No newline at end of file
+}
+
+func (v *votereposutory) FindVotesByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Vote, error) {
+
+	return gorm.G[domain.Vote](conn(ctx, v.db)).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(ctx)
+}
+
+func (v *votereposutory) DeleteByPollID(ctx context.Context, pollID uuid.UUID) error {
+
+	_, err := gorm.G[domain.Vote](conn(ctx, v.db)).Where("poll_id = ?", pollID).Delete(ctx)
+
+	return err
+}
+
+func (v *votereposutory) FindVotesByPollID(ctx context.Context, pollID uuid.UUID, after *domain.VoteCursor, limit int) ([]domain.Vote, error) {
+
+	query := gorm.G[domain.Vote](conn(ctx, v.db)).Where("poll_id = ?", pollID)
+
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	return query.Order("created_at, id").Limit(limit).Find(ctx)
+}
+
+func (v *votereposutory) FindVoterIDs(ctx context.Context, pollID uuid.UUID) ([]uuid.UUID, error) {
+
+	var voterIDs []uuid.UUID
+
+	err := conn(ctx, v.db).WithContext(ctx).Model(&domain.Vote{}).
+		Where("poll_id = ?", pollID).
+		Pluck("user_id", &voterIDs).Error
+
+	return voterIDs, err
+}
+
+func (v *votereposutory) FindVotedUserIDs(ctx context.Context, pollID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var voterIDs []uuid.UUID
+
+	err := conn(ctx, v.db).WithContext(ctx).Model(&domain.Vote{}).
+		Where("poll_id = ? AND user_id IN ?", pollID, userIDs).
+		Pluck("user_id", &voterIDs).Error
+
+	return voterIDs, err
+}
@@ -0,0 +1,286 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/winnerx0/jille/infra/database"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const benchVotes = 100_000
+
+// openTestDB connects to the database in TEST_DATABASE_URL, brought up to
+// the latest migration. Without one the test is skipped, except in CI where
+// it has to run.
+func openTestDB(tb testing.TB) *gorm.DB {
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+
+	if dsn == "" {
+		if os.Getenv("CI") != "" {
+			tb.Fatal("TEST_DATABASE_URL must be set in CI")
+		}
+		tb.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Discard})
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	migrator, err := database.NewMigrator(db)
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		tb.Fatal(err)
+	}
+
+	return db
+}
+
+// seedUsers creates n users whose emails start with prefix and returns
+// their ids. They are removed when the test ends.
+func seedUsers(tb testing.TB, db *gorm.DB, prefix string, n int) []uuid.UUID {
+
+	err := db.Exec(`
+		INSERT INTO users (id, username, email, password, joined_at, created_at, updated_at)
+		SELECT gen_random_uuid(), ? || g, ? || lpad(g::text, 6, '0') || '@example.com', '', now(), now(), now()
+		FROM generate_series(1, ?) AS g`, prefix, prefix, n).Error
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() {
+		db.Exec("DELETE FROM users WHERE email LIKE ?", prefix+"%")
+	})
+
+	var userIDs []uuid.UUID
+
+	if err := db.Raw("SELECT id FROM users WHERE email LIKE ? ORDER BY email", prefix+"%").Scan(&userIDs).Error; err != nil {
+		tb.Fatal(err)
+	}
+
+	return userIDs
+}
+
+// seedPoll creates a poll run by userID with the named options, removed
+// with its votes and events when the test ends.
+func seedPoll(tb testing.TB, db *gorm.DB, userID uuid.UUID, expiresAt time.Time, names ...string) domain.Poll {
+
+	poll := domain.Poll{
+		ID:                uuid.New(),
+		Title:             "Test poll",
+		UserID:            userID,
+		ExpiresAt:         expiresAt,
+		ResultsVisibility: domain.ResultsVisibilityAlways,
+	}
+
+	for _, name := range names {
+		poll.Options = append(poll.Options, domain.Option{ID: uuid.New(), Name: name})
+	}
+
+	if err := db.Create(&poll).Error; err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() {
+		db.Exec("DELETE FROM outbox_events WHERE poll_id = ?", poll.ID)
+		db.Exec("DELETE FROM polls WHERE id = ?", poll.ID)
+	})
+
+	return poll
+}
+
+// seedBenchPoll creates a poll with four options and one vote for each of
+// benchVotes new users, and removes them all when the benchmark ends.
+func seedBenchPoll(b *testing.B, db *gorm.DB) uuid.UUID {
+
+	prefix := "bench-" + uuid.NewString() + "-"
+	userIDs := seedUsers(b, db, prefix, benchVotes+1)
+	poll := seedPoll(b, db, userIDs[0], time.Now().Add(time.Hour), "A", "B", "C", "D")
+
+	err := db.Exec(`
+		INSERT INTO votes (id, user_id, poll_id, option_id, created_at, updated_at)
+		SELECT gen_random_uuid(), u.id, ?, (ARRAY[?, ?, ?, ?]::uuid[])[1 + (row_number() OVER () % 4)], now(), now()
+		FROM users u
+		WHERE u.email LIKE ? AND u.id <> ?`,
+		poll.ID, poll.Options[0].ID, poll.Options[1].ID, poll.Options[2].ID, poll.Options[3].ID, prefix+"%", userIDs[0]).Error
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if err := db.Exec("ANALYZE votes").Error; err != nil {
+		b.Fatal(err)
+	}
+
+	return poll.ID
+}
+
+func TestInsertBallots_ArgumentsFollowPlaceholders(t *testing.T) {
+
+	ballots := []domain.Ballot{
+		{PollID: uuid.New(), OptionID: uuid.New(), UserID: uuid.New()},
+		{PollID: uuid.New(), OptionID: uuid.New(), UserID: uuid.New()},
+	}
+	voteIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	query, args := insertBallots(ballots, voteIDs, time.Now())
+
+	placeholders := strings.Split(query, "?")[1:]
+
+	assert.Len(t, args, len(placeholders))
+
+	for i, rest := range placeholders {
+		if strings.HasPrefix(rest, "::uuid") {
+			assert.IsType(t, uuid.UUID{}, args[i], "placeholder %d", i)
+		} else {
+			assert.IsType(t, time.Time{}, args[i], "placeholder %d", i)
+		}
+	}
+
+	assert.Equal(t, voteIDs[0], args[2])
+	assert.Equal(t, ballots[1].OptionID, args[9])
+}
+
+func TestSaveVotes(t *testing.T) {
+
+	db := openTestDB(t)
+	repo := NewVoteRepository(db)
+	ctx := context.Background()
+
+	userIDs := seedUsers(t, db, "save-votes-"+uuid.NewString()+"-", 4)
+	owner, first, second, late := userIDs[0], userIDs[1], userIDs[2], userIDs[3]
+
+	poll := seedPoll(t, db, owner, time.Now().Add(time.Hour), "A", "B")
+	closed := seedPoll(t, db, owner, time.Now().Add(-time.Hour), "A")
+	a, b := poll.Options[0].ID, poll.Options[1].ID
+
+	results, err := repo.SaveVotes(ctx, []domain.Ballot{
+		{PollID: poll.ID, OptionID: a, UserID: first},
+		{PollID: poll.ID, OptionID: b, UserID: second},
+		{PollID: poll.ID, OptionID: b, UserID: first},
+		{PollID: closed.ID, OptionID: closed.Options[0].ID, UserID: late},
+	})
+
+	if assert.NoError(t, err) {
+		assert.NoError(t, results[0])
+		assert.NoError(t, results[1])
+		assert.ErrorIs(t, results[2], utils.VoteAlreadyExistsError)
+		assert.ErrorIs(t, results[3], utils.PollExpiredError)
+	}
+
+	results, err = repo.SaveVotes(ctx, []domain.Ballot{{PollID: poll.ID, OptionID: a, UserID: second}})
+
+	if assert.NoError(t, err) {
+		assert.ErrorIs(t, results[0], utils.VoteAlreadyExistsError)
+	}
+
+	options, err := NewOptionRepository(db).FindOptionsByPollID(ctx, poll.ID)
+
+	if assert.NoError(t, err) {
+		counts := map[uuid.UUID]int{}
+		for _, option := range *options {
+			counts[option.ID] = option.VoteCount
+		}
+		assert.Equal(t, map[uuid.UUID]int{a: 1, b: 1}, counts)
+	}
+
+	var events int64
+
+	assert.NoError(t, db.Model(&domain.OutboxEvent{}).Where("poll_id = ? AND type = ?", poll.ID, domain.OutboxVoteCast).Count(&events).Error)
+	assert.Equal(t, int64(2), events)
+}
+
+// BenchmarkFindPollByID reads the counts of a poll with benchVotes votes,
+// which the options keep. Run it with
+//
+//	TEST_DATABASE_URL=postgres://... go test ./infra/persistence -run '^$' -bench FindPollByID -benchmem
+func BenchmarkFindPollByID(b *testing.B) {
+
+	db := openTestDB(b)
+	pollID := seedBenchPoll(b, db)
+	repo := NewPollRepository(db)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		poll, err := repo.FindPollByID(ctx, pollID)
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if total := countVotes(poll.Options); total != benchVotes {
+			b.Fatalf("counted %d votes, want %d", total, benchVotes)
+		}
+	}
+}
+
+// BenchmarkFindPollByID_LoadVotes is the same lookup done the way it was
+// before votes were counted in the database: every vote row is loaded and
+// counted in Go. It is kept as the baseline for BenchmarkFindPollByID.
+func BenchmarkFindPollByID_LoadVotes(b *testing.B) {
+
+	db := openTestDB(b)
+	pollID := seedBenchPoll(b, db)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		poll, err := gorm.G[domain.Poll](db).Preload("Options", nil).Where("id = ?", pollID).First(ctx)
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		votes, err := gorm.G[domain.Vote](db).Where("poll_id = ?", pollID).Find(ctx)
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for i := range poll.Options {
+			// the options come with the count the database keeps
+			poll.Options[i].VoteCount = 0
+
+			for _, vote := range votes {
+				if vote.OptionID == poll.Options[i].ID {
+					poll.Options[i].VoteCount++
+				}
+			}
+		}
+
+		if total := countVotes(poll.Options); total != benchVotes {
+			b.Fatalf("counted %d votes, want %d", total, benchVotes)
+		}
+	}
+}
+
+func countVotes(options []domain.Option) int {
+
+	total := 0
+
+	for _, option := range options {
+		total += option.VoteCount
+	}
+
+	return total
+}
@@ -0,0 +1,155 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+func (repo *webhookRepository) Save(ctx context.Context, webhook *domain.Webhook) error {
+
+	return gorm.G[domain.Webhook](repo.db).Create(ctx, webhook)
+}
+
+func (repo *webhookRepository) FindByID(ctx context.Context, webhookID uuid.UUID) (*domain.Webhook, error) {
+
+	webhook, err := gorm.G[domain.Webhook](repo.db).Where("id = ?", webhookID).First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.WebhookNotFoundError
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (repo *webhookRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Webhook, error) {
+
+	return gorm.G[domain.Webhook](repo.db).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(ctx)
+}
+
+func (repo *webhookRepository) FindForPoll(ctx context.Context, pollID uuid.UUID, creatorID uuid.UUID) ([]domain.Webhook, error) {
+
+	return gorm.G[domain.Webhook](repo.db).
+		Where("poll_id = ? OR (poll_id IS NULL AND user_id = ?)", pollID, creatorID).
+		Find(ctx)
+}
+
+func (repo *webhookRepository) Delete(ctx context.Context, webhookID uuid.UUID) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		rows, err := gorm.G[domain.Webhook](tx).Where("id = ?", webhookID).Delete(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return utils.WebhookNotFoundError
+		}
+
+		// nothing is sent to a removed webhook, its log goes with it
+		_, err = gorm.G[domain.WebhookDelivery](tx).Where("webhook_id = ?", webhookID).Delete(ctx)
+
+		return err
+	})
+}
+
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) repository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		db: db,
+	}
+}
+
+func (repo *webhookDeliveryRepository) SaveAll(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+
+	return repo.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&deliveries, 100).Error
+}
+
+func (repo *webhookDeliveryRepository) FindByID(ctx context.Context, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+
+	delivery, err := gorm.G[domain.WebhookDelivery](repo.db).Where("id = ?", deliveryID).First(ctx)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.WebhookDeliveryNotFoundError
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (repo *webhookDeliveryRepository) FindByWebhookID(ctx context.Context, webhookID uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+
+	return gorm.G[domain.WebhookDelivery](repo.db).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(ctx)
+}
+
+func (repo *webhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+
+	var deliveries []domain.WebhookDelivery
+
+	err := repo.db.WithContext(ctx).Raw(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), domain.WebhookDeliveryPending, now, limit).Scan(&deliveries).Error
+
+	return deliveries, err
+}
+
+func (repo *webhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+
+	_, err := gorm.G[domain.WebhookDelivery](repo.db).
+		Where("id = ?", delivery.ID).
+		Select("status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "response_body", "error").
+		Updates(ctx, *delivery)
+
+	return err
+}
+
+func (repo *webhookDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+
+	_, err := gorm.G[domain.WebhookDelivery](repo.db).
+		Where("created_at < ? AND status <> ?", before, domain.WebhookDeliveryPending).
+		Delete(ctx)
+
+	return err
+}
@@ -0,0 +1,65 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type responder struct {
+	client *http.Client
+}
+
+func NewResponder(timeout time.Duration) application.SlackResponder {
+	return &responder{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (r *responder) Respond(ctx context.Context, responseURL string, message dto.SlackMessage) error {
+
+	// response urls come from signed requests, but are still only ever
+	// posted to Slack
+	target, err := url.Parse(responseURL)
+
+	if err != nil || target.Scheme != "https" || target.Hostname() != "hooks.slack.com" {
+		return fmt.Errorf("unexpected slack response url %q", responseURL)
+	}
+
+	body, err := json.Marshal(message)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := r.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("slack responded with status %d: %s", res.StatusCode, detail)
+	}
+
+	return nil
+}
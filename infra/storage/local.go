@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/winnerx0/jille/internal/application"
+)
+
+// localStorage keeps files in a directory on disk that the server exposes
+// under baseURL.
+type localStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir string, baseURL string) application.FileStorage {
+	return &localStorage{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (s *localStorage) Save(ctx context.Context, name string, data []byte) error {
+
+	target, err := s.path(name)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never see a partial upload
+	tmp := target + ".tmp"
+
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, target)
+}
+
+func (s *localStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+
+	target, err := s.path(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(target)
+}
+
+func (s *localStorage) Delete(ctx context.Context, name string) error {
+
+	target, err := s.path(name)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *localStorage) URL(name string) string {
+
+	return s.baseURL + "/" + path.Clean(name)
+}
+
+func (s *localStorage) path(name string) (string, error) {
+
+	clean := path.Clean("/" + name)
+
+	if clean == "/" {
+		return "", errors.New("invalid file name")
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
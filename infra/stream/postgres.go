@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+// channel is the Postgres notification channel stream events go out on.
+const channel = "stream_events"
+
+// message is an event as it is sent between instances. Unlike the event
+// sent to clients it keeps the poll and user it is meant for.
+type message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	PollID  string          `json:"poll_id,omitempty"`
+	UserID  string          `json:"user_id,omitempty"`
+}
+
+type postgresPublisher struct {
+	db *gorm.DB
+}
+
+// NewPostgresPublisher returns a publisher that sends events to every
+// instance of the app, through Postgres notifications, rather than to the
+// streams open in this one. Each instance hands them to its own streams with
+// Listen.
+func NewPostgresPublisher(db *gorm.DB) application.EventPublisher {
+	return &postgresPublisher{
+		db: db,
+	}
+}
+
+func (p *postgresPublisher) Publish(event utils.Event) {
+
+	payload, err := encode(event)
+
+	if err != nil {
+		fmt.Println("error encoding stream event", event.Type, err.Error())
+		return
+	}
+
+	if err := p.db.Exec("SELECT pg_notify(?, ?)", channel, payload).Error; err != nil {
+		fmt.Println("error publishing stream event", event.Type, err.Error())
+	}
+}
+
+// Listen hands the events published by any instance to local until ctx
+// ends, listening again after a lost connection.
+func Listen(ctx context.Context, db *gorm.DB, local application.EventPublisher) {
+
+	for ctx.Err() == nil {
+
+		err := listen(ctx, db, local)
+
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		fmt.Println("error listening for stream events", err.Error())
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second * 5):
+		}
+	}
+}
+
+func listen(ctx context.Context, db *gorm.DB, local application.EventPublisher) error {
+
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+
+		if _, err := pgConn.Exec(ctx, "LISTEN "+channel); err != nil {
+			return err
+		}
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+
+			if err != nil {
+				// the connection is still listening, keep it out of the pool
+				return errors.Join(err, driver.ErrBadConn)
+			}
+
+			event, err := decode(notification.Payload)
+
+			if err != nil {
+				fmt.Println("error decoding stream event", err.Error())
+				continue
+			}
+
+			local.Publish(event)
+		}
+	})
+}
+
+func encode(event utils.Event) (string, error) {
+
+	payload, err := json.Marshal(event.Payload)
+
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(message{
+		ID:      event.ID,
+		Type:    event.Type,
+		Payload: payload,
+		PollID:  event.PollID,
+		UserID:  event.UserID,
+	})
+
+	return string(data), err
+}
+
+func decode(payload string) (utils.Event, error) {
+
+	var m message
+
+	if err := json.Unmarshal([]byte(payload), &m); err != nil {
+		return utils.Event{}, err
+	}
+
+	return utils.Event{
+		ID:      m.ID,
+		Type:    m.Type,
+		Payload: m.Payload,
+		PollID:  m.PollID,
+		UserID:  m.UserID,
+	}, nil
+}
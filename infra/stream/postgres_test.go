@@ -0,0 +1,37 @@
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestDecode_KeepsWhoTheEventIsFor(t *testing.T) {
+	event := utils.Event{
+		ID:      "5c3f0d1e-3b7a-4a53-9d0c-2f3a1d6b7e8f",
+		Type:    "POLL_VOTE",
+		Payload: dto.VoteRequest{PollID: "poll", OptionID: "option"},
+		PollID:  "poll",
+		UserID:  "user",
+	}
+
+	payload, err := encode(event)
+	assert.NoError(t, err)
+
+	decoded, err := decode(payload)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, event.ID, decoded.ID)
+		assert.Equal(t, event.Type, decoded.Type)
+		assert.Equal(t, event.PollID, decoded.PollID)
+		assert.Equal(t, event.UserID, decoded.UserID)
+
+		// clients are sent the same event either way
+		sent, _ := json.Marshal(event)
+		relayed, _ := json.Marshal(decoded)
+		assert.JSONEq(t, string(sent), string(relayed))
+	}
+}
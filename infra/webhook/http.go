@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// maxResponseBody is how much of a receiver's response the delivery log
+// keeps.
+const maxResponseBody = 1024
+
+var errAddressNotAllowed = errors.New("webhook receiver is not at a public address")
+
+type httpSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration) application.WebhookSender {
+	return newHTTPSender(timeout, utils.IsPublicAddr)
+}
+
+// newHTTPSender returns a sender that only connects to addresses allowed
+// reports true for. They are checked once the receiver's name is resolved, so
+// a name pointing at an internal address is refused like the address itself.
+func newHTTPSender(timeout time.Duration, allowed func(netip.Addr) bool) *httpSender {
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+
+			addrPort, err := netip.ParseAddrPort(address)
+
+			if err != nil || !allowed(addrPort.Addr()) {
+				return errAddressNotAllowed
+			}
+
+			return nil
+		},
+	}
+
+	return &httpSender{
+		client: &http.Client{
+			Timeout: timeout,
+			// no proxy, it would be the one connecting to the receiver
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+			// a redirect is reported as the receiver's response rather
+			// than posting the payload somewhere else
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (s *httpSender) Send(ctx context.Context, request application.WebhookRequest) (*application.WebhookResponse, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := s.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+
+	return &application.WebhookResponse{
+		Status: res.StatusCode,
+		Body:   string(body),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/winnerx0/jille/internal/application"
+)
+
+func TestHTTPSender_RefusesInternalAddresses(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the receiver should not have been reached")
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	sender := NewHTTPSender(time.Second)
+
+	// the name is refused once it resolves, like the address
+	for _, url := range []string{server.URL, "http://localhost:" + port} {
+		_, err := sender.Send(context.Background(), application.WebhookRequest{URL: url})
+
+		assert.ErrorIs(t, err, errAddressNotAllowed, url)
+	}
+}
+
+func TestHTTPSender_PostsToAllowedAddresses(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "sig", r.Header.Get("X-Signature"))
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	sender := newHTTPSender(time.Second, func(netip.Addr) bool { return true })
+
+	response, err := sender.Send(context.Background(), application.WebhookRequest{
+		URL:     server.URL,
+		Body:    []byte("{}"),
+		Headers: map[string]string{"X-Signature": "sig"},
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusAccepted, response.Status)
+		assert.Equal(t, "ok", response.Body)
+	}
+}
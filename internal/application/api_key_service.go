@@ -0,0 +1,18 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type APIKeyService interface {
+	CreateKey(ctx context.Context, createRequest dto.CreateAPIKeyRequest) (*dto.CreatedAPIKeyResponse, error)
+
+	GetKeys(ctx context.Context) ([]dto.APIKeyResponse, error)
+
+	RevokeKey(ctx context.Context, keyID uuid.UUID) error
+
+	Authenticate(ctx context.Context, rawKey string) (*dto.APIKeyPrincipal, error)
+}
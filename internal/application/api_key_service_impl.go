@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// APIKeyPrefix marks a bearer credential as an API key rather than a JWT.
+const APIKeyPrefix = "jille_"
+
+type apikeyservice struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apikeyservice{
+		repo: repo,
+	}
+}
+
+func (s *apikeyservice) CreateKey(ctx context.Context, createRequest dto.CreateAPIKeyRequest) (*dto.CreatedAPIKeyResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if createRequest.ExpiresAt != nil && createRequest.ExpiresAt.Before(time.Now()) {
+		return nil, utils.InvalidAPIKeyExpiryError
+	}
+
+	secret, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rawKey := APIKeyPrefix + secret
+
+	scopes := slices.Clone(createRequest.Scopes)
+	slices.Sort(scopes)
+
+	key := domain.APIKey{
+		UserID:    userID,
+		Name:      createRequest.Name,
+		Prefix:    rawKey[:len(APIKeyPrefix)+6],
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: createRequest.ExpiresAt,
+	}
+
+	if err := s.repo.Save(ctx, &key); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedAPIKeyResponse{
+		APIKeyResponse: apiKeyResponse(key),
+		Key:            rawKey,
+	}, nil
+}
+
+func (s *apikeyservice) GetKeys(ctx context.Context) ([]dto.APIKeyResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	keys, err := s.repo.FindByUserID(ctx, userID)
+
+	if err != nil {
+		return []dto.APIKeyResponse{}, err
+	}
+
+	response := make([]dto.APIKeyResponse, len(keys))
+
+	for i, key := range keys {
+		response[i] = apiKeyResponse(key)
+	}
+
+	return response, nil
+}
+
+func (s *apikeyservice) RevokeKey(ctx context.Context, keyID uuid.UUID) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	return s.repo.Revoke(ctx, keyID, userID)
+}
+
+func (s *apikeyservice) Authenticate(ctx context.Context, rawKey string) (*dto.APIKeyPrincipal, error) {
+
+	if !strings.HasPrefix(rawKey, APIKeyPrefix) {
+		return nil, utils.InvalidAPIKeyError
+	}
+
+	key, err := s.repo.FindByHash(ctx, utils.HashToken(rawKey))
+
+	if err != nil {
+		if err == utils.APIKeyNotFoundError {
+			return nil, utils.InvalidAPIKeyError
+		}
+		return nil, err
+	}
+
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now())) {
+		return nil, utils.InvalidAPIKeyError
+	}
+
+	// failing to record usage should not fail the request
+	if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+		fmt.Println("error updating api key last used", err.Error())
+	}
+
+	return &dto.APIKeyPrincipal{
+		UserID: key.UserID,
+		Scopes: key.Scopes,
+	}, nil
+}
+
+func apiKeyResponse(key domain.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,129 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestCreateKey_StoresOnlyHash(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	var saved *domain.APIKey
+
+	mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.APIKey")).Return(nil).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*domain.APIKey)
+	})
+
+	resp, err := service.CreateKey(ctx, dto.CreateAPIKeyRequest{
+		Name:   "ci bot",
+		Scopes: []string{domain.ScopePollsWrite, domain.ScopePollsRead, domain.ScopePollsWrite},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.Key, APIKeyPrefix))
+	assert.Equal(t, utils.HashToken(resp.Key), saved.KeyHash)
+	assert.NotContains(t, saved.KeyHash, resp.Key)
+	assert.True(t, strings.HasPrefix(resp.Key, saved.Prefix))
+	assert.Equal(t, []string{domain.ScopePollsRead, domain.ScopePollsWrite}, saved.Scopes)
+	assert.Equal(t, userID, saved.UserID)
+}
+
+func TestCreateKey_ExpiryInPast(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+	expiresAt := time.Now().Add(-time.Hour)
+
+	_, err := service.CreateKey(ctx, dto.CreateAPIKeyRequest{
+		Name:      "old",
+		Scopes:    []string{domain.ScopePollsRead},
+		ExpiresAt: &expiresAt,
+	})
+
+	assert.ErrorIs(t, err, utils.InvalidAPIKeyExpiryError)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate_Success(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+
+	ctx := context.Background()
+	key := &domain.APIKey{
+		ID:     uuid.New(),
+		UserID: uuid.New(),
+		Scopes: []string{domain.ScopeVotesWrite},
+	}
+
+	mockRepo.On("FindByHash", ctx, utils.HashToken("jille_secret")).Return(key, nil)
+	mockRepo.On("TouchLastUsed", ctx, key.ID).Return(nil)
+
+	principal, err := service.Authenticate(ctx, "jille_secret")
+
+	assert.NoError(t, err)
+	assert.Equal(t, key.UserID, principal.UserID)
+	assert.Equal(t, key.Scopes, principal.Scopes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthenticate_Revoked(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+
+	ctx := context.Background()
+	revokedAt := time.Now()
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		RevokedAt: &revokedAt,
+	}
+
+	mockRepo.On("FindByHash", ctx, utils.HashToken("jille_secret")).Return(key, nil)
+
+	_, err := service.Authenticate(ctx, "jille_secret")
+
+	assert.ErrorIs(t, err, utils.InvalidAPIKeyError)
+	mockRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate_Unknown(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+
+	ctx := context.Background()
+
+	mockRepo.On("FindByHash", ctx, utils.HashToken("jille_missing")).Return(nil, utils.APIKeyNotFoundError)
+
+	_, err := service.Authenticate(ctx, "jille_missing")
+
+	assert.ErrorIs(t, err, utils.InvalidAPIKeyError)
+}
+
+func TestRevokeKey_ScopedToUser(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+
+	userID := uuid.New()
+	keyID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("Revoke", ctx, keyID, userID).Return(nil)
+
+	assert.NoError(t, service.RevokeKey(ctx, keyID))
+	mockRepo.AssertExpectations(t)
+}
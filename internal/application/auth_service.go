@@ -3,6 +3,7 @@ package application
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/common/dto"
 )
 
@@ -11,5 +12,36 @@ type AuthService interface {
 
 	Login(ctx context.Context, loginRequest dto.LoginUserRequest) (*dto.AuthResponse, error)
 
+	// CompleteLogin signs in a user who has proven who they are, with a
+	// password or through an identity provider. Users with two-factor
+	// authentication get an MFA challenge instead of tokens.
+	CompleteLogin(ctx context.Context, userID uuid.UUID) (*dto.AuthResponse, error)
+
+	// LoginMFA completes a login that CompleteLogin answered with an MFA
+	// challenge.
+	LoginMFA(ctx context.Context, mfaRequest dto.MFALoginRequest) (*dto.AuthResponse, error)
+
 	RefreshToken(ctx context.Context, refreshTokenRequest dto.RefreshTokenRequest) (*dto.AuthResponse, error)
+
+	VerifyEmail(ctx context.Context, verifyEmailRequest dto.VerifyEmailRequest) error
+
+	ResendVerification(ctx context.Context, resendRequest dto.ResendVerificationRequest) error
+
+	RequestPasswordReset(ctx context.Context, resetRequest dto.PasswordResetRequest) error
+
+	ResetPassword(ctx context.Context, confirmRequest dto.PasswordResetConfirmRequest) error
+
+	// ChangePassword replaces the password of the signed in user after
+	// checking the current one, ending every other session.
+	ChangePassword(ctx context.Context, changeRequest dto.ChangePasswordRequest) (*dto.AuthResponse, error)
+
+	// RequestEmailChange mails a confirmation link to the new address. The
+	// account keeps its current email until ConfirmEmailChange is called.
+	RequestEmailChange(ctx context.Context, changeRequest dto.ChangeEmailRequest) error
+
+	ConfirmEmailChange(ctx context.Context, confirmRequest dto.VerifyEmailRequest) error
+
+	// IssueTokens starts a new session for a user that has already been
+	// fully authenticated, second factor included.
+	IssueTokens(ctx context.Context, userID uuid.UUID, message string) (*dto.AuthResponse, error)
 }
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/application/repository"
 	"github.com/winnerx0/jille/internal/common/dto"
 	"github.com/winnerx0/jille/internal/domain"
@@ -14,20 +17,71 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	emailVerificationTokenTTL = time.Hour * 24
+	passwordResetTokenTTL     = time.Hour
+	emailChangeTokenTTL       = time.Hour * 24
+	passwordHashCost          = 10
+
+	// providerSignInWindow is how recent a sign-in through an identity
+	// provider has to be to confirm a change to a passwordless account
+	providerSignInWindow = time.Minute * 5
+)
+
+// confirmIdentity checks that the signed in user is the account's owner
+// before a change to it: with their password, or for passwordless accounts
+// with a recent sign-in through their identity provider.
+func confirmIdentity(user dto.UserAuthView, password string) error {
+
+	if user.Passwordless {
+		if user.ProviderSignInAt == nil || time.Since(*user.ProviderSignInAt) > providerSignInWindow {
+			return utils.ReauthenticationRequiredError
+		}
+		return nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return utils.InvalidPasswordError
+	}
+
+	return nil
+}
+
+// dummyPasswordHash is compared against when a login uses an unknown email.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("jille-dummy-password"), passwordHashCost)
+	return hash
+})
+
 type authservice struct {
 	authrepo repository.AuthRepository
 
+	tokenrepo repository.UserTokenRepository
+
 	userservice UserService
 
 	jwtservice JwtService
+
+	twofactorservice TwoFactorService
+
+	loginguard LoginGuard
+
+	mailer Mailer
+
+	appURL string
 }
 
-func NewAuthService(authrepo repository.AuthRepository, userservice UserService, jwtservice JwtService) AuthService {
+func NewAuthService(authrepo repository.AuthRepository, tokenrepo repository.UserTokenRepository, userservice UserService, jwtservice JwtService, twofactorservice TwoFactorService, loginguard LoginGuard, mailer Mailer, appURL string) AuthService {
 
 	return &authservice{
-		authrepo:    authrepo,
-		userservice: userservice,
-		jwtservice:  jwtservice,
+		authrepo:         authrepo,
+		tokenrepo:        tokenrepo,
+		userservice:      userservice,
+		jwtservice:       jwtservice,
+		twofactorservice: twofactorservice,
+		loginguard:       loginguard,
+		mailer:           mailer,
+		appURL:           appURL,
 	}
 }
 
@@ -43,7 +97,6 @@ func (s *authservice) Register(ctx context.Context, registerRequest dto.CreateUs
 		}
 	}
 
-	fmt.Println("user", existingUser)
 	if existingUser != nil {
 		return nil, utils.UserExistsError
 	}
@@ -54,7 +107,7 @@ func (s *authservice) Register(ctx context.Context, registerRequest dto.CreateUs
 	user.Email = registerRequest.Email
 	user.Username = registerRequest.Username
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerRequest.Password), 10)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerRequest.Password), passwordHashCost)
 
 	if err != nil {
 		return nil, err
@@ -62,92 +115,134 @@ func (s *authservice) Register(ctx context.Context, registerRequest dto.CreateUs
 
 	user.Password = string(hashedPassword)
 
-	s.userservice.CreateUser(ctx, &user)
+	if err := s.userservice.CreateUser(ctx, &user); err != nil {
+		return nil, err
+	}
 
-	fmt.Println("user id", user.ID)
+	if err := s.sendVerificationEmail(ctx, user.ID, user.Username, user.Email); err != nil {
+		fmt.Println("error sending verification email", err.Error())
+	}
 
-	accessToken, err := s.jwtservice.GenerateAccessToken(user.ID.String())
+	return s.IssueTokens(ctx, user.ID, "Registration successful")
+}
 
-	if err != nil {
+func (s *authservice) Login(ctx context.Context, loginRequest dto.LoginUserRequest) (*dto.AuthResponse, error) {
+
+	clientIP, _ := ctx.Value("clientIP").(string)
+
+	if err := s.loginguard.Check(ctx, loginRequest.Email, clientIP); err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtservice.GenerateAccessToken(registerRequest.Email)
+	existingUser, err := s.userservice.GetUserByEmail(ctx, loginRequest.Email)
 
 	if err != nil {
-		return nil, err
+
+		if err == gorm.ErrRecordNotFound {
+			// compare against a dummy hash so unknown emails take as long as
+			// wrong passwords and do not reveal which accounts exist
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(loginRequest.Password))
+
+			return nil, s.loginFailed(ctx, loginRequest.Email, clientIP)
+		} else {
+			return nil, err
+		}
 	}
 
-	token := domain.RefreshToken{
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 30),
-		UserID:    user.ID,
+	if err := bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(loginRequest.Password)); err != nil {
+		return nil, s.loginFailed(ctx, loginRequest.Email, clientIP)
 	}
 
-	s.authrepo.SaveToken(ctx, &token)
+	if err := s.loginguard.RecordSuccess(ctx, loginRequest.Email); err != nil {
+		return nil, err
+	}
 
-	return &dto.AuthResponse{
-		Message: "Regtration successful",
-		AuthTokens: dto.AuthTokens{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-		},
-	}, nil
+	return s.CompleteLogin(ctx, existingUser.ID)
 }
 
-func (s *authservice) Login(ctx context.Context, loginRequest dto.LoginUserRequest) (*dto.AuthResponse, error) {
+func (s *authservice) CompleteLogin(ctx context.Context, userID uuid.UUID) (*dto.AuthResponse, error) {
 
-	existingUser, err := s.userservice.GetUserByEmail(ctx, loginRequest.Email)
+	mfaEnabled, err := s.twofactorservice.IsEnabled(ctx, userID)
 
 	if err != nil {
+		return nil, err
+	}
 
-		if err == gorm.ErrRecordNotFound {
-			return nil, utils.UserNotFoundError
-		} else {
+	// the password alone is not enough, hand back a short-lived challenge
+	// that LoginMFA exchanges for real tokens
+	if mfaEnabled {
+		mfaToken, err := s.jwtservice.GenerateMFAToken(userID.String())
+
+		if err != nil {
 			return nil, err
 		}
+
+		return &dto.AuthResponse{
+			Message:     "Two-factor authentication required",
+			MFARequired: true,
+			AuthTokens: dto.AuthTokens{
+				MFAToken: mfaToken,
+			},
+		}, nil
 	}
 
-	s.authrepo.RevokeAllTokens(ctx, existingUser.ID)
+	return s.IssueTokens(ctx, userID, "Login successful")
+}
 
-	fmt.Println("user", existingUser)
+func (s *authservice) LoginMFA(ctx context.Context, mfaRequest dto.MFALoginRequest) (*dto.AuthResponse, error) {
 
-	if err := bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(loginRequest.Password)); err != nil {
-		return nil, errors.New("Invalid password")
+	subject, err := s.jwtservice.GetMFATokenSubject(mfaRequest.MFAToken)
+
+	if err != nil {
+		return nil, utils.InvalidMFATokenError
 	}
 
-	accessToken, err := s.jwtservice.GenerateAccessToken(existingUser.ID.String())
+	userID, err := uuid.Parse(subject)
 
 	if err != nil {
-		return nil, err
+		return nil, utils.InvalidMFATokenError
 	}
 
-	refreshToken, err := s.jwtservice.GenerateAccessToken(loginRequest.Email)
+	clientIP, _ := ctx.Value("clientIP").(string)
 
-	if err != nil {
+	// challenge tokens live for minutes, so guessing codes is throttled the
+	// same way as guessing passwords
+	account := mfaAccount(userID)
+
+	if err := s.loginguard.Check(ctx, account, clientIP); err != nil {
 		return nil, err
 	}
 
-	token := domain.RefreshToken{
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 30),
-		UserID:    existingUser.ID,
+	if err := s.twofactorservice.Verify(ctx, userID, mfaRequest.Code); err != nil {
+		if errors.Is(err, utils.InvalidTwoFactorCodeError) {
+			if err := s.loginguard.RecordFailure(ctx, account, clientIP); err != nil {
+				fmt.Println("error recording failed login", err.Error())
+			}
+		}
+		return nil, err
 	}
 
-	s.authrepo.SaveToken(ctx, &token)
+	if err := s.loginguard.RecordSuccess(ctx, account); err != nil {
+		return nil, err
+	}
 
-	return &dto.AuthResponse{
-		Message: "Login successful",
-		AuthTokens: dto.AuthTokens{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-		},
-	}, nil
+	return s.IssueTokens(ctx, userID, "Login successful")
+}
+
+// loginFailed records a failed attempt and returns the error shown to the
+// client, which is the same whether the email or the password was wrong.
+func (s *authservice) loginFailed(ctx context.Context, email string, clientIP string) error {
+
+	if err := s.loginguard.RecordFailure(ctx, email, clientIP); err != nil {
+		fmt.Println("error recording failed login", err.Error())
+	}
+
+	return utils.InvalidCredentialsError
 }
 
 func (s *authservice) RefreshToken(ctx context.Context, refreshTokenRequest dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
 
-	existingToken, err := s.authrepo.FindByToken(ctx, refreshTokenRequest.RefreshToken)
+	existingToken, err := s.authrepo.FindByHash(ctx, utils.HashToken(refreshTokenRequest.RefreshToken))
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -157,41 +252,315 @@ func (s *authservice) RefreshToken(ctx context.Context, refreshTokenRequest dto.
 		}
 	}
 
+	// a revoked token belongs to a session that was signed out
+	if existingToken.Revoked {
+		return nil, utils.TokenNotFoundError
+	}
+
 	if existingToken.ExpiresAt.Before(time.Now()) {
 		return nil, utils.TokenExpiredError
 	}
 
-	accessToken, err := s.jwtservice.GenerateAccessToken(existingToken.UserID.String())
+	return s.IssueTokens(ctx, existingToken.UserID, "Refresh token successful")
+}
 
-	if err != nil {
+func (s *authservice) IssueTokens(ctx context.Context, userID uuid.UUID, message string) (*dto.AuthResponse, error) {
+
+	if err := s.authrepo.RevokeAllTokens(ctx, userID); err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtservice.GenerateAccessToken(existingToken.UserID.String())
+	accessToken, err := s.jwtservice.GenerateAccessToken(userID.String())
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.authrepo.RevokeAllTokens(ctx, existingToken.UserID)
+	refreshToken, err := s.jwtservice.GenerateRefreshToken(userID.String())
 
 	if err != nil {
 		return nil, err
 	}
 
 	token := domain.RefreshToken{
-		Token:     refreshToken,
+		TokenHash: utils.HashToken(refreshToken),
 		ExpiresAt: time.Now().Add(time.Hour * 24 * 30),
-		UserID:    existingToken.UserID,
+		UserID:    userID,
 	}
 
-	s.authrepo.SaveToken(ctx, &token)
+	if err := s.authrepo.SaveToken(ctx, &token); err != nil {
+		return nil, err
+	}
 
 	return &dto.AuthResponse{
-		Message: "Refresh token successful",
+		Message: message,
 		AuthTokens: dto.AuthTokens{
 			AccessToken:  accessToken,
 			RefreshToken: refreshToken,
 		},
 	}, nil
-}
\ No newline at end of file
+}
+
+func (s *authservice) VerifyEmail(ctx context.Context, verifyEmailRequest dto.VerifyEmailRequest) error {
+
+	token, err := s.consumeToken(ctx, domain.TokenPurposeEmailVerification, verifyEmailRequest.Token)
+
+	if err != nil {
+		return err
+	}
+
+	return s.userservice.MarkEmailVerified(ctx, token.UserID)
+}
+
+func (s *authservice) ResendVerification(ctx context.Context, resendRequest dto.ResendVerificationRequest) error {
+
+	existingUser, err := s.userservice.GetUserByEmail(ctx, resendRequest.Email)
+
+	if err != nil {
+		// do not reveal whether an account exists for the email
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if existingUser.EmailVerified {
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, existingUser.ID, existingUser.Username, existingUser.Email)
+}
+
+func (s *authservice) RequestPasswordReset(ctx context.Context, resetRequest dto.PasswordResetRequest) error {
+
+	existingUser, err := s.userservice.GetUserByEmail(ctx, resetRequest.Email)
+
+	if err != nil {
+		// do not reveal whether an account exists for the email
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issueToken(ctx, existingUser.ID, domain.TokenPurposePasswordReset, passwordResetTokenTTL, "")
+
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, MailMessage{
+		To:      existingUser.Email,
+		Subject: "Reset your Jille password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password for your Jille account. Use the link below within the next hour to choose a new one:\n\n%s/reset-password?token=%s\n\nIf you did not request this, you can ignore this email.\n",
+			existingUser.Username, s.appURL, token,
+		),
+	})
+}
+
+func (s *authservice) ResetPassword(ctx context.Context, confirmRequest dto.PasswordResetConfirmRequest) error {
+
+	token, err := s.consumeToken(ctx, domain.TokenPurposePasswordReset, confirmRequest.Token)
+
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(confirmRequest.Password), passwordHashCost)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.userservice.UpdatePassword(ctx, token.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	// sign out every existing session once the password changes
+	return s.authrepo.RevokeAllTokens(ctx, token.UserID)
+}
+
+func (s *authservice) ChangePassword(ctx context.Context, changeRequest dto.ChangePasswordRequest) (*dto.AuthResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	existingUser, err := s.userservice.GetUserAuthById(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := confirmIdentity(*existingUser, changeRequest.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(changeRequest.NewPassword), passwordHashCost)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userservice.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return nil, err
+	}
+
+	// a reset link requested before the change must not undo it
+	if err := s.tokenrepo.DeleteByUserID(ctx, userID, domain.TokenPurposePasswordReset); err != nil {
+		return nil, err
+	}
+
+	return s.IssueTokens(ctx, userID, "Password changed successfully")
+}
+
+func (s *authservice) RequestEmailChange(ctx context.Context, changeRequest dto.ChangeEmailRequest) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	existingUser, err := s.userservice.GetUserAuthById(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := confirmIdentity(*existingUser, changeRequest.Password); err != nil {
+		return err
+	}
+
+	if strings.EqualFold(existingUser.Email, changeRequest.Email) {
+		return utils.EmailUnchangedError
+	}
+
+	exists, err := s.userservice.ExistsByEmail(ctx, changeRequest.Email)
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return utils.UserExistsError
+	}
+
+	token, err := s.issueToken(ctx, userID, domain.TokenPurposeEmailChange, emailChangeTokenTTL, changeRequest.Email)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, MailMessage{
+		To:      changeRequest.Email,
+		Subject: "Confirm your new Jille email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm that you want to use this address for your Jille account by opening the link below:\n\n%s/confirm-email-change?token=%s\n\nThe link expires in 24 hours.\n",
+			existingUser.Username, s.appURL, token,
+		),
+	}); err != nil {
+		return err
+	}
+
+	// let the current address know, in case someone else is at the keyboard
+	if err := s.mailer.Send(ctx, MailMessage{
+		To:      existingUser.Email,
+		Subject: "Your Jille email address is being changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nA request was made to change the email address of your Jille account to %s. If this was not you, reset your password right away.\n",
+			existingUser.Username, changeRequest.Email,
+		),
+	}); err != nil {
+		fmt.Println("error sending email change notice", err.Error())
+	}
+
+	return nil
+}
+
+func (s *authservice) ConfirmEmailChange(ctx context.Context, confirmRequest dto.VerifyEmailRequest) error {
+
+	token, err := s.consumeToken(ctx, domain.TokenPurposeEmailChange, confirmRequest.Token)
+
+	if err != nil {
+		return err
+	}
+
+	// the address may have been registered since the link was sent
+	exists, err := s.userservice.ExistsByEmail(ctx, token.Payload)
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return utils.UserExistsError
+	}
+
+	return s.userservice.UpdateEmail(ctx, token.UserID, token.Payload)
+}
+
+func (s *authservice) sendVerificationEmail(ctx context.Context, userID uuid.UUID, username string, email string) error {
+
+	token, err := s.issueToken(ctx, userID, domain.TokenPurposeEmailVerification, emailVerificationTokenTTL, "")
+
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, MailMessage{
+		To:      email,
+		Subject: "Verify your Jille email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s/verify-email?token=%s\n\nThe link expires in 24 hours.\n",
+			username, s.appURL, token,
+		),
+	})
+}
+
+// issueToken replaces any outstanding token of the same purpose for the user
+// and returns the new plaintext token. Only its hash is stored.
+func (s *authservice) issueToken(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose, ttl time.Duration, payload string) (string, error) {
+
+	if err := s.tokenrepo.DeleteByUserID(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	plain, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return "", err
+	}
+
+	token := domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(plain),
+		Payload:   payload,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := s.tokenrepo.Save(ctx, &token); err != nil {
+		return "", err
+	}
+
+	return plain, nil
+}
+
+func (s *authservice) consumeToken(ctx context.Context, purpose domain.TokenPurpose, plain string) (*domain.UserToken, error) {
+
+	token, err := s.tokenrepo.FindByHash(ctx, purpose, utils.HashToken(plain))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token.UsedAt != nil {
+		return nil, utils.TokenAlreadyUsedError
+	}
+
+	if token.ExpiresAt.Before(time.Now()) {
+		return nil, utils.TokenExpiredError
+	}
+
+	if err := s.tokenrepo.MarkUsed(ctx, token.ID); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
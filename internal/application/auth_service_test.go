@@ -2,6 +2,8 @@ package application
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/winnerx0/jille/internal/application/repository/mocks"
 	"github.com/winnerx0/jille/internal/common/dto"
 	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -45,6 +49,101 @@ func (m *MockUserService) CreateUser(ctx context.Context, user *domain.User) err
 	return args.Error(0)
 }
 
+func (m *MockUserService) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	args := m.Called(ctx, userID, hashedPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) RecordProviderSignIn(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetUserAuthById(ctx context.Context, userID uuid.UUID) (*dto.UserAuthView, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserAuthView), args.Error(1)
+}
+
+func (m *MockUserService) UpdateUsername(ctx context.Context, updateRequest dto.UpdateUsernameRequest) (*dto.UserResponse, error) {
+	args := m.Called(ctx, updateRequest)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	args := m.Called(ctx, userID, email)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UpdateProfilePicture(ctx context.Context, image []byte) (*dto.UserResponse, error) {
+	args := m.Called(ctx, image)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) DeleteAccount(ctx context.Context, deleteRequest dto.DeleteAccountRequest) error {
+	args := m.Called(ctx, deleteRequest)
+	return args.Error(0)
+}
+
+// MockTwoFactorService
+type MockTwoFactorService struct {
+	TwoFactorService
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+// MockLoginGuard
+type MockLoginGuard struct {
+	mock.Mock
+}
+
+func (m *MockLoginGuard) Check(ctx context.Context, account string, ip string) error {
+	args := m.Called(ctx, account, ip)
+	return args.Error(0)
+}
+
+func (m *MockLoginGuard) RecordFailure(ctx context.Context, account string, ip string) error {
+	args := m.Called(ctx, account, ip)
+	return args.Error(0)
+}
+
+func (m *MockLoginGuard) RecordSuccess(ctx context.Context, account string) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+// MockMailer
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, message MailMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
 // MockJwtService
 type MockJwtService struct {
 	mock.Mock
@@ -85,11 +184,25 @@ func (m *MockJwtService) GetRefreshTokenSecretKey() string {
 	return args.String(0)
 }
 
+func (m *MockJwtService) GenerateMFAToken(userId string) (string, error) {
+	args := m.Called(userId)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJwtService) GetMFATokenSubject(token string) (string, error) {
+	args := m.Called(token)
+	return args.String(0), args.Error(1)
+}
+
 func TestRegister_Success(t *testing.T) {
 	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
 	mockUserService := new(MockUserService)
 	mockJwtService := new(MockJwtService)
-	service := NewAuthService(mockRepo, mockUserService, mockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
 
 	ctx := context.Background()
 	req := dto.CreateUserRequest{
@@ -112,8 +225,16 @@ func TestRegister_Success(t *testing.T) {
 	mockJwtService.On("GenerateRefreshToken", mock.AnythingOfType("string")).Return("refresh_token", nil).Once()
 
 	// Mock SaveToken
+	mockRepo.On("RevokeAllTokens", ctx, mock.AnythingOfType("uuid.UUID")).Return(nil)
 	mockRepo.On("SaveToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
 
+	// Mock verification email
+	mockTokenRepo.On("DeleteByUserID", ctx, mock.AnythingOfType("uuid.UUID"), domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokenRepo.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil)
+	mockMailer.On("Send", ctx, mock.MatchedBy(func(m MailMessage) bool {
+		return m.To == req.Email && strings.Contains(m.Body, "/verify-email?token=")
+	})).Return(nil)
+
 	resp, err := service.Register(ctx, req)
 
 	assert.NoError(t, err)
@@ -123,13 +244,19 @@ func TestRegister_Success(t *testing.T) {
 	mockUserService.AssertExpectations(t)
 	mockJwtService.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
 }
 
 func TestLogin_Success(t *testing.T) {
 	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
 	mockUserService := new(MockUserService)
 	mockJwtService := new(MockJwtService)
-	service := NewAuthService(mockRepo, mockUserService, mockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
 
 	ctx := context.Background()
 	req := dto.LoginUserRequest{
@@ -137,46 +264,239 @@ func TestLogin_Success(t *testing.T) {
 		Password: "password123",
 	}
 
-	// Create a user with hashed password (using bcrypt cost 4 for speed in tests if possible, but here we just mock)
-	// We need actual valid hash because Login calls bcrypt.CompareHashAndPassword
-	// $2a$10$X... is a valid bcrypt hash for "password123"
-	// But calculating it might be slow or tricky.
-	// The service calls `bcrypt.CompareHashAndPassword`.
-	// We can pre-calculate a hash for "password123".
-	hashedPassword := "$2a$10$89.invalid.hash.but.needs.to.work.if.we.could.mock.bcrypt"
-	// Actually we cannot mock bcrypt package calls. We must provide a valid hash.
-	// Valid hash for "password123" (cost 10)
-	// $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
-	hashedPassword = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+	// Login calls bcrypt.CompareHashAndPassword, so the user needs a real hash
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+	assert.NoError(t, err)
 
 	user := &dto.UserAuthView{
 		ID:       uuid.New(),
 		Email:    req.Email,
-		Password: hashedPassword,
+		Password: string(hashedPassword),
 	}
 
+	mockLoginGuard.On("Check", ctx, req.Email, "").Return(nil)
 	mockUserService.On("GetUserByEmail", ctx, req.Email).Return(user, nil)
+	mockLoginGuard.On("RecordSuccess", ctx, req.Email).Return(nil)
+	mockTwoFactorService.On("IsEnabled", ctx, user.ID).Return(false, nil)
 	mockRepo.On("RevokeAllTokens", ctx, user.ID).Return(nil)
 
-	// Implementation calls GenerateAccessToken for both access and refresh
-	mockJwtService.On("GenerateAccessToken", mock.AnythingOfType("string")).Return("token", nil).Twice()
+	mockJwtService.On("GenerateAccessToken", user.ID.String()).Return("access_token", nil).Once()
+	mockJwtService.On("GenerateRefreshToken", user.ID.String()).Return("refresh_token", nil).Once()
 	mockRepo.On("SaveToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
 
 	resp, err := service.Login(ctx, req)
 
 	assert.NoError(t, err)
-	assert.NotNil(t, resp)
+	assert.Equal(t, "access_token", resp.AuthTokens.AccessToken)
+	assert.Equal(t, "refresh_token", resp.AuthTokens.RefreshToken)
 
 	mockUserService.AssertExpectations(t)
 	mockJwtService.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestLogin_FailsWhenTokenIsNotSaved(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	req := dto.LoginUserRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	user := &dto.UserAuthView{
+		ID:       uuid.New(),
+		Email:    req.Email,
+		Password: string(hashedPassword),
+	}
+
+	mockLoginGuard.On("Check", ctx, req.Email, "").Return(nil)
+	mockUserService.On("GetUserByEmail", ctx, req.Email).Return(user, nil)
+	mockLoginGuard.On("RecordSuccess", ctx, req.Email).Return(nil)
+	mockTwoFactorService.On("IsEnabled", ctx, user.ID).Return(false, nil)
+	mockRepo.On("RevokeAllTokens", ctx, user.ID).Return(nil)
+	mockJwtService.On("GenerateAccessToken", user.ID.String()).Return("access_token", nil)
+	mockJwtService.On("GenerateRefreshToken", user.ID.String()).Return("refresh_token", nil)
+	mockRepo.On("SaveToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(errors.New("connection refused"))
+
+	resp, err := service.Login(ctx, req)
+
+	// a refresh token that was never stored would not work, so the login
+	// fails rather than handing it out
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestLogin_UnknownEmailAndWrongPasswordLookTheSame(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.WithValue(context.Background(), "clientIP", "10.0.0.1")
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockLoginGuard.On("Check", ctx, mock.AnythingOfType("string"), "10.0.0.1").Return(nil)
+	mockLoginGuard.On("RecordFailure", ctx, mock.AnythingOfType("string"), "10.0.0.1").Return(nil)
+	mockUserService.On("GetUserByEmail", ctx, "missing@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockUserService.On("GetUserByEmail", ctx, "test@example.com").Return(&dto.UserAuthView{
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+	}, nil)
+
+	_, unknownErr := service.Login(ctx, dto.LoginUserRequest{Email: "missing@example.com", Password: "password123"})
+	_, wrongErr := service.Login(ctx, dto.LoginUserRequest{Email: "test@example.com", Password: "wrongpassword"})
+
+	assert.ErrorIs(t, unknownErr, utils.InvalidCredentialsError)
+	assert.ErrorIs(t, wrongErr, utils.InvalidCredentialsError)
+	mockLoginGuard.AssertCalled(t, "RecordFailure", ctx, "missing@example.com", "10.0.0.1")
+	mockLoginGuard.AssertCalled(t, "RecordFailure", ctx, "test@example.com", "10.0.0.1")
+	mockLoginGuard.AssertNotCalled(t, "RecordSuccess", mock.Anything, mock.Anything)
+}
+
+func TestLogin_LockedOut(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+
+	mockLoginGuard.On("Check", ctx, "test@example.com", "").Return(&utils.LockoutError{RetryAfter: time.Minute})
+
+	_, err := service.Login(ctx, dto.LoginUserRequest{Email: "test@example.com", Password: "password123"})
+
+	assert.ErrorIs(t, err, utils.TooManyAttemptsError)
+	mockUserService.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func TestLogin_MFARequired(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	req := dto.LoginUserRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	user := &dto.UserAuthView{
+		ID:       uuid.New(),
+		Email:    req.Email,
+		Password: string(hashedPassword),
+	}
+
+	mockLoginGuard.On("Check", ctx, req.Email, "").Return(nil)
+	mockUserService.On("GetUserByEmail", ctx, req.Email).Return(user, nil)
+	mockLoginGuard.On("RecordSuccess", ctx, req.Email).Return(nil)
+	mockTwoFactorService.On("IsEnabled", ctx, user.ID).Return(true, nil)
+	mockJwtService.On("GenerateMFAToken", user.ID.String()).Return("mfa_token", nil)
+
+	resp, err := service.Login(ctx, req)
+
+	assert.NoError(t, err)
+	assert.True(t, resp.MFARequired)
+	assert.Equal(t, "mfa_token", resp.AuthTokens.MFAToken)
+	assert.Empty(t, resp.AuthTokens.AccessToken)
+	mockRepo.AssertNotCalled(t, "SaveToken", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "RevokeAllTokens", mock.Anything, mock.Anything)
+}
+
+func TestLoginMFA_Success(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockJwtService.On("GetMFATokenSubject", "mfa_token").Return(userID.String(), nil)
+	mockLoginGuard.On("Check", ctx, "mfa:"+userID.String(), "").Return(nil)
+	mockTwoFactorService.On("Verify", ctx, userID, "123456").Return(nil)
+	mockLoginGuard.On("RecordSuccess", ctx, "mfa:"+userID.String()).Return(nil)
+	mockRepo.On("RevokeAllTokens", ctx, userID).Return(nil)
+	mockJwtService.On("GenerateAccessToken", userID.String()).Return("access_token", nil)
+	mockJwtService.On("GenerateRefreshToken", userID.String()).Return("refresh_token", nil)
+	mockRepo.On("SaveToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	resp, err := service.LoginMFA(ctx, dto.MFALoginRequest{MFAToken: "mfa_token", Code: "123456"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "access_token", resp.AuthTokens.AccessToken)
+	assert.Equal(t, "refresh_token", resp.AuthTokens.RefreshToken)
+	mockTwoFactorService.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLoginMFA_InvalidCode(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockJwtService.On("GetMFATokenSubject", "mfa_token").Return(userID.String(), nil)
+	mockLoginGuard.On("Check", ctx, "mfa:"+userID.String(), "").Return(nil)
+	mockTwoFactorService.On("Verify", ctx, userID, "000000").Return(utils.InvalidTwoFactorCodeError)
+	mockLoginGuard.On("RecordFailure", ctx, "mfa:"+userID.String(), "").Return(nil)
+
+	_, err := service.LoginMFA(ctx, dto.MFALoginRequest{MFAToken: "mfa_token", Code: "000000"})
+
+	assert.ErrorIs(t, err, utils.InvalidTwoFactorCodeError)
+	mockLoginGuard.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "SaveToken", mock.Anything, mock.Anything)
+}
+
 func TestRefreshToken_Success(t *testing.T) {
 	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
 	mockUserService := new(MockUserService)
 	mockJwtService := new(MockJwtService)
-	service := NewAuthService(mockRepo, mockUserService, mockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
 
 	ctx := context.Background()
 	req := dto.RefreshTokenRequest{
@@ -185,13 +505,14 @@ func TestRefreshToken_Success(t *testing.T) {
 
 	existingToken := &domain.RefreshToken{
 		ID:        uuid.New(),
-		Token:     req.RefreshToken,
+		TokenHash: utils.HashToken(req.RefreshToken),
 		UserID:    uuid.New(),
 		ExpiresAt: time.Now().Add(time.Hour),
 	}
 
-	mockRepo.On("FindByToken", ctx, req.RefreshToken).Return(existingToken, nil)
-	mockJwtService.On("GenerateAccessToken", existingToken.UserID.String()).Return("new_access_token", nil).Twice()
+	mockRepo.On("FindByHash", ctx, utils.HashToken(req.RefreshToken)).Return(existingToken, nil)
+	mockJwtService.On("GenerateAccessToken", existingToken.UserID.String()).Return("new_access_token", nil).Once()
+	mockJwtService.On("GenerateRefreshToken", existingToken.UserID.String()).Return("new_refresh_token", nil).Once()
 	mockRepo.On("RevokeAllTokens", ctx, existingToken.UserID).Return(nil)
 	mockRepo.On("SaveToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
 
@@ -199,6 +520,411 @@ func TestRefreshToken_Success(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, "new_access_token", resp.AuthTokens.AccessToken)
+	assert.Equal(t, "new_refresh_token", resp.AuthTokens.RefreshToken)
+
+	mockRepo.AssertExpectations(t)
+	mockJwtService.AssertExpectations(t)
+}
+
+func TestVerifyEmail_Success(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	token := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Purpose:   domain.TokenPurposeEmailVerification,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockTokenRepo.On("FindByHash", ctx, domain.TokenPurposeEmailVerification, utils.HashToken("plain_token")).Return(token, nil)
+	mockTokenRepo.On("MarkUsed", ctx, token.ID).Return(nil)
+	mockUserService.On("MarkEmailVerified", ctx, token.UserID).Return(nil)
+
+	err := service.VerifyEmail(ctx, dto.VerifyEmailRequest{Token: "plain_token"})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestVerifyEmail_Expired(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	token := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Purpose:   domain.TokenPurposeEmailVerification,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+
+	mockTokenRepo.On("FindByHash", ctx, domain.TokenPurposeEmailVerification, utils.HashToken("plain_token")).Return(token, nil)
+
+	err := service.VerifyEmail(ctx, dto.VerifyEmailRequest{Token: "plain_token"})
+
+	assert.ErrorIs(t, err, utils.TokenExpiredError)
+	mockTokenRepo.AssertNotCalled(t, "MarkUsed", ctx, token.ID)
+	mockUserService.AssertNotCalled(t, "MarkEmailVerified", ctx, token.UserID)
+}
+
+func TestRequestPasswordReset_UnknownEmail(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+
+	mockUserService.On("GetUserByEmail", ctx, "missing@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	err := service.RequestPasswordReset(ctx, dto.PasswordResetRequest{Email: "missing@example.com"})
+
+	assert.NoError(t, err)
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestRequestPasswordReset_Success(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	user := &dto.UserAuthView{
+		ID:    uuid.New(),
+		Email: "test@example.com",
+	}
 
+	var savedHash string
+
+	mockUserService.On("GetUserByEmail", ctx, user.Email).Return(user, nil)
+	mockTokenRepo.On("DeleteByUserID", ctx, user.ID, domain.TokenPurposePasswordReset).Return(nil)
+	mockTokenRepo.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil).Run(func(args mock.Arguments) {
+		savedHash = args.Get(1).(*domain.UserToken).TokenHash
+	})
+	mockMailer.On("Send", ctx, mock.MatchedBy(func(m MailMessage) bool {
+		_, plain, found := strings.Cut(m.Body, "/reset-password?token=")
+		plain, _, _ = strings.Cut(plain, "\n")
+		return found && m.To == user.Email && utils.HashToken(plain) == savedHash
+	})).Return(nil)
+
+	err := service.RequestPasswordReset(ctx, dto.PasswordResetRequest{Email: user.Email})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	token := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Purpose:   domain.TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockTokenRepo.On("FindByHash", ctx, domain.TokenPurposePasswordReset, utils.HashToken("reset_token")).Return(token, nil)
+	mockTokenRepo.On("MarkUsed", ctx, token.ID).Return(nil)
+	mockUserService.On("UpdatePassword", ctx, token.UserID, mock.AnythingOfType("string")).Return(nil)
+	mockRepo.On("RevokeAllTokens", ctx, token.UserID).Return(nil)
+
+	err := service.ResetPassword(ctx, dto.PasswordResetConfirmRequest{Token: "reset_token", Password: "newpassword"})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+	mockUserService.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestResetPassword_SignsOutRefreshTokens(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	userID := uuid.New()
+	token := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   domain.TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	stolen := &domain.RefreshToken{
+		ID:        uuid.New(),
+		TokenHash: utils.HashToken("stolen_refresh_token"),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockTokenRepo.On("FindByHash", ctx, domain.TokenPurposePasswordReset, utils.HashToken("reset_token")).Return(token, nil)
+	mockTokenRepo.On("MarkUsed", ctx, token.ID).Return(nil)
+	mockUserService.On("UpdatePassword", ctx, userID, mock.AnythingOfType("string")).Return(nil)
+	mockRepo.On("RevokeAllTokens", ctx, userID).Run(func(args mock.Arguments) {
+		stolen.Revoked = true
+	}).Return(nil)
+	mockRepo.On("FindByHash", ctx, stolen.TokenHash).Return(stolen, nil)
+
+	err := service.ResetPassword(ctx, dto.PasswordResetConfirmRequest{Token: "reset_token", Password: "newpassword"})
+
+	assert.NoError(t, err)
+
+	_, err = service.RefreshToken(ctx, dto.RefreshTokenRequest{RefreshToken: "stolen_refresh_token"})
+
+	assert.ErrorIs(t, err, utils.TokenNotFoundError)
+	mockRepo.AssertNotCalled(t, "SaveToken", mock.Anything, mock.Anything)
+	mockJwtService.AssertNotCalled(t, "GenerateAccessToken", mock.Anything)
+}
+
+func TestResetPassword_TokenAlreadyUsed(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	token := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Purpose:   domain.TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockTokenRepo.On("FindByHash", ctx, domain.TokenPurposePasswordReset, utils.HashToken("reset_token")).Return(token, nil)
+	mockTokenRepo.On("MarkUsed", ctx, token.ID).Return(utils.TokenAlreadyUsedError)
+
+	err := service.ResetPassword(ctx, dto.PasswordResetConfirmRequest{Token: "reset_token", Password: "newpassword"})
+
+	assert.ErrorIs(t, err, utils.TokenAlreadyUsedError)
+	mockUserService.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockUserService.On("GetUserAuthById", ctx, userID).Return(&dto.UserAuthView{ID: userID, Password: string(hashedPassword)}, nil)
+	mockUserService.On("UpdatePassword", ctx, userID, mock.AnythingOfType("string")).Return(nil)
+	mockTokenRepo.On("DeleteByUserID", ctx, userID, domain.TokenPurposePasswordReset).Return(nil)
+	mockRepo.On("RevokeAllTokens", ctx, userID).Return(nil)
+	mockJwtService.On("GenerateAccessToken", userID.String()).Return("access_token", nil)
+	mockJwtService.On("GenerateRefreshToken", userID.String()).Return("refresh_token", nil)
+	mockRepo.On("SaveToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	resp, err := service.ChangePassword(ctx, dto.ChangePasswordRequest{CurrentPassword: "oldpassword", NewPassword: "newpassword"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "access_token", resp.AccessToken)
+	mockUserService.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockUserService.On("GetUserAuthById", ctx, userID).Return(&dto.UserAuthView{ID: userID, Password: string(hashedPassword)}, nil)
+
+	_, err = service.ChangePassword(ctx, dto.ChangePasswordRequest{CurrentPassword: "guessed", NewPassword: "newpassword"})
+
+	assert.ErrorIs(t, err, utils.InvalidPasswordError)
+	mockUserService.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_PasswordlessAccount(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	signedIn := time.Now().Add(-time.Minute)
+
+	mockUserService.On("GetUserAuthById", ctx, userID).Return(&dto.UserAuthView{ID: userID, Passwordless: true, ProviderSignInAt: &signedIn}, nil).Once()
+	mockUserService.On("UpdatePassword", ctx, userID, mock.AnythingOfType("string")).Return(nil)
+	mockTokenRepo.On("DeleteByUserID", ctx, userID, domain.TokenPurposePasswordReset).Return(nil)
+	mockRepo.On("RevokeAllTokens", ctx, userID).Return(nil)
+	mockJwtService.On("GenerateAccessToken", userID.String()).Return("access_token", nil)
+	mockJwtService.On("GenerateRefreshToken", userID.String()).Return("refresh_token", nil)
+	mockRepo.On("SaveToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	// a recent sign-in through the provider stands in for the password
+	_, err := service.ChangePassword(ctx, dto.ChangePasswordRequest{NewPassword: "newpassword"})
+
+	assert.NoError(t, err)
+	mockUserService.AssertExpectations(t)
+
+	signedIn = time.Now().Add(-providerSignInWindow - time.Minute)
+
+	mockUserService.On("GetUserAuthById", ctx, userID).Return(&dto.UserAuthView{ID: userID, Passwordless: true, ProviderSignInAt: &signedIn}, nil).Once()
+
+	_, err = service.ChangePassword(ctx, dto.ChangePasswordRequest{NewPassword: "newpassword"})
+
+	assert.ErrorIs(t, err, utils.ReauthenticationRequiredError)
+	mockUserService.AssertNumberOfCalls(t, "UpdatePassword", 1)
+}
+
+func TestRequestEmailChange_Success(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockUserService.On("GetUserAuthById", ctx, userID).Return(&dto.UserAuthView{ID: userID, Username: "testuser", Email: "old@example.com", Password: string(hashedPassword)}, nil)
+	mockUserService.On("ExistsByEmail", ctx, "new@example.com").Return(false, nil)
+	mockTokenRepo.On("DeleteByUserID", ctx, userID, domain.TokenPurposeEmailChange).Return(nil)
+	mockTokenRepo.On("Save", ctx, mock.MatchedBy(func(token *domain.UserToken) bool {
+		return token.Purpose == domain.TokenPurposeEmailChange && token.Payload == "new@example.com"
+	})).Return(nil)
+	mockMailer.On("Send", ctx, mock.MatchedBy(func(message MailMessage) bool {
+		return message.To == "new@example.com" && strings.Contains(message.Body, "/confirm-email-change?token=")
+	})).Return(nil)
+	mockMailer.On("Send", ctx, mock.MatchedBy(func(message MailMessage) bool {
+		return message.To == "old@example.com"
+	})).Return(nil)
+
+	err = service.RequestEmailChange(ctx, dto.ChangeEmailRequest{Email: "new@example.com", Password: "password123"})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+	mockUserService.AssertNotCalled(t, "UpdateEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRequestEmailChange_EmailTaken(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockUserService.On("GetUserAuthById", ctx, userID).Return(&dto.UserAuthView{ID: userID, Email: "old@example.com", Password: string(hashedPassword)}, nil)
+	mockUserService.On("ExistsByEmail", ctx, "taken@example.com").Return(true, nil)
+
+	err = service.RequestEmailChange(ctx, dto.ChangeEmailRequest{Email: "taken@example.com", Password: "password123"})
+
+	assert.ErrorIs(t, err, utils.UserExistsError)
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestConfirmEmailChange_Success(t *testing.T) {
+	mockRepo := new(mocks.AuthRepository)
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockUserService := new(MockUserService)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockLoginGuard := new(MockLoginGuard)
+	mockMailer := new(MockMailer)
+	service := NewAuthService(mockRepo, mockTokenRepo, mockUserService, mockJwtService, mockTwoFactorService, mockLoginGuard, mockMailer, "http://localhost:3000")
+
+	ctx := context.Background()
+	token := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Purpose:   domain.TokenPurposeEmailChange,
+		Payload:   "new@example.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockTokenRepo.On("FindByHash", ctx, domain.TokenPurposeEmailChange, utils.HashToken("change_token")).Return(token, nil)
+	mockTokenRepo.On("MarkUsed", ctx, token.ID).Return(nil)
+	mockUserService.On("ExistsByEmail", ctx, "new@example.com").Return(false, nil)
+	mockUserService.On("UpdateEmail", ctx, token.UserID, "new@example.com").Return(nil)
+
+	err := service.ConfirmEmailChange(ctx, dto.VerifyEmailRequest{Token: "change_token"})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+	mockUserService.AssertExpectations(t)
+}
@@ -0,0 +1,43 @@
+package application
+
+import "io"
+
+type ChartKind string
+
+const (
+	ChartKindBar ChartKind = "bar"
+	ChartKindPie ChartKind = "pie"
+)
+
+type ChartTheme string
+
+const (
+	ChartThemeLight ChartTheme = "light"
+	ChartThemeDark  ChartTheme = "dark"
+)
+
+// Chart describes a results chart independent of its image format.
+type Chart struct {
+	Title   string
+	Options []ChartOption
+	Kind    ChartKind
+	Theme   ChartTheme
+	Width   int
+	Height  int
+
+	// HideResults draws only the options, for viewers who may not see
+	// the tally yet.
+	HideResults bool
+}
+
+type ChartOption struct {
+	Name  string
+	Votes int
+}
+
+// ChartRenderer draws poll results as an image.
+type ChartRenderer interface {
+	SVG(w io.Writer, chart Chart) error
+
+	PNG(w io.Writer, chart Chart) error
+}
@@ -0,0 +1,24 @@
+package application
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type DataExportService interface {
+	// RequestExport starts building an archive of the signed in user's data
+	// in the background. The response carries the only copy of the download
+	// link, which is also mailed to the user once the archive is ready.
+	RequestExport(ctx context.Context) (*dto.DataExportResponse, error)
+
+	GetExport(ctx context.Context, exportID uuid.UUID) (*dto.DataExportResponse, error)
+
+	// OpenDownload checks the download token and returns the archive.
+	OpenDownload(ctx context.Context, exportID uuid.UUID, token string) (io.ReadCloser, error)
+
+	// PurgeExpired deletes archives whose download window has closed.
+	PurgeExpired(ctx context.Context) error
+}
@@ -0,0 +1,516 @@
+package application
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+const (
+	dataExportDownloadTTL  = time.Hour * 24
+	dataExportBuildTimeout = time.Minute * 10
+)
+
+type dataexportservice struct {
+	exportrepo repository.DataExportRepository
+	userrepo   repository.UserRepository
+	pollrepo   repository.PollRepository
+	voterepo   repository.VoteRepository
+	authrepo   repository.AuthRepository
+	apikeyrepo repository.APIKeyRepository
+
+	identityrepo     repository.IdentityRepository
+	organizationrepo repository.OrganizationRepository
+	webhookrepo      repository.WebhookRepository
+	notificationrepo repository.NotificationRepository
+
+	storage FileStorage
+	mailer  Mailer
+
+	// apiURL is the public base of the API that download links point at
+	apiURL string
+
+	// run starts a background job, tests swap it to run jobs inline
+	run func(job func())
+}
+
+func NewDataExportService(exportrepo repository.DataExportRepository, userrepo repository.UserRepository, pollrepo repository.PollRepository, voterepo repository.VoteRepository, authrepo repository.AuthRepository, apikeyrepo repository.APIKeyRepository, identityrepo repository.IdentityRepository, organizationrepo repository.OrganizationRepository, webhookrepo repository.WebhookRepository, notificationrepo repository.NotificationRepository, storage FileStorage, mailer Mailer, apiURL string) DataExportService {
+	return &dataexportservice{
+		exportrepo:       exportrepo,
+		userrepo:         userrepo,
+		pollrepo:         pollrepo,
+		voterepo:         voterepo,
+		authrepo:         authrepo,
+		apikeyrepo:       apikeyrepo,
+		identityrepo:     identityrepo,
+		organizationrepo: organizationrepo,
+		webhookrepo:      webhookrepo,
+		notificationrepo: notificationrepo,
+		storage:          storage,
+		mailer:           mailer,
+		apiURL:           apiURL,
+		run: func(job func()) {
+			go job()
+		},
+	}
+}
+
+func (s *dataexportservice) RequestExport(ctx context.Context) (*dto.DataExportResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	active, err := s.exportrepo.FindActiveByUserID(ctx, userID)
+
+	switch {
+	case err == nil && time.Since(active.CreatedAt) < dataExportBuildTimeout:
+		return nil, utils.DataExportInProgressError
+	case err == nil:
+		// the job gives up after dataExportBuildTimeout, an export still
+		// unfinished by then was lost with the server that was building it
+		if err := s.exportrepo.UpdateStatus(ctx, active.ID, domain.DataExportFailed); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, utils.DataExportNotFoundError):
+		return nil, err
+	}
+
+	token, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	export := domain.DataExport{
+		UserID:    userID,
+		Status:    domain.DataExportPending,
+		TokenHash: utils.HashToken(token),
+	}
+
+	if err := s.exportrepo.Save(ctx, &export); err != nil {
+		return nil, err
+	}
+
+	downloadURL := fmt.Sprintf("%s/exports/%s/download?token=%s", s.apiURL, export.ID, token)
+
+	// the request context ends with the response, the job gets its own
+	s.run(func() {
+		jobCtx, cancel := context.WithTimeout(context.Background(), dataExportBuildTimeout)
+		defer cancel()
+
+		if err := s.build(jobCtx, export, downloadURL); err != nil {
+			fmt.Println("error building data export", export.ID, err.Error())
+
+			if err := s.exportrepo.UpdateStatus(jobCtx, export.ID, domain.DataExportFailed); err != nil {
+				fmt.Println("error marking data export failed", export.ID, err.Error())
+			}
+		}
+	})
+
+	response := exportResponse(export)
+	response.DownloadURL = downloadURL
+
+	return response, nil
+}
+
+func (s *dataexportservice) GetExport(ctx context.Context, exportID uuid.UUID) (*dto.DataExportResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	export, err := s.exportrepo.FindByID(ctx, exportID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if export.UserID != userID {
+		return nil, utils.DataExportNotFoundError
+	}
+
+	return exportResponse(*export), nil
+}
+
+func (s *dataexportservice) OpenDownload(ctx context.Context, exportID uuid.UUID, token string) (io.ReadCloser, error) {
+
+	export, err := s.exportrepo.FindByID(ctx, exportID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a wrong token looks exactly like a missing export
+	if subtle.ConstantTimeCompare([]byte(export.TokenHash), []byte(utils.HashToken(token))) != 1 {
+		return nil, utils.DataExportNotFoundError
+	}
+
+	switch export.Status {
+	case domain.DataExportReady:
+	case domain.DataExportExpired:
+		return nil, utils.DataExportExpiredError
+	default:
+		return nil, utils.DataExportNotReadyError
+	}
+
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(time.Now()) {
+		return nil, utils.DataExportExpiredError
+	}
+
+	return s.storage.Open(ctx, export.FileName)
+}
+
+func (s *dataexportservice) PurgeExpired(ctx context.Context) error {
+
+	exports, err := s.exportrepo.FindExpired(ctx, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+
+		if err := s.storage.Delete(ctx, export.FileName); err != nil {
+			return err
+		}
+
+		if err := s.exportrepo.UpdateStatus(ctx, export.ID, domain.DataExportExpired); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *dataexportservice) build(ctx context.Context, export domain.DataExport, downloadURL string) error {
+
+	if err := s.exportrepo.UpdateStatus(ctx, export.ID, domain.DataExportProcessing); err != nil {
+		return err
+	}
+
+	user, err := s.userrepo.FindById(ctx, export.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	archive, err := s.archive(ctx, user)
+
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s/%s.zip", export.UserID, export.ID)
+
+	if err := s.storage.Save(ctx, fileName, archive); err != nil {
+		return err
+	}
+
+	if err := s.exportrepo.MarkReady(ctx, export.ID, fileName, time.Now().Add(dataExportDownloadTTL)); err != nil {
+		return err
+	}
+
+	// the archive is ready either way, the link was also returned when the
+	// export was requested
+	if err := s.mailer.Send(ctx, MailMessage{
+		To:      user.Email,
+		Subject: "Your Jille data export is ready",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe copy of your Jille data you asked for is ready. Download it within the next 24 hours from:\n\n%s\n",
+			user.Username, downloadURL,
+		),
+	}); err != nil {
+		fmt.Println("error sending data export email", export.ID, err.Error())
+	}
+
+	return nil
+}
+
+// archive collects everything stored about the user into a zip of JSON files.
+func (s *dataexportservice) archive(ctx context.Context, user domain.User) ([]byte, error) {
+
+	polls, err := s.pollrepo.FindPollsByUserID(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.voterepo.FindVotesByUserID(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.authrepo.FindTokensByUserID(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.apikeyrepo.FindByUserID(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := s.identityrepo.FindByUserID(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	organizations, err := s.organizationrepo.FindByUserID(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.webhookrepo.FindByUserID(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := s.notificationrepo.FindNotifications(ctx, user.ID, false, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", exportProfile(user)},
+		{"polls.json", exportPolls(polls)},
+		{"votes.json", exportVotes(votes)},
+		{"sessions.json", exportSessions(sessions)},
+		{"api_keys.json", exportAPIKeys(apiKeys)},
+		{"identities.json", exportIdentities(identities)},
+		{"organizations.json", exportMemberships(user.ID, organizations)},
+		{"webhooks.json", exportWebhooks(webhooks)},
+		{"notifications.json", exportNotifications(notifications)},
+	}
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range files {
+
+		writer, err := archive.Create(file.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func exportResponse(export domain.DataExport) *dto.DataExportResponse {
+
+	return &dto.DataExportResponse{
+		ID:          export.ID,
+		Status:      string(export.Status),
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
+
+func exportProfile(user domain.User) dto.ExportProfile {
+
+	return dto.ExportProfile{
+		ID:             user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		EmailVerified:  user.EmailVerified,
+		ProfilePicture: user.ProfilePicture,
+		JoinedAt:       user.JoinedAt,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+func exportPolls(polls []domain.Poll) []dto.ExportPoll {
+
+	exported := make([]dto.ExportPoll, 0, len(polls))
+
+	for _, poll := range polls {
+
+		exportedPoll := dto.ExportPoll{
+			ID:        poll.ID,
+			Title:     poll.Title,
+			CreatedAt: poll.CreatedAt,
+			ExpiresAt: poll.ExpiresAt,
+			Options:   make([]dto.ExportOption, 0, len(poll.Options)),
+		}
+
+		for _, option := range poll.Options {
+			exportedPoll.Options = append(exportedPoll.Options, dto.ExportOption{
+				ID:    option.ID,
+				Name:  option.Name,
+				Votes: option.VoteCount,
+			})
+			exportedPoll.TotalVotes += option.VoteCount
+		}
+
+		exported = append(exported, exportedPoll)
+	}
+
+	return exported
+}
+
+func exportVotes(votes []domain.Vote) []dto.ExportVote {
+
+	exported := make([]dto.ExportVote, 0, len(votes))
+
+	for _, vote := range votes {
+		exported = append(exported, dto.ExportVote{
+			ID:       vote.ID,
+			PollID:   vote.PollID,
+			OptionID: vote.OptionID,
+			VotedAt:  vote.CreatedAt,
+		})
+	}
+
+	return exported
+}
+
+// exportSessions lists when sessions were started, the tokens themselves are
+// credentials and stay out of the archive.
+func exportSessions(sessions []domain.RefreshToken) []dto.ExportSession {
+
+	exported := make([]dto.ExportSession, 0, len(sessions))
+
+	for _, session := range sessions {
+		exported = append(exported, dto.ExportSession{
+			ID:        session.ID,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Revoked:   session.Revoked,
+		})
+	}
+
+	return exported
+}
+
+// exportAPIKeys lists the keys by name and prefix, the keys themselves are
+// credentials and stay out of the archive.
+func exportAPIKeys(keys []domain.APIKey) []dto.ExportAPIKey {
+
+	exported := make([]dto.ExportAPIKey, 0, len(keys))
+
+	for _, key := range keys {
+		exported = append(exported, dto.ExportAPIKey{
+			ID:         key.ID,
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     key.Scopes,
+			CreatedAt:  key.CreatedAt,
+			ExpiresAt:  key.ExpiresAt,
+			LastUsedAt: key.LastUsedAt,
+			RevokedAt:  key.RevokedAt,
+		})
+	}
+
+	return exported
+}
+
+func exportIdentities(identities []domain.UserIdentity) []dto.ExportIdentity {
+
+	exported := make([]dto.ExportIdentity, 0, len(identities))
+
+	for _, identity := range identities {
+		exported = append(exported, dto.ExportIdentity{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+			LinkedAt: identity.CreatedAt,
+		})
+	}
+
+	return exported
+}
+
+// exportMemberships lists the user's own membership of each organization,
+// not the other members.
+func exportMemberships(userID uuid.UUID, organizations []domain.Organization) []dto.ExportMembership {
+
+	exported := make([]dto.ExportMembership, 0, len(organizations))
+
+	for _, organization := range organizations {
+		for _, member := range organization.Members {
+
+			if member.UserID != userID {
+				continue
+			}
+
+			exported = append(exported, dto.ExportMembership{
+				OrganizationID:   organization.ID,
+				OrganizationName: organization.Name,
+				Role:             string(member.Role),
+				JoinedAt:         member.CreatedAt,
+			})
+		}
+	}
+
+	return exported
+}
+
+// exportWebhooks leaves out the signing secrets, which are credentials.
+func exportWebhooks(webhooks []domain.Webhook) []dto.ExportWebhook {
+
+	exported := make([]dto.ExportWebhook, 0, len(webhooks))
+
+	for _, webhook := range webhooks {
+		exported = append(exported, dto.ExportWebhook{
+			ID:        webhook.ID,
+			URL:       webhook.URL,
+			PollID:    webhook.PollID,
+			Events:    webhook.Events,
+			CreatedAt: webhook.CreatedAt,
+		})
+	}
+
+	return exported
+}
+
+func exportNotifications(notifications []domain.Notification) []dto.ExportNotification {
+
+	exported := make([]dto.ExportNotification, 0, len(notifications))
+
+	for _, notification := range notifications {
+		exported = append(exported, dto.ExportNotification{
+			ID:        notification.ID,
+			Kind:      string(notification.Kind),
+			PollID:    notification.PollID,
+			Message:   notification.Message,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	return exported
+}
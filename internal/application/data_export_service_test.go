@@ -0,0 +1,245 @@
+package application
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type dataExportMocks struct {
+	exportRepo *mocks.DataExportRepository
+	userRepo   *mocks.UserRepository
+	pollRepo   *mocks.PollRepository
+	voteRepo   *mocks.VoteRepository
+	authRepo   *mocks.AuthRepository
+	apiKeyRepo *mocks.APIKeyRepository
+
+	identityRepo     *mocks.IdentityRepository
+	organizationRepo *mocks.OrganizationRepository
+	webhookRepo      *mocks.WebhookRepository
+	notificationRepo *mocks.NotificationRepository
+
+	storage *MockFileStorage
+	mailer  *MockMailer
+}
+
+func newTestDataExportService() (*dataexportservice, dataExportMocks) {
+	m := dataExportMocks{
+		exportRepo: new(mocks.DataExportRepository),
+		userRepo:   new(mocks.UserRepository),
+		pollRepo:   new(mocks.PollRepository),
+		voteRepo:   new(mocks.VoteRepository),
+		authRepo:   new(mocks.AuthRepository),
+		apiKeyRepo: new(mocks.APIKeyRepository),
+
+		identityRepo:     new(mocks.IdentityRepository),
+		organizationRepo: new(mocks.OrganizationRepository),
+		webhookRepo:      new(mocks.WebhookRepository),
+		notificationRepo: new(mocks.NotificationRepository),
+
+		storage: new(MockFileStorage),
+		mailer:  new(MockMailer),
+	}
+
+	service := NewDataExportService(m.exportRepo, m.userRepo, m.pollRepo, m.voteRepo, m.authRepo, m.apiKeyRepo, m.identityRepo, m.organizationRepo, m.webhookRepo, m.notificationRepo, m.storage, m.mailer, "http://localhost:9000/api/v1").(*dataexportservice)
+
+	// run the background job inline so the test can inspect its result
+	service.run = func(job func()) {
+		job()
+	}
+
+	return service, m
+}
+
+func TestRequestExport_BuildsArchive(t *testing.T) {
+	service, m := newTestDataExportService()
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	pollID := uuid.New()
+	polls := []domain.Poll{{
+		ID:     pollID,
+		Title:  "Best language",
+		UserID: userID,
+		Options: []domain.Option{
+			{ID: uuid.New(), Name: "Go", VoteCount: 2},
+			{ID: uuid.New(), Name: "Rust", VoteCount: 1},
+		},
+	}}
+
+	var archive []byte
+
+	m.exportRepo.On("FindActiveByUserID", ctx, userID).Return(nil, utils.DataExportNotFoundError)
+	m.exportRepo.On("Save", ctx, mock.AnythingOfType("*domain.DataExport")).Return(nil)
+	m.exportRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.DataExportProcessing).Return(nil)
+	m.userRepo.On("FindById", mock.Anything, userID).Return(domain.User{ID: userID, Username: "testuser", Email: "test@example.com"}, nil)
+	m.pollRepo.On("FindPollsByUserID", mock.Anything, userID).Return(polls, nil)
+	m.voteRepo.On("FindVotesByUserID", mock.Anything, userID).Return([]domain.Vote{{ID: uuid.New(), PollID: pollID}}, nil)
+	m.authRepo.On("FindTokensByUserID", mock.Anything, userID).Return([]domain.RefreshToken{{ID: uuid.New(), TokenHash: "secret_refresh_token"}}, nil)
+	m.apiKeyRepo.On("FindByUserID", mock.Anything, userID).Return([]domain.APIKey{{ID: uuid.New(), Name: "CI", Prefix: "jk_abc", KeyHash: "secret_api_key"}}, nil)
+	m.identityRepo.On("FindByUserID", mock.Anything, userID).Return([]domain.UserIdentity{{Provider: "google", Subject: "sub"}}, nil)
+	m.organizationRepo.On("FindByUserID", mock.Anything, userID).Return([]domain.Organization{{
+		ID:      uuid.New(),
+		Name:    "Acme",
+		Members: []domain.OrganizationMember{{UserID: userID, Role: domain.OrganizationRoleEditor}},
+	}}, nil)
+	m.webhookRepo.On("FindByUserID", mock.Anything, userID).Return([]domain.Webhook{{ID: uuid.New(), URL: "https://example.com/hook", Secret: "secret_signing_key"}}, nil)
+	m.notificationRepo.On("FindNotifications", mock.Anything, userID, false, 0).Return([]domain.Notification{{ID: uuid.New(), Message: "Your poll closed"}}, nil)
+	m.storage.On("Save", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Run(func(args mock.Arguments) {
+		archive = args.Get(2).([]byte)
+	}).Return(nil)
+	m.exportRepo.On("MarkReady", mock.Anything, mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	m.mailer.On("Send", mock.Anything, mock.MatchedBy(func(message MailMessage) bool {
+		return message.To == "test@example.com" && strings.Contains(message.Body, "/download?token=")
+	})).Return(nil)
+
+	resp, err := service.RequestExport(ctx)
+
+	assert.NoError(t, err)
+	assert.Contains(t, resp.DownloadURL, "http://localhost:9000/api/v1/exports/")
+	m.exportRepo.AssertExpectations(t)
+	m.mailer.AssertExpectations(t)
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		assert.NoError(t, err)
+		files[file.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	assert.Len(t, files, 9)
+
+	var exportedPolls []dto.ExportPoll
+	assert.NoError(t, json.Unmarshal(files["polls.json"], &exportedPolls))
+	assert.Equal(t, 3, exportedPolls[0].TotalVotes)
+	assert.Equal(t, 2, exportedPolls[0].Options[0].Votes)
+
+	var memberships []dto.ExportMembership
+	assert.NoError(t, json.Unmarshal(files["organizations.json"], &memberships))
+	assert.Equal(t, []dto.ExportMembership{{OrganizationID: memberships[0].OrganizationID, OrganizationName: "Acme", Role: string(domain.OrganizationRoleEditor)}}, memberships)
+
+	assert.Contains(t, string(files["identities.json"]), `"provider": "google"`)
+	assert.Contains(t, string(files["notifications.json"]), "Your poll closed")
+
+	// session tokens, API keys and webhook secrets are credentials and must
+	// never leave the server
+	assert.NotContains(t, string(files["sessions.json"]), "secret_refresh_token")
+	assert.NotContains(t, string(files["api_keys.json"]), "secret_api_key")
+	assert.NotContains(t, string(files["webhooks.json"]), "secret_signing_key")
+}
+
+func TestRequestExport_AlreadyInProgress(t *testing.T) {
+	service, m := newTestDataExportService()
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	m.exportRepo.On("FindActiveByUserID", ctx, userID).Return(&domain.DataExport{ID: uuid.New(), Status: domain.DataExportProcessing, CreatedAt: time.Now().Add(-time.Minute)}, nil)
+
+	_, err := service.RequestExport(ctx)
+
+	assert.ErrorIs(t, err, utils.DataExportInProgressError)
+	m.exportRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestRequestExport_LosesRaceToAnotherRequest(t *testing.T) {
+	service, m := newTestDataExportService()
+
+	built := false
+	service.run = func(func()) { built = true }
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	// both requests found no export, the other one saved first
+	m.exportRepo.On("FindActiveByUserID", ctx, userID).Return(nil, utils.DataExportNotFoundError)
+	m.exportRepo.On("Save", ctx, mock.AnythingOfType("*domain.DataExport")).Return(utils.DataExportInProgressError)
+
+	_, err := service.RequestExport(ctx)
+
+	assert.ErrorIs(t, err, utils.DataExportInProgressError)
+	assert.False(t, built)
+}
+
+func TestRequestExport_AbandonedExportFails(t *testing.T) {
+	service, m := newTestDataExportService()
+
+	// builds are not followed past the start here
+	service.run = func(func()) {}
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	abandoned := &domain.DataExport{ID: uuid.New(), Status: domain.DataExportProcessing, CreatedAt: time.Now().Add(-dataExportBuildTimeout - time.Minute)}
+
+	m.exportRepo.On("FindActiveByUserID", ctx, userID).Return(abandoned, nil)
+	m.exportRepo.On("UpdateStatus", ctx, abandoned.ID, domain.DataExportFailed).Return(nil)
+	m.exportRepo.On("Save", ctx, mock.AnythingOfType("*domain.DataExport")).Return(nil)
+
+	resp, err := service.RequestExport(ctx)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	m.exportRepo.AssertExpectations(t)
+}
+
+func TestOpenDownload(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	expiredAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		export domain.DataExport
+		token  string
+		err    error
+	}{
+		{"ready", domain.DataExport{Status: domain.DataExportReady, ExpiresAt: &expiresAt}, "download_token", nil},
+		{"wrong token", domain.DataExport{Status: domain.DataExportReady, ExpiresAt: &expiresAt}, "guessed", utils.DataExportNotFoundError},
+		{"still building", domain.DataExport{Status: domain.DataExportProcessing}, "download_token", utils.DataExportNotReadyError},
+		{"expired", domain.DataExport{Status: domain.DataExportReady, ExpiresAt: &expiredAt}, "download_token", utils.DataExportExpiredError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestDataExportService()
+
+			ctx := context.Background()
+			export := tt.export
+			export.ID = uuid.New()
+			export.TokenHash = utils.HashToken("download_token")
+			export.FileName = "user/export.zip"
+
+			m.exportRepo.On("FindByID", ctx, export.ID).Return(&export, nil)
+			m.storage.On("Open", ctx, "user/export.zip").Return(io.NopCloser(strings.NewReader("zip")), nil)
+
+			archive, err := service.OpenDownload(ctx, export.ID, tt.token)
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				m.storage.AssertNotCalled(t, "Open", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, archive)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package application
+
+import (
+	"context"
+
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type EmbedService interface {
+	// OEmbed answers an oEmbed request for a link to a poll with an iframe
+	// of the poll's widget.
+	OEmbed(ctx context.Context, oembedRequest dto.OEmbedRequest) (*dto.OEmbedResponse, error)
+}
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+const (
+	defaultEmbedWidth  = 480
+	defaultEmbedHeight = 360
+)
+
+type embedservice struct {
+	pollrepo  repository.PollRepository
+	appURL    string
+	publicURL string
+}
+
+func NewEmbedService(pollrepo repository.PollRepository, appURL string, publicURL string) EmbedService {
+	return &embedservice{
+		pollrepo:  pollrepo,
+		appURL:    appURL,
+		publicURL: publicURL,
+	}
+}
+
+func (s *embedservice) OEmbed(ctx context.Context, oembedRequest dto.OEmbedRequest) (*dto.OEmbedResponse, error) {
+
+	if oembedRequest.Format != "" && oembedRequest.Format != "json" {
+		return nil, utils.UnsupportedEmbedFormatError
+	}
+
+	pollID, ok := s.pollID(oembedRequest.URL)
+
+	if !ok {
+		return nil, utils.UnsupportedEmbedURLError
+	}
+
+	// the response does not describe the poll, since whoever asks is not
+	// signed in; the widget checks access itself once it loads
+	if _, err := s.pollrepo.FindPollByID(ctx, pollID); err != nil {
+		return nil, err
+	}
+
+	width := defaultEmbedWidth
+	height := defaultEmbedHeight
+
+	if oembedRequest.MaxWidth > 0 && oembedRequest.MaxWidth < width {
+		width = oembedRequest.MaxWidth
+	}
+
+	if oembedRequest.MaxHeight > 0 && oembedRequest.MaxHeight < height {
+		height = oembedRequest.MaxHeight
+	}
+
+	src := fmt.Sprintf("%s/embed/polls/%s", s.publicURL, pollID)
+
+	return &dto.OEmbedResponse{
+		Type:         "rich",
+		Version:      "1.0",
+		ProviderName: "Jille",
+		ProviderURL:  s.appURL,
+		HTML:         fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="Jille poll" loading="lazy" style="border:0"></iframe>`, html.EscapeString(src), width, height),
+		Width:        width,
+		Height:       height,
+	}, nil
+}
+
+// pollID finds the poll a link points to. Links to the poll in the app and
+// to its widget are both accepted.
+func (s *embedservice) pollID(raw string) (uuid.UUID, bool) {
+
+	link, err := url.Parse(raw)
+
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	routes := []struct {
+		base   string
+		prefix string
+	}{
+		{s.appURL, "/polls/"},
+		{s.publicURL, "/embed/polls/"},
+	}
+
+	for _, route := range routes {
+
+		base, err := url.Parse(route.base)
+
+		if err != nil || !strings.EqualFold(base.Host, link.Host) {
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(link.Path, strings.TrimSuffix(base.Path, "/")+route.prefix)
+
+		if !ok {
+			continue
+		}
+
+		rest = strings.TrimSuffix(strings.TrimSuffix(rest, "/"), "/view")
+
+		if pollID, err := uuid.Parse(rest); err == nil {
+			return pollID, true
+		}
+	}
+
+	return uuid.Nil, false
+}
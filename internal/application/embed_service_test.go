@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestOEmbed_PollLinks(t *testing.T) {
+	mockPollRepo := new(mocks.PollRepository)
+	service := NewEmbedService(mockPollRepo, "https://jille.example.com", "https://api.jille.example.com")
+
+	pollID := uuid.New()
+	ctx := context.Background()
+
+	mockPollRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID}, nil)
+
+	for _, link := range []string{
+		"https://jille.example.com/polls/" + pollID.String(),
+		"http://jille.example.com/polls/" + pollID.String() + "/view",
+		"https://api.jille.example.com/embed/polls/" + pollID.String(),
+	} {
+		response, err := service.OEmbed(ctx, dto.OEmbedRequest{URL: link, MaxWidth: 320})
+
+		if assert.NoError(t, err, link) {
+			assert.Equal(t, "rich", response.Type)
+			assert.Equal(t, 320, response.Width)
+			assert.Equal(t, defaultEmbedHeight, response.Height)
+			assert.Contains(t, response.HTML, `src="https://api.jille.example.com/embed/polls/`+pollID.String()+`"`)
+		}
+	}
+}
+
+func TestOEmbed_RejectsOtherLinks(t *testing.T) {
+	mockPollRepo := new(mocks.PollRepository)
+	service := NewEmbedService(mockPollRepo, "https://jille.example.com", "https://api.jille.example.com")
+
+	pollID := uuid.New()
+
+	for _, link := range []string{
+		"https://evil.example.com/polls/" + pollID.String(),
+		"https://jille.example.com/organizations/" + pollID.String(),
+		"https://jille.example.com/polls/not-a-poll",
+	} {
+		_, err := service.OEmbed(context.Background(), dto.OEmbedRequest{URL: link})
+
+		assert.ErrorIs(t, err, utils.UnsupportedEmbedURLError, link)
+	}
+
+	_, err := service.OEmbed(context.Background(), dto.OEmbedRequest{URL: "https://jille.example.com/polls/" + pollID.String(), Format: "xml"})
+
+	assert.ErrorIs(t, err, utils.UnsupportedEmbedFormatError)
+	mockPollRepo.AssertNotCalled(t, "FindPollByID", mock.Anything, mock.Anything)
+}
+
+func TestOEmbed_UnknownPoll(t *testing.T) {
+	mockPollRepo := new(mocks.PollRepository)
+	service := NewEmbedService(mockPollRepo, "https://jille.example.com", "https://api.jille.example.com")
+
+	pollID := uuid.New()
+	ctx := context.Background()
+
+	mockPollRepo.On("FindPollByID", ctx, pollID).Return(nil, utils.PollNotFoundError)
+
+	_, err := service.OEmbed(ctx, dto.OEmbedRequest{URL: "https://jille.example.com/polls/" + pollID.String()})
+
+	assert.ErrorIs(t, err, utils.PollNotFoundError)
+}
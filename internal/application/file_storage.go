@@ -0,0 +1,19 @@
+package application
+
+import (
+	"context"
+	"io"
+)
+
+// FileStorage keeps uploaded files, such as profile pictures, addressed by a
+// slash separated name.
+type FileStorage interface {
+	Save(ctx context.Context, name string, data []byte) error
+
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	Delete(ctx context.Context, name string) error
+
+	// URL returns the public address of a stored file.
+	URL(name string) string
+}
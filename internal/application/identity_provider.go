@@ -0,0 +1,26 @@
+package application
+
+import "context"
+
+// ExternalIdentity is the verified set of claims an identity provider
+// returned for a user.
+type ExternalIdentity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nonce             string
+}
+
+type IdentityProvider interface {
+	Name() string
+
+	// AuthCodeURL builds the authorization endpoint URL using a S256 PKCE
+	// code challenge.
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+
+	// Exchange redeems an authorization code and returns the claims of the
+	// validated ID token.
+	Exchange(ctx context.Context, code string, codeVerifier string) (*ExternalIdentity, error)
+}
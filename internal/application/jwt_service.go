@@ -8,4 +8,6 @@ type JwtService interface {
 	VerifyRefreshToken(token string) (bool, error)
 	GetAccessTokenSecretKey() string
 	GetRefreshTokenSecretKey() string
+	GenerateMFAToken(userId string) (string, error)
+	GetMFATokenSubject(token string) (string, error)
 }
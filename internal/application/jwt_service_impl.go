@@ -1,7 +1,7 @@
 package application
 
 import (
-	"fmt"
+	"crypto/sha256"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
@@ -67,8 +67,6 @@ func (j *jwtservice) VerifyAccessToken(token string) (bool, error) {
 		return []byte(j.GetAccessTokenSecretKey()), nil
 	})
 
-	fmt.Println(jwtToken)
-
 	if err != nil {
 		return false, err
 	}
@@ -115,3 +113,37 @@ func (j *jwtservice) GetAccessTokenSecretKey() string {
 func (j *jwtservice) GetRefreshTokenSecretKey() string {
 	return j.refreshTokenSecret
 }
+
+// MFA challenge tokens are signed with a key derived from the access token
+// secret so they can never be mistaken for an access token.
+func (j *jwtservice) mfaTokenSecretKey() []byte {
+
+	sum := sha256.Sum256([]byte("mfa-challenge:" + j.GetAccessTokenSecretKey()))
+
+	return sum[:]
+}
+
+func (j *jwtservice) GenerateMFAToken(userId string) (string, error) {
+
+	jwt := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.RegisteredClaims{
+		Subject:   userId,
+		Audience:  jwt.ClaimStrings{"mfa"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 5)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	})
+
+	return jwt.SignedString(j.mfaTokenSecretKey())
+}
+
+func (j *jwtservice) GetMFATokenSubject(token string) (string, error) {
+
+	jwtToken, err := jwt.Parse(token, func(ts *jwt.Token) (interface{}, error) {
+		return j.mfaTokenSecretKey(), nil
+	}, jwt.WithAudience("mfa"), jwt.WithValidMethods([]string{"HS512"}))
+
+	if err != nil {
+		return "", err
+	}
+
+	return jwtToken.Claims.GetSubject()
+}
@@ -0,0 +1,14 @@
+package application
+
+import "context"
+
+// LoginGuard throttles repeated failed logins per account and per client IP.
+type LoginGuard interface {
+	// Check fails with a *utils.LockoutError while either the account or the
+	// IP is locked out.
+	Check(ctx context.Context, account string, ip string) error
+
+	RecordFailure(ctx context.Context, account string, ip string) error
+
+	RecordSuccess(ctx context.Context, account string) error
+}
@@ -0,0 +1,125 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+const loginFailureWindow = time.Hour
+
+type lockoutPolicy struct {
+	// threshold is the number of failures allowed before locking
+	threshold int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+var (
+	accountLockoutPolicy = lockoutPolicy{threshold: 5, baseDelay: time.Second * 30, maxDelay: time.Hour}
+	ipLockoutPolicy      = lockoutPolicy{threshold: 20, baseDelay: time.Second * 30, maxDelay: time.Hour}
+)
+
+// delay doubles for every failure past the threshold, up to maxDelay.
+func (p lockoutPolicy) delay(failures int) time.Duration {
+
+	if failures < p.threshold {
+		return 0
+	}
+
+	delay := p.baseDelay
+
+	for i := p.threshold; i < failures && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.maxDelay)
+}
+
+type loginguard struct {
+	repo repository.LoginThrottleRepository
+}
+
+func NewLoginGuard(repo repository.LoginThrottleRepository) LoginGuard {
+	return &loginguard{
+		repo: repo,
+	}
+}
+
+func accountKey(account string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(account))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (g *loginguard) keys(account string, ip string) []string {
+
+	keys := []string{accountKey(account)}
+
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+
+	return keys
+}
+
+func (g *loginguard) Check(ctx context.Context, account string, ip string) error {
+
+	throttles, err := g.repo.FindByKeys(ctx, g.keys(account, ip))
+
+	if err != nil {
+		return err
+	}
+
+	var retryAfter time.Duration
+
+	for _, throttle := range throttles {
+		retryAfter = max(retryAfter, time.Until(throttle.LockedUntil))
+	}
+
+	if retryAfter > 0 {
+		return &utils.LockoutError{RetryAfter: retryAfter.Round(time.Second)}
+	}
+
+	return nil
+}
+
+func (g *loginguard) RecordFailure(ctx context.Context, account string, ip string) error {
+
+	if err := g.recordFailure(ctx, accountKey(account), accountLockoutPolicy); err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	return g.recordFailure(ctx, ipKey(ip), ipLockoutPolicy)
+}
+
+func (g *loginguard) recordFailure(ctx context.Context, key string, policy lockoutPolicy) error {
+
+	failures, err := g.repo.RecordFailure(ctx, key, loginFailureWindow)
+
+	if err != nil {
+		return err
+	}
+
+	if delay := policy.delay(failures); delay > 0 {
+		return g.repo.Lock(ctx, key, time.Now().Add(delay))
+	}
+
+	return nil
+}
+
+// RecordSuccess clears the account's failures. IP failures are left to expire
+// so one valid account cannot be used to reset an attacker's IP.
+func (g *loginguard) RecordSuccess(ctx context.Context, account string) error {
+
+	return g.repo.Reset(ctx, accountKey(account))
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestLockoutPolicy_Delay(t *testing.T) {
+	policy := lockoutPolicy{threshold: 5, baseDelay: time.Second * 30, maxDelay: time.Hour}
+
+	assert.Equal(t, time.Duration(0), policy.delay(4))
+	assert.Equal(t, time.Second*30, policy.delay(5))
+	assert.Equal(t, time.Minute, policy.delay(6))
+	assert.Equal(t, time.Minute*2, policy.delay(7))
+	assert.Equal(t, time.Hour, policy.delay(50))
+}
+
+func TestLoginGuard_CheckLocked(t *testing.T) {
+	mockRepo := new(mocks.LoginThrottleRepository)
+	guard := NewLoginGuard(mockRepo)
+
+	ctx := context.Background()
+
+	mockRepo.On("FindByKeys", ctx, []string{"account:test@example.com", "ip:10.0.0.1"}).Return([]domain.LoginThrottle{
+		{Key: "account:test@example.com", LockedUntil: time.Now().Add(-time.Minute)},
+		{Key: "ip:10.0.0.1", LockedUntil: time.Now().Add(time.Minute * 2)},
+	}, nil)
+
+	err := guard.Check(ctx, "Test@Example.com", "10.0.0.1")
+
+	var lockout *utils.LockoutError
+	assert.True(t, errors.As(err, &lockout))
+	assert.ErrorIs(t, err, utils.TooManyAttemptsError)
+	assert.InDelta(t, (time.Minute * 2).Seconds(), lockout.RetryAfter.Seconds(), 1)
+}
+
+func TestLoginGuard_CheckNotLocked(t *testing.T) {
+	mockRepo := new(mocks.LoginThrottleRepository)
+	guard := NewLoginGuard(mockRepo)
+
+	ctx := context.Background()
+
+	mockRepo.On("FindByKeys", ctx, []string{"account:test@example.com"}).Return([]domain.LoginThrottle{}, nil)
+
+	assert.NoError(t, guard.Check(ctx, "test@example.com", ""))
+}
+
+func TestLoginGuard_RecordFailureLocksPastThreshold(t *testing.T) {
+	mockRepo := new(mocks.LoginThrottleRepository)
+	guard := NewLoginGuard(mockRepo)
+
+	ctx := context.Background()
+
+	mockRepo.On("RecordFailure", ctx, "account:test@example.com", loginFailureWindow).Return(5, nil)
+	mockRepo.On("RecordFailure", ctx, "ip:10.0.0.1", loginFailureWindow).Return(3, nil)
+	mockRepo.On("Lock", ctx, "account:test@example.com", mock.MatchedBy(func(until time.Time) bool {
+		return time.Until(until) > time.Second*25 && time.Until(until) <= time.Second*30
+	})).Return(nil)
+
+	err := guard.RecordFailure(ctx, "test@example.com", "10.0.0.1")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Lock", ctx, "ip:10.0.0.1", mock.Anything)
+}
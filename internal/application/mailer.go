@@ -0,0 +1,13 @@
+package application
+
+import "context"
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message MailMessage) error
+}
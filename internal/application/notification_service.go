@@ -0,0 +1,47 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type NotificationService interface {
+	// GetPreferences returns how the signed in user hears about each kind
+	// of notification, defaults included.
+	GetPreferences(ctx context.Context) ([]dto.NotificationPreferenceResponse, error)
+
+	UpdatePreferences(ctx context.Context, updateRequest dto.UpdateNotificationPreferencesRequest) ([]dto.NotificationPreferenceResponse, error)
+
+	// GetNotifications returns the signed in user's newest inbox
+	// notifications.
+	GetNotifications(ctx context.Context, unreadOnly bool) ([]dto.NotificationResponse, error)
+
+	CountUnread(ctx context.Context) (*dto.UnreadNotificationsResponse, error)
+
+	MarkRead(ctx context.Context, notificationID uuid.UUID) error
+
+	MarkAllRead(ctx context.Context) error
+
+	// RemindExpiring records poll.expiring events for polls that close
+	// within a day.
+	RemindExpiring(ctx context.Context) error
+
+	// HandleEvent turns an outbox event into emails for everyone who wants
+	// to hear about it and into inbox notifications. It is the outbox
+	// relay's notifications consumer.
+	HandleEvent(ctx context.Context, event domain.OutboxEvent) error
+
+	// SendDue sends the emails that are not part of a digest.
+	SendDue(ctx context.Context) error
+
+	// SendDigests sends each recipient with due digest emails a single
+	// email collecting them.
+	SendDigests(ctx context.Context) error
+
+	// Purge drops sent emails and read notifications once they are no
+	// longer needed.
+	Purge(ctx context.Context) error
+}
@@ -0,0 +1,734 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+const (
+	notificationLease       = time.Minute * 2
+	notificationEmailBatch  = 50
+	notificationDigestBatch = 50
+
+	// a failed email is retried a few times, waiting twice as long each
+	// time, before it is given up
+	notificationMaxAttempts = 5
+	notificationRetryDelay  = time.Minute
+
+	// digests go out once a day at this hour, UTC
+	notificationDigestHour = 8
+
+	notificationExpiringWindow = time.Hour * 24
+	notificationExpiringBatch  = 100
+
+	notificationEmailRetention = time.Hour * 24 * 30
+
+	notificationInboxSize = 50
+	// read notifications are dropped after this, unread ones are kept
+	notificationInboxRetention = time.Hour * 24 * 90
+)
+
+//go:embed templates/notifications
+var notificationTemplateFS embed.FS
+
+var notificationTemplates = map[domain.NotificationKind]*template.Template{
+	domain.NotificationPollClosed:   parseNotificationTemplate("poll_closed"),
+	domain.NotificationResultsReady: parseNotificationTemplate("results_ready"),
+	domain.NotificationPollInvited:  parseNotificationTemplate("poll_invited"),
+	domain.NotificationPollExpiring: parseNotificationTemplate("poll_expiring"),
+}
+
+var digestTemplate = parseNotificationTemplate("digest")
+
+func parseNotificationTemplate(name string) *template.Template {
+	return template.Must(template.ParseFS(notificationTemplateFS, "templates/notifications/layout.tmpl", "templates/notifications/"+name+".tmpl"))
+}
+
+type notificationservice struct {
+	repo       repository.NotificationRepository
+	pollrepo   repository.PollRepository
+	voterepo   repository.VoteRepository
+	inviterepo repository.PollInviteRepository
+	userrepo   repository.UserRepository
+	mailer     Mailer
+	publisher  EventPublisher
+	appURL     string
+
+	now func() time.Time
+}
+
+func NewNotificationService(repo repository.NotificationRepository, pollrepo repository.PollRepository, voterepo repository.VoteRepository, inviterepo repository.PollInviteRepository, userrepo repository.UserRepository, mailer Mailer, publisher EventPublisher, appURL string) NotificationService {
+	return &notificationservice{
+		repo:       repo,
+		pollrepo:   pollrepo,
+		voterepo:   voterepo,
+		inviterepo: inviterepo,
+		userrepo:   userrepo,
+		mailer:     mailer,
+		publisher:  publisher,
+		appURL:     appURL,
+		now:        time.Now,
+	}
+}
+
+func (s *notificationservice) GetPreferences(ctx context.Context) ([]dto.NotificationPreferenceResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	preferences, err := s.repo.FindPreferences(ctx, []uuid.UUID{userID})
+
+	if err != nil {
+		return nil, err
+	}
+
+	chosen := make(map[domain.NotificationKind]domain.NotificationDelivery, len(preferences))
+
+	for _, preference := range preferences {
+		chosen[preference.Kind] = preference.Delivery
+	}
+
+	responses := make([]dto.NotificationPreferenceResponse, 0, len(domain.NotificationKinds))
+
+	for _, kind := range domain.NotificationKinds {
+
+		delivery, ok := chosen[kind]
+
+		if !ok {
+			delivery = kind.DefaultDelivery()
+		}
+
+		responses = append(responses, dto.NotificationPreferenceResponse{Kind: string(kind), Delivery: string(delivery)})
+	}
+
+	return responses, nil
+}
+
+func (s *notificationservice) UpdatePreferences(ctx context.Context, updateRequest dto.UpdateNotificationPreferencesRequest) ([]dto.NotificationPreferenceResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	preferences := make([]domain.NotificationPreference, 0, len(updateRequest.Preferences))
+
+	for _, preference := range updateRequest.Preferences {
+		preferences = append(preferences, domain.NotificationPreference{
+			UserID:    userID,
+			Kind:      domain.NotificationKind(preference.Kind),
+			Delivery:  domain.NotificationDelivery(preference.Delivery),
+			UpdatedAt: s.now(),
+		})
+	}
+
+	if err := s.repo.SavePreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(ctx)
+}
+
+func (s *notificationservice) GetNotifications(ctx context.Context, unreadOnly bool) ([]dto.NotificationResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	notifications, err := s.repo.FindNotifications(ctx, userID, unreadOnly, notificationInboxSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.NotificationResponse, 0, len(notifications))
+
+	for _, notification := range notifications {
+		responses = append(responses, notificationResponse(notification))
+	}
+
+	return responses, nil
+}
+
+func (s *notificationservice) CountUnread(ctx context.Context) (*dto.UnreadNotificationsResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	count, err := s.repo.CountUnread(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.UnreadNotificationsResponse{Count: count}, nil
+}
+
+func (s *notificationservice) MarkRead(ctx context.Context, notificationID uuid.UUID) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	return s.repo.MarkRead(ctx, userID, notificationID, s.now())
+}
+
+func (s *notificationservice) MarkAllRead(ctx context.Context) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	return s.repo.MarkAllRead(ctx, userID, s.now())
+}
+
+// publish pushes the notifications to their users' open streams.
+func (s *notificationservice) publish(notifications []domain.Notification) {
+
+	for _, notification := range notifications {
+		s.publisher.Publish(utils.Event{
+			Type:    "NOTIFICATION",
+			Payload: notificationResponse(notification),
+			UserID:  notification.UserID.String(),
+		})
+	}
+}
+
+func notificationResponse(notification domain.Notification) dto.NotificationResponse {
+	return dto.NotificationResponse{
+		ID:        notification.ID,
+		Kind:      string(notification.Kind),
+		PollID:    notification.PollID,
+		Message:   notification.Message,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+func (s *notificationservice) RemindExpiring(ctx context.Context) error {
+
+	for {
+		marked, err := s.pollrepo.MarkExpiring(ctx, s.now(), notificationExpiringWindow, notificationExpiringBatch)
+
+		if err != nil || marked < notificationExpiringBatch {
+			return err
+		}
+	}
+}
+
+func (s *notificationservice) HandleEvent(ctx context.Context, event domain.OutboxEvent) error {
+
+	switch event.Type {
+	case domain.OutboxPollClosed, domain.OutboxPollInvited, domain.OutboxPollExpiring, domain.OutboxVoteCast:
+	default:
+		return nil
+	}
+
+	poll, err := s.pollrepo.FindPollByID(ctx, event.PollID)
+
+	if err != nil {
+		// nobody hears about polls deleted since
+		if errors.Is(err, utils.PollNotFoundError) {
+			return nil
+		}
+		return err
+	}
+
+	emails, err := s.emailsFor(ctx, event, poll)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SaveEmails(ctx, emails); err != nil {
+		return err
+	}
+
+	notifications, err := s.inboxFor(ctx, event, poll)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SaveNotifications(ctx, notifications); err != nil {
+		return err
+	}
+
+	s.publish(notifications)
+
+	return nil
+}
+
+// inboxFor returns the inbox notifications for an event. Creators hear about
+// votes on their poll and voters that a poll they voted in has closed. IDs
+// are derived from the event and user, so an event handled twice adds and
+// pushes the same notification.
+func (s *notificationservice) inboxFor(ctx context.Context, event domain.OutboxEvent, poll *domain.Poll) ([]domain.Notification, error) {
+
+	notification := func(userID uuid.UUID, kind domain.NotificationKind, message string) domain.Notification {
+		return domain.Notification{
+			ID:        uuid.NewSHA1(event.ID, userID[:]),
+			UserID:    userID,
+			Kind:      kind,
+			PollID:    poll.ID,
+			EventID:   &event.ID,
+			Message:   message,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	switch event.Type {
+
+	case domain.OutboxVoteCast:
+
+		var vote domain.VoteCastPayload
+
+		if err := event.DecodePayload(&vote); err != nil {
+			return nil, err
+		}
+
+		if vote.UserID == poll.UserID {
+			return nil, nil
+		}
+
+		voter, err := s.userrepo.FindById(ctx, vote.UserID)
+
+		if err != nil {
+			if errors.Is(err, utils.UserNotFoundError) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		return []domain.Notification{
+			notification(poll.UserID, domain.NotificationVoteReceived, fmt.Sprintf("%s voted on your poll \"%s\"", voter.Username, poll.Title)),
+		}, nil
+
+	case domain.OutboxPollClosed:
+
+		voterIDs, err := s.voterepo.FindVoterIDs(ctx, poll.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var notifications []domain.Notification
+
+		for _, voterID := range voterIDs {
+			notifications = append(notifications, notification(voterID, domain.NotificationPollClosed, fmt.Sprintf("The poll \"%s\" you voted in has closed", poll.Title)))
+		}
+
+		return notifications, nil
+	}
+
+	return nil, nil
+}
+
+// notificationRecipient is someone an event concerns. Invitees without an
+// account only have an email address.
+type notificationRecipient struct {
+	userID *uuid.UUID
+	email  string
+	kind   domain.NotificationKind
+}
+
+// emailsFor renders the emails an event leads to, leaving out recipients
+// who turned that kind of notification off.
+func (s *notificationservice) emailsFor(ctx context.Context, event domain.OutboxEvent, poll *domain.Poll) ([]domain.EmailNotification, error) {
+
+	recipients, err := s.recipientsFor(ctx, event, poll)
+
+	if err != nil || len(recipients) == 0 {
+		return nil, err
+	}
+
+	var userIDs []uuid.UUID
+
+	for _, recipient := range recipients {
+		if recipient.userID != nil {
+			userIDs = append(userIDs, *recipient.userID)
+		}
+	}
+
+	users, err := s.userrepo.FindByIDs(ctx, userIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	usersByID := make(map[uuid.UUID]domain.User, len(users))
+
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	preferences, err := s.repo.FindPreferences(ctx, userIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	type preferenceKey struct {
+		userID uuid.UUID
+		kind   domain.NotificationKind
+	}
+
+	deliveries := make(map[preferenceKey]domain.NotificationDelivery, len(preferences))
+
+	for _, preference := range preferences {
+		deliveries[preferenceKey{preference.UserID, preference.Kind}] = preference.Delivery
+	}
+
+	now := s.now()
+	data := s.notificationData(poll)
+
+	var emails []domain.EmailNotification
+
+	for _, recipient := range recipients {
+
+		delivery := recipient.kind.DefaultDelivery()
+		data.Name = ""
+
+		if recipient.userID != nil {
+
+			user, ok := usersByID[*recipient.userID]
+
+			// deleted accounts get nothing
+			if !ok {
+				continue
+			}
+
+			if chosen, ok := deliveries[preferenceKey{user.ID, recipient.kind}]; ok {
+				delivery = chosen
+			}
+
+			recipient.email = user.Email
+			data.Name = user.Username
+		}
+
+		if delivery == domain.NotificationOff {
+			continue
+		}
+
+		email := domain.EmailNotification{
+			EventID:   event.ID,
+			Email:     recipient.email,
+			UserID:    recipient.userID,
+			Kind:      recipient.kind,
+			PollID:    poll.ID,
+			Digest:    delivery == domain.NotificationDigest,
+			Status:    domain.EmailNotificationPending,
+			SendAfter: now,
+		}
+
+		if email.Digest {
+			email.SendAfter = nextDigest(now)
+		}
+
+		tmpl := notificationTemplates[recipient.kind]
+
+		for _, part := range []struct {
+			name   string
+			target *string
+		}{
+			{"subject", &email.Subject},
+			{"body", &email.Body},
+			{"summary", &email.Summary},
+		} {
+			var out bytes.Buffer
+
+			if err := tmpl.ExecuteTemplate(&out, part.name, data); err != nil {
+				return nil, err
+			}
+
+			*part.target = out.String()
+		}
+
+		emails = append(emails, email)
+	}
+
+	return emails, nil
+}
+
+func (s *notificationservice) recipientsFor(ctx context.Context, event domain.OutboxEvent, poll *domain.Poll) ([]notificationRecipient, error) {
+
+	var recipients []notificationRecipient
+
+	switch event.Type {
+
+	case domain.OutboxPollClosed:
+
+		recipients = append(recipients, notificationRecipient{userID: &poll.UserID, kind: domain.NotificationPollClosed})
+
+		// voters hear about the results only if they may see them
+		if poll.ResultsVisibility == domain.ResultsVisibilityCreatorOnly {
+			return recipients, nil
+		}
+
+		voterIDs, err := s.voterepo.FindVoterIDs(ctx, poll.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, voterID := range voterIDs {
+			if voterID != poll.UserID {
+				recipients = append(recipients, notificationRecipient{userID: &voterID, kind: domain.NotificationResultsReady})
+			}
+		}
+
+	case domain.OutboxPollInvited:
+
+		var payload domain.PollInvitedPayload
+
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return nil, err
+		}
+
+		recipients = append(recipients, notificationRecipient{userID: payload.UserID, email: payload.Email, kind: domain.NotificationPollInvited})
+
+	case domain.OutboxPollExpiring:
+
+		invites, err := s.inviterepo.FindByPollID(ctx, poll.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var inviteeIDs []uuid.UUID
+
+		for _, invite := range invites {
+			if invite.UserID != nil {
+				inviteeIDs = append(inviteeIDs, *invite.UserID)
+			}
+		}
+
+		votedIDs, err := s.voterepo.FindVotedUserIDs(ctx, poll.ID, inviteeIDs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		voted := make(map[uuid.UUID]bool, len(votedIDs))
+
+		for _, voterID := range votedIDs {
+			voted[voterID] = true
+		}
+
+		for _, invite := range invites {
+			if invite.UserID == nil || !voted[*invite.UserID] {
+				recipients = append(recipients, notificationRecipient{userID: invite.UserID, email: invite.Email, kind: domain.NotificationPollExpiring})
+			}
+		}
+	}
+
+	return recipients, nil
+}
+
+type notificationData struct {
+	Name       string
+	Poll       notificationPoll
+	Results    []notificationResult
+	TotalVotes int
+}
+
+type notificationPoll struct {
+	Title     string
+	URL       string
+	ExpiresAt time.Time
+}
+
+type notificationResult struct {
+	Option  string
+	Votes   int
+	Percent float64
+}
+
+func (s *notificationservice) notificationData(poll *domain.Poll) notificationData {
+
+	data := notificationData{
+		Poll: notificationPoll{
+			Title:     poll.Title,
+			URL:       fmt.Sprintf("%s/polls/%s", s.appURL, poll.ID),
+			ExpiresAt: poll.ExpiresAt.UTC(),
+		},
+	}
+
+	for _, option := range poll.Options {
+		data.TotalVotes += option.VoteCount
+	}
+
+	for _, option := range poll.Options {
+
+		result := notificationResult{Option: option.Name, Votes: option.VoteCount}
+
+		if data.TotalVotes > 0 {
+			result.Percent = 100 * float64(result.Votes) / float64(data.TotalVotes)
+		}
+
+		data.Results = append(data.Results, result)
+	}
+
+	return data
+}
+
+// nextDigest returns the first digest time after now.
+func nextDigest(now time.Time) time.Time {
+
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), notificationDigestHour, 0, 0, 0, time.UTC)
+
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+func (s *notificationservice) SendDue(ctx context.Context) error {
+
+	for {
+		emails, err := s.repo.ClaimDueEmails(ctx, s.now(), notificationLease, notificationEmailBatch)
+
+		if err != nil {
+			return err
+		}
+
+		for i := range emails {
+
+			err := s.mailer.Send(ctx, MailMessage{
+				To:      emails[i].Email,
+				Subject: emails[i].Subject,
+				Body:    emails[i].Body,
+			})
+
+			s.recordAttempt(&emails[i], err)
+		}
+
+		if err := s.repo.UpdateEmails(ctx, emails); err != nil {
+			return err
+		}
+
+		if len(emails) < notificationEmailBatch {
+			return nil
+		}
+	}
+}
+
+func (s *notificationservice) SendDigests(ctx context.Context) error {
+
+	for {
+		emails, err := s.repo.ClaimDueDigests(ctx, s.now(), notificationLease, notificationDigestBatch)
+
+		if err != nil {
+			return err
+		}
+
+		byRecipient := make(map[string][]int)
+		var recipients []string
+
+		for i, email := range emails {
+
+			if _, ok := byRecipient[email.Email]; !ok {
+				recipients = append(recipients, email.Email)
+			}
+
+			byRecipient[email.Email] = append(byRecipient[email.Email], i)
+		}
+
+		for _, recipient := range recipients {
+
+			indexes := byRecipient[recipient]
+
+			slices.SortFunc(indexes, func(a, b int) int {
+				return emails[a].CreatedAt.Compare(emails[b].CreatedAt)
+			})
+
+			err := s.sendDigest(ctx, recipient, emails, indexes)
+
+			for _, i := range indexes {
+				s.recordAttempt(&emails[i], err)
+			}
+		}
+
+		if err := s.repo.UpdateEmails(ctx, emails); err != nil {
+			return err
+		}
+
+		if len(recipients) < notificationDigestBatch {
+			return nil
+		}
+	}
+}
+
+func (s *notificationservice) sendDigest(ctx context.Context, recipient string, emails []domain.EmailNotification, indexes []int) error {
+
+	type digestItem struct {
+		Summary string
+		URL     string
+	}
+
+	data := struct {
+		Name  string
+		Items []digestItem
+	}{}
+
+	for _, i := range indexes {
+		data.Items = append(data.Items, digestItem{
+			Summary: emails[i].Summary,
+			URL:     fmt.Sprintf("%s/polls/%s", s.appURL, emails[i].PollID),
+		})
+	}
+
+	var subject, body bytes.Buffer
+
+	if err := digestTemplate.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return err
+	}
+
+	if err := digestTemplate.ExecuteTemplate(&body, "body", data); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, MailMessage{
+		To:      recipient,
+		Subject: subject.String(),
+		Body:    body.String(),
+	})
+}
+
+// recordAttempt marks the email sent, or schedules a retry until it runs
+// out of attempts.
+func (s *notificationservice) recordAttempt(email *domain.EmailNotification, err error) {
+
+	now := s.now()
+
+	email.Attempts++
+
+	if err == nil {
+		email.Status = domain.EmailNotificationSent
+		email.SentAt = &now
+		email.Error = ""
+		return
+	}
+
+	email.Error = err.Error()
+
+	if email.Attempts >= notificationMaxAttempts {
+		email.Status = domain.EmailNotificationFailed
+		return
+	}
+
+	email.SendAfter = now.Add(notificationRetryDelay << (email.Attempts - 1))
+}
+
+func (s *notificationservice) Purge(ctx context.Context) error {
+
+	if err := s.repo.DeleteEmailsBefore(ctx, s.now().Add(-notificationEmailRetention)); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteReadBefore(ctx, s.now().Add(-notificationInboxRetention))
+}
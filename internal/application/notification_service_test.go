@@ -0,0 +1,350 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// MockEventPublisher
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(event utils.Event) {
+	m.Called(event)
+}
+
+type notificationMocks struct {
+	repo       *mocks.NotificationRepository
+	pollRepo   *mocks.PollRepository
+	voteRepo   *mocks.VoteRepository
+	inviteRepo *mocks.PollInviteRepository
+	userRepo   *mocks.UserRepository
+	mailer     *MockMailer
+	publisher  *MockEventPublisher
+}
+
+func newTestNotificationService(now time.Time) (*notificationservice, notificationMocks) {
+	m := notificationMocks{
+		repo:       new(mocks.NotificationRepository),
+		pollRepo:   new(mocks.PollRepository),
+		voteRepo:   new(mocks.VoteRepository),
+		inviteRepo: new(mocks.PollInviteRepository),
+		userRepo:   new(mocks.UserRepository),
+		mailer:     new(MockMailer),
+		publisher:  new(MockEventPublisher),
+	}
+
+	service := NewNotificationService(m.repo, m.pollRepo, m.voteRepo, m.inviteRepo, m.userRepo, m.mailer, m.publisher, "http://localhost:3000").(*notificationservice)
+	service.now = func() time.Time { return now }
+
+	return service, m
+}
+
+func TestNotificationHandleEvent_PollClosedFollowsPreferences(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	service, m := newTestNotificationService(now)
+	ctx := context.Background()
+
+	creator := domain.User{ID: uuid.New(), Username: "ada", Email: "ada@example.com"}
+	optedOut := domain.User{ID: uuid.New(), Username: "bob", Email: "bob@example.com"}
+	instant := domain.User{ID: uuid.New(), Username: "cy", Email: "cy@example.com"}
+	byDefault := domain.User{ID: uuid.New(), Username: "di", Email: "di@example.com"}
+
+	poll := &domain.Poll{
+		ID:                uuid.New(),
+		UserID:            creator.ID,
+		Title:             "Lunch",
+		ResultsVisibility: domain.ResultsVisibilityAlways,
+		Options: []domain.Option{
+			{Name: "Pizza", VoteCount: 3},
+			{Name: "Sushi", VoteCount: 1},
+		},
+	}
+
+	event := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollClosed, PollID: poll.ID, Payload: "{}"}
+
+	m.pollRepo.On("FindPollByID", ctx, poll.ID).Return(poll, nil)
+	m.voteRepo.On("FindVoterIDs", ctx, poll.ID).Return([]uuid.UUID{creator.ID, optedOut.ID, instant.ID, byDefault.ID}, nil)
+	m.userRepo.On("FindByIDs", ctx, mock.Anything).Return([]domain.User{creator, optedOut, instant, byDefault}, nil)
+	m.repo.On("FindPreferences", ctx, mock.Anything).Return([]domain.NotificationPreference{
+		{UserID: optedOut.ID, Kind: domain.NotificationResultsReady, Delivery: domain.NotificationOff},
+		{UserID: instant.ID, Kind: domain.NotificationResultsReady, Delivery: domain.NotificationInstant},
+		// a preference for another kind leaves results alone
+		{UserID: byDefault.ID, Kind: domain.NotificationPollClosed, Delivery: domain.NotificationOff},
+	}, nil)
+
+	var saved []domain.EmailNotification
+	m.repo.On("SaveEmails", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]domain.EmailNotification)
+	}).Return(nil)
+	var inbox []domain.Notification
+	m.repo.On("SaveNotifications", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		inbox = args.Get(1).([]domain.Notification)
+	}).Return(nil)
+	m.publisher.On("Publish", mock.Anything).Return()
+
+	assert.NoError(t, service.HandleEvent(ctx, event))
+
+	// everyone who voted hears about it in the app, whatever their email
+	// preferences
+	assert.Len(t, inbox, 4)
+	for _, notification := range inbox {
+		assert.Equal(t, domain.NotificationPollClosed, notification.Kind)
+		assert.Equal(t, &event.ID, notification.EventID)
+	}
+	m.publisher.AssertNumberOfCalls(t, "Publish", 4)
+
+	emails := make(map[string]domain.EmailNotification, len(saved))
+	for _, email := range saved {
+		emails[email.Email] = email
+	}
+
+	assert.Len(t, emails, 3)
+	assert.NotContains(t, emails, optedOut.Email)
+
+	assert.Equal(t, domain.NotificationPollClosed, emails[creator.Email].Kind)
+	assert.False(t, emails[creator.Email].Digest)
+	assert.Equal(t, `Your poll "Lunch" has closed`, emails[creator.Email].Subject)
+	assert.Contains(t, emails[creator.Email].Body, "Hi ada,")
+	assert.Contains(t, emails[creator.Email].Body, "Pizza: 3 votes (75%)")
+
+	assert.Equal(t, domain.NotificationResultsReady, emails[instant.Email].Kind)
+	assert.False(t, emails[instant.Email].Digest)
+	assert.Equal(t, now, emails[instant.Email].SendAfter)
+
+	assert.True(t, emails[byDefault.Email].Digest)
+	assert.Equal(t, time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC), emails[byDefault.Email].SendAfter)
+}
+
+func TestNotificationHandleEvent_CreatorOnlyResultsAreNotSentToVoters(t *testing.T) {
+	now := time.Now()
+	service, m := newTestNotificationService(now)
+	ctx := context.Background()
+
+	creator := domain.User{ID: uuid.New(), Email: "ada@example.com"}
+
+	poll := &domain.Poll{
+		ID:                uuid.New(),
+		UserID:            creator.ID,
+		Title:             "Lunch",
+		ResultsVisibility: domain.ResultsVisibilityCreatorOnly,
+		Options:           []domain.Option{{Name: "Pizza", VoteCount: 1}},
+	}
+
+	event := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollClosed, PollID: poll.ID}
+
+	m.pollRepo.On("FindPollByID", ctx, poll.ID).Return(poll, nil)
+	m.voteRepo.On("FindVoterIDs", ctx, poll.ID).Return([]uuid.UUID{uuid.New()}, nil)
+	m.userRepo.On("FindByIDs", ctx, []uuid.UUID{creator.ID}).Return([]domain.User{creator}, nil)
+	m.repo.On("FindPreferences", ctx, []uuid.UUID{creator.ID}).Return([]domain.NotificationPreference{}, nil)
+
+	var saved []domain.EmailNotification
+	m.repo.On("SaveEmails", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]domain.EmailNotification)
+	}).Return(nil)
+	m.repo.On("SaveNotifications", ctx, mock.Anything).Return(nil)
+	m.publisher.On("Publish", mock.Anything).Return()
+
+	assert.NoError(t, service.HandleEvent(ctx, event))
+	assert.Len(t, saved, 1)
+	assert.Equal(t, creator.Email, saved[0].Email)
+}
+
+func TestNotificationHandleEvent_InviteWithoutAccount(t *testing.T) {
+	now := time.Now()
+	service, m := newTestNotificationService(now)
+	ctx := context.Background()
+
+	poll := &domain.Poll{ID: uuid.New(), UserID: uuid.New(), Title: "Lunch", ExpiresAt: now.Add(time.Hour)}
+
+	event, err := domain.NewOutboxEvent(domain.OutboxPollInvited, poll.ID, domain.PollInvitedPayload{InviteID: uuid.New(), Email: "guest@example.com"})
+	assert.NoError(t, err)
+
+	m.pollRepo.On("FindPollByID", ctx, poll.ID).Return(poll, nil)
+	m.userRepo.On("FindByIDs", ctx, []uuid.UUID(nil)).Return([]domain.User{}, nil)
+	m.repo.On("FindPreferences", ctx, []uuid.UUID(nil)).Return([]domain.NotificationPreference{}, nil)
+
+	var saved []domain.EmailNotification
+	m.repo.On("SaveEmails", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]domain.EmailNotification)
+	}).Return(nil)
+	m.repo.On("SaveNotifications", ctx, []domain.Notification(nil)).Return(nil)
+
+	assert.NoError(t, service.HandleEvent(ctx, event))
+	assert.Len(t, saved, 1)
+	assert.Equal(t, "guest@example.com", saved[0].Email)
+	assert.Nil(t, saved[0].UserID)
+	assert.Equal(t, event.ID, saved[0].EventID)
+	assert.Contains(t, saved[0].Body, "http://localhost:3000/polls/"+poll.ID.String())
+}
+
+func TestNotificationHandleEvent_DeletedPoll(t *testing.T) {
+	service, m := newTestNotificationService(time.Now())
+	ctx := context.Background()
+
+	event := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollClosed, PollID: uuid.New()}
+
+	m.pollRepo.On("FindPollByID", ctx, event.PollID).Return(nil, utils.PollNotFoundError)
+
+	assert.NoError(t, service.HandleEvent(ctx, event))
+	m.repo.AssertNotCalled(t, "SaveEmails", mock.Anything, mock.Anything)
+}
+
+func TestNotificationHandleEvent_ReturnsErrors(t *testing.T) {
+	service, m := newTestNotificationService(time.Now())
+	ctx := context.Background()
+
+	event := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollClosed, PollID: uuid.New()}
+
+	m.pollRepo.On("FindPollByID", ctx, event.PollID).Return(nil, errors.New("connection reset"))
+
+	assert.Error(t, service.HandleEvent(ctx, event))
+}
+
+func TestSendDigests_OneEmailPerRecipient(t *testing.T) {
+	now := time.Now()
+	service, m := newTestNotificationService(now)
+	ctx := context.Background()
+
+	firstPoll, secondPoll := uuid.New(), uuid.New()
+
+	claimed := []domain.EmailNotification{
+		{ID: uuid.New(), Email: "ada@example.com", PollID: secondPoll, Summary: "Second", CreatedAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), Email: "bob@example.com", PollID: firstPoll, Summary: "Bob's", CreatedAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), Email: "ada@example.com", PollID: firstPoll, Summary: "First", CreatedAt: now.Add(-2 * time.Hour)},
+	}
+
+	m.repo.On("ClaimDueDigests", ctx, now, notificationLease, notificationDigestBatch).Return(claimed, nil)
+
+	var sent []MailMessage
+	m.mailer.On("Send", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(MailMessage))
+	}).Return(nil)
+
+	var updated []domain.EmailNotification
+	m.repo.On("UpdateEmails", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		updated = args.Get(1).([]domain.EmailNotification)
+	}).Return(nil)
+
+	assert.NoError(t, service.SendDigests(ctx))
+
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "ada@example.com", sent[0].To)
+	assert.Equal(t, "Your Jille digest: 2 updates", sent[0].Subject)
+	assert.Less(t, strings.Index(sent[0].Body, "First"), strings.Index(sent[0].Body, "Second"))
+	assert.Equal(t, "Your Jille digest: 1 update", sent[1].Subject)
+
+	for _, email := range updated {
+		assert.Equal(t, domain.EmailNotificationSent, email.Status)
+		assert.Equal(t, 1, email.Attempts)
+	}
+}
+
+func TestSendDue_Retries(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		attempts     int
+		wantStatus   domain.EmailNotificationStatus
+		wantNextSend time.Time
+	}{
+		{"first failure", 0, domain.EmailNotificationPending, now.Add(time.Minute)},
+		{"later failure", 2, domain.EmailNotificationPending, now.Add(4 * time.Minute)},
+		{"last attempt", notificationMaxAttempts - 1, domain.EmailNotificationFailed, now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newTestNotificationService(now)
+			ctx := context.Background()
+
+			m.repo.On("ClaimDueEmails", ctx, now, notificationLease, notificationEmailBatch).Return([]domain.EmailNotification{
+				{ID: uuid.New(), Email: "ada@example.com", Status: domain.EmailNotificationPending, Attempts: tt.attempts, SendAfter: now},
+			}, nil)
+			m.mailer.On("Send", ctx, mock.Anything).Return(errors.New("mailbox unavailable"))
+
+			var updated []domain.EmailNotification
+			m.repo.On("UpdateEmails", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				updated = args.Get(1).([]domain.EmailNotification)
+			}).Return(nil)
+
+			assert.NoError(t, service.SendDue(ctx))
+			assert.Len(t, updated, 1)
+			assert.Equal(t, tt.wantStatus, updated[0].Status)
+			assert.Equal(t, tt.attempts+1, updated[0].Attempts)
+			assert.Equal(t, tt.wantNextSend, updated[0].SendAfter)
+			assert.Equal(t, "mailbox unavailable", updated[0].Error)
+		})
+	}
+}
+
+func TestNotificationHandleEvent_VoteNotifiesCreator(t *testing.T) {
+	now := time.Now()
+	service, m := newTestNotificationService(now)
+	ctx := context.Background()
+
+	creatorID := uuid.New()
+	voter := domain.User{ID: uuid.New(), Username: "bob"}
+	poll := &domain.Poll{ID: uuid.New(), UserID: creatorID, Title: "Lunch"}
+
+	event, err := domain.NewOutboxEvent(domain.OutboxVoteCast, poll.ID, domain.VoteCastPayload{OptionID: uuid.New(), UserID: voter.ID})
+	assert.NoError(t, err)
+
+	m.pollRepo.On("FindPollByID", ctx, poll.ID).Return(poll, nil)
+	m.userRepo.On("FindById", ctx, voter.ID).Return(voter, nil)
+	m.repo.On("SaveEmails", ctx, []domain.EmailNotification(nil)).Return(nil)
+
+	var saved []domain.Notification
+	m.repo.On("SaveNotifications", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]domain.Notification)
+	}).Return(nil)
+	m.publisher.On("Publish", mock.MatchedBy(func(event utils.Event) bool {
+		return event.UserID == creatorID.String() && event.Type == "NOTIFICATION"
+	})).Return()
+
+	assert.NoError(t, service.HandleEvent(ctx, event))
+	assert.Len(t, saved, 1)
+	assert.Equal(t, creatorID, saved[0].UserID)
+	assert.Equal(t, domain.NotificationVoteReceived, saved[0].Kind)
+	assert.Equal(t, `bob voted on your poll "Lunch"`, saved[0].Message)
+	m.publisher.AssertExpectations(t)
+
+	// the same event handled again lands on the same notification
+	first := saved[0].ID
+	assert.NoError(t, service.HandleEvent(ctx, event))
+	assert.Equal(t, first, saved[0].ID)
+}
+
+func TestNotificationHandleEvent_SkipsCreatorsOwnVote(t *testing.T) {
+	service, m := newTestNotificationService(time.Now())
+	ctx := context.Background()
+
+	poll := &domain.Poll{ID: uuid.New(), UserID: uuid.New(), Title: "Lunch"}
+
+	event, err := domain.NewOutboxEvent(domain.OutboxVoteCast, poll.ID, domain.VoteCastPayload{OptionID: uuid.New(), UserID: poll.UserID})
+	assert.NoError(t, err)
+
+	m.pollRepo.On("FindPollByID", ctx, poll.ID).Return(poll, nil)
+	m.repo.On("SaveEmails", ctx, []domain.EmailNotification(nil)).Return(nil)
+	m.repo.On("SaveNotifications", ctx, []domain.Notification(nil)).Return(nil)
+
+	assert.NoError(t, service.HandleEvent(ctx, event))
+	m.publisher.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestNextDigest(t *testing.T) {
+	assert.Equal(t, time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC), nextDigest(time.Date(2026, 3, 10, 7, 59, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC), nextDigest(time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)))
+}
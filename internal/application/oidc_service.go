@@ -0,0 +1,21 @@
+package application
+
+import (
+	"context"
+
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type OIDCService interface {
+	// StartLogin returns the provider's sign-in URL, and a key the browser
+	// starting the login keeps to finish it.
+	StartLogin(ctx context.Context, provider string) (string, string, error)
+
+	// HandleCallback finishes a login started by the browser holding
+	// browserKey.
+	HandleCallback(ctx context.Context, provider string, callbackRequest dto.OIDCCallbackRequest, browserKey string) (*dto.AuthResponse, error)
+
+	// PurgeExpiredLoginStates deletes logins that were started but never
+	// finished.
+	PurgeExpiredLoginStates(ctx context.Context) error
+}
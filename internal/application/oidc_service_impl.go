@@ -0,0 +1,270 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const oidcLoginStateTTL = time.Minute * 10
+
+type oidcservice struct {
+	identityrepo repository.IdentityRepository
+
+	userservice UserService
+
+	authservice AuthService
+
+	providers map[string]IdentityProvider
+}
+
+func NewOIDCService(identityrepo repository.IdentityRepository, userservice UserService, authservice AuthService, providers ...IdentityProvider) OIDCService {
+
+	byName := make(map[string]IdentityProvider, len(providers))
+
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &oidcservice{
+		identityrepo: identityrepo,
+		userservice:  userservice,
+		authservice:  authservice,
+		providers:    byName,
+	}
+}
+
+func (s *oidcservice) StartLogin(ctx context.Context, providerName string) (string, string, error) {
+
+	provider, ok := s.providers[providerName]
+
+	if !ok {
+		return "", "", utils.IdentityProviderNotFoundError
+	}
+
+	state, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	codeVerifier, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	// the state alone would let anyone who started a login hand its
+	// callback to someone else and sign them in as themselves
+	browserKey, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	loginState := domain.OIDCLoginState{
+		Provider:     providerName,
+		StateHash:    utils.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		BrowserHash:  utils.HashToken(browserKey),
+		ExpiresAt:    time.Now().Add(oidcLoginStateTTL),
+	}
+
+	if err := s.identityrepo.SaveLoginState(ctx, &loginState); err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeChallenge(codeVerifier))
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, browserKey, nil
+}
+
+func (s *oidcservice) HandleCallback(ctx context.Context, providerName string, callbackRequest dto.OIDCCallbackRequest, browserKey string) (*dto.AuthResponse, error) {
+
+	provider, ok := s.providers[providerName]
+
+	if !ok {
+		return nil, utils.IdentityProviderNotFoundError
+	}
+
+	loginState, err := s.identityrepo.ConsumeLoginState(ctx, providerName, utils.HashToken(callbackRequest.State))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if browserKey == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(browserKey)), []byte(loginState.BrowserHash)) != 1 {
+		return nil, utils.InvalidLoginStateError
+	}
+
+	if loginState.ExpiresAt.Before(time.Now()) {
+		return nil, utils.InvalidLoginStateError
+	}
+
+	identity, err := provider.Exchange(ctx, callbackRequest.Code, loginState.CodeVerifier)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if identity.Subject == "" || identity.Nonce != loginState.Nonce {
+		return nil, utils.IdentityNotVerifiedError
+	}
+
+	userID, err := s.resolveUser(ctx, providerName, identity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userservice.RecordProviderSignIn(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// the provider stands in for the password, not for the second factor
+	return s.authservice.CompleteLogin(ctx, userID)
+}
+
+func (s *oidcservice) PurgeExpiredLoginStates(ctx context.Context) error {
+	return s.identityrepo.DeleteExpiredLoginStates(ctx, time.Now())
+}
+
+// resolveUser finds the user linked to the external identity. Unlinked
+// identities are attached to the account with the same email when both the
+// provider and the account have verified that email, otherwise a new
+// account is created.
+func (s *oidcservice) resolveUser(ctx context.Context, providerName string, identity *ExternalIdentity) (uuid.UUID, error) {
+
+	linked, err := s.identityrepo.FindByProviderAndSubject(ctx, providerName, identity.Subject)
+
+	if err == nil {
+		return linked.UserID, nil
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		return uuid.Nil, err
+	}
+
+	if identity.Email == "" {
+		return uuid.Nil, utils.IdentityEmailRequiredError
+	}
+
+	var userID uuid.UUID
+
+	existingUser, err := s.userservice.GetUserByEmail(ctx, identity.Email)
+
+	switch {
+	case err == nil:
+		// linking on an unverified email would let anyone who controls an
+		// identity provider account take over a local account, and linking
+		// to a local account whose email was never verified would share
+		// the account with whoever registered that address first
+		if !identity.EmailVerified || !existingUser.EmailVerified {
+			return uuid.Nil, utils.UserExistsError
+		}
+
+		userID = existingUser.ID
+
+	case err == gorm.ErrRecordNotFound:
+		user, err := s.createUser(ctx, identity)
+
+		if err != nil {
+			return uuid.Nil, err
+		}
+
+		userID = user.ID
+
+	default:
+		return uuid.Nil, err
+	}
+
+	err = s.identityrepo.Save(ctx, &domain.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+func (s *oidcservice) createUser(ctx context.Context, identity *ExternalIdentity) (*domain.User, error) {
+
+	// accounts created through a provider get a random password so they can
+	// only sign in with it until the user resets their password, and are
+	// marked passwordless until then
+	randomPassword, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), passwordHashCost)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := domain.User{
+		Username:      usernameFor(identity),
+		Email:         identity.Email,
+		Password:      string(hashedPassword),
+		EmailVerified: identity.EmailVerified,
+		Passwordless:  true,
+		JoinedAt:      time.Now(),
+	}
+
+	if err := s.userservice.CreateUser(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func usernameFor(identity *ExternalIdentity) string {
+
+	if identity.PreferredUsername != "" {
+		return identity.PreferredUsername
+	}
+
+	if identity.Name != "" {
+		return identity.Name
+	}
+
+	username, _, _ := strings.Cut(identity.Email, "@")
+
+	return username
+}
+
+func codeChallenge(codeVerifier string) string {
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,308 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+// MockIdentityProvider
+type MockIdentityProvider struct {
+	mock.Mock
+}
+
+func (m *MockIdentityProvider) Name() string {
+	return "test"
+}
+
+func (m *MockIdentityProvider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	args := m.Called(ctx, state, nonce, codeChallenge)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockIdentityProvider) Exchange(ctx context.Context, code string, codeVerifier string) (*ExternalIdentity, error) {
+	args := m.Called(ctx, code, codeVerifier)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ExternalIdentity), args.Error(1)
+}
+
+// MockAuthService
+type MockAuthService struct {
+	AuthService
+	mock.Mock
+}
+
+func (m *MockAuthService) CompleteLogin(ctx context.Context, userID uuid.UUID) (*dto.AuthResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AuthResponse), args.Error(1)
+}
+
+func (m *MockAuthService) IssueTokens(ctx context.Context, userID uuid.UUID, message string) (*dto.AuthResponse, error) {
+	args := m.Called(ctx, userID, message)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AuthResponse), args.Error(1)
+}
+
+func TestStartLogin_StoresStateAndUsesPKCE(t *testing.T) {
+	mockRepo := new(mocks.IdentityRepository)
+	mockProvider := new(MockIdentityProvider)
+	service := NewOIDCService(mockRepo, new(MockUserService), new(MockAuthService), mockProvider)
+
+	ctx := context.Background()
+
+	var saved *domain.OIDCLoginState
+
+	mockRepo.On("SaveLoginState", ctx, mock.AnythingOfType("*domain.OIDCLoginState")).Return(nil).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*domain.OIDCLoginState)
+	})
+	mockProvider.On("AuthCodeURL", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return("", nil).
+		Run(func(args mock.Arguments) {
+			assert.Equal(t, saved.StateHash, utils.HashToken(args.String(1)))
+			assert.Equal(t, saved.Nonce, args.String(2))
+			assert.Equal(t, codeChallenge(saved.CodeVerifier), args.String(3))
+		})
+
+	_, browserKey, err := service.StartLogin(ctx, "test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test", saved.Provider)
+	assert.Equal(t, utils.HashToken(browserKey), saved.BrowserHash)
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestStartLogin_UnknownProvider(t *testing.T) {
+	service := NewOIDCService(new(mocks.IdentityRepository), new(MockUserService), new(MockAuthService))
+
+	_, _, err := service.StartLogin(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, utils.IdentityProviderNotFoundError)
+}
+
+func TestHandleCallback_LinkedIdentity(t *testing.T) {
+	mockRepo := new(mocks.IdentityRepository)
+	mockProvider := new(MockIdentityProvider)
+	mockUserService := new(MockUserService)
+	mockAuthService := new(MockAuthService)
+	service := NewOIDCService(mockRepo, mockUserService, mockAuthService, mockProvider)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	state := &domain.OIDCLoginState{
+		Provider:     "test",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		BrowserHash:  utils.HashToken("browser"),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	mockRepo.On("ConsumeLoginState", ctx, "test", utils.HashToken("state")).Return(state, nil)
+	mockProvider.On("Exchange", ctx, "code", "verifier").Return(&ExternalIdentity{Subject: "sub", Nonce: "nonce"}, nil)
+	mockRepo.On("FindByProviderAndSubject", ctx, "test", "sub").Return(&domain.UserIdentity{UserID: userID}, nil)
+	mockUserService.On("RecordProviderSignIn", ctx, userID).Return(nil)
+	mockAuthService.On("CompleteLogin", ctx, userID).Return(&dto.AuthResponse{Message: "Login successful"}, nil)
+
+	resp, err := service.HandleCallback(ctx, "test", dto.OIDCCallbackRequest{Code: "code", State: "state"}, "browser")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Login successful", resp.Message)
+	mockUserService.AssertExpectations(t)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestHandleCallback_AsksForSecondFactor(t *testing.T) {
+	mockRepo := new(mocks.IdentityRepository)
+	mockProvider := new(MockIdentityProvider)
+	mockAuthRepo := new(mocks.AuthRepository)
+	mockJwtService := new(MockJwtService)
+	mockTwoFactorService := new(MockTwoFactorService)
+	mockUserService := new(MockUserService)
+	authService := NewAuthService(mockAuthRepo, new(mocks.UserTokenRepository), mockUserService, mockJwtService, mockTwoFactorService, new(MockLoginGuard), new(MockMailer), "http://localhost:3000")
+	service := NewOIDCService(mockRepo, mockUserService, authService, mockProvider)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	state := &domain.OIDCLoginState{
+		Provider:     "test",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		BrowserHash:  utils.HashToken("browser"),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	mockRepo.On("ConsumeLoginState", ctx, "test", utils.HashToken("state")).Return(state, nil)
+	mockProvider.On("Exchange", ctx, "code", "verifier").Return(&ExternalIdentity{Subject: "sub", Nonce: "nonce"}, nil)
+	mockRepo.On("FindByProviderAndSubject", ctx, "test", "sub").Return(&domain.UserIdentity{UserID: userID}, nil)
+	mockUserService.On("RecordProviderSignIn", ctx, userID).Return(nil)
+	mockTwoFactorService.On("IsEnabled", ctx, userID).Return(true, nil)
+	mockJwtService.On("GenerateMFAToken", userID.String()).Return("mfa_token", nil)
+
+	resp, err := service.HandleCallback(ctx, "test", dto.OIDCCallbackRequest{Code: "code", State: "state"}, "browser")
+
+	assert.NoError(t, err)
+	assert.True(t, resp.MFARequired)
+	assert.Equal(t, "mfa_token", resp.AuthTokens.MFAToken)
+	assert.Empty(t, resp.AuthTokens.AccessToken)
+	mockAuthRepo.AssertNotCalled(t, "SaveToken", mock.Anything, mock.Anything)
+}
+
+func TestHandleCallback_RefusesAnotherBrowser(t *testing.T) {
+	mockRepo := new(mocks.IdentityRepository)
+	mockProvider := new(MockIdentityProvider)
+	mockAuthService := new(MockAuthService)
+	service := NewOIDCService(mockRepo, new(MockUserService), mockAuthService, mockProvider)
+
+	ctx := context.Background()
+	state := &domain.OIDCLoginState{
+		Provider:     "test",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		BrowserHash:  utils.HashToken("browser"),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	mockRepo.On("ConsumeLoginState", ctx, "test", utils.HashToken("state")).Return(state, nil)
+
+	for _, browserKey := range []string{"", "someone else"} {
+		_, err := service.HandleCallback(ctx, "test", dto.OIDCCallbackRequest{Code: "code", State: "state"}, browserKey)
+
+		assert.ErrorIs(t, err, utils.InvalidLoginStateError)
+	}
+	mockProvider.AssertNotCalled(t, "Exchange", mock.Anything, mock.Anything, mock.Anything)
+	mockAuthService.AssertNotCalled(t, "CompleteLogin", mock.Anything, mock.Anything)
+}
+
+func TestHandleCallback_NonceMismatch(t *testing.T) {
+	mockRepo := new(mocks.IdentityRepository)
+	mockProvider := new(MockIdentityProvider)
+	mockAuthService := new(MockAuthService)
+	service := NewOIDCService(mockRepo, new(MockUserService), mockAuthService, mockProvider)
+
+	ctx := context.Background()
+	state := &domain.OIDCLoginState{
+		Provider:     "test",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		BrowserHash:  utils.HashToken("browser"),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	mockRepo.On("ConsumeLoginState", ctx, "test", utils.HashToken("state")).Return(state, nil)
+	mockProvider.On("Exchange", ctx, "code", "verifier").Return(&ExternalIdentity{Subject: "sub", Nonce: "replayed"}, nil)
+
+	_, err := service.HandleCallback(ctx, "test", dto.OIDCCallbackRequest{Code: "code", State: "state"}, "browser")
+
+	assert.ErrorIs(t, err, utils.IdentityNotVerifiedError)
+	mockAuthService.AssertNotCalled(t, "CompleteLogin", mock.Anything, mock.Anything)
+}
+
+func TestHandleCallback_CreatesUser(t *testing.T) {
+	mockRepo := new(mocks.IdentityRepository)
+	mockProvider := new(MockIdentityProvider)
+	mockUserService := new(MockUserService)
+	mockAuthService := new(MockAuthService)
+	service := NewOIDCService(mockRepo, mockUserService, mockAuthService, mockProvider)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	state := &domain.OIDCLoginState{
+		Provider:     "test",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		BrowserHash:  utils.HashToken("browser"),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+	identity := &ExternalIdentity{
+		Subject:       "sub",
+		Nonce:         "nonce",
+		Email:         "new@example.com",
+		EmailVerified: true,
+	}
+
+	mockRepo.On("ConsumeLoginState", ctx, "test", utils.HashToken("state")).Return(state, nil)
+	mockProvider.On("Exchange", ctx, "code", "verifier").Return(identity, nil)
+	mockRepo.On("FindByProviderAndSubject", ctx, "test", "sub").Return(nil, gorm.ErrRecordNotFound)
+	mockUserService.On("GetUserByEmail", ctx, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockUserService.On("CreateUser", ctx, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Email == "new@example.com" && u.Username == "new" && u.EmailVerified && u.Passwordless
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.User).ID = userID
+	})
+	mockRepo.On("Save", ctx, mock.MatchedBy(func(i *domain.UserIdentity) bool {
+		return i.UserID == userID && i.Provider == "test" && i.Subject == "sub"
+	})).Return(nil)
+	mockUserService.On("RecordProviderSignIn", ctx, userID).Return(nil)
+	mockAuthService.On("CompleteLogin", ctx, userID).Return(&dto.AuthResponse{}, nil)
+
+	_, err := service.HandleCallback(ctx, "test", dto.OIDCCallbackRequest{Code: "code", State: "state"}, "browser")
+
+	assert.NoError(t, err)
+	mockUserService.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestHandleCallback_RefusesToLinkUnverifiedEmail(t *testing.T) {
+	tests := []struct {
+		name             string
+		providerVerified bool
+		accountVerified  bool
+	}{
+		{name: "not verified by the provider", providerVerified: false, accountVerified: true},
+		// whoever registered the address first would keep their password
+		{name: "not verified by the account", providerVerified: true, accountVerified: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.IdentityRepository)
+			mockProvider := new(MockIdentityProvider)
+			mockUserService := new(MockUserService)
+			mockAuthService := new(MockAuthService)
+			service := NewOIDCService(mockRepo, mockUserService, mockAuthService, mockProvider)
+
+			ctx := context.Background()
+			state := &domain.OIDCLoginState{
+				Provider:     "test",
+				Nonce:        "nonce",
+				CodeVerifier: "verifier",
+				BrowserHash:  utils.HashToken("browser"),
+				ExpiresAt:    time.Now().Add(time.Minute),
+			}
+			identity := &ExternalIdentity{
+				Subject:       "sub",
+				Nonce:         "nonce",
+				Email:         "taken@example.com",
+				EmailVerified: tt.providerVerified,
+			}
+
+			mockRepo.On("ConsumeLoginState", ctx, "test", utils.HashToken("state")).Return(state, nil)
+			mockProvider.On("Exchange", ctx, "code", "verifier").Return(identity, nil)
+			mockRepo.On("FindByProviderAndSubject", ctx, "test", "sub").Return(nil, gorm.ErrRecordNotFound)
+			mockUserService.On("GetUserByEmail", ctx, "taken@example.com").Return(&dto.UserAuthView{ID: uuid.New(), Email: "taken@example.com", EmailVerified: tt.accountVerified}, nil)
+
+			_, err := service.HandleCallback(ctx, "test", dto.OIDCCallbackRequest{Code: "code", State: "state"}, "browser")
+
+			assert.ErrorIs(t, err, utils.UserExistsError)
+			mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+			mockUserService.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything)
+		})
+	}
+}
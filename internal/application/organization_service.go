@@ -0,0 +1,24 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type OrganizationService interface {
+	// CreateOrganization makes the signed in user the organization's owner.
+	CreateOrganization(ctx context.Context, createRequest dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error)
+
+	GetOrganizations(ctx context.Context) ([]dto.OrganizationResponse, error)
+
+	GetOrganization(ctx context.Context, organizationID uuid.UUID) (*dto.OrganizationResponse, error)
+
+	AddMember(ctx context.Context, organizationID uuid.UUID, addRequest dto.AddMemberRequest) (*dto.OrganizationResponse, error)
+
+	UpdateMemberRole(ctx context.Context, organizationID uuid.UUID, memberID uuid.UUID, updateRequest dto.UpdateMemberRoleRequest) (*dto.OrganizationResponse, error)
+
+	// RemoveMember lets owners remove anyone and every member leave.
+	RemoveMember(ctx context.Context, organizationID uuid.UUID, memberID uuid.UUID) error
+}
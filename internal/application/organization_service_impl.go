@@ -0,0 +1,245 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type organizationservice struct {
+	repo        repository.OrganizationRepository
+	userservice UserService
+}
+
+func NewOrganizationService(repo repository.OrganizationRepository, userservice UserService) OrganizationService {
+	return &organizationservice{
+		repo:        repo,
+		userservice: userservice,
+	}
+}
+
+func (s *organizationservice) CreateOrganization(ctx context.Context, createRequest dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	organization := domain.Organization{
+		Name: createRequest.Name,
+	}
+
+	if err := s.repo.Create(ctx, &organization, userID); err != nil {
+		return nil, err
+	}
+
+	return &dto.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		CreatedAt: organization.CreatedAt,
+		Role:      string(domain.OrganizationRoleOwner),
+	}, nil
+}
+
+func (s *organizationservice) GetOrganizations(ctx context.Context) ([]dto.OrganizationResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	organizations, err := s.repo.FindByUserID(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.OrganizationResponse, 0, len(organizations))
+
+	for _, organization := range organizations {
+
+		// only the caller's own membership is loaded
+		var role string
+
+		if len(organization.Members) > 0 {
+			role = string(organization.Members[0].Role)
+		}
+
+		response = append(response, dto.OrganizationResponse{
+			ID:        organization.ID,
+			Name:      organization.Name,
+			CreatedAt: organization.CreatedAt,
+			Role:      role,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *organizationservice) GetOrganization(ctx context.Context, organizationID uuid.UUID) (*dto.OrganizationResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if _, err := s.requireRole(ctx, organizationID, userID, domain.OrganizationRoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.organizationResponse(ctx, organizationID, userID)
+}
+
+func (s *organizationservice) AddMember(ctx context.Context, organizationID uuid.UUID, addRequest dto.AddMemberRequest) (*dto.OrganizationResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if _, err := s.requireRole(ctx, organizationID, userID, domain.OrganizationRoleOwner); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userservice.GetUserByEmail(ctx, addRequest.Email)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.UserNotFoundError
+		}
+		return nil, err
+	}
+
+	err = s.repo.AddMember(ctx, &domain.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         user.ID,
+		Role:           domain.OrganizationRole(addRequest.Role),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.organizationResponse(ctx, organizationID, userID)
+}
+
+func (s *organizationservice) UpdateMemberRole(ctx context.Context, organizationID uuid.UUID, memberID uuid.UUID, updateRequest dto.UpdateMemberRoleRequest) (*dto.OrganizationResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if _, err := s.requireRole(ctx, organizationID, userID, domain.OrganizationRoleOwner); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.FindMember(ctx, organizationID, memberID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	role := domain.OrganizationRole(updateRequest.Role)
+
+	if member.Role == domain.OrganizationRoleOwner && role != domain.OrganizationRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, organizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateMemberRole(ctx, organizationID, memberID, role); err != nil {
+		return nil, err
+	}
+
+	return s.organizationResponse(ctx, organizationID, userID)
+}
+
+func (s *organizationservice) RemoveMember(ctx context.Context, organizationID uuid.UUID, memberID uuid.UUID) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	required := domain.OrganizationRoleOwner
+
+	if memberID == userID {
+		required = domain.OrganizationRoleViewer
+	}
+
+	if _, err := s.requireRole(ctx, organizationID, userID, required); err != nil {
+		return err
+	}
+
+	member, err := s.repo.FindMember(ctx, organizationID, memberID)
+
+	if err != nil {
+		return err
+	}
+
+	if member.Role == domain.OrganizationRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, organizationID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RemoveMember(ctx, organizationID, memberID)
+}
+
+// requireRole returns the user's membership if their role allows required.
+// Non-members get OrganizationNotFoundError so they cannot probe for
+// organizations they are not part of.
+func (s *organizationservice) requireRole(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID, required domain.OrganizationRole) (*domain.OrganizationMember, error) {
+
+	member, err := s.repo.FindMember(ctx, organizationID, userID)
+
+	if err != nil {
+		if errors.Is(err, utils.MemberNotFoundError) {
+			return nil, utils.OrganizationNotFoundError
+		}
+		return nil, err
+	}
+
+	if !member.Role.Allows(required) {
+		return nil, utils.OrganizationPermissionDeniedError
+	}
+
+	return member, nil
+}
+
+func (s *organizationservice) ensureAnotherOwner(ctx context.Context, organizationID uuid.UUID) error {
+
+	owners, err := s.repo.CountOwners(ctx, organizationID)
+
+	if err != nil {
+		return err
+	}
+
+	if owners <= 1 {
+		return utils.LastOwnerError
+	}
+
+	return nil
+}
+
+func (s *organizationservice) organizationResponse(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) (*dto.OrganizationResponse, error) {
+
+	organization, err := s.repo.FindByID(ctx, organizationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		CreatedAt: organization.CreatedAt,
+		Members:   make([]dto.OrganizationMemberResponse, 0, len(organization.Members)),
+	}
+
+	for _, member := range organization.Members {
+
+		if member.UserID == userID {
+			response.Role = string(member.Role)
+		}
+
+		response.Members = append(response.Members, dto.OrganizationMemberResponse{
+			UserID:   member.UserID,
+			Username: member.User.Username,
+			Email:    member.User.Email,
+			Role:     string(member.Role),
+			JoinedAt: member.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
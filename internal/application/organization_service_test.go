@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestCreateOrganization_CreatorBecomesOwner(t *testing.T) {
+	mockRepo := new(mocks.OrganizationRepository)
+	service := NewOrganizationService(mockRepo, new(MockUserService))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Organization"), userID).Return(nil)
+
+	resp, err := service.CreateOrganization(ctx, dto.CreateOrganizationRequest{Name: "Jille"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "owner", resp.Role)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddMember_RequiresOwner(t *testing.T) {
+	mockRepo := new(mocks.OrganizationRepository)
+	mockUserService := new(MockUserService)
+	service := NewOrganizationService(mockRepo, mockUserService)
+
+	userID := uuid.New()
+	organizationID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{Role: domain.OrganizationRoleEditor}, nil)
+
+	_, err := service.AddMember(ctx, organizationID, dto.AddMemberRequest{Email: "new@example.com", Role: "viewer"})
+
+	assert.ErrorIs(t, err, utils.OrganizationPermissionDeniedError)
+	mockUserService.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func TestAddMember_Success(t *testing.T) {
+	mockRepo := new(mocks.OrganizationRepository)
+	mockUserService := new(MockUserService)
+	service := NewOrganizationService(mockRepo, mockUserService)
+
+	userID := uuid.New()
+	newMemberID := uuid.New()
+	organizationID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{Role: domain.OrganizationRoleOwner}, nil)
+	mockUserService.On("GetUserByEmail", ctx, "new@example.com").Return(&dto.UserAuthView{ID: newMemberID}, nil)
+	mockRepo.On("AddMember", ctx, mock.MatchedBy(func(member *domain.OrganizationMember) bool {
+		return member.UserID == newMemberID && member.Role == domain.OrganizationRoleViewer
+	})).Return(nil)
+	mockRepo.On("FindByID", ctx, organizationID).Return(&domain.Organization{
+		ID: organizationID,
+		Members: []domain.OrganizationMember{
+			{UserID: userID, Role: domain.OrganizationRoleOwner},
+			{UserID: newMemberID, Role: domain.OrganizationRoleViewer},
+		},
+	}, nil)
+
+	resp, err := service.AddMember(ctx, organizationID, dto.AddMemberRequest{Email: "new@example.com", Role: "viewer"})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Members, 2)
+	assert.Equal(t, "owner", resp.Role)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateMemberRole_LastOwnerCannotStepDown(t *testing.T) {
+	mockRepo := new(mocks.OrganizationRepository)
+	service := NewOrganizationService(mockRepo, new(MockUserService))
+
+	userID := uuid.New()
+	organizationID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{UserID: userID, Role: domain.OrganizationRoleOwner}, nil)
+	mockRepo.On("CountOwners", ctx, organizationID).Return(int64(1), nil)
+
+	_, err := service.UpdateMemberRole(ctx, organizationID, userID, dto.UpdateMemberRoleRequest{Role: "editor"})
+
+	assert.ErrorIs(t, err, utils.LastOwnerError)
+	mockRepo.AssertNotCalled(t, "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRemoveMember_ViewerCanLeave(t *testing.T) {
+	mockRepo := new(mocks.OrganizationRepository)
+	service := NewOrganizationService(mockRepo, new(MockUserService))
+
+	userID := uuid.New()
+	organizationID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{UserID: userID, Role: domain.OrganizationRoleViewer}, nil)
+	mockRepo.On("RemoveMember", ctx, organizationID, userID).Return(nil)
+
+	err := service.RemoveMember(ctx, organizationID, userID)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrganization_HiddenFromOutsiders(t *testing.T) {
+	mockRepo := new(mocks.OrganizationRepository)
+	service := NewOrganizationService(mockRepo, new(MockUserService))
+
+	userID := uuid.New()
+	organizationID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindMember", ctx, organizationID, userID).Return(nil, utils.MemberNotFoundError)
+
+	_, err := service.GetOrganization(ctx, organizationID)
+
+	assert.ErrorIs(t, err, utils.OrganizationNotFoundError)
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"context"
+
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// OutboxHandler is a consumer of outbox events. Handling an event twice must
+// have the same effect as handling it once, since the relay hands it over
+// again if it stops before the event is marked processed.
+type OutboxHandler interface {
+	HandleEvent(ctx context.Context, event domain.OutboxEvent) error
+}
+
+// OutboxRelay hands committed outbox events to their consumers.
+type OutboxRelay interface {
+	// Relay hands each consumer the events it has not processed yet, in
+	// the order they happened. An event a consumer fails is handed to it
+	// again on a later run, until it has failed too often; the consumer and
+	// the others carry on with the events after it meanwhile.
+	Relay(ctx context.Context) error
+
+	// Purge drops events every consumer has processed once they are no
+	// longer needed.
+	Purge(ctx context.Context) error
+}
+
+// EventPublisher pushes events to the streams open in the app.
+type EventPublisher interface {
+	Publish(event utils.Event)
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+const (
+	outboxLease     = time.Minute * 2
+	outboxBatch     = 100
+	outboxRetention = time.Hour * 24 * 7
+
+	// an event a consumer fails is handed to it again once the lease runs
+	// out, and given up after outboxMaxAttempts
+	outboxMaxAttempts = 10
+)
+
+type outboxrelay struct {
+	repo     repository.OutboxRepository
+	handlers map[domain.OutboxConsumer]OutboxHandler
+
+	now func() time.Time
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, handlers map[domain.OutboxConsumer]OutboxHandler) OutboxRelay {
+	return &outboxrelay{
+		repo:     repo,
+		handlers: handlers,
+		now:      time.Now,
+	}
+}
+
+func (r *outboxrelay) Relay(ctx context.Context) error {
+
+	var errs []error
+
+	for _, consumer := range domain.OutboxConsumers {
+
+		handler, ok := r.handlers[consumer]
+
+		if !ok {
+			continue
+		}
+
+		if err := r.relay(ctx, consumer, handler); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", consumer, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *outboxrelay) relay(ctx context.Context, consumer domain.OutboxConsumer, handler OutboxHandler) error {
+
+	var errs []error
+
+	for {
+		events, err := r.repo.ClaimUnprocessed(ctx, consumer, r.now(), outboxLease, outboxBatch)
+
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		for _, event := range events {
+
+			// a failed event stays locked until its lease runs out while
+			// the consumer carries on with the events after it
+			if err := handler.HandleEvent(ctx, event); err != nil {
+
+				errs = append(errs, fmt.Errorf("event %s: %w", event.ID, err))
+
+				if err := r.repo.RecordFailure(ctx, consumer, event.ID, err.Error(), r.now(), outboxMaxAttempts); err != nil {
+					return errors.Join(append(errs, err)...)
+				}
+
+				continue
+			}
+
+			if err := r.repo.MarkProcessed(ctx, consumer, event.ID, r.now()); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+		}
+
+		if len(events) < outboxBatch {
+			return errors.Join(errs...)
+		}
+	}
+}
+
+func (r *outboxrelay) Purge(ctx context.Context) error {
+	return r.repo.DeleteProcessedBefore(ctx, r.now().Add(-outboxRetention))
+}
+
+type brokeroutboxhandler struct {
+	publisher EventPublisher
+}
+
+// NewBrokerOutboxHandler returns the consumer that streams votes and poll
+// changes to the poll streams open in the app. Events carry the outbox
+// event's ID so clients can drop one they were sent twice.
+func NewBrokerOutboxHandler(publisher EventPublisher) OutboxHandler {
+	return &brokeroutboxhandler{
+		publisher: publisher,
+	}
+}
+
+func (h *brokeroutboxhandler) HandleEvent(ctx context.Context, event domain.OutboxEvent) error {
+
+	streamEvent := utils.Event{
+		ID:     event.ID.String(),
+		PollID: event.PollID.String(),
+	}
+
+	switch event.Type {
+
+	case domain.OutboxVoteCast:
+
+		var vote domain.VoteCastPayload
+
+		if err := event.DecodePayload(&vote); err != nil {
+			return err
+		}
+
+		streamEvent.Type = "POLL_VOTE"
+		streamEvent.Payload = dto.VoteRequest{PollID: event.PollID.String(), OptionID: vote.OptionID.String()}
+
+	case domain.OutboxPollClosed:
+
+		streamEvent.Type = "POLL_CLOSED"
+		streamEvent.Payload = dto.PollStreamEvent{PollID: event.PollID}
+
+	case domain.OutboxPollDeleted:
+
+		streamEvent.Type = "POLL_DELETED"
+		streamEvent.Payload = dto.PollStreamEvent{PollID: event.PollID}
+
+	default:
+		return nil
+	}
+
+	h.publisher.Publish(streamEvent)
+
+	return nil
+}
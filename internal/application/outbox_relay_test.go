@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type MockOutboxHandler struct {
+	mock.Mock
+}
+
+func (m *MockOutboxHandler) HandleEvent(ctx context.Context, event domain.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func TestRelay_FailingConsumerDoesNotBlockOthers(t *testing.T) {
+	now := time.Now()
+	ctx := context.Background()
+
+	repo := new(mocks.OutboxRepository)
+	broker := new(MockOutboxHandler)
+	webhooks := new(MockOutboxHandler)
+
+	relay := NewOutboxRelay(repo, map[domain.OutboxConsumer]OutboxHandler{
+		domain.OutboxConsumerBroker:   broker,
+		domain.OutboxConsumerWebhooks: webhooks,
+	}).(*outboxrelay)
+	relay.now = func() time.Time { return now }
+
+	first := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollCreated, PollID: uuid.New()}
+	second := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollClosed, PollID: first.PollID}
+
+	repo.On("ClaimUnprocessed", ctx, domain.OutboxConsumerBroker, now, outboxLease, outboxBatch).Return([]domain.OutboxEvent{first, second}, nil)
+	repo.On("ClaimUnprocessed", ctx, domain.OutboxConsumerWebhooks, now, outboxLease, outboxBatch).Return([]domain.OutboxEvent{first, second}, nil)
+
+	broker.On("HandleEvent", ctx, mock.Anything).Return(nil)
+	webhooks.On("HandleEvent", ctx, first).Return(errors.New("connection reset"))
+	webhooks.On("HandleEvent", ctx, second).Return(nil)
+
+	repo.On("MarkProcessed", ctx, mock.Anything, mock.Anything, now).Return(nil)
+	repo.On("RecordFailure", ctx, domain.OutboxConsumerWebhooks, first.ID, "connection reset", now, outboxMaxAttempts).Return(nil)
+
+	err := relay.Relay(ctx)
+
+	assert.ErrorContains(t, err, "webhooks: event "+first.ID.String()+": connection reset")
+	repo.AssertCalled(t, "MarkProcessed", ctx, domain.OutboxConsumerBroker, first.ID, now)
+	repo.AssertCalled(t, "MarkProcessed", ctx, domain.OutboxConsumerBroker, second.ID, now)
+	// the failed event is counted and left for a later run, while the
+	// events after it go through
+	repo.AssertCalled(t, "RecordFailure", ctx, domain.OutboxConsumerWebhooks, first.ID, "connection reset", now, outboxMaxAttempts)
+	repo.AssertNotCalled(t, "MarkProcessed", ctx, domain.OutboxConsumerWebhooks, first.ID, now)
+	repo.AssertCalled(t, "MarkProcessed", ctx, domain.OutboxConsumerWebhooks, second.ID, now)
+	// consumers without a handler are left alone
+	repo.AssertNotCalled(t, "ClaimUnprocessed", ctx, domain.OutboxConsumerNotifications, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBrokerOutboxHandler_StreamsVotes(t *testing.T) {
+	publisher := new(MockEventPublisher)
+	handler := NewBrokerOutboxHandler(publisher)
+
+	pollID, optionID := uuid.New(), uuid.New()
+
+	event, err := domain.NewOutboxEvent(domain.OutboxVoteCast, pollID, domain.VoteCastPayload{OptionID: optionID, UserID: uuid.New()})
+	assert.NoError(t, err)
+
+	publisher.On("Publish", utils.Event{
+		ID:      event.ID.String(),
+		Type:    "POLL_VOTE",
+		PollID:  pollID.String(),
+		Payload: dto.VoteRequest{PollID: pollID.String(), OptionID: optionID.String()},
+	}).Return()
+
+	assert.NoError(t, handler.HandleEvent(context.Background(), event))
+
+	// invites are nothing the poll's viewers need to see
+	invited := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollInvited, PollID: pollID}
+	assert.NoError(t, handler.HandleEvent(context.Background(), invited))
+
+	publisher.AssertExpectations(t)
+	publisher.AssertNumberOfCalls(t, "Publish", 1)
+}
@@ -0,0 +1,126 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+const (
+	pollCacheTTL  = time.Second * 5
+	pollCacheSize = 10000
+
+	// pollCacheLoadTimeout bounds a lookup, which no single caller can
+	// cancel since others may be waiting on it
+	pollCacheLoadTimeout = time.Second * 5
+)
+
+// pollCache keeps polls for a few seconds so a crowd voting in the same poll
+// is validated against a single lookup. The polls it hands out are shared
+// and must not be changed. A poll that closes or is deleted in the meantime
+// still turns its votes away when they are written.
+type pollCache struct {
+	repo repository.PollRepository
+	ttl  time.Duration
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]*cachedPoll
+}
+
+type cachedPoll struct {
+	// ready is closed once the poll has been looked up
+	ready    chan struct{}
+	poll     *domain.Poll
+	err      error
+	loadedAt time.Time
+}
+
+func newPollCache(repo repository.PollRepository, ttl time.Duration) *pollCache {
+	return &pollCache{
+		repo:    repo,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[uuid.UUID]*cachedPoll),
+	}
+}
+
+// Get returns the poll, looking it up once for everyone asking for it at
+// the same time when the cached copy is missing or too old.
+func (c *pollCache) Get(ctx context.Context, pollID uuid.UUID) (*domain.Poll, error) {
+
+	c.mu.Lock()
+
+	entry, ok := c.entries[pollID]
+
+	if !ok || c.stale(entry) {
+
+		if len(c.entries) >= pollCacheSize {
+			c.evict()
+		}
+
+		entry = &cachedPoll{ready: make(chan struct{})}
+		c.entries[pollID] = entry
+
+		go c.load(ctx, pollID, entry)
+	}
+
+	c.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.poll, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load looks the poll up for everyone waiting on entry. The lookup keeps the
+// values of the ctx of the caller that started it but not its cancellation,
+// so that caller giving up does not fail the lookup for the others.
+func (c *pollCache) load(ctx context.Context, pollID uuid.UUID, entry *cachedPoll) {
+
+	loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), pollCacheLoadTimeout)
+	defer cancel()
+
+	entry.poll, entry.err = c.repo.FindPollByID(loadCtx, pollID)
+	entry.loadedAt = c.now()
+	close(entry.ready)
+
+	// failed lookups, timeouts included, are not kept, the next vote tries
+	// again
+	if entry.err != nil {
+		c.mu.Lock()
+		if c.entries[pollID] == entry {
+			delete(c.entries, pollID)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// stale reports whether the entry was looked up too long ago. An entry still
+// being looked up is not stale. c.mu must be held.
+func (c *pollCache) stale(entry *cachedPoll) bool {
+
+	select {
+	case <-entry.ready:
+		return c.now().Sub(entry.loadedAt) > c.ttl
+	default:
+		return false
+	}
+}
+
+// evict drops the stale entries. c.mu must be held.
+func (c *pollCache) evict() {
+
+	for pollID, entry := range c.entries {
+		if c.stale(entry) {
+			delete(c.entries, pollID)
+		}
+	}
+}
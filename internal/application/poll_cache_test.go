@@ -0,0 +1,101 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestPollCache_LooksUpOncePerTTL(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	cache := newPollCache(mockRepo, time.Second*5)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	pollID := uuid.New()
+
+	mockRepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{ID: pollID}, nil)
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Go(func() {
+			poll, err := cache.Get(ctx, pollID)
+			assert.NoError(t, err)
+			assert.Equal(t, pollID, poll.ID)
+		})
+	}
+
+	wg.Wait()
+	mockRepo.AssertNumberOfCalls(t, "FindPollByID", 1)
+
+	now = now.Add(time.Second * 6)
+
+	_, err := cache.Get(ctx, pollID)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "FindPollByID", 2)
+}
+
+func TestPollCache_ForgetsFailedLookups(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	cache := newPollCache(mockRepo, time.Second*5)
+
+	ctx := context.Background()
+	pollID := uuid.New()
+
+	mockRepo.On("FindPollByID", mock.Anything, pollID).Return(nil, utils.PollNotFoundError).Once()
+	mockRepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{ID: pollID}, nil).Once()
+
+	_, err := cache.Get(ctx, pollID)
+
+	assert.ErrorIs(t, err, utils.PollNotFoundError)
+
+	poll, err := cache.Get(ctx, pollID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, pollID, poll.ID)
+}
+
+func TestPollCache_LookupOutlivesTheCallerThatStartedIt(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	cache := newPollCache(mockRepo, time.Second*5)
+
+	pollID := uuid.New()
+	release := make(chan struct{})
+
+	mockRepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{ID: pollID}, nil).Run(func(args mock.Arguments) {
+		<-release
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+	}).Once()
+
+	// the caller has already given up when the lookup starts
+	first, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cache.Get(first, pollID)
+
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the lookup the cancelled caller started is still the one others get
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	poll, err := cache.Get(context.Background(), pollID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, pollID, poll.ID)
+	mockRepo.AssertNumberOfCalls(t, "FindPollByID", 1)
+}
@@ -0,0 +1,21 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type PollCollaboratorService interface {
+	// AddCollaborator grants a registered user a role on the poll.
+	AddCollaborator(ctx context.Context, pollID uuid.UUID, addRequest dto.AddCollaboratorRequest) ([]dto.PollCollaboratorResponse, error)
+
+	GetCollaborators(ctx context.Context, pollID uuid.UUID) ([]dto.PollCollaboratorResponse, error)
+
+	UpdateCollaboratorRole(ctx context.Context, pollID uuid.UUID, collaboratorID uuid.UUID, updateRequest dto.UpdateCollaboratorRoleRequest) ([]dto.PollCollaboratorResponse, error)
+
+	// RemoveCollaborator revokes a collaborator's role. Collaborators can
+	// always remove themselves.
+	RemoveCollaborator(ctx context.Context, pollID uuid.UUID, collaboratorID uuid.UUID) error
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type pollcollaboratorservice struct {
+	repo        repository.PollPermissionRepository
+	pollrepo    repository.PollRepository
+	userservice UserService
+	policy      PollPolicy
+}
+
+func NewPollCollaboratorService(repo repository.PollPermissionRepository, pollrepo repository.PollRepository, userservice UserService, policy PollPolicy) PollCollaboratorService {
+	return &pollcollaboratorservice{
+		repo:        repo,
+		pollrepo:    pollrepo,
+		userservice: userservice,
+		policy:      policy,
+	}
+}
+
+func (s *pollcollaboratorservice) AddCollaborator(ctx context.Context, pollID uuid.UUID, addRequest dto.AddCollaboratorRequest) ([]dto.PollCollaboratorResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	poll, err := s.authorize(ctx, pollID, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userservice.GetUserByEmail(ctx, addRequest.Email)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.UserNotFoundError
+		}
+		return nil, err
+	}
+
+	if user.ID == poll.UserID {
+		return nil, utils.CreatorCollaboratorError
+	}
+
+	err = s.repo.Add(ctx, &domain.PollPermission{
+		PollID:    pollID,
+		UserID:    user.ID,
+		Role:      domain.PollRole(addRequest.Role),
+		GrantedBy: userID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.collaborators(ctx, pollID)
+}
+
+func (s *pollcollaboratorservice) GetCollaborators(ctx context.Context, pollID uuid.UUID) ([]dto.PollCollaboratorResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if _, err := s.authorize(ctx, pollID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.collaborators(ctx, pollID)
+}
+
+func (s *pollcollaboratorservice) UpdateCollaboratorRole(ctx context.Context, pollID uuid.UUID, collaboratorID uuid.UUID, updateRequest dto.UpdateCollaboratorRoleRequest) ([]dto.PollCollaboratorResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if _, err := s.authorize(ctx, pollID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRole(ctx, pollID, collaboratorID, domain.PollRole(updateRequest.Role)); err != nil {
+		return nil, err
+	}
+
+	return s.collaborators(ctx, pollID)
+}
+
+func (s *pollcollaboratorservice) RemoveCollaborator(ctx context.Context, pollID uuid.UUID, collaboratorID uuid.UUID) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if collaboratorID != userID {
+		if _, err := s.authorize(ctx, pollID, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Remove(ctx, pollID, collaboratorID)
+}
+
+func (s *pollcollaboratorservice) authorize(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) (*domain.Poll, error) {
+
+	poll, err := s.pollrepo.FindPollByID(ctx, pollID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.policy.Can(ctx, poll, userID, domain.PollActionManage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, utils.PollPermissionDeniedError
+	}
+
+	return poll, nil
+}
+
+func (s *pollcollaboratorservice) collaborators(ctx context.Context, pollID uuid.UUID) ([]dto.PollCollaboratorResponse, error) {
+
+	permissions, err := s.repo.FindByPollID(ctx, pollID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.PollCollaboratorResponse, 0, len(permissions))
+
+	for _, permission := range permissions {
+		response = append(response, dto.PollCollaboratorResponse{
+			UserID:    permission.UserID,
+			Username:  permission.User.Username,
+			Email:     permission.User.Email,
+			Role:      string(permission.Role),
+			GrantedAt: permission.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
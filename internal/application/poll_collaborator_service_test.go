@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestAddCollaborator_RequiresManage(t *testing.T) {
+	mockRepo := new(mocks.PollPermissionRepository)
+	mockPollRepo := new(mocks.PollRepository)
+	mockUserService := new(MockUserService)
+	service := NewPollCollaboratorService(mockRepo, mockPollRepo, mockUserService, NewPollPolicy(new(mocks.OrganizationRepository), mockRepo, new(mocks.VoteRepository)))
+
+	userID := uuid.New()
+	pollID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockPollRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: uuid.New()}, nil)
+	mockRepo.On("Find", ctx, pollID, userID).Return(&domain.PollPermission{Role: domain.PollRoleResultsViewer}, nil)
+
+	_, err := service.AddCollaborator(ctx, pollID, dto.AddCollaboratorRequest{Email: "facilitator@example.com", Role: "results_viewer"})
+
+	assert.ErrorIs(t, err, utils.PollPermissionDeniedError)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestAddCollaborator_Success(t *testing.T) {
+	mockRepo := new(mocks.PollPermissionRepository)
+	mockPollRepo := new(mocks.PollRepository)
+	mockUserService := new(MockUserService)
+	service := NewPollCollaboratorService(mockRepo, mockPollRepo, mockUserService, newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)))
+
+	userID := uuid.New()
+	pollID := uuid.New()
+	facilitatorID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockPollRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: userID}, nil)
+	mockUserService.On("GetUserByEmail", ctx, "facilitator@example.com").Return(&dto.UserAuthView{ID: facilitatorID}, nil)
+	mockRepo.On("Add", ctx, mock.MatchedBy(func(permission *domain.PollPermission) bool {
+		return permission.UserID == facilitatorID && permission.Role == domain.PollRoleResultsViewer && permission.GrantedBy == userID
+	})).Return(nil)
+	mockRepo.On("FindByPollID", ctx, pollID).Return([]domain.PollPermission{{UserID: facilitatorID, Role: domain.PollRoleResultsViewer}}, nil)
+
+	resp, err := service.AddCollaborator(ctx, pollID, dto.AddCollaboratorRequest{Email: "facilitator@example.com", Role: "results_viewer"})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "results_viewer", resp[0].Role)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRemoveCollaborator_Self(t *testing.T) {
+	mockRepo := new(mocks.PollPermissionRepository)
+	mockPollRepo := new(mocks.PollRepository)
+	service := NewPollCollaboratorService(mockRepo, mockPollRepo, new(MockUserService), newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)))
+
+	userID := uuid.New()
+	pollID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("Remove", ctx, pollID, userID).Return(nil)
+
+	err := service.RemoveCollaborator(ctx, pollID, userID)
+
+	assert.NoError(t, err)
+	mockPollRepo.AssertNotCalled(t, "FindPollByID", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,19 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type PollInviteService interface {
+	// CreateInvites adds addresses and users to the poll's invite list and
+	// emails everyone who was not invited before.
+	CreateInvites(ctx context.Context, pollID uuid.UUID, createRequest dto.CreateInvitesRequest) (*dto.PollInvitesResponse, error)
+
+	// GetInvites lists the invite list with who has voted so far.
+	GetInvites(ctx context.Context, pollID uuid.UUID) (*dto.PollInvitesResponse, error)
+
+	RevokeInvite(ctx context.Context, pollID uuid.UUID, inviteID uuid.UUID) error
+}
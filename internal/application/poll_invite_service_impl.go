@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type pollinviteservice struct {
+	repo     repository.PollInviteRepository
+	pollrepo repository.PollRepository
+	voterepo repository.VoteRepository
+	userrepo repository.UserRepository
+	policy   PollPolicy
+}
+
+func NewPollInviteService(repo repository.PollInviteRepository, pollrepo repository.PollRepository, voterepo repository.VoteRepository, userrepo repository.UserRepository, policy PollPolicy) PollInviteService {
+	return &pollinviteservice{
+		repo:     repo,
+		pollrepo: pollrepo,
+		voterepo: voterepo,
+		userrepo: userrepo,
+		policy:   policy,
+	}
+}
+
+func (s *pollinviteservice) CreateInvites(ctx context.Context, pollID uuid.UUID, createRequest dto.CreateInvitesRequest) (*dto.PollInvitesResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if len(createRequest.Emails) == 0 && len(createRequest.UserIDs) == 0 {
+		return nil, utils.EmptyInviteListError
+	}
+
+	poll, err := s.authorize(ctx, pollID, userID, domain.PollActionManage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByPollID(ctx, pollID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	invited := make(map[string]bool, len(existing))
+
+	for _, invite := range existing {
+		invited[invite.Email] = true
+	}
+
+	var invites []domain.PollInvite
+
+	add := func(email string, inviteeID *uuid.UUID) {
+
+		if invited[email] {
+			return
+		}
+
+		invited[email] = true
+
+		invites = append(invites, domain.PollInvite{
+			PollID:    pollID,
+			Email:     email,
+			UserID:    inviteeID,
+			InvitedBy: userID,
+		})
+	}
+
+	for _, inviteeID := range createRequest.UserIDs {
+
+		user, err := s.userrepo.FindById(ctx, inviteeID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		add(normalizeEmail(user.Email), &user.ID)
+	}
+
+	for _, email := range createRequest.Emails {
+
+		email = normalizeEmail(email)
+
+		if invited[email] {
+			continue
+		}
+
+		var inviteeID *uuid.UUID
+
+		// link the invite to an existing account only when that account
+		// has proven it owns the address
+		if user, err := s.userrepo.FindByEmail(ctx, email); err == nil && user.EmailVerified {
+			inviteeID = &user.ID
+		}
+
+		add(email, inviteeID)
+	}
+
+	// the invitation emails are sent by the notification service once the
+	// invites are committed
+	if err := s.repo.SaveAll(ctx, invites); err != nil {
+		return nil, err
+	}
+
+	return s.invitesResponse(ctx, poll)
+}
+
+func (s *pollinviteservice) GetInvites(ctx context.Context, pollID uuid.UUID) (*dto.PollInvitesResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	poll, err := s.authorize(ctx, pollID, userID, domain.PollActionViewResults)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.invitesResponse(ctx, poll)
+}
+
+func (s *pollinviteservice) RevokeInvite(ctx context.Context, pollID uuid.UUID, inviteID uuid.UUID) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if _, err := s.authorize(ctx, pollID, userID, domain.PollActionManage); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, pollID, inviteID)
+}
+
+func (s *pollinviteservice) authorize(ctx context.Context, pollID uuid.UUID, userID uuid.UUID, action domain.PollAction) (*domain.Poll, error) {
+
+	poll, err := s.pollrepo.FindPollByID(ctx, pollID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.policy.Can(ctx, poll, userID, action)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, utils.PollPermissionDeniedError
+	}
+
+	return poll, nil
+}
+
+func (s *pollinviteservice) invitesResponse(ctx context.Context, poll *domain.Poll) (*dto.PollInvitesResponse, error) {
+
+	invites, err := s.repo.FindByPollID(ctx, poll.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var inviteeIDs []uuid.UUID
+
+	for _, invite := range invites {
+		if invite.UserID != nil {
+			inviteeIDs = append(inviteeIDs, *invite.UserID)
+		}
+	}
+
+	voterIDs, err := s.voterepo.FindVotedUserIDs(ctx, poll.ID, inviteeIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	voters := make(map[uuid.UUID]bool, len(voterIDs))
+
+	for _, voterID := range voterIDs {
+		voters[voterID] = true
+	}
+
+	response := &dto.PollInvitesResponse{
+		Invited: len(invites),
+		Invites: make([]dto.PollInviteResponse, 0, len(invites)),
+	}
+
+	for _, invite := range invites {
+
+		voted := invite.UserID != nil && voters[*invite.UserID]
+
+		if voted {
+			response.Voted++
+		}
+
+		response.Invites = append(response.Invites, dto.PollInviteResponse{
+			ID:        invite.ID,
+			Email:     invite.Email,
+			UserID:    invite.UserID,
+			Voted:     voted,
+			InvitedAt: invite.CreatedAt,
+		})
+	}
+
+	if response.Invited > 0 {
+		response.ParticipationRate = float64(response.Voted) / float64(response.Invited)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestCreateInvites_RequiresPollEditor(t *testing.T) {
+	mockRepo := new(mocks.PollInviteRepository)
+	mockPollRepo := new(mocks.PollRepository)
+	service := NewPollInviteService(mockRepo, mockPollRepo, new(mocks.VoteRepository), new(mocks.UserRepository), newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)))
+
+	pollID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+
+	mockPollRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: uuid.New()}, nil)
+
+	_, err := service.CreateInvites(ctx, pollID, dto.CreateInvitesRequest{Emails: []string{"guest@example.com"}})
+
+	assert.ErrorIs(t, err, utils.PollPermissionDeniedError)
+	mockRepo.AssertNotCalled(t, "SaveAll", mock.Anything, mock.Anything)
+}
+
+func TestCreateInvites_SkipsExistingAndLinksVerifiedUsers(t *testing.T) {
+	mockRepo := new(mocks.PollInviteRepository)
+	mockPollRepo := new(mocks.PollRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	service := NewPollInviteService(mockRepo, mockPollRepo, mockVoteRepo, mockUserRepo, newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)))
+
+	userID := uuid.New()
+	pollID := uuid.New()
+	verifiedID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockPollRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: userID, Title: "Lunch"}, nil)
+	mockRepo.On("FindByPollID", ctx, pollID).Return([]domain.PollInvite{{PollID: pollID, Email: "old@example.com"}}, nil).Once()
+	mockUserRepo.On("FindByEmail", ctx, "verified@example.com").Return(domain.User{ID: verifiedID, EmailVerified: true}, nil)
+	mockUserRepo.On("FindByEmail", ctx, "unverified@example.com").Return(domain.User{ID: uuid.New()}, nil)
+	mockUserRepo.On("FindByEmail", ctx, "new@example.com").Return(domain.User{}, utils.UserNotFoundError)
+
+	var saved []domain.PollInvite
+	mockRepo.On("SaveAll", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]domain.PollInvite)
+	}).Return(nil)
+	mockRepo.On("FindByPollID", ctx, pollID).Return([]domain.PollInvite{}, nil)
+	mockVoteRepo.On("FindVotedUserIDs", ctx, pollID, mock.Anything).Return([]uuid.UUID{}, nil)
+
+	_, err := service.CreateInvites(ctx, pollID, dto.CreateInvitesRequest{
+		Emails: []string{"Old@example.com", "Verified@Example.com", "unverified@example.com", "new@example.com", "new@example.com"},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, saved, 3)
+	assert.Equal(t, "verified@example.com", saved[0].Email)
+	assert.Equal(t, &verifiedID, saved[0].UserID)
+	assert.Nil(t, saved[1].UserID)
+	assert.Nil(t, saved[2].UserID)
+}
+
+func TestGetInvites_ParticipationRate(t *testing.T) {
+	mockRepo := new(mocks.PollInviteRepository)
+	mockPollRepo := new(mocks.PollRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	service := NewPollInviteService(mockRepo, mockPollRepo, mockVoteRepo, new(mocks.UserRepository), newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)))
+
+	userID := uuid.New()
+	pollID := uuid.New()
+	voterID := uuid.New()
+	otherID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockPollRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{
+		ID:     pollID,
+		UserID: userID,
+	}, nil)
+	mockRepo.On("FindByPollID", ctx, pollID).Return([]domain.PollInvite{
+		{Email: "voter@example.com", UserID: &voterID},
+		{Email: "pending@example.com"},
+		{Email: "other@example.com", UserID: &otherID},
+		{Email: "late@example.com"},
+	}, nil)
+	mockVoteRepo.On("FindVotedUserIDs", ctx, pollID, []uuid.UUID{voterID, otherID}).Return([]uuid.UUID{voterID}, nil)
+
+	resp, err := service.GetInvites(ctx, pollID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, resp.Invited)
+	assert.Equal(t, 1, resp.Voted)
+	assert.Equal(t, 0.25, resp.ParticipationRate)
+	assert.True(t, resp.Invites[0].Voted)
+}
+
+func TestVotePoll_InviteOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		verified bool
+		invite   *domain.PollInvite
+		err      error
+	}{
+		{"invited by email", true, &domain.PollInvite{ID: uuid.New(), Email: "guest@example.com"}, nil},
+		{"not invited", true, nil, utils.PollNotInvitedError},
+		{"unverified email", false, nil, utils.PollNotInvitedError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVoteRepo := new(mocks.VoteRepository)
+			mockPollRepo := new(mocks.PollRepository)
+			mockInviteRepo := new(mocks.PollInviteRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			service := NewVoteService(newTestVoteIngester(t, mockVoteRepo, mockInviteRepo, newTestUnitOfWork()), mockPollRepo, new(mocks.OptionRepository), newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)), mockInviteRepo, mockUserRepo)
+
+			userID := uuid.New()
+			pollID := uuid.New()
+			optionID := uuid.New()
+			ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+			mockPollRepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{
+				ID:         pollID,
+				UserID:     uuid.New(),
+				ExpiresAt:  time.Now().Add(time.Hour),
+				InviteOnly: true,
+				Options:    []domain.Option{{ID: optionID}},
+			}, nil)
+			mockUserRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Email: "Guest@example.com", EmailVerified: tt.verified}, nil)
+
+			email := ""
+			if tt.verified {
+				email = "guest@example.com"
+			}
+
+			if tt.invite != nil {
+				mockInviteRepo.On("FindForUser", ctx, pollID, userID, email).Return(tt.invite, nil)
+				mockInviteRepo.On("Claim", mock.Anything, tt.invite.ID, userID).Return(nil)
+			} else {
+				mockInviteRepo.On("FindForUser", ctx, pollID, userID, email).Return(nil, utils.InviteNotFoundError)
+			}
+
+			mockVoteRepo.On("SaveVotes", mock.Anything, mock.Anything).Return([]error{nil}, nil)
+
+			_, err := service.VotePoll(ctx, dto.VoteRequest{PollID: pollID.String(), OptionID: optionID.String()})
+
+			if tt.err == nil {
+				assert.NoError(t, err)
+				mockInviteRepo.AssertCalled(t, "Claim", mock.Anything, tt.invite.ID, userID)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+				mockVoteRepo.AssertNotCalled(t, "SaveVotes", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// PollPolicy decides what a user may do with a poll.
+type PollPolicy interface {
+	// Can reports whether the user may perform the action on the poll. The
+	// creator can do everything; everyone else gets the union of their
+	// collaborator role on the poll and their role in its organization.
+	Can(ctx context.Context, poll *domain.Poll, userID uuid.UUID, action domain.PollAction) (bool, error)
+
+	// ResultsPublished reports whether the poll's results visibility shows
+	// the tally to a participant who cannot otherwise view the results.
+	ResultsPublished(ctx context.Context, poll *domain.Poll, userID uuid.UUID) (bool, error)
+}
+
+// organizationRoleActions maps organization roles onto poll actions. Viewers
+// follow the organization's polls, editors also run them.
+var organizationRoleActions = map[domain.PollAction]domain.OrganizationRole{
+	domain.PollActionParticipate: domain.OrganizationRoleViewer,
+	domain.PollActionViewResults: domain.OrganizationRoleViewer,
+	domain.PollActionManage:      domain.OrganizationRoleEditor,
+}
+
+type pollpolicy struct {
+	orgrepo        repository.OrganizationRepository
+	permissionrepo repository.PollPermissionRepository
+	voterepo       repository.VoteRepository
+}
+
+func NewPollPolicy(orgrepo repository.OrganizationRepository, permissionrepo repository.PollPermissionRepository, voterepo repository.VoteRepository) PollPolicy {
+	return &pollpolicy{
+		orgrepo:        orgrepo,
+		permissionrepo: permissionrepo,
+		voterepo:       voterepo,
+	}
+}
+
+func (p *pollpolicy) Can(ctx context.Context, poll *domain.Poll, userID uuid.UUID, action domain.PollAction) (bool, error) {
+
+	if poll.UserID == userID {
+		return true, nil
+	}
+
+	permission, err := p.permissionrepo.Find(ctx, poll.ID, userID)
+
+	if err != nil && !errors.Is(err, utils.CollaboratorNotFoundError) {
+		return false, err
+	}
+
+	if permission != nil && permission.Role.Allows(action) {
+		return true, nil
+	}
+
+	if poll.OrganizationID == nil {
+		return false, nil
+	}
+
+	member, err := p.orgrepo.FindMember(ctx, *poll.OrganizationID, userID)
+
+	if err != nil {
+		if errors.Is(err, utils.MemberNotFoundError) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return member.Role.Allows(organizationRoleActions[action]), nil
+}
+
+func (p *pollpolicy) ResultsPublished(ctx context.Context, poll *domain.Poll, userID uuid.UUID) (bool, error) {
+
+	switch poll.ResultsVisibility {
+	case domain.ResultsVisibilityAlways:
+	case domain.ResultsVisibilityAfterClose:
+		if poll.ExpiresAt.After(time.Now()) {
+			return false, nil
+		}
+	case domain.ResultsVisibilityAfterVote:
+		// visitors who are not signed in have not voted
+		if userID == uuid.Nil {
+			return false, nil
+		}
+
+		voted, err := p.voterepo.ExistsByPollIDAndAndUserID(ctx, poll.ID, userID)
+
+		if err != nil || !voted {
+			return false, err
+		}
+	default:
+		return false, nil
+	}
+
+	// results of members-only polls stay within the organization
+	if poll.MembersOnly {
+		return p.Can(ctx, poll, userID, domain.PollActionParticipate)
+	}
+
+	return true, nil
+}
+
+// findInvite returns the user's invite to the poll. An invite sent to an
+// address only counts once the user has verified that address, so nobody can
+// claim it by registering with someone else's email.
+func findInvite(ctx context.Context, inviterepo repository.PollInviteRepository, userrepo repository.UserRepository, pollID uuid.UUID, userID uuid.UUID) (*domain.PollInvite, error) {
+
+	user, err := userrepo.FindById(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var email string
+
+	if user.EmailVerified {
+		email = normalizeEmail(user.Email)
+	}
+
+	return inviterepo.FindForUser(ctx, pollID, userID, email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
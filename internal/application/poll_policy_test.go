@@ -0,0 +1,78 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// newTestPollPolicy returns a policy over orgrepo and voterepo for users
+// without collaborator roles.
+func newTestPollPolicy(orgrepo repository.OrganizationRepository, voterepo repository.VoteRepository) PollPolicy {
+	permissionrepo := new(mocks.PollPermissionRepository)
+	permissionrepo.On("Find", mock.Anything, mock.Anything, mock.Anything).Return(nil, utils.CollaboratorNotFoundError)
+	return NewPollPolicy(orgrepo, permissionrepo, voterepo)
+}
+
+func TestPollPolicy_Can(t *testing.T) {
+	tests := []struct {
+		name       string
+		creator    bool
+		pollRole   domain.PollRole
+		memberRole domain.OrganizationRole
+		action     domain.PollAction
+		allowed    bool
+	}{
+		{"creator manages", true, "", "", domain.PollActionManage, true},
+		{"stranger views results", false, "", "", domain.PollActionViewResults, false},
+		{"results viewer views results", false, domain.PollRoleResultsViewer, "", domain.PollActionViewResults, true},
+		{"results viewer manages", false, domain.PollRoleResultsViewer, "", domain.PollActionManage, false},
+		{"co-owner manages", false, domain.PollRoleCoOwner, "", domain.PollActionManage, true},
+		{"organization viewer views results", false, "", domain.OrganizationRoleViewer, domain.PollActionViewResults, true},
+		{"organization viewer manages", false, "", domain.OrganizationRoleViewer, domain.PollActionManage, false},
+		{"organization editor manages", false, "", domain.OrganizationRoleEditor, domain.PollActionManage, true},
+		{"results viewer in organization", false, domain.PollRoleResultsViewer, domain.OrganizationRoleEditor, domain.PollActionManage, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockOrgRepo := new(mocks.OrganizationRepository)
+			mockPermissionRepo := new(mocks.PollPermissionRepository)
+			policy := NewPollPolicy(mockOrgRepo, mockPermissionRepo, new(mocks.VoteRepository))
+
+			userID := uuid.New()
+			organizationID := uuid.New()
+			ctx := context.Background()
+
+			poll := &domain.Poll{ID: uuid.New(), UserID: uuid.New(), OrganizationID: &organizationID}
+
+			if tt.creator {
+				poll.UserID = userID
+			}
+
+			if tt.pollRole != "" {
+				mockPermissionRepo.On("Find", ctx, poll.ID, userID).Return(&domain.PollPermission{Role: tt.pollRole}, nil)
+			} else {
+				mockPermissionRepo.On("Find", ctx, poll.ID, userID).Return(nil, utils.CollaboratorNotFoundError)
+			}
+
+			if tt.memberRole != "" {
+				mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{Role: tt.memberRole}, nil)
+			} else {
+				mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(nil, utils.MemberNotFoundError)
+			}
+
+			allowed, err := policy.Can(ctx, poll, userID, tt.action)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
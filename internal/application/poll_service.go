@@ -10,12 +10,40 @@ import (
 type PollService interface {
 	GetPollCount(ctx context.Context, userID uuid.UUID) (int, error)
 
-	CreatePoll(ctx context.Context, poll *dto.CreatePollRequest) error
+	CreatePoll(ctx context.Context, poll *dto.CreatePollRequest) (uuid.UUID, error)
 
 	DeletePoll(ctx context.Context, pollID uuid.UUID) error
 
+	// GetPollView returns the live results when the poll's results policy
+	// shows them to the signed in user.
 	GetPollView(ctx context.Context, pollID uuid.UUID) (*dto.PollViewResponse, error)
+
+	// GetPoll returns the voting page, with the tally only when the results
+	// policy allows it. Without a signed in user only public polls are
+	// returned.
 	GetPoll(ctx context.Context, pollID uuid.UUID) (*dto.PollViewResponse, error)
 
+	// GetPollVotes lists who voted in the poll, a page at a time, for the
+	// people running it. cursor is the previous page's NextCursor, or empty
+	// for the first page.
+	GetPollVotes(ctx context.Context, pollID uuid.UUID, cursor string) (*dto.PollVotesResponse, error)
+
+	// EachPollVote calls fn for every vote in the poll in the order they
+	// were cast, for the people running it, without holding them all in
+	// memory.
+	EachPollVote(ctx context.Context, pollID uuid.UUID, fn func(dto.Vote) error) error
+
+	// AuthorizeResultsStream checks that the signed in user may follow the
+	// poll's results live.
+	AuthorizeResultsStream(ctx context.Context, pollID uuid.UUID) error
+
 	GetAllPolls(ctx context.Context) (dto.ApiResponse[[]dto.PollViewResponse], error)
+
+	// CloseExpiredPolls closes the polls that have expired, which records
+	// their poll.closed events.
+	CloseExpiredPolls(ctx context.Context) error
+
+	// GetOrganizationPolls lists the polls of an organization the signed in
+	// user is a member of.
+	GetOrganizationPolls(ctx context.Context, organizationID uuid.UUID) ([]dto.PollViewResponse, error)
 }
@@ -2,9 +2,11 @@ package application
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
-
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/application/repository"
@@ -13,17 +15,34 @@ import (
 	"github.com/winnerx0/jille/internal/utils"
 )
 
+const (
+	// pollCloseBatch is how many expired polls are closed per transaction.
+	pollCloseBatch = 100
+
+	// pollVotesPage is how many votes a page of GetPollVotes lists.
+	pollVotesPage = 100
+
+	// pollVotesBatch is how many votes EachPollVote loads at a time.
+	pollVotesBatch = 1000
+)
+
 type pollservice struct {
 	repo       repository.PollRepository
 	optionrepo repository.OptionRepository
 	voterepo   repository.VoteRepository
+	orgrepo    repository.OrganizationRepository
+	uow        repository.UnitOfWork
+	policy     PollPolicy
 }
 
-func NewPollService(repo repository.PollRepository, optionrepo repository.OptionRepository, voterepo repository.VoteRepository) PollService {
+func NewPollService(repo repository.PollRepository, optionrepo repository.OptionRepository, voterepo repository.VoteRepository, orgrepo repository.OrganizationRepository, uow repository.UnitOfWork, policy PollPolicy) PollService {
 	return &pollservice{
 		repo:       repo,
 		optionrepo: optionrepo,
 		voterepo:   voterepo,
+		orgrepo:    orgrepo,
+		uow:        uow,
+		policy:     policy,
 	}
 }
 
@@ -38,38 +57,68 @@ func (s *pollservice) GetPollCount(ctx context.Context, userID uuid.UUID) (int,
 	return count, nil
 }
 
-func (s *pollservice) CreatePoll(ctx context.Context, pollRequest *dto.CreatePollRequest) error {
+func (s *pollservice) CreatePoll(ctx context.Context, pollRequest *dto.CreatePollRequest) (uuid.UUID, error) {
 
 	userID := ctx.Value("userID").(string)
 
-	poll := &domain.Poll{
-		Title:     pollRequest.Title,
-		UserID:    uuid.MustParse(userID),
-		ExpiresAt: pollRequest.ExpiresAt,
+	if pollRequest.OrganizationID != nil {
+
+		// only editors and owners publish polls on behalf of the organization
+		member, err := s.orgrepo.FindMember(ctx, *pollRequest.OrganizationID, uuid.MustParse(userID))
+
+		if err != nil {
+			if errors.Is(err, utils.MemberNotFoundError) {
+				return uuid.Nil, utils.OrganizationNotFoundError
+			}
+			return uuid.Nil, err
+		}
+
+		if !member.Role.Allows(domain.OrganizationRoleEditor) {
+			return uuid.Nil, utils.OrganizationPermissionDeniedError
+		}
+	} else if pollRequest.MembersOnly {
+		return uuid.Nil, utils.InvalidMembersOnlyPollError
 	}
 
-	err := s.repo.Save(ctx, poll)
+	poll := &domain.Poll{
+		Title:          pollRequest.Title,
+		UserID:         uuid.MustParse(userID),
+		ExpiresAt:      pollRequest.ExpiresAt,
+		OrganizationID: pollRequest.OrganizationID,
+		MembersOnly:    pollRequest.MembersOnly,
+		InviteOnly:     pollRequest.InviteOnly,
+
+		ResultsVisibility: domain.ResultsVisibility(pollRequest.ResultsVisibility),
+	}
 
-	if err != nil {
-		return err
+	if poll.ResultsVisibility == "" {
+		poll.ResultsVisibility = domain.ResultsVisibilityCreatorOnly
 	}
 
-	options := make([]domain.Option, len(pollRequest.Options))
+	// a poll without its options is never committed
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
 
-	for i, option := range pollRequest.Options {
-		options[i] = domain.Option{
-			Name:   option,
-			PollID: poll.ID,
+		if err := s.repo.Save(ctx, poll); err != nil {
+			return err
+		}
+
+		options := make([]domain.Option, len(pollRequest.Options))
+
+		for i, option := range pollRequest.Options {
+			options[i] = domain.Option{
+				Name:   option,
+				PollID: poll.ID,
+			}
 		}
-	}
 
-	err = s.optionrepo.Save(ctx, &options)
+		return s.optionrepo.Save(ctx, &options)
+	})
 
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
-	return nil
+	return poll.ID, nil
 }
 
 func (s *pollservice) DeletePoll(ctx context.Context, pollID uuid.UUID) error {
@@ -80,17 +129,39 @@ func (s *pollservice) DeletePoll(ctx context.Context, pollID uuid.UUID) error {
 		return err
 	}
 
-	if poll.UserID != uuid.MustParse(ctx.Value("userID").(string)) {
-		return errors.New("unauthorized")
-	}
-
-	err = s.repo.Delete(ctx, pollID)
+	allowed, err := s.policy.Can(ctx, poll, uuid.MustParse(ctx.Value("userID").(string)), domain.PollActionManage)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if !allowed {
+		return utils.PollPermissionDeniedError
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+
+		if err := s.voterepo.DeleteByPollID(ctx, pollID); err != nil {
+			return err
+		}
+
+		if err := s.optionrepo.DeleteByPollID(ctx, pollID); err != nil {
+			return err
+		}
+
+		return s.repo.Delete(ctx, pollID)
+	})
+}
+
+func (s *pollservice) CloseExpiredPolls(ctx context.Context) error {
+
+	for {
+		closed, err := s.repo.CloseExpired(ctx, time.Now(), pollCloseBatch)
+
+		if err != nil || closed < pollCloseBatch {
+			return err
+		}
+	}
 }
 
 func (s *pollservice) GetPollView(ctx context.Context, pollID uuid.UUID) (*dto.PollViewResponse, error) {
@@ -101,48 +172,38 @@ func (s *pollservice) GetPollView(ctx context.Context, pollID uuid.UUID) (*dto.P
 		return &dto.PollViewResponse{}, err
 	}
 
-	if ctx.Value("userID").(string) != poll.UserID.String() {
-		return &dto.PollViewResponse{}, utils.PollAccessDeniedError
-	}
-
-	options, err := s.optionrepo.FindOptionsByPollID(ctx, pollID)
+	visible, voters, err := s.resultsAccess(ctx, poll, uuid.MustParse(ctx.Value("userID").(string)))
 
 	if err != nil {
 		return &dto.PollViewResponse{}, err
 	}
 
-	var opts []dto.Option
-
-	for _, o := range *options {
-
-		votes := []dto.Vote{}
-
-		for _, v := range o.Votes {
-			vote := dto.Vote{
-				ID:       v.ID.String(),
-				UserID:   v.UserID.String(),
-				PollID:   v.PollID.String(),
-				OptionID: v.OptionID.String(),
-			}
+	if !visible {
+		return &dto.PollViewResponse{}, utils.PollAccessDeniedError
+	}
 
-			votes = append(votes, vote)
-		}
-		option := dto.Option{
-			ID:    o.ID.String(),
-			Votes: votes,
-			Name:  o.Name,
-		}
+	options, err := s.optionrepo.FindOptionsByPollID(ctx, pollID)
 
-		opts = append(opts, option)
+	if err != nil {
+		return &dto.PollViewResponse{}, err
 	}
 
 	return &dto.PollViewResponse{
 		ID:        pollID.String(),
 		Title:     poll.Title,
-		Options:   opts,
+		Options:   optionResponses(*options, true),
 		CreatedAt: poll.CreatedAt,
 		ExpiresAt: poll.ExpiresAt,
 		CreatorID: poll.UserID.String(),
+
+		OrganizationID: organizationID(poll),
+		MembersOnly:    poll.MembersOnly,
+		InviteOnly:     poll.InviteOnly,
+
+		ResultsVisibility: resultsVisibility(poll),
+		ResultsVisible:    true,
+		VotersVisible:     voters,
+		LastVoteAt:        lastVoteAt(*options),
 	}, nil
 }
 
@@ -154,25 +215,29 @@ func (s *pollservice) GetPoll(ctx context.Context, pollID uuid.UUID) (*dto.PollV
 		return &dto.PollViewResponse{}, err
 	}
 
-	options, err := s.optionrepo.FindOptionsByPollID(ctx, pollID)
+	userID, signedIn := ctx.Value("userID").(string)
 
-	if err != nil {
-		return &dto.PollViewResponse{}, err
+	if !signedIn {
+		return s.getPublicPoll(ctx, poll)
 	}
 
-	var opts []dto.Option
+	if poll.MembersOnly {
+		allowed, err := s.policy.Can(ctx, poll, uuid.MustParse(userID), domain.PollActionParticipate)
 
-	for _, o := range *options {
-		option := dto.Option{
-			ID:    o.ID.String(),
-			Votes: []dto.Vote{}, // Hidden for public voting page
-			Name:  o.Name,
+		if err != nil {
+			return &dto.PollViewResponse{}, err
 		}
 
-		opts = append(opts, option)
+		if !allowed {
+			return &dto.PollViewResponse{}, utils.MembersOnlyPollError
+		}
+	}
+
+	options, err := s.optionrepo.FindOptionsByPollID(ctx, pollID)
+
+	if err != nil {
+		return &dto.PollViewResponse{}, err
 	}
-	
-	userID := ctx.Value("userID").(string)
 
 	voted, err := s.voterepo.ExistsByPollIDAndAndUserID(ctx, pollID, uuid.MustParse(userID))
 
@@ -180,17 +245,234 @@ func (s *pollservice) GetPoll(ctx context.Context, pollID uuid.UUID) (*dto.PollV
 		return &dto.PollViewResponse{}, err
 	}
 
+	// votes stay hidden on the voting page unless the results policy
+	// shows them to this user
+	visible, voters, err := s.resultsAccess(ctx, poll, uuid.MustParse(userID))
+
+	if err != nil {
+		return &dto.PollViewResponse{}, err
+	}
+
 	return &dto.PollViewResponse{
 		ID:        pollID.String(),
 		Title:     poll.Title,
-		Options:   opts,
+		Options:   optionResponses(*options, visible),
 		CreatedAt: poll.CreatedAt,
 		ExpiresAt: poll.ExpiresAt,
 		CreatorID: poll.UserID.String(),
 		Voted:     voted,
+
+		OrganizationID: organizationID(poll),
+		MembersOnly:    poll.MembersOnly,
+		InviteOnly:     poll.InviteOnly,
+
+		ResultsVisibility: resultsVisibility(poll),
+		ResultsVisible:    visible,
+		VotersVisible:     voters,
 	}, nil
 }
 
+// getPublicPoll is GetPoll for visitors who are not signed in, such as link
+// preview crawlers. They see the tally once the results policy publishes it
+// to everyone.
+func (s *pollservice) getPublicPoll(ctx context.Context, poll *domain.Poll) (*dto.PollViewResponse, error) {
+
+	if !poll.Public() {
+		return &dto.PollViewResponse{}, utils.PollSignInRequiredError
+	}
+
+	visible, err := s.policy.ResultsPublished(ctx, poll, uuid.Nil)
+
+	if err != nil {
+		return &dto.PollViewResponse{}, err
+	}
+
+	options, err := s.optionrepo.FindOptionsByPollID(ctx, poll.ID)
+
+	if err != nil {
+		return &dto.PollViewResponse{}, err
+	}
+
+	return &dto.PollViewResponse{
+		ID:        poll.ID.String(),
+		Title:     poll.Title,
+		Options:   optionResponses(*options, visible),
+		CreatedAt: poll.CreatedAt,
+		ExpiresAt: poll.ExpiresAt,
+		CreatorID: poll.UserID.String(),
+
+		ResultsVisibility: resultsVisibility(poll),
+		ResultsVisible:    visible,
+	}, nil
+}
+
+func (s *pollservice) AuthorizeResultsStream(ctx context.Context, pollID uuid.UUID) error {
+
+	poll, err := s.repo.FindPollByID(ctx, pollID)
+
+	if err != nil {
+		return err
+	}
+
+	visible, _, err := s.resultsAccess(ctx, poll, uuid.MustParse(ctx.Value("userID").(string)))
+
+	if err != nil {
+		return err
+	}
+
+	if !visible {
+		return utils.PollAccessDeniedError
+	}
+
+	return nil
+}
+
+func (s *pollservice) GetPollVotes(ctx context.Context, pollID uuid.UUID, cursor string) (*dto.PollVotesResponse, error) {
+
+	after, err := decodeVoteCursor(cursor)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeVoters(ctx, pollID); err != nil {
+		return nil, err
+	}
+
+	votes, err := s.voterepo.FindVotesByPollID(ctx, pollID, after, pollVotesPage+1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PollVotesResponse{Votes: make([]dto.Vote, 0, min(len(votes), pollVotesPage))}
+
+	// the extra vote only tells that there is another page
+	if len(votes) > pollVotesPage {
+		votes = votes[:pollVotesPage]
+		response.NextCursor = encodeVoteCursor(votes[len(votes)-1])
+	}
+
+	for _, vote := range votes {
+		response.Votes = append(response.Votes, voteResponse(vote))
+	}
+
+	return response, nil
+}
+
+func (s *pollservice) EachPollVote(ctx context.Context, pollID uuid.UUID, fn func(dto.Vote) error) error {
+
+	if err := s.authorizeVoters(ctx, pollID); err != nil {
+		return err
+	}
+
+	var after *domain.VoteCursor
+
+	for {
+		votes, err := s.voterepo.FindVotesByPollID(ctx, pollID, after, pollVotesBatch)
+
+		if err != nil {
+			return err
+		}
+
+		for _, vote := range votes {
+			if err := fn(voteResponse(vote)); err != nil {
+				return err
+			}
+		}
+
+		if len(votes) < pollVotesBatch {
+			return nil
+		}
+
+		last := votes[len(votes)-1]
+		after = &domain.VoteCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// authorizeVoters checks that the signed in user may see who voted in the
+// poll, which only the people running it may.
+func (s *pollservice) authorizeVoters(ctx context.Context, pollID uuid.UUID) error {
+
+	poll, err := s.repo.FindPollByID(ctx, pollID)
+
+	if err != nil {
+		return err
+	}
+
+	allowed, err := s.policy.Can(ctx, poll, uuid.MustParse(ctx.Value("userID").(string)), domain.PollActionViewResults)
+
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return utils.VotersAccessDeniedError
+	}
+
+	return nil
+}
+
+func voteResponse(vote domain.Vote) dto.Vote {
+	return dto.Vote{
+		ID:       vote.ID.String(),
+		UserID:   vote.UserID.String(),
+		PollID:   vote.PollID.String(),
+		OptionID: vote.OptionID.String(),
+		VotedAt:  vote.CreatedAt,
+	}
+}
+
+// encodeVoteCursor returns the cursor of the page that follows vote.
+func encodeVoteCursor(vote domain.Vote) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(vote.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + vote.ID.String()))
+}
+
+func decodeVoteCursor(cursor string) (*domain.VoteCursor, error) {
+
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return nil, utils.InvalidCursorError
+	}
+
+	createdAt, id, _ := strings.Cut(string(raw), ",")
+
+	votedAt, err := time.Parse(time.RFC3339Nano, createdAt)
+
+	if err != nil {
+		return nil, utils.InvalidCursorError
+	}
+
+	voteID, err := uuid.Parse(id)
+
+	if err != nil {
+		return nil, utils.InvalidCursorError
+	}
+
+	return &domain.VoteCursor{CreatedAt: votedAt, ID: voteID}, nil
+}
+
+// resultsAccess reports whether the user may see the poll's results and, if
+// so, whether they may also see who cast each vote. Only the people running
+// the poll see voters; everyone the results policy admits sees the tally.
+func (s *pollservice) resultsAccess(ctx context.Context, poll *domain.Poll, userID uuid.UUID) (bool, bool, error) {
+
+	voters, err := s.policy.Can(ctx, poll, userID, domain.PollActionViewResults)
+
+	if err != nil || voters {
+		return voters, voters, err
+	}
+
+	visible, err := s.policy.ResultsPublished(ctx, poll, userID)
+
+	return visible, false, err
+}
+
 func (s *pollservice) GetAllPolls(ctx context.Context) (dto.ApiResponse[[]dto.PollViewResponse], error) {
 
 	polls, err := s.repo.FindAllPolls(ctx)
@@ -201,49 +483,113 @@ func (s *pollservice) GetAllPolls(ctx context.Context) (dto.ApiResponse[[]dto.Po
 		return dto.ApiResponse[[]dto.PollViewResponse]{Message: "Polls reteieved successfully", Data: []dto.PollViewResponse{}}, err
 	}
 
-	var pollResponse []dto.PollViewResponse
+	pollResponse := pollViewResponses(polls)
 
-	for _, poll := range polls {
+	fmt.Println("poll response", pollResponse)
+
+	return dto.ApiResponse[[]dto.PollViewResponse]{Message: "Polls reteieved successfully", Data: pollResponse}, nil
+}
 
-		var opts []dto.Option
+func (s *pollservice) GetOrganizationPolls(ctx context.Context, organizationID uuid.UUID) ([]dto.PollViewResponse, error) {
 
-		for _, o := range poll.Options {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
 
-			votes := []dto.Vote{}
+	// outsiders cannot tell an organization they are not in from a missing one
+	if _, err := s.orgrepo.FindMember(ctx, organizationID, userID); err != nil {
+		if errors.Is(err, utils.MemberNotFoundError) {
+			return nil, utils.OrganizationNotFoundError
+		}
+		return nil, err
+	}
 
-			for _, v := range o.Votes {
-				vote := dto.Vote{
-					ID:       v.ID.String(),
-					UserID:   v.UserID.String(),
-					PollID:   v.PollID.String(),
-					OptionID: v.OptionID.String(),
-				}
+	polls, err := s.repo.FindPollsByOrganizationID(ctx, organizationID)
 
-				votes = append(votes, vote)
-			}
+	if err != nil {
+		return nil, err
+	}
 
-			option := dto.Option{
-				ID:    o.ID.String(),
-				Votes: votes,
-				Name:  o.Name,
-			}
+	return pollViewResponses(polls), nil
+}
 
-			opts = append(opts, option)
-		}
+func pollViewResponses(polls []domain.Poll) []dto.PollViewResponse {
+
+	var pollResponse []dto.PollViewResponse
+
+	for _, poll := range polls {
 
 		response := dto.PollViewResponse{
 			ID:        poll.ID.String(),
 			Title:     poll.Title,
-			Options:   opts,
+			Options:   optionResponses(poll.Options, true),
 			CreatedAt: poll.CreatedAt,
 			ExpiresAt: poll.ExpiresAt,
 			CreatorID: poll.UserID.String(),
+
+			OrganizationID: organizationID(&poll),
+			MembersOnly:    poll.MembersOnly,
+			InviteOnly:     poll.InviteOnly,
+
+			ResultsVisibility: resultsVisibility(&poll),
+			ResultsVisible:    true,
+			VotersVisible:     true,
 		}
 
 		pollResponse = append(pollResponse, response)
 	}
 
-	fmt.Println("poll response", pollResponse)
+	return pollResponse
+}
 
-	return dto.ApiResponse[[]dto.PollViewResponse]{Message: "Polls reteieved successfully", Data: pollResponse}, nil
+// optionResponses converts options for a response. Without withVotes every
+// option shows no votes.
+func optionResponses(options []domain.Option, withVotes bool) []dto.Option {
+
+	var opts []dto.Option
+
+	for _, o := range options {
+
+		option := dto.Option{
+			ID:   o.ID.String(),
+			Name: o.Name,
+		}
+
+		if withVotes {
+			option.Votes = o.VoteCount
+		}
+
+		opts = append(opts, option)
+	}
+
+	return opts
+}
+
+func lastVoteAt(options []domain.Option) *time.Time {
+
+	var last *time.Time
+
+	for _, o := range options {
+		if o.LastVoteAt != nil && (last == nil || o.LastVoteAt.After(*last)) {
+			last = o.LastVoteAt
+		}
+	}
+
+	return last
+}
+
+func resultsVisibility(poll *domain.Poll) string {
+
+	if poll.ResultsVisibility == "" {
+		return string(domain.ResultsVisibilityCreatorOnly)
+	}
+
+	return string(poll.ResultsVisibility)
+}
+
+func organizationID(poll *domain.Poll) string {
+
+	if poll.OrganizationID == nil {
+		return ""
+	}
+
+	return poll.OrganizationID.String()
 }
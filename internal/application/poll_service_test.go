@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -11,13 +12,22 @@ import (
 	"github.com/winnerx0/jille/internal/application/repository/mocks"
 	"github.com/winnerx0/jille/internal/common/dto"
 	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
 )
 
+// newTestUnitOfWork returns a unit of work whose transactions commit.
+func newTestUnitOfWork() *mocks.UnitOfWork {
+	uow := new(mocks.UnitOfWork)
+	uow.On("Do", mock.Anything).Return(nil)
+	return uow
+}
+
 func TestGetPollCount(t *testing.T) {
 	mockRepo := new(mocks.PollRepository)
 	mockOptionRepo := new(mocks.OptionRepository)
 	mockVoteRepo := new(mocks.VoteRepository)
-	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
 
 	ctx := context.Background()
 	userID := uuid.New()
@@ -35,7 +45,8 @@ func TestCreatePoll_Success(t *testing.T) {
 	mockRepo := new(mocks.PollRepository)
 	mockOptionRepo := new(mocks.OptionRepository)
 	mockVoteRepo := new(mocks.VoteRepository)
-	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
 
 	userID := uuid.New()
 	ctx := context.WithValue(context.Background(), "userID", userID.String())
@@ -53,43 +64,446 @@ func TestCreatePoll_Success(t *testing.T) {
 		return len(*opts) == 2
 	})).Return(nil)
 
-	err := service.CreatePoll(ctx, pollRequest)
+	_, err := service.CreatePoll(ctx, pollRequest)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 	mockOptionRepo.AssertExpectations(t)
 }
 
+func TestCreatePoll_OptionsFailRollsBackPoll(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	mockUnitOfWork := newTestUnitOfWork()
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, mockUnitOfWork, newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+
+	// both saves have to happen inside the unit of work for the poll to be
+	// rolled back with the options
+	mockRepo.On("Save", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		mockUnitOfWork.AssertNumberOfCalls(t, "Do", 1)
+	}).Return(nil)
+	mockOptionRepo.On("Save", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		mockUnitOfWork.AssertNumberOfCalls(t, "Do", 1)
+	}).Return(errors.New("connection reset"))
+
+	pollID, err := service.CreatePoll(ctx, &dto.CreatePollRequest{
+		Title:   "Lunch",
+		Options: []string{"Pizza", "Sushi"},
+	})
+
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, uuid.Nil, pollID)
+	mockRepo.AssertExpectations(t)
+	mockOptionRepo.AssertExpectations(t)
+}
+
 func TestDeletePoll(t *testing.T) {
 	mockRepo := new(mocks.PollRepository)
 	mockOptionRepo := new(mocks.OptionRepository)
 	mockVoteRepo := new(mocks.VoteRepository)
-	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
 
-	ctx := context.Background()
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
 	pollID := uuid.New()
 
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: userID}, nil)
+	mockVoteRepo.On("DeleteByPollID", ctx, pollID).Return(nil)
+	mockOptionRepo.On("DeleteByPollID", ctx, pollID).Return(nil)
 	mockRepo.On("Delete", ctx, pollID).Return(nil)
 
 	err := service.DeletePoll(ctx, pollID)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockOptionRepo.AssertExpectations(t)
+	mockVoteRepo.AssertExpectations(t)
 }
 
 func TestDeletePoll_Fail(t *testing.T) {
 	mockRepo := new(mocks.PollRepository)
 	mockOptionRepo := new(mocks.OptionRepository)
 	mockVoteRepo := new(mocks.VoteRepository)
-	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
 
-	ctx := context.Background()
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
 	pollID := uuid.New()
 
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: userID}, nil)
+	mockVoteRepo.On("DeleteByPollID", ctx, pollID).Return(nil)
+	mockOptionRepo.On("DeleteByPollID", ctx, pollID).Return(nil)
 	mockRepo.On("Delete", ctx, pollID).Return(errors.New("Poll not found"))
 
 	err := service.DeletePoll(ctx, pollID)
 
-	assert.NoError(t, err)
+	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestDeletePoll_StopsAtFirstFailure(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	pollID := uuid.New()
+
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: userID}, nil)
+	mockVoteRepo.On("DeleteByPollID", ctx, pollID).Return(nil)
+	mockOptionRepo.On("DeleteByPollID", ctx, pollID).Return(errors.New("connection reset"))
+
+	err := service.DeletePoll(ctx, pollID)
+
+	// the votes deleted so far are rolled back with the failed work
+	assert.EqualError(t, err, "connection reset")
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestCreatePoll_OrganizationViewerDenied(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	userID := uuid.New()
+	organizationID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{Role: domain.OrganizationRoleViewer}, nil)
+
+	_, err := service.CreatePoll(ctx, &dto.CreatePollRequest{
+		Title:          "Team lunch",
+		Options:        []string{"Pizza", "Sushi"},
+		OrganizationID: &organizationID,
+	})
+
+	assert.ErrorIs(t, err, utils.OrganizationPermissionDeniedError)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestCreatePoll_MembersOnlyNeedsOrganization(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+
+	_, err := service.CreatePoll(ctx, &dto.CreatePollRequest{
+		Title:       "Team lunch",
+		Options:     []string{"Pizza", "Sushi"},
+		MembersOnly: true,
+	})
+
+	assert.ErrorIs(t, err, utils.InvalidMembersOnlyPollError)
+}
+
+func TestDeletePoll_OrganizationRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    domain.OrganizationRole
+		member  bool
+		allowed bool
+	}{
+		{"owner", domain.OrganizationRoleOwner, true, true},
+		{"editor", domain.OrganizationRoleEditor, true, true},
+		{"viewer", domain.OrganizationRoleViewer, true, false},
+		{"outsider", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.PollRepository)
+			mockOptionRepo := new(mocks.OptionRepository)
+			mockVoteRepo := new(mocks.VoteRepository)
+			mockOrgRepo := new(mocks.OrganizationRepository)
+			service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+			userID := uuid.New()
+			organizationID := uuid.New()
+			ctx := context.WithValue(context.Background(), "userID", userID.String())
+			pollID := uuid.New()
+
+			mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: uuid.New(), OrganizationID: &organizationID}, nil)
+
+			if tt.member {
+				mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{Role: tt.role}, nil)
+			} else {
+				mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(nil, utils.MemberNotFoundError)
+			}
+
+			mockVoteRepo.On("DeleteByPollID", ctx, pollID).Return(nil)
+			mockOptionRepo.On("DeleteByPollID", ctx, pollID).Return(nil)
+			mockRepo.On("Delete", ctx, pollID).Return(nil)
+
+			err := service.DeletePoll(ctx, pollID)
+
+			if tt.allowed {
+				assert.NoError(t, err)
+				mockRepo.AssertCalled(t, "Delete", ctx, pollID)
+			} else {
+				assert.ErrorIs(t, err, utils.PollPermissionDeniedError)
+				mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetPollView_OrganizationViewerAllowed(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	userID := uuid.New()
+	organizationID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	pollID := uuid.New()
+
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: uuid.New(), OrganizationID: &organizationID}, nil)
+	mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{Role: domain.OrganizationRoleViewer}, nil)
+	mockOptionRepo.On("FindOptionsByPollID", ctx, pollID).Return(&[]domain.Option{{ID: uuid.New(), Name: "Pizza"}}, nil)
+
+	resp, err := service.GetPollView(ctx, pollID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, organizationID.String(), resp.OrganizationID)
+}
+
+func TestGetPollView_PersonalPollDeniedToOthers(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+	pollID := uuid.New()
+
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: uuid.New()}, nil)
+
+	_, err := service.GetPollView(ctx, pollID)
+
+	assert.ErrorIs(t, err, utils.PollAccessDeniedError)
+	mockOrgRepo.AssertNotCalled(t, "FindMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetPollView_ResultsVisibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility domain.ResultsVisibility
+		voted      bool
+		closed     bool
+		visible    bool
+	}{
+		{"always", domain.ResultsVisibilityAlways, false, false, true},
+		{"after vote before voting", domain.ResultsVisibilityAfterVote, false, false, false},
+		{"after vote once voted", domain.ResultsVisibilityAfterVote, true, false, true},
+		{"after close while open", domain.ResultsVisibilityAfterClose, true, false, false},
+		{"after close once closed", domain.ResultsVisibilityAfterClose, false, true, true},
+		{"creator only", domain.ResultsVisibilityCreatorOnly, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.PollRepository)
+			mockOptionRepo := new(mocks.OptionRepository)
+			mockVoteRepo := new(mocks.VoteRepository)
+			mockOrgRepo := new(mocks.OrganizationRepository)
+			service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+			userID := uuid.New()
+			ctx := context.WithValue(context.Background(), "userID", userID.String())
+			pollID := uuid.New()
+
+			expiresAt := time.Now().Add(time.Hour)
+			if tt.closed {
+				expiresAt = time.Now().Add(-time.Hour)
+			}
+
+			mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: uuid.New(), ExpiresAt: expiresAt, ResultsVisibility: tt.visibility}, nil)
+			mockVoteRepo.On("ExistsByPollIDAndAndUserID", ctx, pollID, userID).Return(tt.voted, nil)
+			mockOptionRepo.On("FindOptionsByPollID", ctx, pollID).Return(&[]domain.Option{{
+				ID:        uuid.New(),
+				Name:      "Pizza",
+				VoteCount: 1,
+			}}, nil)
+
+			resp, err := service.GetPollView(ctx, pollID)
+
+			if tt.visible {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, resp.Options[0].Votes)
+				assert.False(t, resp.VotersVisible, "voters stay anonymous to participants")
+			} else {
+				assert.ErrorIs(t, err, utils.PollAccessDeniedError)
+			}
+		})
+	}
+}
+
+func TestGetPoll_HidesVotesUntilVoted(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	pollID := uuid.New()
+
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour), ResultsVisibility: domain.ResultsVisibilityAfterVote}, nil)
+	mockVoteRepo.On("ExistsByPollIDAndAndUserID", ctx, pollID, userID).Return(false, nil)
+	mockOptionRepo.On("FindOptionsByPollID", ctx, pollID).Return(&[]domain.Option{{
+		ID:        uuid.New(),
+		Name:      "Pizza",
+		VoteCount: 1,
+	}}, nil)
+
+	resp, err := service.GetPoll(ctx, pollID)
+
+	assert.NoError(t, err)
+	assert.False(t, resp.ResultsVisible)
+	assert.Zero(t, resp.Options[0].Votes)
+}
+
+func TestGetPoll_NotSignedIn(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockOptionRepo := new(mocks.OptionRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, mockOptionRepo, mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	ctx := context.Background()
+	publicPollID := uuid.New()
+	invitePollID := uuid.New()
+
+	mockRepo.On("FindPollByID", ctx, publicPollID).Return(&domain.Poll{ID: publicPollID, UserID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour), ResultsVisibility: domain.ResultsVisibilityAlways}, nil)
+	mockRepo.On("FindPollByID", ctx, invitePollID).Return(&domain.Poll{ID: invitePollID, UserID: uuid.New(), InviteOnly: true, ResultsVisibility: domain.ResultsVisibilityAlways}, nil)
+	mockOptionRepo.On("FindOptionsByPollID", ctx, publicPollID).Return(&[]domain.Option{{
+		ID:        uuid.New(),
+		Name:      "Pizza",
+		VoteCount: 1,
+	}}, nil)
+
+	resp, err := service.GetPoll(ctx, publicPollID)
+
+	if assert.NoError(t, err) {
+		assert.True(t, resp.ResultsVisible)
+		assert.False(t, resp.Voted)
+		assert.Equal(t, 1, resp.Options[0].Votes)
+		assert.False(t, resp.VotersVisible)
+	}
+
+	_, err = service.GetPoll(ctx, invitePollID)
+
+	assert.ErrorIs(t, err, utils.PollSignInRequiredError)
+	mockVoteRepo.AssertNotCalled(t, "ExistsByPollIDAndAndUserID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetPollVotes_Pages(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, new(mocks.OptionRepository), mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	pollID := uuid.New()
+
+	votes := make([]domain.Vote, pollVotesPage+1)
+	start := time.Now().UTC()
+
+	for i := range votes {
+		votes[i] = domain.Vote{ID: uuid.New(), UserID: uuid.New(), PollID: pollID, OptionID: uuid.New(), CreatedAt: start.Add(time.Duration(i) * time.Second)}
+	}
+
+	last := votes[pollVotesPage-1]
+
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: userID}, nil)
+	mockVoteRepo.On("FindVotesByPollID", ctx, pollID, (*domain.VoteCursor)(nil), pollVotesPage+1).Return(votes, nil)
+	mockVoteRepo.On("FindVotesByPollID", ctx, pollID, mock.MatchedBy(func(after *domain.VoteCursor) bool {
+		return after != nil && after.ID == last.ID && after.CreatedAt.Equal(last.CreatedAt)
+	}), pollVotesPage+1).Return(votes[pollVotesPage:], nil)
+
+	first, err := service.GetPollVotes(ctx, pollID, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, first.Votes, pollVotesPage)
+	assert.Equal(t, votes[0].UserID.String(), first.Votes[0].UserID)
+	assert.NotEmpty(t, first.NextCursor)
+
+	second, err := service.GetPollVotes(ctx, pollID, first.NextCursor)
+
+	assert.NoError(t, err)
+	assert.Len(t, second.Votes, 1)
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestGetPollVotes_Errors(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, new(mocks.OptionRepository), mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	ownPollID := uuid.New()
+	otherPollID := uuid.New()
+
+	mockRepo.On("FindPollByID", ctx, ownPollID).Return(&domain.Poll{ID: ownPollID, UserID: userID}, nil)
+	mockRepo.On("FindPollByID", ctx, otherPollID).Return(&domain.Poll{ID: otherPollID, UserID: uuid.New(), ResultsVisibility: domain.ResultsVisibilityAlways}, nil)
+
+	_, err := service.GetPollVotes(ctx, otherPollID, "")
+
+	assert.ErrorIs(t, err, utils.VotersAccessDeniedError)
+
+	_, err = service.GetPollVotes(ctx, ownPollID, "not a cursor")
+
+	assert.ErrorIs(t, err, utils.InvalidCursorError)
+	mockVoteRepo.AssertNotCalled(t, "FindVotesByPollID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEachPollVote_Batches(t *testing.T) {
+	mockRepo := new(mocks.PollRepository)
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockOrgRepo := new(mocks.OrganizationRepository)
+	service := NewPollService(mockRepo, new(mocks.OptionRepository), mockVoteRepo, mockOrgRepo, newTestUnitOfWork(), newTestPollPolicy(mockOrgRepo, mockVoteRepo))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	pollID := uuid.New()
+
+	batch := make([]domain.Vote, pollVotesBatch)
+
+	for i := range batch {
+		batch[i] = domain.Vote{ID: uuid.New(), PollID: pollID}
+	}
+
+	mockRepo.On("FindPollByID", ctx, pollID).Return(&domain.Poll{ID: pollID, UserID: userID}, nil)
+	mockVoteRepo.On("FindVotesByPollID", ctx, pollID, (*domain.VoteCursor)(nil), pollVotesBatch).Return(batch, nil)
+	mockVoteRepo.On("FindVotesByPollID", ctx, pollID, &domain.VoteCursor{ID: batch[pollVotesBatch-1].ID}, pollVotesBatch).Return([]domain.Vote{{ID: uuid.New(), PollID: pollID}}, nil)
+
+	seen := 0
+	err := service.EachPollVote(ctx, pollID, func(dto.Vote) error {
+		seen++
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, pollVotesBatch+1, seen)
+}
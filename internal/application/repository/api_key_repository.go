@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type APIKeyRepository interface {
+	Save(ctx context.Context, key *domain.APIKey) error
+
+	FindByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error)
+
+	Revoke(ctx context.Context, keyID uuid.UUID, userID uuid.UUID) error
+
+	TouchLastUsed(ctx context.Context, keyID uuid.UUID) error
+}
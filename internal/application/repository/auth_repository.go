@@ -15,5 +15,7 @@ type AuthRepository interface {
 
 	SaveToken(ctx context.Context, token *domain.RefreshToken) error
 
-	FindByToken(ctx context.Context, token string) (*domain.RefreshToken, error)
+	FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+
+	FindTokensByUserID(ctx context.Context, userID uuid.UUID) ([]domain.RefreshToken, error)
 }
\ No newline at end of file
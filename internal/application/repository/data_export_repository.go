@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type DataExportRepository interface {
+	// Save records a new export. It returns DataExportInProgressError if the
+	// user already has one pending or processing.
+	Save(ctx context.Context, export *domain.DataExport) error
+
+	FindByID(ctx context.Context, exportID uuid.UUID) (*domain.DataExport, error)
+
+	// FindActiveByUserID returns the user's pending or processing export.
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID) (*domain.DataExport, error)
+
+	UpdateStatus(ctx context.Context, exportID uuid.UUID, status domain.DataExportStatus) error
+
+	MarkReady(ctx context.Context, exportID uuid.UUID, fileName string, expiresAt time.Time) error
+
+	// FindExpired returns ready exports whose download window closed before now.
+	FindExpired(ctx context.Context, now time.Time) ([]domain.DataExport, error)
+}
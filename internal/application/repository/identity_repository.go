@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type IdentityRepository interface {
+	Save(ctx context.Context, identity *domain.UserIdentity) error
+
+	FindByProviderAndSubject(ctx context.Context, provider string, subject string) (*domain.UserIdentity, error)
+
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.UserIdentity, error)
+
+	SaveLoginState(ctx context.Context, state *domain.OIDCLoginState) error
+
+	// ConsumeLoginState returns the login state and deletes it so it cannot be
+	// replayed.
+	ConsumeLoginState(ctx context.Context, provider string, stateHash string) (*domain.OIDCLoginState, error)
+
+	// DeleteExpiredLoginStates removes logins that were never finished and
+	// expired before the given time.
+	DeleteExpiredLoginStates(ctx context.Context, before time.Time) error
+}
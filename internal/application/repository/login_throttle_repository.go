@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type LoginThrottleRepository interface {
+	FindByKeys(ctx context.Context, keys []string) ([]domain.LoginThrottle, error)
+
+	// RecordFailure increments the failure count for key, starting over when
+	// the previous failure is older than window, and returns the new count.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	Reset(ctx context.Context, key string) error
+}
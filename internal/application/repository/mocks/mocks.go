@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -18,6 +19,11 @@ func (m *UserRepository) FindById(ctx context.Context, userID uuid.UUID) (domain
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
+func (m *UserRepository) FindByIDs(ctx context.Context, userIDs []uuid.UUID) ([]domain.User, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
 func (m *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	args := m.Called(ctx, email)
 	return args.Bool(0), args.Error(1)
@@ -33,6 +39,41 @@ func (m *UserRepository) Save(ctx context.Context, user *domain.User) error {
 	return args.Error(0)
 }
 
+func (m *UserRepository) RecordProviderSignIn(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *UserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	args := m.Called(ctx, userID, password)
+	return args.Error(0)
+}
+
+func (m *UserRepository) UpdateUsername(ctx context.Context, userID uuid.UUID, username string) error {
+	args := m.Called(ctx, userID, username)
+	return args.Error(0)
+}
+
+func (m *UserRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	args := m.Called(ctx, userID, email)
+	return args.Error(0)
+}
+
+func (m *UserRepository) UpdateProfilePicture(ctx context.Context, userID uuid.UUID, profilePicture string) error {
+	args := m.Called(ctx, userID, profilePicture)
+	return args.Error(0)
+}
+
+func (m *UserRepository) DeleteAccount(ctx context.Context, userID uuid.UUID, mode domain.AccountDeletionMode) error {
+	args := m.Called(ctx, userID, mode)
+	return args.Error(0)
+}
+
 // PollRepository Mock
 type PollRepository struct {
 	mock.Mock
@@ -63,7 +104,27 @@ func (m *PollRepository) Delete(ctx context.Context, pollID uuid.UUID) error {
 
 func (m *PollRepository) FindAllPolls(ctx context.Context) ([]domain.Poll, error) {
 	args := m.Called(ctx)
-	return args.Get(0).([]domain.Poll), args.Error(0)
+	return args.Get(0).([]domain.Poll), args.Error(1)
+}
+
+func (m *PollRepository) FindPollsByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Poll, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Poll), args.Error(1)
+}
+
+func (m *PollRepository) FindPollsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]domain.Poll, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).([]domain.Poll), args.Error(1)
+}
+
+func (m *PollRepository) CloseExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *PollRepository) MarkExpiring(ctx context.Context, now time.Time, window time.Duration, limit int) (int, error) {
+	args := m.Called(ctx, now, window, limit)
+	return args.Int(0), args.Error(1)
 }
 
 // OptionRepository Mock
@@ -83,6 +144,11 @@ func (m *OptionRepository) FindOptionsByPollID(ctx context.Context, pollID uuid.
 	return args.Get(0).(*[]domain.Option), args.Error(1)
 }
 
+func (m *OptionRepository) DeleteByPollID(ctx context.Context, pollID uuid.UUID) error {
+	args := m.Called(ctx, pollID)
+	return args.Error(0)
+}
+
 // AuthRepository Mock
 type AuthRepository struct {
 	mock.Mock
@@ -98,14 +164,19 @@ func (m *AuthRepository) SaveToken(ctx context.Context, token *domain.RefreshTok
 	return args.Error(0)
 }
 
-func (m *AuthRepository) FindByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
-	args := m.Called(ctx, token)
+func (m *AuthRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.RefreshToken), args.Error(1)
 }
 
+func (m *AuthRepository) FindTokensByUserID(ctx context.Context, userID uuid.UUID) ([]domain.RefreshToken, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.RefreshToken), args.Error(1)
+}
+
 func (m *AuthRepository) Delete(ctx context.Context, pollID uuid.UUID) error {
 	args := m.Called(ctx, pollID)
 	return args.Error(0)
@@ -118,11 +189,15 @@ type VoteRepository struct {
 	mock.Mock
 }
 
-func (m *VoteRepository) Vote(ctx context.Context, pollID uuid.UUID, optionID uuid.UUID, userID uuid.UUID) error {
+func (m *VoteRepository) SaveVotes(ctx context.Context, ballots []domain.Ballot) ([]error, error) {
 
-	args := m.Called(ctx, pollID, optionID, userID)
+	args := m.Called(ctx, ballots)
 
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]error), args.Error(1)
 }
 
 func (m *VoteRepository) ExistsByPollIDAndAndUserID(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) (bool, error) {
@@ -131,4 +206,532 @@ func (m *VoteRepository) ExistsByPollIDAndAndUserID(ctx context.Context, pollID
 	args := m.Called(ctx, pollID, userID)
 
 	return args.Bool(0), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *VoteRepository) FindVotesByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Vote, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Vote), args.Error(1)
+}
+
+func (m *VoteRepository) DeleteByPollID(ctx context.Context, pollID uuid.UUID) error {
+	args := m.Called(ctx, pollID)
+	return args.Error(0)
+}
+
+func (m *VoteRepository) FindVotesByPollID(ctx context.Context, pollID uuid.UUID, after *domain.VoteCursor, limit int) ([]domain.Vote, error) {
+	args := m.Called(ctx, pollID, after, limit)
+	return args.Get(0).([]domain.Vote), args.Error(1)
+}
+
+func (m *VoteRepository) FindVoterIDs(ctx context.Context, pollID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, pollID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *VoteRepository) FindVotedUserIDs(ctx context.Context, pollID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, pollID, userIDs)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+// UserTokenRepository Mock
+type UserTokenRepository struct {
+	mock.Mock
+}
+
+func (m *UserTokenRepository) Save(ctx context.Context, token *domain.UserToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *UserTokenRepository) FindByHash(ctx context.Context, purpose domain.TokenPurpose, tokenHash string) (*domain.UserToken, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserToken), args.Error(1)
+}
+
+func (m *UserTokenRepository) MarkUsed(ctx context.Context, tokenID uuid.UUID) error {
+	args := m.Called(ctx, tokenID)
+	return args.Error(0)
+}
+
+func (m *UserTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose) error {
+	args := m.Called(ctx, userID, purpose)
+	return args.Error(0)
+}
+
+// IdentityRepository Mock
+type IdentityRepository struct {
+	mock.Mock
+}
+
+func (m *IdentityRepository) Save(ctx context.Context, identity *domain.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *IdentityRepository) FindByProviderAndSubject(ctx context.Context, provider string, subject string) (*domain.UserIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserIdentity), args.Error(1)
+}
+
+func (m *IdentityRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.UserIdentity, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.UserIdentity), args.Error(1)
+}
+
+func (m *IdentityRepository) SaveLoginState(ctx context.Context, state *domain.OIDCLoginState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func (m *IdentityRepository) ConsumeLoginState(ctx context.Context, provider string, stateHash string) (*domain.OIDCLoginState, error) {
+	args := m.Called(ctx, provider, stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OIDCLoginState), args.Error(1)
+}
+
+func (m *IdentityRepository) DeleteExpiredLoginStates(ctx context.Context, before time.Time) error {
+	args := m.Called(ctx, before)
+	return args.Error(0)
+}
+
+// TwoFactorRepository Mock
+type TwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *TwoFactorRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.TwoFactor, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TwoFactor), args.Error(1)
+}
+
+func (m *TwoFactorRepository) Save(ctx context.Context, twoFactor *domain.TwoFactor) error {
+	args := m.Called(ctx, twoFactor)
+	return args.Error(0)
+}
+
+func (m *TwoFactorRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *TwoFactorRepository) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	args := m.Called(ctx, userID, step)
+	return args.Error(0)
+}
+
+func (m *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	args := m.Called(ctx, userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Error(0)
+}
+
+// LoginThrottleRepository Mock
+type LoginThrottleRepository struct {
+	mock.Mock
+}
+
+func (m *LoginThrottleRepository) FindByKeys(ctx context.Context, keys []string) ([]domain.LoginThrottle, error) {
+	args := m.Called(ctx, keys)
+	return args.Get(0).([]domain.LoginThrottle), args.Error(1)
+}
+
+func (m *LoginThrottleRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	args := m.Called(ctx, key, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *LoginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	args := m.Called(ctx, key, until)
+	return args.Error(0)
+}
+
+func (m *LoginThrottleRepository) Reset(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// APIKeyRepository Mock
+type APIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *APIKeyRepository) Save(ctx context.Context, key *domain.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *APIKeyRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+
+func (m *APIKeyRepository) Revoke(ctx context.Context, keyID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, keyID, userID)
+	return args.Error(0)
+}
+
+func (m *APIKeyRepository) TouchLastUsed(ctx context.Context, keyID uuid.UUID) error {
+	args := m.Called(ctx, keyID)
+	return args.Error(0)
+}
+
+// DataExportRepository Mock
+type DataExportRepository struct {
+	mock.Mock
+}
+
+func (m *DataExportRepository) Save(ctx context.Context, export *domain.DataExport) error {
+	args := m.Called(ctx, export)
+	return args.Error(0)
+}
+
+func (m *DataExportRepository) FindByID(ctx context.Context, exportID uuid.UUID) (*domain.DataExport, error) {
+	args := m.Called(ctx, exportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DataExport), args.Error(1)
+}
+
+func (m *DataExportRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID) (*domain.DataExport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DataExport), args.Error(1)
+}
+
+func (m *DataExportRepository) UpdateStatus(ctx context.Context, exportID uuid.UUID, status domain.DataExportStatus) error {
+	args := m.Called(ctx, exportID, status)
+	return args.Error(0)
+}
+
+func (m *DataExportRepository) MarkReady(ctx context.Context, exportID uuid.UUID, fileName string, expiresAt time.Time) error {
+	args := m.Called(ctx, exportID, fileName, expiresAt)
+	return args.Error(0)
+}
+
+func (m *DataExportRepository) FindExpired(ctx context.Context, now time.Time) ([]domain.DataExport, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]domain.DataExport), args.Error(1)
+}
+
+// OrganizationRepository Mock
+type OrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *OrganizationRepository) Create(ctx context.Context, organization *domain.Organization, ownerID uuid.UUID) error {
+	args := m.Called(ctx, organization, ownerID)
+	return args.Error(0)
+}
+
+func (m *OrganizationRepository) FindByID(ctx context.Context, organizationID uuid.UUID) (*domain.Organization, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Organization), args.Error(1)
+}
+
+func (m *OrganizationRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Organization, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Organization), args.Error(1)
+}
+
+func (m *OrganizationRepository) FindMember(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) (*domain.OrganizationMember, error) {
+	args := m.Called(ctx, organizationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OrganizationMember), args.Error(1)
+}
+
+func (m *OrganizationRepository) AddMember(ctx context.Context, member *domain.OrganizationMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *OrganizationRepository) UpdateMemberRole(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID, role domain.OrganizationRole) error {
+	args := m.Called(ctx, organizationID, userID, role)
+	return args.Error(0)
+}
+
+func (m *OrganizationRepository) RemoveMember(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, organizationID, userID)
+	return args.Error(0)
+}
+
+func (m *OrganizationRepository) CountOwners(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// PollInviteRepository Mock
+type PollInviteRepository struct {
+	mock.Mock
+}
+
+func (m *PollInviteRepository) SaveAll(ctx context.Context, invites []domain.PollInvite) error {
+	args := m.Called(ctx, invites)
+	return args.Error(0)
+}
+
+func (m *PollInviteRepository) FindByPollID(ctx context.Context, pollID uuid.UUID) ([]domain.PollInvite, error) {
+	args := m.Called(ctx, pollID)
+	return args.Get(0).([]domain.PollInvite), args.Error(1)
+}
+
+func (m *PollInviteRepository) FindForUser(ctx context.Context, pollID uuid.UUID, userID uuid.UUID, email string) (*domain.PollInvite, error) {
+	args := m.Called(ctx, pollID, userID, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PollInvite), args.Error(1)
+}
+
+func (m *PollInviteRepository) Claim(ctx context.Context, inviteID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, inviteID, userID)
+	return args.Error(0)
+}
+
+func (m *PollInviteRepository) Delete(ctx context.Context, pollID uuid.UUID, inviteID uuid.UUID) error {
+	args := m.Called(ctx, pollID, inviteID)
+	return args.Error(0)
+}
+
+// PollPermissionRepository Mock
+type PollPermissionRepository struct {
+	mock.Mock
+}
+
+func (m *PollPermissionRepository) Add(ctx context.Context, permission *domain.PollPermission) error {
+	args := m.Called(ctx, permission)
+	return args.Error(0)
+}
+
+func (m *PollPermissionRepository) FindByPollID(ctx context.Context, pollID uuid.UUID) ([]domain.PollPermission, error) {
+	args := m.Called(ctx, pollID)
+	return args.Get(0).([]domain.PollPermission), args.Error(1)
+}
+
+func (m *PollPermissionRepository) Find(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) (*domain.PollPermission, error) {
+	args := m.Called(ctx, pollID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PollPermission), args.Error(1)
+}
+
+func (m *PollPermissionRepository) UpdateRole(ctx context.Context, pollID uuid.UUID, userID uuid.UUID, role domain.PollRole) error {
+	args := m.Called(ctx, pollID, userID, role)
+	return args.Error(0)
+}
+
+func (m *PollPermissionRepository) Remove(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, pollID, userID)
+	return args.Error(0)
+}
+
+// WebhookRepository Mock
+type WebhookRepository struct {
+	mock.Mock
+}
+
+func (m *WebhookRepository) Save(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) FindByID(ctx context.Context, webhookID uuid.UUID) (*domain.Webhook, error) {
+	args := m.Called(ctx, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *WebhookRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Webhook, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *WebhookRepository) FindForPoll(ctx context.Context, pollID uuid.UUID, creatorID uuid.UUID) ([]domain.Webhook, error) {
+	args := m.Called(ctx, pollID, creatorID)
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *WebhookRepository) Delete(ctx context.Context, webhookID uuid.UUID) error {
+	args := m.Called(ctx, webhookID)
+	return args.Error(0)
+}
+
+// WebhookDeliveryRepository Mock
+type WebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *WebhookDeliveryRepository) SaveAll(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *WebhookDeliveryRepository) FindByID(ctx context.Context, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *WebhookDeliveryRepository) FindByWebhookID(ctx context.Context, webhookID uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *WebhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *WebhookDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	args := m.Called(ctx, before)
+	return args.Error(0)
+}
+
+// OutboxRepository Mock
+type OutboxRepository struct {
+	mock.Mock
+}
+
+func (m *OutboxRepository) ClaimUnprocessed(ctx context.Context, consumer domain.OutboxConsumer, now time.Time, lease time.Duration, limit int) ([]domain.OutboxEvent, error) {
+	args := m.Called(ctx, consumer, now, lease, limit)
+	return args.Get(0).([]domain.OutboxEvent), args.Error(1)
+}
+
+func (m *OutboxRepository) MarkProcessed(ctx context.Context, consumer domain.OutboxConsumer, eventID uuid.UUID, processedAt time.Time) error {
+	args := m.Called(ctx, consumer, eventID, processedAt)
+	return args.Error(0)
+}
+
+func (m *OutboxRepository) RecordFailure(ctx context.Context, consumer domain.OutboxConsumer, eventID uuid.UUID, lastError string, now time.Time, maxAttempts int) error {
+	args := m.Called(ctx, consumer, eventID, lastError, now, maxAttempts)
+	return args.Error(0)
+}
+
+func (m *OutboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) error {
+	args := m.Called(ctx, before)
+	return args.Error(0)
+}
+
+// NotificationRepository Mock
+type NotificationRepository struct {
+	mock.Mock
+}
+
+func (m *NotificationRepository) FindPreferences(ctx context.Context, userIDs []uuid.UUID) ([]domain.NotificationPreference, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).([]domain.NotificationPreference), args.Error(1)
+}
+
+func (m *NotificationRepository) SavePreferences(ctx context.Context, preferences []domain.NotificationPreference) error {
+	args := m.Called(ctx, preferences)
+	return args.Error(0)
+}
+
+func (m *NotificationRepository) SaveEmails(ctx context.Context, emails []domain.EmailNotification) error {
+	args := m.Called(ctx, emails)
+	return args.Error(0)
+}
+
+func (m *NotificationRepository) ClaimDueEmails(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.EmailNotification, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]domain.EmailNotification), args.Error(1)
+}
+
+func (m *NotificationRepository) ClaimDueDigests(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.EmailNotification, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]domain.EmailNotification), args.Error(1)
+}
+
+func (m *NotificationRepository) UpdateEmails(ctx context.Context, emails []domain.EmailNotification) error {
+	args := m.Called(ctx, emails)
+	return args.Error(0)
+}
+
+func (m *NotificationRepository) DeleteEmailsBefore(ctx context.Context, before time.Time) error {
+	args := m.Called(ctx, before)
+	return args.Error(0)
+}
+
+func (m *NotificationRepository) SaveNotifications(ctx context.Context, notifications []domain.Notification) error {
+	args := m.Called(ctx, notifications)
+	return args.Error(0)
+}
+
+func (m *NotificationRepository) FindNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]domain.Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly, limit)
+	return args.Get(0).([]domain.Notification), args.Error(1)
+}
+
+func (m *NotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *NotificationRepository) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID, readAt time.Time) error {
+	args := m.Called(ctx, userID, notificationID, readAt)
+	return args.Error(0)
+}
+
+func (m *NotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID, readAt time.Time) error {
+	args := m.Called(ctx, userID, readAt)
+	return args.Error(0)
+}
+
+func (m *NotificationRepository) DeleteReadBefore(ctx context.Context, before time.Time) error {
+	args := m.Called(ctx, before)
+	return args.Error(0)
+}
+
+// UnitOfWork Mock runs the work with the context it was given, as if it
+// commits unless Do is set up to fail.
+type UnitOfWork struct {
+	mock.Mock
+}
+
+func (m *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
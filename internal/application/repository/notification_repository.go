@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type NotificationRepository interface {
+	// FindPreferences returns the preferences the given users have set.
+	// Kinds without a row use their default delivery.
+	FindPreferences(ctx context.Context, userIDs []uuid.UUID) ([]domain.NotificationPreference, error)
+
+	SavePreferences(ctx context.Context, preferences []domain.NotificationPreference) error
+
+	// SaveEmails queues the emails, skipping any a recipient already has
+	// for the same event.
+	SaveEmails(ctx context.Context, emails []domain.EmailNotification) error
+
+	// ClaimDueEmails takes up to limit pending emails that are not part of a
+	// digest and are due by now, pushing them back by lease while they are
+	// sent.
+	ClaimDueEmails(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.EmailNotification, error)
+
+	// ClaimDueDigests takes every due digest email of up to limit
+	// recipients, pushing them back by lease while they are sent.
+	ClaimDueDigests(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.EmailNotification, error)
+
+	// UpdateEmails stores the outcome of sending the emails.
+	UpdateEmails(ctx context.Context, emails []domain.EmailNotification) error
+
+	// DeleteEmailsBefore removes finished emails created before the given
+	// time.
+	DeleteEmailsBefore(ctx context.Context, before time.Time) error
+
+	// SaveNotifications adds the notifications to their users' inboxes,
+	// skipping any a user already has for the same event.
+	SaveNotifications(ctx context.Context, notifications []domain.Notification) error
+
+	// FindNotifications returns the user's newest notifications first. A
+	// limit below 1 returns all of them.
+	FindNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]domain.Notification, error)
+
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// MarkRead marks one of the user's notifications read, returning
+	// utils.NotificationNotFoundError when the user has no such
+	// notification.
+	MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID, readAt time.Time) error
+
+	MarkAllRead(ctx context.Context, userID uuid.UUID, readAt time.Time) error
+
+	// DeleteReadBefore removes notifications read before the given time.
+	DeleteReadBefore(ctx context.Context, before time.Time) error
+}
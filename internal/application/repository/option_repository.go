@@ -10,5 +10,8 @@ import (
 type OptionRepository interface {
 	Save(ctx context.Context, option *[]domain.Option) error
 
+	// FindOptionsByPollID returns the poll's options with their vote counts.
 	FindOptionsByPollID(ctx context.Context, pollID uuid.UUID) (*[]domain.Option, error)
+
+	DeleteByPollID(ctx context.Context, pollID uuid.UUID) error
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type OrganizationRepository interface {
+	// Create saves the organization together with its first owner.
+	Create(ctx context.Context, organization *domain.Organization, ownerID uuid.UUID) error
+
+	// FindByID returns the organization with its members and their users.
+	FindByID(ctx context.Context, organizationID uuid.UUID) (*domain.Organization, error)
+
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Organization, error)
+
+	FindMember(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) (*domain.OrganizationMember, error)
+
+	AddMember(ctx context.Context, member *domain.OrganizationMember) error
+
+	UpdateMemberRole(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID, role domain.OrganizationRole) error
+
+	RemoveMember(ctx context.Context, organizationID uuid.UUID, userID uuid.UUID) error
+
+	CountOwners(ctx context.Context, organizationID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type OutboxRepository interface {
+	// ClaimUnprocessed takes up to limit events the consumer has not
+	// processed or given up yet, oldest first, and locks them for lease so
+	// no other worker hands them to the consumer meanwhile.
+	ClaimUnprocessed(ctx context.Context, consumer domain.OutboxConsumer, now time.Time, lease time.Duration, limit int) ([]domain.OutboxEvent, error)
+
+	MarkProcessed(ctx context.Context, consumer domain.OutboxConsumer, eventID uuid.UUID, processedAt time.Time) error
+
+	// RecordFailure counts a failed attempt of the consumer at the event,
+	// which stays locked until its lease runs out, and gives the event up
+	// at now once it has been attempted maxAttempts times.
+	RecordFailure(ctx context.Context, consumer domain.OutboxConsumer, eventID uuid.UUID, lastError string, now time.Time, maxAttempts int) error
+
+	// DeleteProcessedBefore removes events every consumer processed or gave
+	// up, and that were created before the given time.
+	DeleteProcessedBefore(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type PollInviteRepository interface {
+	// SaveAll stores the invites, skipping addresses already invited, and
+	// records a poll.invited outbox event for each one it stored.
+	SaveAll(ctx context.Context, invites []domain.PollInvite) error
+
+	FindByPollID(ctx context.Context, pollID uuid.UUID) ([]domain.PollInvite, error)
+
+	// FindForUser returns the invite matching the user's id or, when email is
+	// not empty, their address.
+	FindForUser(ctx context.Context, pollID uuid.UUID, userID uuid.UUID, email string) (*domain.PollInvite, error)
+
+	// Claim links an invite sent to an address to the account that used it.
+	Claim(ctx context.Context, inviteID uuid.UUID, userID uuid.UUID) error
+
+	Delete(ctx context.Context, pollID uuid.UUID, inviteID uuid.UUID) error
+}
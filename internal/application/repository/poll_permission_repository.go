@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type PollPermissionRepository interface {
+	Add(ctx context.Context, permission *domain.PollPermission) error
+
+	// FindByPollID lists the poll's collaborators with their users.
+	FindByPollID(ctx context.Context, pollID uuid.UUID) ([]domain.PollPermission, error)
+
+	Find(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) (*domain.PollPermission, error)
+
+	UpdateRole(ctx context.Context, pollID uuid.UUID, userID uuid.UUID, role domain.PollRole) error
+
+	Remove(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) error
+}
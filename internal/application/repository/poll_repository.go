@@ -2,19 +2,39 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/domain"
 )
 
+// PollRepository returns polls with their options, each carrying its vote
+// count. Votes themselves are listed through VoteRepository.
 type PollRepository interface {
 	FindUserPollCount(ctx context.Context, userID uuid.UUID) (int, error)
 
+	// Save records a poll.created outbox event with the poll.
 	Save(ctx context.Context, poll *domain.Poll) error
 
 	FindPollByID(ctx context.Context, pollID uuid.UUID) (*domain.Poll, error)
 
+	// Delete records a poll.deleted outbox event with the deletion.
 	Delete(ctx context.Context, pollID uuid.UUID) error
 
 	FindAllPolls(ctx context.Context) ([]domain.Poll, error)
+
+	// FindPollsByUserID returns the user's polls with their options.
+	FindPollsByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Poll, error)
+
+	FindPollsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]domain.Poll, error)
+
+	// CloseExpired marks up to limit polls that expired by now as closed,
+	// recording a poll.closed outbox event for each, and returns how many it
+	// closed. Each poll is closed by exactly one call.
+	CloseExpired(ctx context.Context, now time.Time, limit int) (int, error)
+
+	// MarkExpiring flags up to limit open polls that close within window and
+	// have been running for at least as long, recording a poll.expiring
+	// outbox event for each. It returns how many it flagged.
+	MarkExpiring(ctx context.Context, now time.Time, window time.Duration, limit int) (int, error)
 }
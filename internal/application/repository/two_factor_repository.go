@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type TwoFactorRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.TwoFactor, error)
+
+	Save(ctx context.Context, twoFactor *domain.TwoFactor) error
+
+	Delete(ctx context.Context, userID uuid.UUID) error
+
+	// UpdateLastUsedStep records the TOTP time step that was just accepted,
+	// failing if that step or a later one was already used.
+	UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+}
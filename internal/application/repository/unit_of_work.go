@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// UnitOfWork runs work spanning several repositories atomically.
+type UnitOfWork interface {
+	// Do runs fn in a transaction. Repository calls made with the context
+	// fn is given are part of it; they are committed if fn returns nil and
+	// rolled back otherwise.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
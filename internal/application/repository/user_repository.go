@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/domain"
@@ -10,9 +11,35 @@ import (
 type UserRepository interface {
 	FindById(ctx context.Context, userID uuid.UUID) (domain.User, error)
 
+	// FindByIDs returns the users that still exist among userIDs.
+	FindByIDs(ctx context.Context, userIDs []uuid.UUID) ([]domain.User, error)
+
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 
 	FindByEmail(ctx context.Context, email string) (domain.User, error)
 
 	Save(ctx context.Context, user *domain.User) error
+
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+
+	// UpdatePassword sets the password, which makes a passwordless account
+	// an ordinary one.
+	UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error
+
+	// RecordProviderSignIn notes that the user has just signed in through an
+	// identity provider.
+	RecordProviderSignIn(ctx context.Context, userID uuid.UUID, at time.Time) error
+
+	UpdateUsername(ctx context.Context, userID uuid.UUID, username string) error
+
+	// UpdateEmail changes the address and marks it verified, so it must only
+	// be called once the new address has been confirmed.
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+
+	UpdateProfilePicture(ctx context.Context, userID uuid.UUID, profilePicture string) error
+
+	// DeleteAccount scrubs and soft-deletes the user, ends their sessions and,
+	// depending on mode, removes their polls and votes in one transaction.
+	// Removed polls get a poll.deleted outbox event like any other deletion.
+	DeleteAccount(ctx context.Context, userID uuid.UUID, mode domain.AccountDeletionMode) error
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type UserTokenRepository interface {
+	Save(ctx context.Context, token *domain.UserToken) error
+
+	FindByHash(ctx context.Context, purpose domain.TokenPurpose, tokenHash string) (*domain.UserToken, error)
+
+	// MarkUsed consumes the token, failing if it has already been used.
+	MarkUsed(ctx context.Context, tokenID uuid.UUID) error
+
+	DeleteByUserID(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose) error
+}
@@ -4,10 +4,30 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
 )
 
 type VoteRepository interface {
-	Vote(ctx context.Context, pollID uuid.UUID, optionID uuid.UUID, userID uuid.UUID) error
+	// SaveVotes records the ballots with a single insert, each with a
+	// vote.cast outbox event, and returns the outcome of each in order: nil
+	// once recorded, VoteAlreadyExistsError when the user has a vote in the
+	// poll, or PollExpiredError when the poll has closed or is gone. The
+	// error is set when none of them could be recorded.
+	SaveVotes(ctx context.Context, ballots []domain.Ballot) ([]error, error)
 
 	ExistsByPollIDAndAndUserID(ctx context.Context, pollID uuid.UUID, userID uuid.UUID) (bool, error)
+
+	FindVotesByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Vote, error)
+
+	DeleteByPollID(ctx context.Context, pollID uuid.UUID) error
+
+	// FindVotesByPollID returns up to limit of the poll's votes in the order
+	// they were cast, starting after the cursor when one is given.
+	FindVotesByPollID(ctx context.Context, pollID uuid.UUID, after *domain.VoteCursor, limit int) ([]domain.Vote, error)
+
+	// FindVoterIDs returns everyone who voted in the poll.
+	FindVoterIDs(ctx context.Context, pollID uuid.UUID) ([]uuid.UUID, error)
+
+	// FindVotedUserIDs returns which of the users voted in the poll.
+	FindVotedUserIDs(ctx context.Context, pollID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error)
 }
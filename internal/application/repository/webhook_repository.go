@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type WebhookRepository interface {
+	Save(ctx context.Context, webhook *domain.Webhook) error
+
+	FindByID(ctx context.Context, webhookID uuid.UUID) (*domain.Webhook, error)
+
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Webhook, error)
+
+	// FindForPoll returns the webhooks registered on the poll together with
+	// the account-wide webhooks of its creator.
+	FindForPoll(ctx context.Context, pollID uuid.UUID, creatorID uuid.UUID) ([]domain.Webhook, error)
+
+	Delete(ctx context.Context, webhookID uuid.UUID) error
+}
+
+type WebhookDeliveryRepository interface {
+	// SaveAll queues the deliveries, skipping any whose ID is already
+	// taken.
+	SaveAll(ctx context.Context, deliveries []domain.WebhookDelivery) error
+
+	FindByID(ctx context.Context, deliveryID uuid.UUID) (*domain.WebhookDelivery, error)
+
+	// FindByWebhookID returns the webhook's latest deliveries, newest first.
+	FindByWebhookID(ctx context.Context, webhookID uuid.UUID, limit int) ([]domain.WebhookDelivery, error)
+
+	// ClaimDue takes up to limit pending deliveries that are due by now and
+	// pushes their next attempt back by lease, so no other worker sends
+	// them meanwhile. A worker that dies mid-send leaves them to be retried
+	// once the lease runs out.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+
+	// Update stores the outcome of an attempt.
+	Update(ctx context.Context, delivery *domain.WebhookDelivery) error
+
+	// DeleteBefore removes finished deliveries created before the given time.
+	DeleteBefore(ctx context.Context, before time.Time) error
+}
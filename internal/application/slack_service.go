@@ -0,0 +1,29 @@
+package application
+
+import (
+	"context"
+
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type SlackService interface {
+	// VerifyRequest checks the signature Slack puts on every request
+	// against the raw body.
+	VerifyRequest(timestamp string, signature string, body []byte) error
+
+	// HandleCommand creates a poll from the /jille slash command form and
+	// returns the message to post in the channel. Usage mistakes are
+	// answered with a message only the user sees.
+	HandleCommand(ctx context.Context, body []byte) (*dto.SlackMessage, error)
+
+	// HandleInteraction casts the vote behind a button click and updates
+	// the message through its response_url. A vote counts even if updating
+	// the message failed.
+	HandleInteraction(ctx context.Context, body []byte) error
+}
+
+// SlackResponder sends messages to the response_url Slack hands out with
+// commands and interactions.
+type SlackResponder interface {
+	Respond(ctx context.Context, responseURL string, message dto.SlackMessage) error
+}
@@ -0,0 +1,520 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// slackIdentityProvider links Slack users to accounts. It matches the
+	// provider name "Sign in with Slack" is configured under, so people
+	// who signed in that way vote as themselves.
+	slackIdentityProvider = "slack"
+
+	// Slack signs the time of the request too, older requests are
+	// rejected as replays
+	slackRequestMaxAge = time.Minute * 5
+
+	slackDefaultDuration = time.Hour * 24
+	slackMaxDuration     = time.Hour * 24 * 30
+
+	slackVoteAction = "vote"
+	slackBarWidth   = 12
+)
+
+const slackUsage = "Create a poll with `/jille \"Question?\" \"Option 1\" \"Option 2\"`, or `/jille Question? | Option 1 | Option 2`. " +
+	"Add a duration such as `2h` or `3d` after the quoted options to close it sooner or later than a day."
+
+type slackservice struct {
+	identityrepo repository.IdentityRepository
+	pollrepo     repository.PollRepository
+	userservice  UserService
+	pollservice  PollService
+	voteservice  VoteService
+	responder    SlackResponder
+
+	signingSecret string
+	appURL        string
+
+	now func() time.Time
+}
+
+func NewSlackService(identityrepo repository.IdentityRepository, pollrepo repository.PollRepository, userservice UserService, pollservice PollService, voteservice VoteService, responder SlackResponder, signingSecret string, appURL string) SlackService {
+	return &slackservice{
+		identityrepo:  identityrepo,
+		pollrepo:      pollrepo,
+		userservice:   userservice,
+		pollservice:   pollservice,
+		voteservice:   voteservice,
+		responder:     responder,
+		signingSecret: signingSecret,
+		appURL:        appURL,
+		now:           time.Now,
+	}
+}
+
+func (s *slackservice) VerifyRequest(timestamp string, signature string, body []byte) error {
+
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return utils.InvalidSlackSignatureError
+	}
+
+	if age := s.now().Sub(time.Unix(sent, 0)); age > slackRequestMaxAge || age < -slackRequestMaxAge {
+		return utils.InvalidSlackSignatureError
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return utils.InvalidSlackSignatureError
+	}
+
+	return nil
+}
+
+func (s *slackservice) HandleCommand(ctx context.Context, body []byte) (*dto.SlackMessage, error) {
+
+	form, err := url.ParseQuery(string(body))
+
+	if err != nil || form.Get("user_id") == "" || form.Get("team_id") == "" {
+		return nil, utils.InvalidSlackRequestError
+	}
+
+	text := strings.TrimSpace(form.Get("text"))
+
+	if text == "" || strings.EqualFold(text, "help") {
+		return slackEphemeral(slackUsage), nil
+	}
+
+	command, err := parseSlackCommand(text)
+
+	if err != nil {
+		return slackEphemeral(err.Error() + "\n" + slackUsage), nil
+	}
+
+	pollRequest := dto.CreatePollRequest{
+		Title:     command.title,
+		Options:   command.options,
+		ExpiresAt: s.now().Add(command.duration),
+
+		// everyone in the channel sees the message, so everyone sees the
+		// tally
+		ResultsVisibility: string(domain.ResultsVisibilityAlways),
+	}
+
+	if err := (utils.XValidator{}).Validate(pollRequest); err != nil {
+		return slackEphemeral(err.Error() + "\n" + slackUsage), nil
+	}
+
+	userID, err := s.resolveUser(ctx, form.Get("team_id"), form.Get("user_id"), form.Get("user_name"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, "userID", userID.String())
+
+	pollID, err := s.pollservice.CreatePoll(ctx, &pollRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	poll, err := s.pollrepo.FindPollByID(ctx, pollID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	message := s.pollMessage(poll)
+	message.ResponseType = "in_channel"
+
+	return &message, nil
+}
+
+func (s *slackservice) HandleInteraction(ctx context.Context, body []byte) error {
+
+	form, err := url.ParseQuery(string(body))
+
+	if err != nil {
+		return utils.InvalidSlackRequestError
+	}
+
+	var interaction dto.SlackInteraction
+
+	if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil {
+		return utils.InvalidSlackRequestError
+	}
+
+	if interaction.Type != "block_actions" {
+		return nil
+	}
+
+	var action *dto.SlackAction
+
+	for i := range interaction.Actions {
+		if interaction.Actions[i].ActionID == slackVoteAction {
+			action = &interaction.Actions[i]
+			break
+		}
+	}
+
+	if action == nil {
+		return nil
+	}
+
+	rawPollID, rawOptionID, _ := strings.Cut(action.Value, ":")
+
+	pollID, err := uuid.Parse(rawPollID)
+
+	if err != nil {
+		return utils.InvalidSlackRequestError
+	}
+
+	optionID, err := uuid.Parse(rawOptionID)
+
+	if err != nil {
+		return utils.InvalidSlackRequestError
+	}
+
+	teamID := interaction.Team.ID
+
+	if teamID == "" {
+		teamID = interaction.User.TeamID
+	}
+
+	userID, err := s.resolveUser(ctx, teamID, interaction.User.ID, interaction.User.Username)
+
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, "userID", userID.String())
+
+	voteRequest := dto.VoteRequest{
+		PollID:   pollID.String(),
+		OptionID: optionID.String(),
+	}
+
+	if _, err := s.voteservice.VotePoll(ctx, voteRequest); err != nil {
+
+		// votes the poll turns down are explained to the voter only
+		for _, rejected := range []error{utils.VoteAlreadyExistsError, utils.PollExpiredError, utils.OptionNotFound, utils.PollNotFoundError, utils.MembersOnlyPollError, utils.PollNotInvitedError} {
+			if errors.Is(err, rejected) {
+				return s.responder.Respond(ctx, interaction.ResponseURL, *slackEphemeral(err.Error()))
+			}
+		}
+
+		return err
+	}
+
+	poll, err := s.pollrepo.FindPollByID(ctx, pollID)
+
+	if err != nil {
+		fmt.Println("error loading slack poll", pollID, err.Error())
+		return nil
+	}
+
+	message := s.pollMessage(poll)
+	message.ReplaceOriginal = true
+
+	if err := s.responder.Respond(ctx, interaction.ResponseURL, message); err != nil {
+		fmt.Println("error updating slack poll message", pollID, err.Error())
+	}
+
+	return nil
+}
+
+// resolveUser returns the account linked to the Slack user. Slack users
+// without one vote as a guest account that is created for them and linked
+// the same way.
+func (s *slackservice) resolveUser(ctx context.Context, teamID string, slackUserID string, username string) (uuid.UUID, error) {
+
+	if teamID == "" || slackUserID == "" {
+		return uuid.Nil, utils.InvalidSlackRequestError
+	}
+
+	// user IDs are only unique within a workspace, so the same ID in
+	// another workspace is someone else
+	subject := teamID + ":" + slackUserID
+
+	identity, err := s.identityrepo.FindByProviderAndSubject(ctx, slackIdentityProvider, subject)
+
+	if err == nil {
+		return identity.UserID, nil
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		return uuid.Nil, err
+	}
+
+	// guests get a random password and an address that cannot receive
+	// mail, so the account is only usable from Slack
+	randomPassword, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), passwordHashCost)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if username == "" {
+		username = slackUserID
+	}
+
+	guest := domain.User{
+		Username:     username,
+		Email:        strings.ToLower(slackUserID+"."+teamID) + "@slack.invalid",
+		Password:     string(hashedPassword),
+		Passwordless: true,
+		JoinedAt:     s.now(),
+	}
+
+	if err := s.userservice.CreateUser(ctx, &guest); err != nil {
+		return uuid.Nil, err
+	}
+
+	err = s.identityrepo.Save(ctx, &domain.UserIdentity{
+		UserID:   guest.ID,
+		Provider: slackIdentityProvider,
+		Subject:  subject,
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return guest.ID, nil
+}
+
+// pollMessage renders the poll with a vote button per option while it is
+// open, and the tally when the poll's results visibility shows it to
+// everyone.
+func (s *slackservice) pollMessage(poll *domain.Poll) dto.SlackMessage {
+
+	now := s.now()
+	open := poll.ExpiresAt.After(now)
+	showTally := poll.ResultsVisibility == domain.ResultsVisibilityAlways ||
+		(poll.ResultsVisibility == domain.ResultsVisibilityAfterClose && !open)
+
+	total := 0
+
+	for _, option := range poll.Options {
+		total += option.VoteCount
+	}
+
+	message := dto.SlackMessage{
+		Text: poll.Title,
+		Blocks: []dto.SlackBlock{
+			{Type: "section", Text: &dto.SlackText{Type: "mrkdwn", Text: "*" + slackEscape(poll.Title) + "*"}},
+		},
+	}
+
+	for _, option := range poll.Options {
+
+		text := "*" + slackEscape(option.Name) + "*"
+
+		if showTally {
+			text += "\n" + slackTally(option.VoteCount, total)
+		}
+
+		block := dto.SlackBlock{
+			Type:    "section",
+			BlockID: "option:" + option.ID.String(),
+			Text:    &dto.SlackText{Type: "mrkdwn", Text: text},
+		}
+
+		if open {
+			block.Accessory = &dto.SlackButton{
+				Type:     "button",
+				Text:     dto.SlackText{Type: "plain_text", Text: "Vote"},
+				ActionID: slackVoteAction,
+				Value:    poll.ID.String() + ":" + option.ID.String(),
+			}
+		}
+
+		message.Blocks = append(message.Blocks, block)
+	}
+
+	status := "Closed"
+
+	if open {
+		status = fmt.Sprintf("Closes <!date^%d^{date_short_pretty} at {time}|%s>", poll.ExpiresAt.Unix(), poll.ExpiresAt.UTC().Format(time.RFC1123))
+	}
+
+	if showTally {
+		status = fmt.Sprintf("%d %s · %s", total, plural(total, "vote", "votes"), status)
+	}
+
+	message.Blocks = append(message.Blocks, dto.SlackBlock{
+		Type: "context",
+		Elements: []dto.SlackText{
+			{Type: "mrkdwn", Text: status},
+			{Type: "mrkdwn", Text: fmt.Sprintf("<%s/polls/%s|Open in Jille>", s.appURL, poll.ID)},
+		},
+	})
+
+	return message
+}
+
+type slackPollCommand struct {
+	title    string
+	options  []string
+	duration time.Duration
+}
+
+// parseSlackCommand reads `"Question?" "Option 1" "Option 2" [duration]` or
+// `Question? | Option 1 | Option 2`.
+func parseSlackCommand(text string) (*slackPollCommand, error) {
+
+	// Slack clients often turn straight quotes into curly ones
+	text = strings.NewReplacer("“", `"`, "”", `"`).Replace(text)
+
+	command := &slackPollCommand{duration: slackDefaultDuration}
+
+	if !strings.Contains(text, `"`) {
+
+		for _, part := range strings.Split(text, "|") {
+			if part = strings.TrimSpace(part); part != "" {
+				command.options = append(command.options, part)
+			}
+		}
+
+		if len(command.options) < 3 {
+			return nil, errors.New("A poll needs a question and at least two options.")
+		}
+
+		command.title, command.options = command.options[0], command.options[1:]
+
+		return command, nil
+	}
+
+	var quoted []string
+	durationSet := false
+
+	for rest := strings.TrimSpace(text); rest != ""; rest = strings.TrimSpace(rest) {
+
+		if rest[0] == '"' {
+
+			value, after, ok := strings.Cut(rest[1:], `"`)
+
+			if !ok {
+				return nil, errors.New("A quote is not closed.")
+			}
+
+			if value = strings.TrimSpace(value); value != "" {
+				quoted = append(quoted, value)
+			}
+
+			rest = after
+			continue
+		}
+
+		word, after, _ := strings.Cut(rest, " ")
+
+		duration, err := parseSlackDuration(word)
+
+		if err != nil || durationSet {
+			return nil, fmt.Errorf("Put the question and each option in quotes, `%s` is neither.", word)
+		}
+
+		command.duration = duration
+		durationSet = true
+		rest = after
+	}
+
+	if len(quoted) < 3 {
+		return nil, errors.New("A poll needs a question and at least two options.")
+	}
+
+	command.title, command.options = quoted[0], quoted[1:]
+
+	return command, nil
+}
+
+// parseSlackDuration accepts Go durations and whole days such as 3d.
+func parseSlackDuration(value string) (time.Duration, error) {
+
+	var duration time.Duration
+	var err error
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		duration = time.Duration(n) * time.Hour * 24
+	} else {
+		duration, err = time.ParseDuration(value)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if duration < time.Minute || duration > slackMaxDuration {
+		return 0, errors.New("duration out of range")
+	}
+
+	return duration, nil
+}
+
+func slackTally(votes int, total int) string {
+
+	filled := 0
+	share := 0.0
+
+	if total > 0 {
+		share = float64(votes) / float64(total)
+		filled = int(share*slackBarWidth + 0.5)
+	}
+
+	return fmt.Sprintf("`%s%s` %d (%.0f%%)", strings.Repeat("█", filled), strings.Repeat("░", slackBarWidth-filled), votes, share*100)
+}
+
+// slackEscape escapes the characters mrkdwn treats as markup for links and
+// mentions.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func slackEphemeral(text string) *dto.SlackMessage {
+	return &dto.SlackMessage{
+		ResponseType: "ephemeral",
+		Text:         text,
+	}
+}
+
+func plural(n int, one string, many string) string {
+
+	if n == 1 {
+		return one
+	}
+
+	return many
+}
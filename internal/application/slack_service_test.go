@@ -0,0 +1,278 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type MockVoteService struct {
+	mock.Mock
+}
+
+func (m *MockVoteService) VotePoll(ctx context.Context, voteRequest dto.VoteRequest) (*dto.VoteResponse, error) {
+	args := m.Called(ctx, voteRequest)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.VoteResponse), args.Error(1)
+}
+
+type MockSlackResponder struct {
+	mock.Mock
+}
+
+func (m *MockSlackResponder) Respond(ctx context.Context, responseURL string, message dto.SlackMessage) error {
+	args := m.Called(ctx, responseURL, message)
+	return args.Error(0)
+}
+
+type slackTestDeps struct {
+	identityrepo *mocks.IdentityRepository
+	pollrepo     *mocks.PollRepository
+	userservice  *MockUserService
+	pollservice  *MockPollService
+	voteservice  *MockVoteService
+	responder    *MockSlackResponder
+}
+
+func newTestSlackService(now time.Time) (*slackservice, slackTestDeps) {
+	deps := slackTestDeps{
+		identityrepo: new(mocks.IdentityRepository),
+		pollrepo:     new(mocks.PollRepository),
+		userservice:  new(MockUserService),
+		pollservice:  new(MockPollService),
+		voteservice:  new(MockVoteService),
+		responder:    new(MockSlackResponder),
+	}
+
+	service := NewSlackService(deps.identityrepo, deps.pollrepo, deps.userservice, deps.pollservice, deps.voteservice, deps.responder, "8f742231b10e8888abcd99yyyzzz85a5", "https://jille.app").(*slackservice)
+	service.now = func() time.Time { return now }
+
+	return service, deps
+}
+
+// readSlackPayload returns a request body recorded from Slack.
+func readSlackPayload(t *testing.T, name string) []byte {
+	body, err := os.ReadFile("testdata/slack/" + name)
+	assert.NoError(t, err)
+	return body
+}
+
+func TestSlackVerifyRequest(t *testing.T) {
+	body := readSlackPayload(t, "signed_command.txt")
+	signature := "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		timestamp string
+		signature string
+		body      []byte
+		err       error
+	}{
+		{"valid signature", time.Unix(1531420618, 0), "1531420618", signature, body, nil},
+		{"tampered body", time.Unix(1531420618, 0), "1531420618", signature, append([]byte("x"), body...), utils.InvalidSlackSignatureError},
+		{"wrong signature", time.Unix(1531420618, 0), "1531420618", "v0=00", body, utils.InvalidSlackSignatureError},
+		{"replayed request", time.Unix(1531420618, 0).Add(10 * time.Minute), "1531420618", signature, body, utils.InvalidSlackSignatureError},
+		{"missing timestamp", time.Unix(1531420618, 0), "", signature, body, utils.InvalidSlackSignatureError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestSlackService(tt.now)
+
+			err := service.VerifyRequest(tt.timestamp, tt.signature, tt.body)
+
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSlackHandleCommand_CreatesPollForLinkedUser(t *testing.T) {
+	now := time.Now()
+	service, deps := newTestSlackService(now)
+
+	userID := uuid.New()
+	pollID := uuid.New()
+
+	deps.identityrepo.On("FindByProviderAndSubject", mock.Anything, "slack", "T1DC2JH3J:U2CERLKJA").Return(&domain.UserIdentity{UserID: userID}, nil)
+	deps.pollservice.On("CreatePoll", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value("userID") == userID.String()
+	}), mock.MatchedBy(func(pollRequest *dto.CreatePollRequest) bool {
+		return pollRequest.Title == "Where should we eat?" &&
+			assert.ObjectsAreEqual([]string{"Pizza", "Sushi", "Tacos"}, pollRequest.Options) &&
+			pollRequest.ExpiresAt.Equal(now.Add(2*time.Hour)) &&
+			pollRequest.ResultsVisibility == string(domain.ResultsVisibilityAlways)
+	})).Return(pollID, nil)
+	deps.pollrepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{
+		ID:                pollID,
+		Title:             "Where should we eat?",
+		ExpiresAt:         now.Add(2 * time.Hour),
+		ResultsVisibility: domain.ResultsVisibilityAlways,
+		Options: []domain.Option{
+			{ID: uuid.New(), Name: "Pizza"},
+			{ID: uuid.New(), Name: "Sushi"},
+			{ID: uuid.New(), Name: "Tacos"},
+		},
+	}, nil)
+
+	message, err := service.HandleCommand(context.Background(), readSlackPayload(t, "command.txt"))
+
+	assert.NoError(t, err)
+	deps.pollservice.AssertExpectations(t)
+	assert.Equal(t, "in_channel", message.ResponseType)
+	assert.Len(t, message.Blocks, 5)
+	assert.Equal(t, "vote", message.Blocks[1].Accessory.ActionID)
+	assert.Contains(t, message.Blocks[1].Text.Text, "0 (0%)")
+	deps.userservice.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestSlackHandleCommand_Usage(t *testing.T) {
+	service, deps := newTestSlackService(time.Now())
+
+	body := []byte("team_id=T1DC2JH3J&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fjille&text=%22Lunch%3F%22+%22Pizza%22")
+
+	message, err := service.HandleCommand(context.Background(), body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ephemeral", message.ResponseType)
+	assert.Contains(t, message.Text, "at least two options")
+	deps.pollservice.AssertNotCalled(t, "CreatePoll", mock.Anything, mock.Anything)
+}
+
+func TestSlackHandleInteraction_GuestVote(t *testing.T) {
+	now := time.Now()
+	service, deps := newTestSlackService(now)
+
+	pollID := uuid.MustParse("0b7e5c1a-3f2d-4e6b-8a9c-7d1e2f3a4b5c")
+	optionID := uuid.MustParse("6f1c2a9e-8d3b-4f5a-9c7e-1b2d3e4f5a6b")
+	guestID := uuid.New()
+
+	deps.identityrepo.On("FindByProviderAndSubject", mock.Anything, "slack", "T1DC2JH3J:U2CERLKJA").Return(nil, gorm.ErrRecordNotFound)
+	deps.userservice.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
+		return user.Username == "roadrunner" && user.Email == "u2cerlkja.t1dc2jh3j@slack.invalid"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.User).ID = guestID
+	}).Return(nil)
+	deps.identityrepo.On("Save", mock.Anything, mock.MatchedBy(func(identity *domain.UserIdentity) bool {
+		return identity.UserID == guestID && identity.Provider == "slack" && identity.Subject == "T1DC2JH3J:U2CERLKJA"
+	})).Return(nil)
+
+	voteRequest := dto.VoteRequest{PollID: pollID.String(), OptionID: optionID.String()}
+
+	deps.voteservice.On("VotePoll", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value("userID") == guestID.String()
+	}), voteRequest).Return(&dto.VoteResponse{Message: "Voted successfully"}, nil)
+	deps.pollrepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{
+		ID:                pollID,
+		Title:             "Where should we eat?",
+		ExpiresAt:         now.Add(time.Hour),
+		ResultsVisibility: domain.ResultsVisibilityAlways,
+		Options: []domain.Option{
+			{ID: optionID, Name: "Pizza", VoteCount: 1},
+			{ID: uuid.New(), Name: "Sushi"},
+		},
+	}, nil)
+
+	var updated dto.SlackMessage
+	deps.responder.On("Respond", mock.Anything, "https://hooks.slack.com/actions/T1DC2JH3J/7331557702291/J7dzqXlIiHdKq5JzqDmXKJnV", mock.Anything).Run(func(args mock.Arguments) {
+		updated = args.Get(2).(dto.SlackMessage)
+	}).Return(nil)
+
+	err := service.HandleInteraction(context.Background(), readSlackPayload(t, "block_actions.txt"))
+
+	assert.NoError(t, err)
+	deps.voteservice.AssertExpectations(t)
+	assert.True(t, updated.ReplaceOriginal)
+	assert.Contains(t, updated.Blocks[1].Text.Text, "1 (100%)")
+	assert.Contains(t, updated.Blocks[3].Elements[0].Text, "1 vote ·")
+}
+
+func TestSlackHandleInteraction_RejectedVote(t *testing.T) {
+	service, deps := newTestSlackService(time.Now())
+
+	deps.identityrepo.On("FindByProviderAndSubject", mock.Anything, "slack", "T1DC2JH3J:U2CERLKJA").Return(&domain.UserIdentity{UserID: uuid.New()}, nil)
+	deps.voteservice.On("VotePoll", mock.Anything, mock.Anything).Return(nil, utils.VoteAlreadyExistsError)
+	deps.responder.On("Respond", mock.Anything, mock.Anything, dto.SlackMessage{
+		ResponseType: "ephemeral",
+		Text:         utils.VoteAlreadyExistsError.Error(),
+	}).Return(nil)
+
+	err := service.HandleInteraction(context.Background(), readSlackPayload(t, "block_actions.txt"))
+
+	assert.NoError(t, err)
+	deps.responder.AssertExpectations(t)
+}
+
+func TestSlackPollMessage_EscapesText(t *testing.T) {
+	service, _ := newTestSlackService(time.Now())
+
+	poll := &domain.Poll{
+		ID:                uuid.New(),
+		Title:             "<!channel> & friends",
+		ExpiresAt:         time.Now().Add(-time.Hour),
+		ResultsVisibility: domain.ResultsVisibilityCreatorOnly,
+		Options:           []domain.Option{{ID: uuid.New(), Name: "<@U123>"}},
+	}
+
+	message := service.pollMessage(poll)
+
+	data, err := json.Marshal(message)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "*&lt;!channel&gt; &amp; friends*", message.Blocks[0].Text.Text)
+	assert.Equal(t, "*&lt;@U123&gt;*", message.Blocks[1].Text.Text)
+	assert.Nil(t, message.Blocks[1].Accessory)
+	assert.NotContains(t, string(data), "votes")
+}
+
+func TestParseSlackCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		title    string
+		options  []string
+		duration time.Duration
+		ok       bool
+	}{
+		{"quoted", `"Lunch?" "Pizza" "Sushi"`, "Lunch?", []string{"Pizza", "Sushi"}, slackDefaultDuration, true},
+		{"curly quotes with days", `“Lunch?” “Pizza” “Sushi” 3d`, "Lunch?", []string{"Pizza", "Sushi"}, 72 * time.Hour, true},
+		{"pipes", `Lunch? | Pizza | Sushi`, "Lunch?", []string{"Pizza", "Sushi"}, slackDefaultDuration, true},
+		{"unquoted word", `"Lunch?" Pizza "Sushi"`, "", nil, 0, false},
+		{"unclosed quote", `"Lunch? "Pizza" "Sushi`, "", nil, 0, false},
+		{"duration too long", `"Lunch?" "Pizza" "Sushi" 90d`, "", nil, 0, false},
+		{"one option", `Lunch? | Pizza`, "", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := parseSlackCommand(tt.text)
+
+			if !tt.ok {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.title, command.title)
+			assert.Equal(t, tt.options, command.options)
+			assert.Equal(t, tt.duration, command.duration)
+		})
+	}
+}
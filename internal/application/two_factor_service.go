@@ -0,0 +1,23 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+)
+
+type TwoFactorService interface {
+	Enroll(ctx context.Context) (*dto.TwoFactorEnrollResponse, error)
+
+	Confirm(ctx context.Context, codeRequest dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+
+	Disable(ctx context.Context, codeRequest dto.TwoFactorCodeRequest) error
+
+	RegenerateRecoveryCodes(ctx context.Context, codeRequest dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+
+	IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+
+	// Verify accepts either a current TOTP code or an unused recovery code.
+	Verify(ctx context.Context, userID uuid.UUID, code string) error
+}
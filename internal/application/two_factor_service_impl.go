@@ -0,0 +1,261 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer        = "Jille"
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+type twofactorservice struct {
+	repo repository.TwoFactorRepository
+
+	userservice UserService
+
+	loginguard LoginGuard
+}
+
+func NewTwoFactorService(repo repository.TwoFactorRepository, userservice UserService, loginguard LoginGuard) TwoFactorService {
+	return &twofactorservice{
+		repo:        repo,
+		userservice: userservice,
+		loginguard:  loginguard,
+	}
+}
+
+// mfaAccount is the login guard account for a user's two-factor codes, so
+// wrong codes count against one limit wherever they are entered.
+func mfaAccount(userID uuid.UUID) string {
+	return "mfa:" + userID.String()
+}
+
+func (s *twofactorservice) Enroll(ctx context.Context) (*dto.TwoFactorEnrollResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	existing, err := s.repo.FindByUserID(ctx, userID)
+
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if existing != nil && existing.Enabled {
+		return nil, utils.TwoFactorAlreadyEnabledError
+	}
+
+	user, err := s.userservice.GetUserById(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// restarting enrollment replaces a secret that was never confirmed
+	twoFactor := &domain.TwoFactor{UserID: userID}
+
+	if existing != nil {
+		twoFactor = existing
+	}
+
+	twoFactor.Secret = secret
+	twoFactor.LastUsedStep = 0
+
+	if err := s.repo.Save(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrollResponse{
+		Secret: secret,
+		URI:    utils.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *twofactorservice) Confirm(ctx context.Context, codeRequest dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	twoFactor, err := s.repo.FindByUserID(ctx, userID)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.TwoFactorNotEnrolledError
+		}
+		return nil, err
+	}
+
+	if twoFactor.Enabled {
+		return nil, utils.TwoFactorAlreadyEnabledError
+	}
+
+	step, ok := utils.ValidateTOTP(twoFactor.Secret, codeRequest.Code, time.Now(), totpSkew)
+
+	if !ok {
+		return nil, utils.InvalidTwoFactorCodeError
+	}
+
+	enabledAt := time.Now()
+
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &enabledAt
+	twoFactor.LastUsedStep = step
+
+	if err := s.repo.Save(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{
+		Message: "Two-factor authentication enabled",
+		Codes:   codes,
+	}, nil
+}
+
+func (s *twofactorservice) Disable(ctx context.Context, codeRequest dto.TwoFactorCodeRequest) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if err := s.verifyGuarded(ctx, userID, codeRequest.Code); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, userID)
+}
+
+func (s *twofactorservice) RegenerateRecoveryCodes(ctx context.Context, codeRequest dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if err := s.verifyGuarded(ctx, userID, codeRequest.Code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{
+		Message: "Recovery codes regenerated",
+		Codes:   codes,
+	}, nil
+}
+
+func (s *twofactorservice) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+
+	twoFactor, err := s.repo.FindByUserID(ctx, userID)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return twoFactor.Enabled, nil
+}
+
+func (s *twofactorservice) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+
+	twoFactor, err := s.repo.FindByUserID(ctx, userID)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return utils.TwoFactorNotEnabledError
+		}
+		return err
+	}
+
+	if !twoFactor.Enabled {
+		return utils.TwoFactorNotEnabledError
+	}
+
+	if step, ok := utils.ValidateTOTP(twoFactor.Secret, code, time.Now(), totpSkew); ok {
+		// a code may only be used once, even within its validity window
+		return s.repo.UpdateLastUsedStep(ctx, userID, step)
+	}
+
+	return s.repo.UseRecoveryCode(ctx, userID, utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+// verifyGuarded verifies a code like Verify, but refuses while the user is
+// locked out and records wrong codes, so a stolen session cannot be used to
+// guess them.
+func (s *twofactorservice) verifyGuarded(ctx context.Context, userID uuid.UUID, code string) error {
+
+	clientIP, _ := ctx.Value("clientIP").(string)
+
+	account := mfaAccount(userID)
+
+	if err := s.loginguard.Check(ctx, account, clientIP); err != nil {
+		return err
+	}
+
+	if err := s.Verify(ctx, userID, code); err != nil {
+		if errors.Is(err, utils.InvalidTwoFactorCodeError) {
+			if err := s.loginguard.RecordFailure(ctx, account, clientIP); err != nil {
+				fmt.Println("error recording failed two-factor code", err.Error())
+			}
+		}
+		return err
+	}
+
+	return s.loginguard.RecordSuccess(ctx, account)
+}
+
+// replaceRecoveryCodes generates a fresh set of recovery codes, stores their
+// hashes and returns the plaintext codes so they can be shown once.
+func (s *twofactorservice) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+
+		secret, err := utils.GenerateTOTPSecret()
+
+		if err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(secret[:5] + "-" + secret[5:10])
+
+		codes[i] = code
+		hashes[i] = utils.HashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+func TestEnroll_Success(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	mockUserService := new(MockUserService)
+	service := NewTwoFactorService(mockRepo, mockUserService, new(MockLoginGuard))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindByUserID", ctx, userID).Return(nil, gorm.ErrRecordNotFound)
+	mockUserService.On("GetUserById", ctx, userID).Return(&dto.UserResponse{ID: userID, Email: "test@example.com"}, nil)
+	mockRepo.On("Save", ctx, mock.MatchedBy(func(tf *domain.TwoFactor) bool {
+		return tf.UserID == userID && !tf.Enabled && tf.Secret != ""
+	})).Return(nil)
+
+	resp, err := service.Enroll(ctx)
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.URI, "otpauth://totp/Jille:test@example.com?"))
+	assert.Contains(t, resp.URI, "secret="+resp.Secret)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEnroll_AlreadyEnabled(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), new(MockLoginGuard))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindByUserID", ctx, userID).Return(&domain.TwoFactor{UserID: userID, Enabled: true}, nil)
+
+	_, err := service.Enroll(ctx)
+
+	assert.ErrorIs(t, err, utils.TwoFactorAlreadyEnabledError)
+}
+
+func TestConfirm_EnablesAndReturnsRecoveryCodes(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), new(MockLoginGuard))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	secret, err := utils.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	code, err := utils.TOTPCode(secret, time.Now())
+	assert.NoError(t, err)
+
+	var storedHashes []string
+
+	mockRepo.On("FindByUserID", ctx, userID).Return(&domain.TwoFactor{UserID: userID, Secret: secret}, nil)
+	mockRepo.On("Save", ctx, mock.MatchedBy(func(tf *domain.TwoFactor) bool {
+		return tf.Enabled && tf.LastUsedStep > 0
+	})).Return(nil)
+	mockRepo.On("ReplaceRecoveryCodes", ctx, userID, mock.AnythingOfType("[]string")).Return(nil).Run(func(args mock.Arguments) {
+		storedHashes = args.Get(2).([]string)
+	})
+
+	resp, err := service.Confirm(ctx, dto.TwoFactorCodeRequest{Code: code})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Codes, 10)
+	assert.Len(t, storedHashes, 10)
+	// only hashes are persisted
+	assert.NotContains(t, storedHashes, resp.Codes[0])
+	assert.Equal(t, utils.HashToken(normalizeRecoveryCode(resp.Codes[0])), storedHashes[0])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConfirm_InvalidCode(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), new(MockLoginGuard))
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	secret, err := utils.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	mockRepo.On("FindByUserID", ctx, userID).Return(&domain.TwoFactor{UserID: userID, Secret: secret}, nil)
+
+	_, err = service.Confirm(ctx, dto.TwoFactorCodeRequest{Code: "12345"})
+
+	assert.ErrorIs(t, err, utils.InvalidTwoFactorCodeError)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestVerify_TOTPRecordsStep(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), new(MockLoginGuard))
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	secret, err := utils.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	now := time.Now()
+	code, err := utils.TOTPCode(secret, now)
+	assert.NoError(t, err)
+
+	mockRepo.On("FindByUserID", ctx, userID).Return(&domain.TwoFactor{UserID: userID, Secret: secret, Enabled: true}, nil)
+	mockRepo.On("UpdateLastUsedStep", ctx, userID, mock.AnythingOfType("int64")).Return(nil)
+
+	err = service.Verify(ctx, userID, code)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerify_FallsBackToRecoveryCode(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), new(MockLoginGuard))
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	secret, err := utils.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	mockRepo.On("FindByUserID", ctx, userID).Return(&domain.TwoFactor{UserID: userID, Secret: secret, Enabled: true}, nil)
+	mockRepo.On("UseRecoveryCode", ctx, userID, utils.HashToken("abcdefghij")).Return(nil)
+
+	err = service.Verify(ctx, userID, "ABCDE-FGHIJ")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerify_NotEnabled(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), new(MockLoginGuard))
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockRepo.On("FindByUserID", ctx, userID).Return(&domain.TwoFactor{UserID: userID, Secret: "SECRET"}, nil)
+
+	err := service.Verify(ctx, userID, "123456")
+
+	assert.ErrorIs(t, err, utils.TwoFactorNotEnabledError)
+}
+
+func TestDisable_WrongCodeCountsAsFailure(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	mockLoginGuard := new(MockLoginGuard)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), mockLoginGuard)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	secret, err := utils.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	mockLoginGuard.On("Check", ctx, "mfa:"+userID.String(), "").Return(nil)
+	mockLoginGuard.On("RecordFailure", ctx, "mfa:"+userID.String(), "").Return(nil)
+	mockRepo.On("FindByUserID", ctx, userID).Return(&domain.TwoFactor{UserID: userID, Secret: secret, Enabled: true}, nil)
+	mockRepo.On("UseRecoveryCode", ctx, userID, utils.HashToken("abcdefghij")).Return(utils.InvalidTwoFactorCodeError)
+
+	err = service.Disable(ctx, dto.TwoFactorCodeRequest{Code: "abcde-fghij"})
+
+	assert.ErrorIs(t, err, utils.InvalidTwoFactorCodeError)
+	mockLoginGuard.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestRegenerateRecoveryCodes_LockedOut(t *testing.T) {
+	mockRepo := new(mocks.TwoFactorRepository)
+	mockLoginGuard := new(MockLoginGuard)
+	service := NewTwoFactorService(mockRepo, new(MockUserService), mockLoginGuard)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockLoginGuard.On("Check", ctx, "mfa:"+userID.String(), "").Return(&utils.LockoutError{RetryAfter: time.Minute})
+
+	_, err := service.RegenerateRecoveryCodes(ctx, dto.TwoFactorCodeRequest{Code: "123456"})
+
+	assert.ErrorIs(t, err, utils.TooManyAttemptsError)
+	mockRepo.AssertNotCalled(t, "FindByUserID", mock.Anything, mock.Anything)
+}
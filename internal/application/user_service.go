@@ -15,5 +15,26 @@ type UserService interface {
 
 	GetUserByEmail(ctx context.Context, email string) (*dto.UserAuthView, error)
 
+	GetUserAuthById(ctx context.Context, userID uuid.UUID) (*dto.UserAuthView, error)
+
 	CreateUser(ctx context.Context, user *domain.User) error
+
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+
+	UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error
+
+	// RecordProviderSignIn notes that the user has just signed in through an
+	// identity provider, which confirms changes to passwordless accounts.
+	RecordProviderSignIn(ctx context.Context, userID uuid.UUID) error
+
+	UpdateUsername(ctx context.Context, updateRequest dto.UpdateUsernameRequest) (*dto.UserResponse, error)
+
+	// UpdateEmail switches the user to an address they have already confirmed.
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+
+	// UpdateProfilePicture stores an uploaded PNG, JPEG, GIF or WebP image as
+	// the user's profile picture, replacing any previous one.
+	UpdateProfilePicture(ctx context.Context, image []byte) (*dto.UserResponse, error)
+
+	DeleteAccount(ctx context.Context, deleteRequest dto.DeleteAccountRequest) error
 }
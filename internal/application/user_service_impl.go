@@ -3,22 +3,39 @@ package application
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/application/repository"
 	"github.com/winnerx0/jille/internal/common/dto"
 	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
 )
 
+const maxProfilePictureSize = 2 << 20
+
+// profilePictureExtensions lists the accepted image types, keyed by the
+// content type sniffed from the upload itself.
+var profilePictureExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
 type userservice struct {
 	userRepo    repository.UserRepository
 	pollService PollService
+	storage     FileStorage
 }
 
-func NewUserService(userRepo repository.UserRepository, pollservice PollService) UserService {
+func NewUserService(userRepo repository.UserRepository, pollservice PollService, storage FileStorage) UserService {
 	return &userservice{
 		userRepo:    userRepo,
 		pollService: pollservice,
+		storage:     storage,
 	}
 }
 
@@ -41,9 +58,12 @@ func (s *userservice) GetUserById(ctx context.Context, userID uuid.UUID) (*dto.U
 	}
 
 	return &dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		PollCount: pollCount,
+		ID:             user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		EmailVerified:  user.EmailVerified,
+		PollCount:      pollCount,
+		ProfilePicture: s.profilePictureURL(user.ProfilePicture),
 	}, nil
 }
 
@@ -66,11 +86,30 @@ func (s *userservice) GetUserByEmail(ctx context.Context, email string) (*dto.Us
 		return nil, err
 	}
 
+	return authView(user), nil
+}
+
+func (s *userservice) GetUserAuthById(ctx context.Context, userID uuid.UUID) (*dto.UserAuthView, error) {
+
+	user, err := s.userRepo.FindById(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return authView(user), nil
+}
+
+func authView(user domain.User) *dto.UserAuthView {
 	return &dto.UserAuthView{
-		Email:    user.Email,
-		Password: user.Password,
-		ID:       user.ID,
-	}, nil
+		Username:         user.Username,
+		Email:            user.Email,
+		Password:         user.Password,
+		ID:               user.ID,
+		EmailVerified:    user.EmailVerified,
+		Passwordless:     user.Passwordless,
+		ProviderSignInAt: user.ProviderSignInAt,
+	}
 }
 
 func (s *userservice) CreateUser(ctx context.Context, user *domain.User) error {
@@ -79,3 +118,124 @@ func (s *userservice) CreateUser(ctx context.Context, user *domain.User) error {
 
 	return err
 }
+
+func (s *userservice) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+
+	return s.userRepo.MarkEmailVerified(ctx, userID)
+}
+
+func (s *userservice) RecordProviderSignIn(ctx context.Context, userID uuid.UUID) error {
+
+	return s.userRepo.RecordProviderSignIn(ctx, userID, time.Now())
+}
+
+func (s *userservice) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+
+	return s.userRepo.UpdatePassword(ctx, userID, hashedPassword)
+}
+
+func (s *userservice) UpdateUsername(ctx context.Context, updateRequest dto.UpdateUsernameRequest) (*dto.UserResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if err := s.userRepo.UpdateUsername(ctx, userID, updateRequest.Username); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserById(ctx, userID)
+}
+
+func (s *userservice) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+
+	return s.userRepo.UpdateEmail(ctx, userID, email)
+}
+
+func (s *userservice) UpdateProfilePicture(ctx context.Context, image []byte) (*dto.UserResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	if len(image) > maxProfilePictureSize {
+		return nil, utils.ImageTooLargeError
+	}
+
+	// trust the bytes rather than the file name or the client's content type
+	extension, ok := profilePictureExtensions[http.DetectContentType(image)]
+
+	if !ok {
+		return nil, utils.UnsupportedImageError
+	}
+
+	user, err := s.userRepo.FindById(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, err := utils.GenerateToken(8)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a fresh name per upload keeps cached copies of the old picture from
+	// being served in its place
+	name := fmt.Sprintf("avatars/%s-%s%s", userID, suffix, extension)
+
+	if err := s.storage.Save(ctx, name, image); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateProfilePicture(ctx, userID, name); err != nil {
+		s.removeFile(ctx, name)
+		return nil, err
+	}
+
+	s.removeFile(ctx, user.ProfilePicture)
+
+	return s.GetUserById(ctx, userID)
+}
+
+func (s *userservice) DeleteAccount(ctx context.Context, deleteRequest dto.DeleteAccountRequest) error {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	user, err := s.userRepo.FindById(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := confirmIdentity(*authView(user), deleteRequest.Password); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DeleteAccount(ctx, userID, domain.AccountDeletionMode(deleteRequest.Mode)); err != nil {
+		return err
+	}
+
+	s.removeFile(ctx, user.ProfilePicture)
+
+	return nil
+}
+
+func (s *userservice) profilePictureURL(name string) string {
+
+	if name == "" {
+		return ""
+	}
+
+	return s.storage.URL(name)
+}
+
+// removeFile deletes a stored file on a best-effort basis, an orphaned file
+// is not worth failing the request over.
+func (s *userservice) removeFile(ctx context.Context, name string) {
+
+	if name == "" {
+		return
+	}
+
+	if err := s.storage.Delete(ctx, name); err != nil {
+		fmt.Println("error deleting file", name, err.Error())
+	}
+}
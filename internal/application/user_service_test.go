@@ -2,6 +2,8 @@ package application
 
 import (
 	"context"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,12 +13,14 @@ import (
 	"github.com/winnerx0/jille/internal/application/repository/mocks"
 	"github.com/winnerx0/jille/internal/common/dto"
 	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestGetUserById_Success(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
 	mockPollService := new(MockPollService)
-	service := NewUserService(mockRepo, mockPollService)
+	service := NewUserService(mockRepo, mockPollService, nil)
 
 	ctx := context.Background()
 	userID := uuid.New()
@@ -35,6 +39,7 @@ func TestGetUserById_Success(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser.Email, resp.Email)
+	assert.Equal(t, expectedUser.Username, resp.Username)
 	assert.Equal(t, 5, resp.PollCount)
 	mockRepo.AssertExpectations(t)
 	mockPollService.AssertExpectations(t)
@@ -42,7 +47,7 @@ func TestGetUserById_Success(t *testing.T) {
 
 func TestExistsByEmail(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
-	service := NewUserService(mockRepo, nil)
+	service := NewUserService(mockRepo, nil, nil)
 
 	ctx := context.Background()
 	email := "test@example.com"
@@ -58,7 +63,7 @@ func TestExistsByEmail(t *testing.T) {
 
 func TestGetUserByEmail_Success(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
-	service := NewUserService(mockRepo, nil)
+	service := NewUserService(mockRepo, nil, nil)
 
 	ctx := context.Background()
 	email := "test@example.com"
@@ -80,7 +85,7 @@ func TestGetUserByEmail_Success(t *testing.T) {
 
 func TestCreateUser_Success(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
-	service := NewUserService(mockRepo, nil)
+	service := NewUserService(mockRepo, nil, nil)
 
 	ctx := context.Background()
 	user := &domain.User{
@@ -96,14 +101,148 @@ func TestCreateUser_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUpdateProfilePicture_Success(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockPollService := new(MockPollService)
+	mockStorage := new(MockFileStorage)
+	service := NewUserService(mockRepo, mockPollService, mockStorage)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	image := []byte("\x89PNG\r\n\x1a\n0000")
+
+	mockRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Email: "test@example.com", ProfilePicture: "avatars/old.png"}, nil).Once()
+	mockStorage.On("Save", ctx, mock.MatchedBy(func(name string) bool {
+		return strings.HasPrefix(name, "avatars/"+userID.String()) && strings.HasSuffix(name, ".png")
+	}), image).Return(nil)
+	mockRepo.On("UpdateProfilePicture", ctx, userID, mock.AnythingOfType("string")).Return(nil)
+	mockStorage.On("Delete", ctx, "avatars/old.png").Return(nil)
+	mockRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Email: "test@example.com", ProfilePicture: "avatars/new.png"}, nil).Once()
+	mockPollService.On("GetPollCount", ctx, userID).Return(0, nil)
+	mockStorage.On("URL", "avatars/new.png").Return("http://localhost:9000/uploads/avatars/new.png")
+
+	resp, err := service.UpdateProfilePicture(ctx, image)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:9000/uploads/avatars/new.png", resp.ProfilePicture)
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestUpdateProfilePicture_RejectsNonImage(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockStorage := new(MockFileStorage)
+	service := NewUserService(mockRepo, nil, mockStorage)
+
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+
+	_, err := service.UpdateProfilePicture(ctx, []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+
+	assert.ErrorIs(t, err, utils.UnsupportedImageError)
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteAccount_Success(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockStorage := new(MockFileStorage)
+	service := NewUserService(mockRepo, nil, mockStorage)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Password: string(hashedPassword), ProfilePicture: "avatars/me.png"}, nil)
+	mockRepo.On("DeleteAccount", ctx, userID, domain.AccountDeletionAnonymize).Return(nil)
+	mockStorage.On("Delete", ctx, "avatars/me.png").Return(nil)
+
+	err = service.DeleteAccount(ctx, dto.DeleteAccountRequest{Password: "password123", Mode: "anonymize"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestDeleteAccount_WrongPassword(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	service := NewUserService(mockRepo, nil, nil)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Password: string(hashedPassword)}, nil)
+
+	err = service.DeleteAccount(ctx, dto.DeleteAccountRequest{Password: "guessed", Mode: "remove"})
+
+	assert.ErrorIs(t, err, utils.InvalidPasswordError)
+	mockRepo.AssertNotCalled(t, "DeleteAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteAccount_PasswordlessNeedsProviderSignIn(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	service := NewUserService(mockRepo, nil, nil)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Passwordless: true}, nil).Once()
+
+	err := service.DeleteAccount(ctx, dto.DeleteAccountRequest{Mode: "remove"})
+
+	assert.ErrorIs(t, err, utils.ReauthenticationRequiredError)
+	mockRepo.AssertNotCalled(t, "DeleteAccount", mock.Anything, mock.Anything, mock.Anything)
+
+	signedIn := time.Now()
+
+	mockRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Passwordless: true, ProviderSignInAt: &signedIn}, nil).Once()
+	mockRepo.On("DeleteAccount", ctx, userID, domain.AccountDeletionRemove).Return(nil)
+
+	err = service.DeleteAccount(ctx, dto.DeleteAccountRequest{Mode: "remove"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// MockFileStorage
+type MockFileStorage struct {
+	mock.Mock
+}
+
+func (m *MockFileStorage) Save(ctx context.Context, name string, data []byte) error {
+	args := m.Called(ctx, name, data)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileStorage) Delete(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) URL(name string) string {
+	args := m.Called(name)
+	return args.String(0)
+}
+
 // MockPollService local definition for testing
 type MockPollService struct {
 	mock.Mock
 }
 
-func (m *MockPollService) CreatePoll(ctx context.Context, pollRequest *dto.CreatePollRequest) error {
+func (m *MockPollService) CreatePoll(ctx context.Context, pollRequest *dto.CreatePollRequest) (uuid.UUID, error) {
 	args := m.Called(ctx, pollRequest)
-	return args.Error(0)
+	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
 func (m *MockPollService) GetPollCount(ctx context.Context, userID uuid.UUID) (int, error) {
@@ -130,9 +269,36 @@ func (m *MockPollService) GetPoll(ctx context.Context, pollID uuid.UUID) (*dto.P
 	return args.Get(0).(*dto.PollViewResponse), args.Error(1)
 }
 
+func (m *MockPollService) GetPollVotes(ctx context.Context, pollID uuid.UUID, cursor string) (*dto.PollVotesResponse, error) {
+
+	args := m.Called(ctx, pollID, cursor)
+
+	return args.Get(0).(*dto.PollVotesResponse), args.Error(1)
+}
+
+func (m *MockPollService) EachPollVote(ctx context.Context, pollID uuid.UUID, fn func(dto.Vote) error) error {
+	args := m.Called(ctx, pollID, fn)
+	return args.Error(0)
+}
+
 func (m *MockPollService) GetAllPolls(ctx context.Context) (dto.ApiResponse[[]dto.PollViewResponse], error) {
 	args := m.Called(ctx)
 
 	return args.Get(0).(dto.ApiResponse[[]dto.PollViewResponse]), args.Error(1)
 
+}
+
+func (m *MockPollService) GetOrganizationPolls(ctx context.Context, organizationID uuid.UUID) ([]dto.PollViewResponse, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).([]dto.PollViewResponse), args.Error(1)
+}
+
+func (m *MockPollService) CloseExpiredPolls(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockPollService) AuthorizeResultsStream(ctx context.Context, pollID uuid.UUID) error {
+	args := m.Called(ctx, pollID)
+	return args.Error(0)
 }
\ No newline at end of file
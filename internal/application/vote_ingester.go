@@ -0,0 +1,19 @@
+package application
+
+import (
+	"context"
+
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+// VoteIngester records votes in batches, so a burst of voters costs a few
+// transactions rather than one each.
+type VoteIngester interface {
+	// Submit queues the ballot and waits until the batch it joined is
+	// written, returning the ballot's own outcome. A ballot whose ctx ends
+	// while it waits may still be recorded.
+	Submit(ctx context.Context, ballot domain.Ballot) error
+
+	// Run writes queued ballots until ctx is done.
+	Run(ctx context.Context)
+}
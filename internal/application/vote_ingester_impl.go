@@ -0,0 +1,161 @@
+package application
+
+import (
+	"context"
+	"sync"
+
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+const (
+	voteQueueSize    = 10000
+	voteBatchSize    = 500
+	voteBatchWriters = 4
+)
+
+type queuedBallot struct {
+	ballot domain.Ballot
+	done   chan error
+}
+
+type voteingester struct {
+	repo       repository.VoteRepository
+	inviterepo repository.PollInviteRepository
+	uow        repository.UnitOfWork
+
+	queue chan queuedBallot
+}
+
+func NewVoteIngester(repo repository.VoteRepository, inviterepo repository.PollInviteRepository, uow repository.UnitOfWork) VoteIngester {
+	return &voteingester{
+		repo:       repo,
+		inviterepo: inviterepo,
+		uow:        uow,
+		queue:      make(chan queuedBallot, voteQueueSize),
+	}
+}
+
+func (i *voteingester) Submit(ctx context.Context, ballot domain.Ballot) error {
+
+	done := make(chan error, 1)
+
+	select {
+	case i.queue <- queuedBallot{ballot: ballot, done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (i *voteingester) Run(ctx context.Context) {
+
+	var wg sync.WaitGroup
+
+	for range voteBatchWriters {
+		wg.Go(func() {
+			i.write(ctx)
+		})
+	}
+
+	wg.Wait()
+}
+
+// write takes whatever is queued, up to a batch, and writes it. Nothing
+// waits for a batch to fill: ballots that arrive while one batch is written
+// make up the next, so batches grow with the load.
+func (i *voteingester) write(ctx context.Context) {
+
+	batch := make([]queuedBallot, 0, voteBatchSize)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case first := <-i.queue:
+			batch = append(batch[:0], first)
+		}
+
+	fill:
+		for len(batch) < voteBatchSize {
+			select {
+			case next := <-i.queue:
+				batch = append(batch, next)
+			default:
+				break fill
+			}
+		}
+
+		// a batch that has started is finished even when ctx ends, so
+		// its voters hear whether their vote counted
+		results, err := i.save(context.WithoutCancel(ctx), batch)
+
+		for j, queued := range batch {
+			if err != nil {
+				queued.done <- err
+			} else {
+				queued.done <- results[j]
+			}
+		}
+	}
+}
+
+func (i *voteingester) save(ctx context.Context, batch []queuedBallot) ([]error, error) {
+
+	results := make([]error, len(batch))
+
+	err := i.uow.Do(ctx, func(ctx context.Context) error {
+
+		ballots := make([]domain.Ballot, 0, len(batch))
+		positions := make([]int, 0, len(batch))
+
+		for j, queued := range batch {
+
+			ballot := queued.ballot
+
+			// tie email-only invites to the account that used them so
+			// participation is tracked even if the user later changes
+			// their address. Each claim is a unit of its own, so one that
+			// fails turns away its ballot but not the rest of the batch. A
+			// ballot that is then turned away keeps its claim, which still
+			// names the right account.
+			if ballot.InviteID != nil {
+				err := i.uow.Do(ctx, func(ctx context.Context) error {
+					return i.inviterepo.Claim(ctx, *ballot.InviteID, ballot.UserID)
+				})
+
+				if err != nil {
+					results[j] = err
+					continue
+				}
+			}
+
+			ballots = append(ballots, ballot)
+			positions = append(positions, j)
+		}
+
+		if len(ballots) == 0 {
+			return nil
+		}
+
+		saved, err := i.repo.SaveVotes(ctx, ballots)
+
+		if err != nil {
+			return err
+		}
+
+		for k, j := range positions {
+			results[j] = saved[k]
+		}
+
+		return nil
+	})
+
+	return results, err
+}
@@ -0,0 +1,173 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// newTestVoteIngester returns an ingester that writes ballots until the
+// test ends.
+func newTestVoteIngester(t *testing.T, repo repository.VoteRepository, inviterepo repository.PollInviteRepository, uow repository.UnitOfWork) VoteIngester {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ingester := NewVoteIngester(repo, inviterepo, uow)
+	go ingester.Run(ctx)
+
+	return ingester
+}
+
+// submitQueued submits the ballots and returns once all of them are queued,
+// along with a wait that returns their outcomes.
+func submitQueued(t *testing.T, ingester *voteingester, ballots []domain.Ballot) func() []error {
+
+	results := make([]error, len(ballots))
+	var wg sync.WaitGroup
+
+	for i, ballot := range ballots {
+		wg.Go(func() {
+			results[i] = ingester.Submit(context.Background(), ballot)
+		})
+	}
+
+	assert.Eventually(t, func() bool { return len(ingester.queue) == len(ballots) }, time.Second, time.Millisecond)
+
+	return func() []error {
+		wg.Wait()
+		return results
+	}
+}
+
+func TestVoteIngester_WritesQueuedBallotsTogether(t *testing.T) {
+	mockVoteRepo := new(mocks.VoteRepository)
+	ingester := NewVoteIngester(mockVoteRepo, new(mocks.PollInviteRepository), newTestUnitOfWork()).(*voteingester)
+
+	pollID := uuid.New()
+	optionID := uuid.New()
+	ballots := make([]domain.Ballot, 50)
+
+	for i := range ballots {
+		ballots[i] = domain.Ballot{PollID: pollID, OptionID: optionID, UserID: uuid.New()}
+	}
+
+	repeated := ballots[7].UserID
+
+	// the batch comes in the order the ballots were queued, which is not
+	// the order they were submitted in
+	outcomes := make([]error, len(ballots))
+
+	mockVoteRepo.On("SaveVotes", mock.Anything, mock.MatchedBy(func(batch []domain.Ballot) bool {
+		return len(batch) == len(ballots)
+	})).Run(func(args mock.Arguments) {
+		for i, ballot := range args.Get(1).([]domain.Ballot) {
+			if ballot.UserID == repeated {
+				outcomes[i] = utils.VoteAlreadyExistsError
+			}
+		}
+	}).Return(outcomes, nil).Once()
+
+	wait := submitQueued(t, ingester, ballots)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ingester.write(ctx)
+
+	results := wait()
+
+	for i, err := range results {
+		if i == 7 {
+			assert.ErrorIs(t, err, utils.VoteAlreadyExistsError)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	mockVoteRepo.AssertNumberOfCalls(t, "SaveVotes", 1)
+}
+
+func TestVoteIngester_FailedBatch(t *testing.T) {
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockInviteRepo := new(mocks.PollInviteRepository)
+	ingester := NewVoteIngester(mockVoteRepo, mockInviteRepo, newTestUnitOfWork()).(*voteingester)
+
+	inviteID := uuid.New()
+	ballots := []domain.Ballot{
+		{PollID: uuid.New(), OptionID: uuid.New(), UserID: uuid.New(), InviteID: &inviteID},
+		{PollID: uuid.New(), OptionID: uuid.New(), UserID: uuid.New()},
+	}
+
+	mockInviteRepo.On("Claim", mock.Anything, inviteID, ballots[0].UserID).Return(nil)
+	mockVoteRepo.On("SaveVotes", mock.Anything, mock.Anything).Return(nil, errors.New("connection reset"))
+
+	wait := submitQueued(t, ingester, ballots)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ingester.write(ctx)
+
+	for _, err := range wait() {
+		assert.EqualError(t, err, "connection reset")
+	}
+}
+
+func TestVoteIngester_FailedClaimTurnsAwayOnlyItsBallot(t *testing.T) {
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockInviteRepo := new(mocks.PollInviteRepository)
+	mockUnitOfWork := new(mocks.UnitOfWork)
+	ingester := NewVoteIngester(mockVoteRepo, mockInviteRepo, mockUnitOfWork).(*voteingester)
+
+	pollID := uuid.New()
+	optionID := uuid.New()
+	claimed, unclaimed := uuid.New(), uuid.New()
+	ballots := []domain.Ballot{
+		{PollID: pollID, OptionID: optionID, UserID: uuid.New(), InviteID: &claimed},
+		{PollID: pollID, OptionID: optionID, UserID: uuid.New(), InviteID: &unclaimed},
+		{PollID: pollID, OptionID: optionID, UserID: uuid.New()},
+	}
+
+	// the batch and each claim are units of work, the claims nested in the
+	// batch
+	mockUnitOfWork.On("Do", mock.Anything).Return(nil).Times(3)
+	mockInviteRepo.On("Claim", mock.Anything, claimed, ballots[0].UserID).Return(nil)
+	mockInviteRepo.On("Claim", mock.Anything, unclaimed, ballots[1].UserID).Return(errors.New("connection reset"))
+	mockVoteRepo.On("SaveVotes", mock.Anything, mock.MatchedBy(func(batch []domain.Ballot) bool {
+		return len(batch) == 2 && batch[0].UserID != ballots[1].UserID && batch[1].UserID != ballots[1].UserID
+	})).Return([]error{nil, nil}, nil).Once()
+
+	wait := submitQueued(t, ingester, ballots)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ingester.write(ctx)
+
+	for i, err := range wait() {
+		if i == 1 {
+			assert.EqualError(t, err, "connection reset")
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	mockVoteRepo.AssertExpectations(t)
+	mockUnitOfWork.AssertExpectations(t)
+}
+
+func TestVoteIngester_SubmitGivesUpWithContext(t *testing.T) {
+	ingester := NewVoteIngester(new(mocks.VoteRepository), new(mocks.PollInviteRepository), newTestUnitOfWork())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	err := ingester.Submit(ctx, domain.Ballot{PollID: uuid.New(), OptionID: uuid.New(), UserID: uuid.New()})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -2,25 +2,33 @@ package application
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/winnerx0/jille/internal/application/repository"
 	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
 	"github.com/winnerx0/jille/internal/utils"
 )
 
 type voteservice struct {
-	repo       repository.VoteRepository
-	pollrepo   repository.PollRepository
+	ingester   VoteIngester
+	polls      *pollCache
 	optionrepo repository.OptionRepository
+	policy     PollPolicy
+	inviterepo repository.PollInviteRepository
+	userrepo   repository.UserRepository
 }
 
-func NewVoteService(repo repository.VoteRepository, pollrepo repository.PollRepository, optionrepo repository.OptionRepository) VoteService {
+func NewVoteService(ingester VoteIngester, pollrepo repository.PollRepository, optionrepo repository.OptionRepository, policy PollPolicy, inviterepo repository.PollInviteRepository, userrepo repository.UserRepository) VoteService {
 	return &voteservice{
-		repo:       repo,
-		pollrepo:   pollrepo,
+		ingester:   ingester,
+		polls:      newPollCache(pollrepo, pollCacheTTL),
 		optionrepo: optionrepo,
+		policy:     policy,
+		inviterepo: inviterepo,
+		userrepo:   userrepo,
 	}
 }
 
@@ -28,7 +36,9 @@ func (s *voteservice) VotePoll(ctx context.Context, voteRequest dto.VoteRequest)
 
 	userID := ctx.Value("userID").(string)
 
-	poll, err := s.pollrepo.FindPollByID(ctx, uuid.MustParse(voteRequest.PollID))
+	// votes are checked against a recent copy of the poll, and the poll is
+	// checked once more when they are written
+	poll, err := s.polls.Get(ctx, uuid.MustParse(voteRequest.PollID))
 
 	if err != nil {
 		return &dto.VoteResponse{}, err
@@ -38,6 +48,41 @@ func (s *voteservice) VotePoll(ctx context.Context, voteRequest dto.VoteRequest)
 		return &dto.VoteResponse{}, utils.PollExpiredError
 	}
 
+	if poll.MembersOnly {
+		allowed, err := s.policy.Can(ctx, poll, uuid.MustParse(userID), domain.PollActionParticipate)
+
+		if err != nil {
+			return &dto.VoteResponse{}, err
+		}
+
+		if !allowed {
+			return &dto.VoteResponse{}, utils.MembersOnlyPollError
+		}
+	}
+
+	var invite *domain.PollInvite
+
+	if poll.InviteOnly {
+		// whoever runs the poll needs no invite to vote in it
+		manager, err := s.policy.Can(ctx, poll, uuid.MustParse(userID), domain.PollActionManage)
+
+		if err != nil {
+			return &dto.VoteResponse{}, err
+		}
+
+		if !manager {
+			invite, err = findInvite(ctx, s.inviterepo, s.userrepo, poll.ID, uuid.MustParse(userID))
+		}
+
+		if errors.Is(err, utils.InviteNotFoundError) {
+			return &dto.VoteResponse{}, utils.PollNotInvitedError
+		}
+
+		if err != nil {
+			return &dto.VoteResponse{}, err
+		}
+	}
+
 	optionExists := false
 
 	for _, option := range poll.Options {
@@ -51,9 +96,17 @@ func (s *voteservice) VotePoll(ctx context.Context, voteRequest dto.VoteRequest)
 		return &dto.VoteResponse{}, utils.OptionNotFound
 	}
 
-	err = s.repo.Vote(ctx, uuid.MustParse(voteRequest.PollID), uuid.MustParse(voteRequest.OptionID), uuid.MustParse(userID))
+	ballot := domain.Ballot{
+		PollID:   poll.ID,
+		OptionID: uuid.MustParse(voteRequest.OptionID),
+		UserID:   uuid.MustParse(userID),
+	}
 
-	if err != nil {
+	if invite != nil && invite.UserID == nil {
+		ballot.InviteID = &invite.ID
+	}
+
+	if err := s.ingester.Submit(ctx, ballot); err != nil {
 		return &dto.VoteResponse{}, err
 	}
 
@@ -0,0 +1,103 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+func TestVotePoll_MembersOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  bool
+		allowed bool
+	}{
+		{"member", true, true},
+		{"outsider", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVoteRepo := new(mocks.VoteRepository)
+			mockPollRepo := new(mocks.PollRepository)
+			mockOptionRepo := new(mocks.OptionRepository)
+			mockOrgRepo := new(mocks.OrganizationRepository)
+			service := NewVoteService(newTestVoteIngester(t, mockVoteRepo, new(mocks.PollInviteRepository), newTestUnitOfWork()), mockPollRepo, mockOptionRepo, newTestPollPolicy(mockOrgRepo, mockVoteRepo), new(mocks.PollInviteRepository), new(mocks.UserRepository))
+
+			userID := uuid.New()
+			organizationID := uuid.New()
+			pollID := uuid.New()
+			optionID := uuid.New()
+			ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+			mockPollRepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{
+				ID:             pollID,
+				UserID:         uuid.New(),
+				ExpiresAt:      time.Now().Add(time.Hour),
+				OrganizationID: &organizationID,
+				MembersOnly:    true,
+				Options:        []domain.Option{{ID: optionID}},
+			}, nil)
+
+			if tt.member {
+				mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(&domain.OrganizationMember{Role: domain.OrganizationRoleViewer}, nil)
+			} else {
+				mockOrgRepo.On("FindMember", ctx, organizationID, userID).Return(nil, utils.MemberNotFoundError)
+			}
+
+			mockVoteRepo.On("SaveVotes", mock.Anything, []domain.Ballot{{PollID: pollID, OptionID: optionID, UserID: userID}}).Return([]error{nil}, nil)
+
+			_, err := service.VotePoll(ctx, dto.VoteRequest{PollID: pollID.String(), OptionID: optionID.String()})
+
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, utils.MembersOnlyPollError)
+				mockVoteRepo.AssertNotCalled(t, "SaveVotes", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestVotePoll_ClaimFailureCastsNoVote(t *testing.T) {
+	mockVoteRepo := new(mocks.VoteRepository)
+	mockPollRepo := new(mocks.PollRepository)
+	mockInviteRepo := new(mocks.PollInviteRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	mockUnitOfWork := newTestUnitOfWork()
+	service := NewVoteService(newTestVoteIngester(t, mockVoteRepo, mockInviteRepo, mockUnitOfWork), mockPollRepo, new(mocks.OptionRepository), newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)), mockInviteRepo, mockUserRepo)
+
+	userID := uuid.New()
+	pollID := uuid.New()
+	optionID := uuid.New()
+	invite := &domain.PollInvite{ID: uuid.New(), Email: "guest@example.com"}
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	mockPollRepo.On("FindPollByID", mock.Anything, pollID).Return(&domain.Poll{
+		ID:         pollID,
+		UserID:     uuid.New(),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		InviteOnly: true,
+		Options:    []domain.Option{{ID: optionID}},
+	}, nil)
+	mockUserRepo.On("FindById", ctx, userID).Return(domain.User{ID: userID, Email: "guest@example.com", EmailVerified: true}, nil)
+	mockInviteRepo.On("FindForUser", ctx, pollID, userID, "guest@example.com").Return(invite, nil)
+
+	// the invite is claimed before the vote is cast, so a failed claim
+	// casts no vote
+	mockInviteRepo.On("Claim", mock.Anything, invite.ID, userID).Return(errors.New("connection reset"))
+
+	_, err := service.VotePoll(ctx, dto.VoteRequest{PollID: pollID.String(), OptionID: optionID.String()})
+
+	assert.EqualError(t, err, "connection reset")
+	mockVoteRepo.AssertNotCalled(t, "SaveVotes", mock.Anything, mock.Anything)
+}
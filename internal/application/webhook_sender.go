@@ -0,0 +1,21 @@
+package application
+
+import "context"
+
+type WebhookRequest struct {
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+type WebhookResponse struct {
+	Status int
+	// Body is the start of the response body, kept for the delivery log
+	Body string
+}
+
+// WebhookSender posts webhook payloads. An error means no response was
+// received; any response, whatever its status, is returned as is.
+type WebhookSender interface {
+	Send(ctx context.Context, request WebhookRequest) (*WebhookResponse, error)
+}
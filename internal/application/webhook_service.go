@@ -0,0 +1,38 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+)
+
+type WebhookService interface {
+	// CreateWebhook registers a webhook for one of the user's polls or for
+	// all of them. The response carries the only copy of the signing
+	// secret.
+	CreateWebhook(ctx context.Context, createRequest dto.CreateWebhookRequest) (*dto.WebhookResponse, error)
+
+	GetWebhooks(ctx context.Context) ([]dto.WebhookResponse, error)
+
+	DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error
+
+	// GetDeliveries returns the webhook's delivery log, newest first.
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID) ([]dto.WebhookDeliveryResponse, error)
+
+	// Redeliver queues the payload of an earlier delivery again.
+	Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) (*dto.WebhookDeliveryResponse, error)
+
+	// HandleEvent queues the webhook events that follow from an outbox
+	// event: poll.created, vote.cast, and poll.closed with
+	// poll.results_finalized. It is the outbox relay's webhooks consumer.
+	HandleEvent(ctx context.Context, event domain.OutboxEvent) error
+
+	// DeliverDue sends the deliveries that are due and schedules retries
+	// for the ones that fail.
+	DeliverDue(ctx context.Context) error
+
+	// PurgeDeliveries drops finished deliveries older than the log keeps.
+	PurgeDeliveries(ctx context.Context) error
+}
@@ -0,0 +1,548 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application/repository"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+const (
+	// a delivery is given up after webhookMaxAttempts, waiting twice as
+	// long before each retry
+	webhookMaxAttempts = 10
+	webhookRetryDelay  = time.Second * 30
+	webhookMaxDelay    = time.Hour
+
+	// webhookDeliveryLease covers sending a claimed delivery, which the
+	// sender times out well before
+	webhookDeliveryLease = time.Minute * 2
+	webhookClaimBatch    = 50
+
+	webhookLogSize      = 100
+	webhookLogRetention = time.Hour * 24 * 30
+)
+
+type webhookservice struct {
+	repo         repository.WebhookRepository
+	deliveryrepo repository.WebhookDeliveryRepository
+	pollrepo     repository.PollRepository
+	policy       PollPolicy
+	sender       WebhookSender
+
+	now func() time.Time
+}
+
+func NewWebhookService(repo repository.WebhookRepository, deliveryrepo repository.WebhookDeliveryRepository, pollrepo repository.PollRepository, policy PollPolicy, sender WebhookSender) WebhookService {
+	return &webhookservice{
+		repo:         repo,
+		deliveryrepo: deliveryrepo,
+		pollrepo:     pollrepo,
+		policy:       policy,
+		sender:       sender,
+		now:          time.Now,
+	}
+}
+
+func (s *webhookservice) CreateWebhook(ctx context.Context, createRequest dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	target, err := url.Parse(createRequest.URL)
+
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, utils.InvalidWebhookURLError
+	}
+
+	// names are checked again when deliveries connect, once they resolve
+	if addr, err := netip.ParseAddr(target.Hostname()); (err == nil && !utils.IsPublicAddr(addr)) || strings.EqualFold(target.Hostname(), "localhost") {
+		return nil, utils.WebhookAddressNotAllowedError
+	}
+
+	if createRequest.PollID != nil {
+
+		poll, err := s.pollrepo.FindPollByID(ctx, *createRequest.PollID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		allowed, err := s.policy.Can(ctx, poll, userID, domain.PollActionManage)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !allowed {
+			return nil, utils.PollPermissionDeniedError
+		}
+	}
+
+	secret, err := utils.GenerateToken(32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := domain.Webhook{
+		UserID: userID,
+		PollID: createRequest.PollID,
+		URL:    createRequest.URL,
+		Secret: "whsec_" + secret,
+		Events: createRequest.Events,
+	}
+
+	if err := s.repo.Save(ctx, &webhook); err != nil {
+		return nil, err
+	}
+
+	response := webhookResponse(webhook)
+	response.Secret = webhook.Secret
+
+	return &response, nil
+}
+
+func (s *webhookservice) GetWebhooks(ctx context.Context) ([]dto.WebhookResponse, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	webhooks, err := s.repo.FindByUserID(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WebhookResponse, 0, len(webhooks))
+
+	for _, webhook := range webhooks {
+		responses = append(responses, webhookResponse(webhook))
+	}
+
+	return responses, nil
+}
+
+func (s *webhookservice) DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error {
+
+	if _, err := s.ownWebhook(ctx, webhookID); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, webhookID)
+}
+
+func (s *webhookservice) GetDeliveries(ctx context.Context, webhookID uuid.UUID) ([]dto.WebhookDeliveryResponse, error) {
+
+	if _, err := s.ownWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.deliveryrepo.FindByWebhookID(ctx, webhookID, webhookLogSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WebhookDeliveryResponse, 0, len(deliveries))
+
+	for _, delivery := range deliveries {
+		responses = append(responses, webhookDeliveryResponse(delivery))
+	}
+
+	return responses, nil
+}
+
+func (s *webhookservice) Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) (*dto.WebhookDeliveryResponse, error) {
+
+	if _, err := s.ownWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	original, err := s.deliveryrepo.FindByID(ctx, deliveryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if original.WebhookID != webhookID {
+		return nil, utils.WebhookDeliveryNotFoundError
+	}
+
+	// the payload is sent unchanged, so its id still lets the receiver
+	// tell it apart from a new event
+	delivery := domain.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		PollID:        original.PollID,
+		Payload:       original.Payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: s.now(),
+	}
+
+	if err := s.deliveryrepo.SaveAll(ctx, []domain.WebhookDelivery{delivery}); err != nil {
+		return nil, err
+	}
+
+	response := webhookDeliveryResponse(delivery)
+
+	return &response, nil
+}
+
+// ownWebhook returns the webhook if the signed in user registered it. Other
+// users' webhooks are reported as missing.
+func (s *webhookservice) ownWebhook(ctx context.Context, webhookID uuid.UUID) (*domain.Webhook, error) {
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	webhook, err := s.repo.FindByID(ctx, webhookID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook.UserID != userID {
+		return nil, utils.WebhookNotFoundError
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookservice) HandleEvent(ctx context.Context, event domain.OutboxEvent) error {
+
+	switch event.Type {
+	case domain.OutboxPollCreated, domain.OutboxVoteCast, domain.OutboxPollClosed:
+	default:
+		return nil
+	}
+
+	poll, err := s.pollrepo.FindPollByID(ctx, event.PollID)
+
+	if err != nil {
+		// webhooks go quiet once their poll is deleted
+		if errors.Is(err, utils.PollNotFoundError) {
+			return nil
+		}
+		return err
+	}
+
+	switch event.Type {
+
+	case domain.OutboxPollCreated:
+
+		return s.publish(ctx, poll, event.ID, domain.WebhookEventPollCreated, poll.CreatedAt, dto.WebhookPollCreated{
+			Poll: webhookPoll(poll),
+		})
+
+	case domain.OutboxVoteCast:
+
+		var vote domain.VoteCastPayload
+
+		if err := event.DecodePayload(&vote); err != nil {
+			return err
+		}
+
+		return s.publish(ctx, poll, event.ID, domain.WebhookEventVoteCast, event.CreatedAt, dto.WebhookVoteCast{
+			PollID:   poll.ID,
+			OptionID: vote.OptionID,
+			VoterID:  vote.UserID,
+			VotedAt:  event.CreatedAt,
+		})
+
+	default:
+
+		if err := s.publish(ctx, poll, event.ID, domain.WebhookEventPollClosed, poll.ExpiresAt, dto.WebhookPollClosed{
+			Poll:     webhookPoll(poll),
+			ClosedAt: poll.ExpiresAt,
+		}); err != nil {
+			return err
+		}
+
+		// the second webhook event needs an ID of its own, derived so it
+		// stays the same if the outbox event is handled again
+		resultsID := uuid.NewSHA1(event.ID, []byte(domain.WebhookEventResultsFinalized))
+
+		return s.publish(ctx, poll, resultsID, domain.WebhookEventResultsFinalized, poll.ExpiresAt, webhookResults(poll))
+	}
+}
+
+// publish queues the event for every webhook on the poll that subscribes to
+// it. Webhooks registered after the event happened are skipped, as are poll
+// webhooks whose owner no longer runs the poll. Delivery IDs are derived from
+// the event and webhook, so publishing an event again queues nothing new.
+func (s *webhookservice) publish(ctx context.Context, poll *domain.Poll, eventID uuid.UUID, event domain.WebhookEvent, occurredAt time.Time, data any) error {
+
+	webhooks, err := s.repo.FindForPoll(ctx, poll.ID, poll.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(dto.WebhookPayload{
+		ID:        eventID,
+		Event:     string(event),
+		CreatedAt: occurredAt.UTC(),
+		Data:      data,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	var deliveries []domain.WebhookDelivery
+
+	for _, webhook := range webhooks {
+
+		if !webhook.Subscribes(event) || webhook.CreatedAt.After(occurredAt) {
+			continue
+		}
+
+		if webhook.UserID != poll.UserID {
+
+			allowed, err := s.policy.Can(ctx, poll, webhook.UserID, domain.PollActionManage)
+
+			if err != nil {
+				return err
+			}
+
+			if !allowed {
+				continue
+			}
+		}
+
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			ID:            uuid.NewSHA1(eventID, webhook.ID[:]),
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			Event:         event,
+			PollID:        poll.ID,
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: s.now(),
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.deliveryrepo.SaveAll(ctx, deliveries)
+}
+
+func (s *webhookservice) DeliverDue(ctx context.Context) error {
+
+	for {
+		deliveries, err := s.deliveryrepo.ClaimDue(ctx, s.now(), webhookDeliveryLease, webhookClaimBatch)
+
+		if err != nil {
+			return err
+		}
+
+		webhooks := make(map[uuid.UUID]*domain.Webhook)
+
+		for i := range deliveries {
+
+			delivery := &deliveries[i]
+
+			webhook, ok := webhooks[delivery.WebhookID]
+
+			if !ok {
+				webhook, err = s.repo.FindByID(ctx, delivery.WebhookID)
+
+				if err != nil && !errors.Is(err, utils.WebhookNotFoundError) {
+					return err
+				}
+
+				webhooks[delivery.WebhookID] = webhook
+			}
+
+			s.deliver(ctx, webhook, delivery)
+
+			if err := s.deliveryrepo.Update(ctx, delivery); err != nil {
+				return err
+			}
+		}
+
+		if len(deliveries) < webhookClaimBatch {
+			return nil
+		}
+	}
+}
+
+// deliver makes one attempt at sending the delivery and records the outcome
+// on it. Anything but a 2xx response is retried until the attempts run out.
+func (s *webhookservice) deliver(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) {
+
+	now := s.now()
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	if webhook == nil {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.Error = utils.WebhookNotFoundError.Error()
+		return
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	response, err := s.sender.Send(ctx, WebhookRequest{
+		URL: webhook.URL,
+		Headers: map[string]string{
+			"Content-Type":      "application/json",
+			"User-Agent":        "Jille-Webhooks/1.0",
+			"X-Jille-Event":     string(delivery.Event),
+			"X-Jille-Delivery":  delivery.ID.String(),
+			"X-Jille-Signature": "t=" + timestamp + ",v1=" + signWebhook(webhook.Secret, timestamp, []byte(delivery.Payload)),
+		},
+		Body: []byte(delivery.Payload),
+	})
+
+	if err != nil {
+		delivery.Error = err.Error()
+	} else {
+		delivery.ResponseStatus = response.Status
+		delivery.ResponseBody = response.Body
+
+		if response.Status >= 200 && response.Status < 300 {
+			delivery.Status = domain.WebhookDeliverySucceeded
+			return
+		}
+
+		delivery.Error = fmt.Sprintf("webhook responded with status %d", response.Status)
+	}
+
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = domain.WebhookDeliveryFailed
+		return
+	}
+
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+}
+
+func (s *webhookservice) PurgeDeliveries(ctx context.Context) error {
+	return s.deliveryrepo.DeleteBefore(ctx, s.now().Add(-webhookLogRetention))
+}
+
+// signWebhook signs the timestamp and body the way receivers check them:
+// the hex HMAC-SHA256 of "timestamp.body" keyed with the webhook's secret.
+// Covering the timestamp lets receivers reject replayed payloads.
+func signWebhook(secret string, timestamp string, body []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff is the wait before the retry that follows the given attempt.
+func webhookBackoff(attempt int) time.Duration {
+
+	delay := webhookRetryDelay << (attempt - 1)
+
+	if delay <= 0 || delay > webhookMaxDelay {
+		return webhookMaxDelay
+	}
+
+	return delay
+}
+
+func webhookPoll(poll *domain.Poll) dto.WebhookPoll {
+
+	options := make([]dto.WebhookOption, 0, len(poll.Options))
+
+	for _, option := range poll.Options {
+		options = append(options, dto.WebhookOption{ID: option.ID, Name: option.Name})
+	}
+
+	return dto.WebhookPoll{
+		ID:        poll.ID,
+		Title:     poll.Title,
+		Options:   options,
+		CreatedAt: poll.CreatedAt,
+		ExpiresAt: poll.ExpiresAt,
+	}
+}
+
+func webhookResults(poll *domain.Poll) dto.WebhookResultsFinalized {
+
+	results := dto.WebhookResultsFinalized{
+		Poll:    webhookPoll(poll),
+		Results: make([]dto.WebhookResult, 0, len(poll.Options)),
+	}
+
+	for _, option := range poll.Options {
+		results.TotalVotes += option.VoteCount
+	}
+
+	for _, option := range poll.Options {
+
+		result := dto.WebhookResult{
+			OptionID: option.ID,
+			Option:   option.Name,
+			Votes:    option.VoteCount,
+		}
+
+		if results.TotalVotes > 0 {
+			result.Share = float64(result.Votes) / float64(results.TotalVotes)
+		}
+
+		results.Results = append(results.Results, result)
+	}
+
+	return results
+}
+
+func webhookResponse(webhook domain.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		PollID:    webhook.PollID,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func webhookDeliveryResponse(delivery domain.WebhookDelivery) dto.WebhookDeliveryResponse {
+
+	response := dto.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		Event:          string(delivery.Event),
+		PollID:         delivery.PollID,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		Payload:        json.RawMessage(delivery.Payload),
+		CreatedAt:      delivery.CreatedAt,
+	}
+
+	if delivery.Status == domain.WebhookDeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+
+	return response
+}
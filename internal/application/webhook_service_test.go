@@ -0,0 +1,296 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/winnerx0/jille/internal/application/repository/mocks"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/domain"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, request WebhookRequest) (*WebhookResponse, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WebhookResponse), args.Error(1)
+}
+
+func newTestWebhookService(now time.Time) (*webhookservice, *mocks.WebhookRepository, *mocks.WebhookDeliveryRepository, *mocks.PollRepository, *MockWebhookSender) {
+	repo := new(mocks.WebhookRepository)
+	deliveryrepo := new(mocks.WebhookDeliveryRepository)
+	pollrepo := new(mocks.PollRepository)
+	sender := new(MockWebhookSender)
+
+	service := NewWebhookService(repo, deliveryrepo, pollrepo, newTestPollPolicy(new(mocks.OrganizationRepository), new(mocks.VoteRepository)), sender).(*webhookservice)
+	service.now = func() time.Time { return now }
+
+	return service, repo, deliveryrepo, pollrepo, sender
+}
+
+func TestCreateWebhook_RejectsNonHTTPURL(t *testing.T) {
+	service, repo, _, _, _ := newTestWebhookService(time.Now())
+
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+
+	_, err := service.CreateWebhook(ctx, dto.CreateWebhookRequest{
+		URL:    "ftp://example.com/hook",
+		Events: []string{string(domain.WebhookEventVoteCast)},
+	})
+
+	assert.ErrorIs(t, err, utils.InvalidWebhookURLError)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestCreateWebhook_RejectsInternalAddresses(t *testing.T) {
+	service, repo, _, _, _ := newTestWebhookService(time.Now())
+
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+
+	for _, url := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"https://[fd00:ec2::254]/hook",
+	} {
+		_, err := service.CreateWebhook(ctx, dto.CreateWebhookRequest{
+			URL:    url,
+			Events: []string{string(domain.WebhookEventVoteCast)},
+		})
+
+		assert.ErrorIs(t, err, utils.WebhookAddressNotAllowedError, url)
+	}
+
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestWebhookHandleEvent_QueuesSubscribedWebhooks(t *testing.T) {
+	now := time.Now()
+	service, repo, deliveryrepo, pollrepo, _ := newTestWebhookService(now)
+
+	creatorID := uuid.New()
+	poll := &domain.Poll{
+		ID:        uuid.New(),
+		Title:     "Lunch",
+		UserID:    creatorID,
+		CreatedAt: now,
+		Options:   []domain.Option{{ID: uuid.New(), Name: "Pizza"}},
+	}
+
+	subscribed := domain.Webhook{ID: uuid.New(), UserID: creatorID, Events: []string{string(domain.WebhookEventPollCreated)}, CreatedAt: now.Add(-time.Hour)}
+	otherEvent := domain.Webhook{ID: uuid.New(), UserID: creatorID, Events: []string{string(domain.WebhookEventVoteCast)}, CreatedAt: now.Add(-time.Hour)}
+
+	pollrepo.On("FindPollByID", mock.Anything, poll.ID).Return(poll, nil)
+	repo.On("FindForPoll", mock.Anything, poll.ID, creatorID).Return([]domain.Webhook{subscribed, otherEvent}, nil)
+
+	var queued []domain.WebhookDelivery
+	deliveryrepo.On("SaveAll", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		queued = args.Get(1).([]domain.WebhookDelivery)
+	}).Return(nil)
+
+	event := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollCreated, PollID: poll.ID, CreatedAt: now}
+
+	err := service.HandleEvent(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Len(t, queued, 1)
+	assert.Equal(t, subscribed.ID, queued[0].WebhookID)
+	assert.Equal(t, event.ID, queued[0].EventID)
+	assert.Equal(t, domain.WebhookDeliveryPending, queued[0].Status)
+
+	// handled again, the event queues a delivery with the same ID, which
+	// the repository skips
+	first := queued[0].ID
+	assert.NoError(t, service.HandleEvent(context.Background(), event))
+	assert.Equal(t, first, queued[0].ID)
+
+	var payload struct {
+		ID    uuid.UUID `json:"id"`
+		Event string    `json:"event"`
+		Data  struct {
+			Poll dto.WebhookPoll `json:"poll"`
+		} `json:"data"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(queued[0].Payload), &payload))
+	assert.Equal(t, queued[0].EventID, payload.ID)
+	assert.Equal(t, "poll.created", payload.Event)
+	assert.Equal(t, "Lunch", payload.Data.Poll.Title)
+}
+
+func TestWebhookHandleEvent_PollClosedQueuesResults(t *testing.T) {
+	now := time.Now()
+	service, repo, deliveryrepo, pollrepo, _ := newTestWebhookService(now)
+
+	poll := &domain.Poll{ID: uuid.New(), Title: "Lunch", UserID: uuid.New(), ExpiresAt: now}
+	webhook := domain.Webhook{ID: uuid.New(), UserID: poll.UserID, Events: []string{string(domain.WebhookEventPollClosed), string(domain.WebhookEventResultsFinalized)}, CreatedAt: now.Add(-time.Hour)}
+
+	pollrepo.On("FindPollByID", mock.Anything, poll.ID).Return(poll, nil)
+	repo.On("FindForPoll", mock.Anything, poll.ID, poll.UserID).Return([]domain.Webhook{webhook}, nil)
+
+	var queued []domain.WebhookDelivery
+	deliveryrepo.On("SaveAll", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		queued = append(queued, args.Get(1).([]domain.WebhookDelivery)...)
+	}).Return(nil)
+
+	event := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxPollClosed, PollID: poll.ID}
+
+	assert.NoError(t, service.HandleEvent(context.Background(), event))
+	assert.Len(t, queued, 2)
+	assert.Equal(t, domain.WebhookEventPollClosed, queued[0].Event)
+	assert.Equal(t, event.ID, queued[0].EventID)
+	assert.Equal(t, domain.WebhookEventResultsFinalized, queued[1].Event)
+	assert.NotEqual(t, event.ID, queued[1].EventID)
+}
+
+func TestWebhookHandleEvent_DeletedPoll(t *testing.T) {
+	service, _, deliveryrepo, pollrepo, _ := newTestWebhookService(time.Now())
+
+	event := domain.OutboxEvent{ID: uuid.New(), Type: domain.OutboxVoteCast, PollID: uuid.New()}
+
+	pollrepo.On("FindPollByID", mock.Anything, event.PollID).Return(nil, utils.PollNotFoundError)
+
+	assert.NoError(t, service.HandleEvent(context.Background(), event))
+	deliveryrepo.AssertNotCalled(t, "SaveAll", mock.Anything, mock.Anything)
+}
+
+func TestDeliverDue_SignsPayload(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	service, repo, deliveryrepo, _, sender := newTestWebhookService(now)
+
+	webhook := &domain.Webhook{ID: uuid.New(), URL: "https://example.com/hook", Secret: "whsec_test"}
+	delivery := domain.WebhookDelivery{ID: uuid.New(), WebhookID: webhook.ID, Event: domain.WebhookEventVoteCast, Payload: `{"event":"vote.cast"}`}
+
+	deliveryrepo.On("ClaimDue", mock.Anything, now, webhookDeliveryLease, webhookClaimBatch).Return([]domain.WebhookDelivery{delivery}, nil)
+	repo.On("FindByID", mock.Anything, webhook.ID).Return(webhook, nil)
+
+	var sent WebhookRequest
+	sender.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(1).(WebhookRequest)
+	}).Return(&WebhookResponse{Status: 204}, nil)
+
+	var updated *domain.WebhookDelivery
+	deliveryrepo.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		updated = args.Get(1).(*domain.WebhookDelivery)
+	}).Return(nil)
+
+	err := service.DeliverDue(context.Background())
+
+	assert.NoError(t, err)
+
+	// receivers check the signature like this
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + delivery.Payload))
+
+	assert.Equal(t, "t=1700000000,v1="+hex.EncodeToString(mac.Sum(nil)), sent.Headers["X-Jille-Signature"])
+	assert.Equal(t, "vote.cast", sent.Headers["X-Jille-Event"])
+	assert.Equal(t, delivery.ID.String(), sent.Headers["X-Jille-Delivery"])
+	assert.Equal(t, domain.WebhookDeliverySucceeded, updated.Status)
+	assert.Equal(t, 1, updated.Attempts)
+}
+
+func TestDeliverDue_RetriesFailures(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		attempts int
+		response *WebhookResponse
+		err      error
+		status   domain.WebhookDeliveryStatus
+	}{
+		{"server error is retried", 0, &WebhookResponse{Status: 500, Body: "oops"}, nil, domain.WebhookDeliveryPending},
+		{"unreachable receiver is retried", 2, nil, errors.New("connection refused"), domain.WebhookDeliveryPending},
+		{"last attempt fails the delivery", webhookMaxAttempts - 1, &WebhookResponse{Status: 502}, nil, domain.WebhookDeliveryFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, deliveryrepo, _, sender := newTestWebhookService(now)
+
+			webhook := &domain.Webhook{ID: uuid.New(), URL: "https://example.com/hook", Secret: "whsec_test"}
+			delivery := domain.WebhookDelivery{ID: uuid.New(), WebhookID: webhook.ID, Attempts: tt.attempts, Payload: "{}"}
+
+			deliveryrepo.On("ClaimDue", mock.Anything, now, webhookDeliveryLease, webhookClaimBatch).Return([]domain.WebhookDelivery{delivery}, nil)
+			repo.On("FindByID", mock.Anything, webhook.ID).Return(webhook, nil)
+			sender.On("Send", mock.Anything, mock.Anything).Return(tt.response, tt.err)
+
+			var updated *domain.WebhookDelivery
+			deliveryrepo.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				updated = args.Get(1).(*domain.WebhookDelivery)
+			}).Return(nil)
+
+			assert.NoError(t, service.DeliverDue(context.Background()))
+			assert.Equal(t, tt.status, updated.Status)
+			assert.Equal(t, tt.attempts+1, updated.Attempts)
+			assert.NotEmpty(t, updated.Error)
+
+			if tt.status == domain.WebhookDeliveryPending {
+				assert.Equal(t, now.Add(webhookBackoff(updated.Attempts)), updated.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, time.Minute, webhookBackoff(2))
+	assert.Equal(t, 4*time.Minute, webhookBackoff(4))
+	assert.Equal(t, webhookMaxDelay, webhookBackoff(webhookMaxAttempts))
+}
+
+func TestRedeliver_OtherUsersWebhook(t *testing.T) {
+	service, repo, deliveryrepo, _, _ := newTestWebhookService(time.Now())
+
+	webhook := &domain.Webhook{ID: uuid.New(), UserID: uuid.New()}
+	ctx := context.WithValue(context.Background(), "userID", uuid.New().String())
+
+	repo.On("FindByID", mock.Anything, webhook.ID).Return(webhook, nil)
+
+	_, err := service.Redeliver(ctx, webhook.ID, uuid.New())
+
+	assert.ErrorIs(t, err, utils.WebhookNotFoundError)
+	deliveryrepo.AssertNotCalled(t, "SaveAll", mock.Anything, mock.Anything)
+}
+
+func TestRedeliver_CopiesPayload(t *testing.T) {
+	service, repo, deliveryrepo, _, _ := newTestWebhookService(time.Now())
+
+	userID := uuid.New()
+	webhook := &domain.Webhook{ID: uuid.New(), UserID: userID}
+	original := &domain.WebhookDelivery{ID: uuid.New(), WebhookID: webhook.ID, EventID: uuid.New(), Event: domain.WebhookEventPollClosed, Payload: `{"event":"poll.closed"}`, Status: domain.WebhookDeliveryFailed, Attempts: webhookMaxAttempts}
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+	repo.On("FindByID", mock.Anything, webhook.ID).Return(webhook, nil)
+	deliveryrepo.On("FindByID", mock.Anything, original.ID).Return(original, nil)
+	deliveryrepo.On("SaveAll", mock.Anything, mock.MatchedBy(func(deliveries []domain.WebhookDelivery) bool {
+		return len(deliveries) == 1 &&
+			deliveries[0].EventID == original.EventID &&
+			deliveries[0].Payload == original.Payload &&
+			deliveries[0].Status == domain.WebhookDeliveryPending &&
+			deliveries[0].Attempts == 0
+	})).Return(nil)
+
+	response, err := service.Redeliver(ctx, webhook.ID, original.ID)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(response.Payload), "poll.closed")
+	deliveryrepo.AssertExpectations(t)
+}
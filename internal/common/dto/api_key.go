@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=64"`
+
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=polls:read polls:write votes:read votes:write"`
+
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+
+	// Key is only ever returned when the key is created.
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal is who a request authenticated with an API key acts as.
+type APIKeyPrincipal struct {
+	UserID uuid.UUID
+	Scopes []string
+}
@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+
+	// DownloadURL is only known when the export is requested, it is never
+	// stored in plain text.
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// The types below describe the files inside a data export archive.
+
+type ExportProfile struct {
+	ID             uuid.UUID `json:"id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	EmailVerified  bool      `json:"email_verified"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+	JoinedAt       time.Time `json:"joined_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type ExportPoll struct {
+	ID         uuid.UUID      `json:"id"`
+	Title      string         `json:"title"`
+	CreatedAt  time.Time      `json:"created_at"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	TotalVotes int            `json:"total_votes"`
+	Options    []ExportOption `json:"options"`
+}
+
+type ExportOption struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Votes int       `json:"votes"`
+}
+
+type ExportVote struct {
+	ID       uuid.UUID `json:"id"`
+	PollID   uuid.UUID `json:"poll_id"`
+	OptionID uuid.UUID `json:"option_id"`
+	VotedAt  time.Time `json:"voted_at"`
+}
+
+type ExportSession struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Revoked   bool      `json:"revoked"`
+}
+
+// ExportAPIKey describes a key without the key itself, which is a credential.
+type ExportAPIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type ExportIdentity struct {
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email,omitempty"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+type ExportMembership struct {
+	OrganizationID   uuid.UUID `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Role             string    `json:"role"`
+	JoinedAt         time.Time `json:"joined_at"`
+}
+
+// ExportWebhook describes a webhook without its signing secret.
+type ExportWebhook struct {
+	ID        uuid.UUID  `json:"id"`
+	URL       string     `json:"url"`
+	PollID    *uuid.UUID `json:"poll_id,omitempty"`
+	Events    []string   `json:"events"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ExportNotification struct {
+	ID        uuid.UUID  `json:"id"`
+	Kind      string     `json:"kind"`
+	PollID    uuid.UUID  `json:"poll_id"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
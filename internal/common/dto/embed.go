@@ -0,0 +1,19 @@
+package dto
+
+type OEmbedRequest struct {
+	URL       string
+	Format    string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// OEmbedResponse is a rich oEmbed response, see https://oembed.com
+type OEmbedResponse struct {
+	Type         string `json:"type"`
+	Version      string `json:"version"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationPreferenceResponse struct {
+	Kind     string `json:"kind"`
+	Delivery string `json:"delivery"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" validate:"required,min=1,dive"`
+}
+
+type NotificationPreferenceRequest struct {
+	Kind     string `json:"kind" validate:"required,oneof=poll_closed results_ready poll_invited poll_expiring"`
+	Delivery string `json:"delivery" validate:"required,oneof=instant digest off"`
+}
+
+type NotificationResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Kind      string     `json:"kind"`
+	PollID    uuid.UUID  `json:"poll_id"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type UnreadNotificationsResponse struct {
+	Count int64 `json:"count"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=64"`
+}
+
+type AddMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+
+	Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type OrganizationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Role is the signed in user's role in the organization
+	Role string `json:"role"`
+
+	Members []OrganizationMemberResponse `json:"members,omitempty"`
+}
+
+type OrganizationMemberResponse struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
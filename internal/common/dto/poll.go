@@ -1,11 +1,21 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type CreatePollRequest struct {
 	Title     string    `json:"title" validate:"required"`
 	Options   []string  `json:"options" validate:"required,optionlistmin=2,optionlistmax=15"`
 	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+
+	OrganizationID *uuid.UUID `json:"organization_id"`
+	MembersOnly    bool       `json:"members_only"`
+	InviteOnly     bool       `json:"invite_only"`
+
+	ResultsVisibility string `json:"results_visibility" validate:"omitempty,oneof=always after_vote after_close creator_only"`
 }
 
 type PollResponse struct {
@@ -22,19 +32,51 @@ type PollViewResponse struct {
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatorID string    `json:"creator_id"`
 	Voted     bool      `json:"voted"`
+
+	OrganizationID string `json:"organization_id,omitempty"`
+	MembersOnly    bool   `json:"members_only"`
+	InviteOnly     bool   `json:"invite_only"`
+
+	ResultsVisibility string `json:"results_visibility"`
+
+	// ResultsVisible tells whether Options carry vote counts for the
+	// signed in user
+	ResultsVisible bool `json:"results_visible"`
+
+	// VotersVisible tells whether the signed in user may list who voted
+	VotersVisible bool `json:"voters_visible"`
+
+	// LastVoteAt is when the latest vote was cast, set with the results
+	LastVoteAt *time.Time `json:"last_vote_at,omitempty"`
 }
 
 type Vote struct {
-	ID       string `json:"id"`
-	UserID   string `json:"user_id"`
-	PollID   string `json:"poll_id"`
-	OptionID string `json:"option_id"`
+	ID       string    `json:"id"`
+	UserID   string    `json:"user_id"`
+	PollID   string    `json:"poll_id"`
+	OptionID string    `json:"option_id"`
+	VotedAt  time.Time `json:"voted_at"`
+}
+
+// PollVotesResponse is a page of a poll's votes, oldest first. NextCursor
+// fetches the next page and is empty on the last one.
+type PollVotesResponse struct {
+	Votes      []Vote `json:"votes"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
+// Option carries the number of votes for the option, which is 0 unless the
+// results are visible.
 type Option struct {
 	ID    string `json:"id"`
 	Name  string `json:"name"`
-	Votes []Vote `json:"votes"`
+	Votes int    `json:"votes"`
+}
+
+// PollStreamEvent is the payload of poll stream events about the poll as a
+// whole.
+type PollStreamEvent struct {
+	PollID uuid.UUID `json:"poll_id"`
 }
 
 type ApiResponse[T any] struct {
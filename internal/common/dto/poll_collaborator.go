@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AddCollaboratorRequest struct {
+	Email string `json:"email" validate:"required,email"`
+
+	Role string `json:"role" validate:"required,oneof=co_owner results_viewer"`
+}
+
+type UpdateCollaboratorRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=co_owner results_viewer"`
+}
+
+type PollCollaboratorResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	GrantedAt time.Time `json:"granted_at"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateInvitesRequest struct {
+	Emails []string `json:"emails" validate:"omitempty,max=500,dive,email"`
+
+	UserIDs []uuid.UUID `json:"user_ids" validate:"omitempty,max=500"`
+}
+
+type PollInvitesResponse struct {
+	Invited int `json:"invited"`
+	Voted   int `json:"voted"`
+
+	// ParticipationRate is Voted divided by Invited, between 0 and 1
+	ParticipationRate float64 `json:"participation_rate"`
+
+	Invites []PollInviteResponse `json:"invites"`
+}
+
+type PollInviteResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Email     string     `json:"email"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Voted     bool       `json:"voted"`
+	InvitedAt time.Time  `json:"invited_at"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+// SlackMessage is a Block Kit message, either the reply to a slash command
+// or a message sent to an interaction's response_url.
+type SlackMessage struct {
+	ResponseType    string       `json:"response_type,omitempty"`
+	ReplaceOriginal bool         `json:"replace_original,omitempty"`
+	Text            string       `json:"text"`
+	Blocks          []SlackBlock `json:"blocks,omitempty"`
+}
+
+type SlackBlock struct {
+	Type      string       `json:"type"`
+	BlockID   string       `json:"block_id,omitempty"`
+	Text      *SlackText   `json:"text,omitempty"`
+	Accessory *SlackButton `json:"accessory,omitempty"`
+	Elements  []SlackText  `json:"elements,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type SlackButton struct {
+	Type     string    `json:"type"`
+	Text     SlackText `json:"text"`
+	ActionID string    `json:"action_id"`
+	Value    string    `json:"value"`
+	Style    string    `json:"style,omitempty"`
+}
+
+// SlackInteraction is the part of a block_actions payload the poll messages
+// need.
+type SlackInteraction struct {
+	Type        string        `json:"type"`
+	User        SlackUser     `json:"user"`
+	Team        SlackTeam     `json:"team"`
+	ResponseURL string        `json:"response_url"`
+	Actions     []SlackAction `json:"actions"`
+}
+
+type SlackUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	TeamID   string `json:"team_id"`
+}
+
+type SlackTeam struct {
+	ID     string `json:"id"`
+	Domain string `json:"domain"`
+}
+
+type SlackAction struct {
+	ActionID string `json:"action_id"`
+	BlockID  string `json:"block_id"`
+	Value    string `json:"value"`
+}
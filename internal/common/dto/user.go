@@ -1,10 +1,17 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UserResponse struct {
-	ID    uuid.UUID `json:"id"`
-	Email string    `json:"email"`
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+
+	EmailVerified bool `json:"email_verified"`
 
 	PollCount int `json:"poll_count"`
 
@@ -28,19 +35,98 @@ type LoginUserRequest struct {
 type AuthTokens struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
+	MFAToken     string `json:"mfaToken,omitempty"`
 }
 
 type AuthResponse struct {
-	Message    string `json:"message"`
-	AuthTokens `json:"data"`
+	Message     string `json:"message"`
+	MFARequired bool   `json:"mfaRequired,omitempty"`
+	AuthTokens  `json:"data"`
 }
 
 type UserAuthView struct {
-	ID       uuid.UUID
-	Email    string
-	Password string
+	ID            uuid.UUID
+	Username      string
+	Email         string
+	Password      string
+	EmailVerified bool
+
+	Passwordless     bool
+	ProviderSignInAt *time.Time
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `validate:"required"`
-}
\ No newline at end of file
+}
+
+type VerifyEmailRequest struct {
+	Token string `validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `validate:"required,email"`
+}
+
+type PasswordResetRequest struct {
+	Email string `validate:"required,email"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token string `validate:"required"`
+
+	Password string `validate:"required,min=8,max=16"`
+}
+
+type OIDCCallbackRequest struct {
+	Code string `validate:"required"`
+
+	State string `validate:"required"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+
+	URI string `json:"uri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	Message string `json:"message"`
+
+	Codes []string `json:"recovery_codes"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `validate:"required"`
+
+	Code string `validate:"required"`
+}
+
+type UpdateUsernameRequest struct {
+	Username string `validate:"required,min=5,max=10"`
+}
+
+// The password confirming a change is left out by passwordless accounts,
+// which sign in through their identity provider again instead.
+
+type ChangeEmailRequest struct {
+	Email string `validate:"required,email"`
+
+	Password string
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+
+	NewPassword string `json:"new_password" validate:"required,min=8,max=16"`
+}
+
+type DeleteAccountRequest struct {
+	Password string
+
+	// Mode is either "anonymize" or "remove"
+	Mode string `validate:"required,oneof=anonymize remove"`
+}
@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,url,max=2048"`
+
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=poll.created vote.cast poll.closed poll.results_finalized"`
+
+	// PollID limits the webhook to one poll, otherwise it covers every
+	// poll the user creates
+	PollID *uuid.UUID `json:"poll_id"`
+}
+
+type WebhookResponse struct {
+	ID     uuid.UUID  `json:"id"`
+	URL    string     `json:"url"`
+	Events []string   `json:"events"`
+	PollID *uuid.UUID `json:"poll_id,omitempty"`
+
+	// Secret signs the payloads and is only returned when the webhook is
+	// created
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	Event          string          `json:"event"`
+	PollID         uuid.UUID       `json:"poll_id"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookPayload is the body posted to a webhook.
+type WebhookPayload struct {
+	ID        uuid.UUID `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type WebhookPoll struct {
+	ID        uuid.UUID       `json:"id"`
+	Title     string          `json:"title"`
+	Options   []WebhookOption `json:"options"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+type WebhookOption struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type WebhookPollCreated struct {
+	Poll WebhookPoll `json:"poll"`
+}
+
+type WebhookVoteCast struct {
+	PollID   uuid.UUID `json:"poll_id"`
+	OptionID uuid.UUID `json:"option_id"`
+	VoterID  uuid.UUID `json:"voter_id"`
+	VotedAt  time.Time `json:"voted_at"`
+}
+
+type WebhookPollClosed struct {
+	Poll     WebhookPoll `json:"poll"`
+	ClosedAt time.Time   `json:"closed_at"`
+}
+
+type WebhookResultsFinalized struct {
+	Poll       WebhookPoll     `json:"poll"`
+	TotalVotes int             `json:"total_votes"`
+	Results    []WebhookResult `json:"results"`
+}
+
+type WebhookResult struct {
+	OptionID uuid.UUID `json:"option_id"`
+	Option   string    `json:"option"`
+	Votes    int       `json:"votes"`
+	Share    float64   `json:"share"`
+}
@@ -0,0 +1,198 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// maxAvatarUpload caps how much of an upload is read; the service rejects
+// anything over its own limit.
+const maxAvatarUpload = 2<<20 + 1
+
+type accountHandler struct {
+	userservice application.UserService
+	authservice application.AuthService
+	validator   utils.XValidator
+}
+
+func NewAccountHandler(userservice application.UserService, authservice application.AuthService, validator utils.XValidator) *accountHandler {
+	return &accountHandler{
+		userservice: userservice,
+		authservice: authservice,
+		validator:   validator,
+	}
+}
+
+func (h *accountHandler) GetAccount(c fiber.Ctx) error {
+
+	userID := uuid.MustParse(c.Locals("userID").(string))
+
+	response, err := h.userservice.GetUserById(c.Context(), userID)
+
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.UserResponse]{Message: "User retrieved successfully", Data: response})
+}
+
+func (h *accountHandler) UpdateUsername(c fiber.Ctx) error {
+
+	var updateRequest dto.UpdateUsernameRequest
+
+	if ok, err := h.bind(c, &updateRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.userservice.UpdateUsername(ctx, updateRequest)
+
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.UserResponse]{Message: "Username updated successfully", Data: response})
+}
+
+func (h *accountHandler) ChangeEmail(c fiber.Ctx) error {
+
+	var changeRequest dto.ChangeEmailRequest
+
+	if ok, err := h.bind(c, &changeRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	if err := h.authservice.RequestEmailChange(ctx, changeRequest); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(202).JSON(fiber.Map{"message": "Check your new email address to confirm the change"})
+}
+
+func (h *accountHandler) ConfirmEmailChange(c fiber.Ctx) error {
+
+	var confirmRequest dto.VerifyEmailRequest
+
+	if ok, err := h.bind(c, &confirmRequest); !ok {
+		return err
+	}
+
+	if err := h.authservice.ConfirmEmailChange(c.Context(), confirmRequest); err != nil {
+		if errors.Is(err, utils.UserExistsError) {
+			return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+		}
+		return tokenError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Email changed successfully"})
+}
+
+func (h *accountHandler) ChangePassword(c fiber.Ctx) error {
+
+	var changeRequest dto.ChangePasswordRequest
+
+	if ok, err := h.bind(c, &changeRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.authservice.ChangePassword(ctx, changeRequest)
+
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(response)
+}
+
+func (h *accountHandler) UploadProfilePicture(c fiber.Ctx) error {
+
+	fileHeader, err := c.FormFile("avatar")
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Upload the image in the avatar form field"})
+	}
+
+	file, err := fileHeader.Open()
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+	defer file.Close()
+
+	image, err := io.ReadAll(io.LimitReader(file, maxAvatarUpload))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.userservice.UpdateProfilePicture(ctx, image)
+
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.UserResponse]{Message: "Profile picture updated successfully", Data: response})
+}
+
+func (h *accountHandler) DeleteAccount(c fiber.Ctx) error {
+
+	var deleteRequest dto.DeleteAccountRequest
+
+	if ok, err := h.bind(c, &deleteRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	if err := h.userservice.DeleteAccount(ctx, deleteRequest); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Account deleted successfully"})
+}
+
+// bind parses and validates the request body. When it returns false the
+// error response has already been written.
+func (h *accountHandler) bind(c fiber.Ctx, request any) (bool, error) {
+
+	if err := c.Bind().Body(request); err != nil {
+		return false, c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(request); err != nil {
+		return false, c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return true, nil
+}
+
+func accountError(c fiber.Ctx, err error) error {
+
+	switch {
+	case errors.Is(err, utils.InvalidPasswordError), errors.Is(err, utils.ReauthenticationRequiredError):
+		return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.UserNotFoundError):
+		return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.UserExistsError):
+		return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.EmailUnchangedError), errors.Is(err, utils.UnsupportedImageError):
+		return c.Status(422).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.ImageTooLargeError):
+		return c.Status(413).JSON(fiber.Map{"message": err.Error()})
+	default:
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+}
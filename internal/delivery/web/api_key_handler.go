@@ -0,0 +1,85 @@
+package web
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type apiKeyHandler struct {
+	apikeyservice application.APIKeyService
+	validator     utils.XValidator
+}
+
+func NewAPIKeyHandler(apikeyservice application.APIKeyService, validator utils.XValidator) *apiKeyHandler {
+	return &apiKeyHandler{
+		apikeyservice: apikeyservice,
+		validator:     validator,
+	}
+}
+
+func (h *apiKeyHandler) CreateKey(c fiber.Ctx) error {
+
+	var createRequest dto.CreateAPIKeyRequest
+
+	if err := c.Bind().Body(&createRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(createRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.apikeyservice.CreateKey(ctx, createRequest)
+
+	if err != nil {
+		if errors.Is(err, utils.InvalidAPIKeyExpiryError) {
+			return c.Status(422).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(201).JSON(dto.ApiResponse[*dto.CreatedAPIKeyResponse]{
+		Message: "API key created. Copy it now, it will not be shown again",
+		Data:    response,
+	})
+}
+
+func (h *apiKeyHandler) GetKeys(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	keys, err := h.apikeyservice.GetKeys(ctx)
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.APIKeyResponse]{Message: "API keys retrieved successfully", Data: keys})
+}
+
+func (h *apiKeyHandler) RevokeKey(c fiber.Ctx) error {
+
+	keyID, err := uuid.Parse(c.Params("keyID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid API key id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	if err := h.apikeyservice.RevokeKey(ctx, keyID); err != nil {
+		if errors.Is(err, utils.APIKeyNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "API key revoked successfully"})
+}
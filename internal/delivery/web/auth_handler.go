@@ -1,8 +1,11 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/winnerx0/jille/internal/application"
@@ -67,11 +70,19 @@ func (h *authHandler) LoginUser(c fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.authservie.Login(c.RequestCtx(), loginRequest)
+	ctx := context.WithValue(c.Context(), "clientIP", c.IP())
+	response, err := h.authservie.Login(ctx, loginRequest)
 
 	if err != nil {
-		fmt.Println("error", err.Error())
-		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+		switch {
+		case errors.Is(err, utils.TooManyAttemptsError):
+			return lockoutError(c, err)
+		case errors.Is(err, utils.InvalidCredentialsError):
+			return c.Status(401).JSON(fiber.Map{"message": err.Error()})
+		default:
+			fmt.Println("error", err.Error())
+			return c.Status(500).JSON(fiber.Map{"message": "Login failed"})
+		}
 	}
 
 	if response == nil {
@@ -82,6 +93,33 @@ func (h *authHandler) LoginUser(c fiber.Ctx) error {
 
 }
 
+func (h *authHandler) LoginMFA(c fiber.Ctx) error {
+
+	var mfaRequest dto.MFALoginRequest
+
+	if err := c.Bind().Body(&mfaRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(mfaRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := context.WithValue(c.Context(), "clientIP", c.IP())
+	response, err := h.authservie.LoginMFA(ctx, mfaRequest)
+
+	if err != nil {
+		if errors.Is(err, utils.TooManyAttemptsError) {
+			return lockoutError(c, err)
+		}
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(response)
+}
+
 func (h *authHandler) RefreshToken(c fiber.Ctx) error {
 
 	var refreshTokenRequest dto.RefreshTokenRequest
@@ -115,4 +153,114 @@ func (h *authHandler) RefreshToken(c fiber.Ctx) error {
 
 	return c.JSON(response)
 
-}
\ No newline at end of file
+}
+
+func (h *authHandler) VerifyEmail(c fiber.Ctx) error {
+
+	var verifyEmailRequest dto.VerifyEmailRequest
+
+	if err := c.Bind().Body(&verifyEmailRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(verifyEmailRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.authservie.VerifyEmail(c.RequestCtx(), verifyEmailRequest); err != nil {
+		return tokenError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Email verified successfully"})
+}
+
+func (h *authHandler) ResendVerification(c fiber.Ctx) error {
+
+	var resendRequest dto.ResendVerificationRequest
+
+	if err := c.Bind().Body(&resendRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(resendRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.authservie.ResendVerification(c.RequestCtx(), resendRequest); err != nil {
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": "Failed to send verification email"})
+	}
+
+	return c.JSON(fiber.Map{"message": "If the account exists and is unverified, a verification email has been sent"})
+}
+
+func (h *authHandler) RequestPasswordReset(c fiber.Ctx) error {
+
+	var resetRequest dto.PasswordResetRequest
+
+	if err := c.Bind().Body(&resetRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(resetRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.authservie.RequestPasswordReset(c.RequestCtx(), resetRequest); err != nil {
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": "Failed to send password reset email"})
+	}
+
+	return c.JSON(fiber.Map{"message": "If the account exists, a password reset email has been sent"})
+}
+
+func (h *authHandler) ResetPassword(c fiber.Ctx) error {
+
+	var confirmRequest dto.PasswordResetConfirmRequest
+
+	if err := c.Bind().Body(&confirmRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(confirmRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.authservie.ResetPassword(c.RequestCtx(), confirmRequest); err != nil {
+		return tokenError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Password reset successfully"})
+}
+
+func lockoutError(c fiber.Ctx, err error) error {
+
+	var lockout *utils.LockoutError
+
+	if errors.As(err, &lockout) {
+		c.Set("Retry-After", strconv.Itoa(int(lockout.RetryAfter.Seconds())))
+	}
+
+	return c.Status(429).JSON(fiber.Map{"message": err.Error()})
+}
+
+func tokenError(c fiber.Ctx, err error) error {
+
+	switch {
+	case errors.Is(err, utils.TokenNotFoundError):
+		return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.TokenExpiredError), errors.Is(err, utils.TokenAlreadyUsedError):
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	default:
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+}
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type dataExportHandler struct {
+	dataexportservice application.DataExportService
+}
+
+func NewDataExportHandler(dataexportservice application.DataExportService) *dataExportHandler {
+	return &dataExportHandler{
+		dataexportservice: dataexportservice,
+	}
+}
+
+func (h *dataExportHandler) RequestExport(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.dataexportservice.RequestExport(ctx)
+
+	if err != nil {
+		return dataExportError(c, err)
+	}
+
+	return c.Status(202).JSON(dto.ApiResponse[*dto.DataExportResponse]{
+		Message: "Your data export is being prepared, we will email you the link when it is ready",
+		Data:    response,
+	})
+}
+
+func (h *dataExportHandler) GetExport(c fiber.Ctx) error {
+
+	exportID, err := uuid.Parse(c.Params("exportID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid export id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.dataexportservice.GetExport(ctx, exportID)
+
+	if err != nil {
+		return dataExportError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.DataExportResponse]{Message: "Data export retrieved successfully", Data: response})
+}
+
+// Download serves the archive to anyone holding the link, so it can be
+// opened straight from the email without signing in.
+func (h *dataExportHandler) Download(c fiber.Ctx) error {
+
+	exportID, err := uuid.Parse(c.Params("exportID"))
+
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": utils.DataExportNotFoundError.Error()})
+	}
+
+	archive, err := h.dataexportservice.OpenDownload(c.Context(), exportID, c.Query("token"))
+
+	if err != nil {
+		return dataExportError(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Attachment(fmt.Sprintf("jille-data-%s.zip", time.Now().Format("2006-01-02")))
+
+	return c.SendStream(archive)
+}
+
+func dataExportError(c fiber.Ctx, err error) error {
+
+	switch {
+	case errors.Is(err, utils.DataExportNotFoundError):
+		return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.DataExportInProgressError), errors.Is(err, utils.DataExportNotReadyError):
+		return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.DataExportExpiredError):
+		return c.Status(410).JSON(fiber.Map{"message": err.Error()})
+	default:
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+}
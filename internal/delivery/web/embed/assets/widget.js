@@ -0,0 +1,215 @@
+// Poll widget for the page served at /embed/polls/:pollID. It keeps its own
+// sign in, since it runs on the API's origin rather than the app's, and asks
+// the server for the poll as HTML whenever something changes.
+(() => {
+  const api = "/api/v1";
+  const root = document.getElementById("poll");
+  const pollId = root.dataset.pollId;
+  const form = document.getElementById("sign-in");
+  const formError = form.querySelector(".error");
+  const signOut = document.getElementById("sign-out");
+  const jsonHeaders = { "Content-Type": "application/json" };
+
+  let mfaToken = null;
+  let events = null;
+  let reload = null;
+
+  const accessToken = () => localStorage.getItem("jille.accessToken");
+
+  function saveTokens(tokens) {
+    localStorage.setItem("jille.accessToken", tokens.accessToken);
+    localStorage.setItem("jille.refreshToken", tokens.refreshToken);
+  }
+
+  function clearTokens() {
+    localStorage.removeItem("jille.accessToken");
+    localStorage.removeItem("jille.refreshToken");
+  }
+
+  async function refresh() {
+    const refreshToken = localStorage.getItem("jille.refreshToken");
+
+    if (!refreshToken) {
+      return false;
+    }
+
+    const res = await fetch(api + "/auth/refresh", {
+      method: "POST",
+      headers: jsonHeaders,
+      body: JSON.stringify({ refreshToken }),
+    });
+
+    if (!res.ok) {
+      clearTokens();
+      return false;
+    }
+
+    saveTokens((await res.json()).data);
+    return true;
+  }
+
+  // call sends an authenticated API request, refreshing the session once
+  // when the access token has expired.
+  async function call(path, options = {}, retry = true) {
+    const res = await fetch(api + path, {
+      ...options,
+      headers: { ...options.headers, Authorization: "Bearer " + accessToken() },
+    });
+
+    if (res.status === 401 && retry && (await refresh())) {
+      return call(path, options, false);
+    }
+
+    return res;
+  }
+
+  function notice(message) {
+    const p = document.createElement("p");
+    p.className = "notice";
+    p.textContent = message;
+    return p;
+  }
+
+  async function message(res, fallback) {
+    const body = await res.json().catch(() => ({}));
+    return body.message || fallback;
+  }
+
+  function showSignIn() {
+    unsubscribe();
+    root.replaceChildren(notice("Sign in to see this poll."));
+    form.hidden = false;
+    signOut.hidden = true;
+  }
+
+  async function load() {
+    if (!accessToken()) {
+      return showSignIn();
+    }
+
+    const res = await call("/embed/polls/" + pollId);
+
+    if (res.status === 401) {
+      clearTokens();
+      return showSignIn();
+    }
+
+    form.hidden = true;
+    signOut.hidden = false;
+
+    if (!(res.headers.get("Content-Type") || "").startsWith("text/html")) {
+      root.replaceChildren(notice(await message(res, "The poll could not be loaded.")));
+      return;
+    }
+
+    // the server escapes everything it renders
+    root.innerHTML = await res.text();
+
+    if (root.querySelector("[data-live]")) {
+      subscribe();
+    }
+  }
+
+  // subscribe reloads the poll when someone votes. Bursts of votes are
+  // batched into a single reload.
+  function subscribe() {
+    if (events) {
+      return;
+    }
+
+    events = new EventSource(api + "/sse/" + pollId + "?access_token=" + encodeURIComponent(accessToken()));
+
+    events.onmessage = () => {
+      clearTimeout(reload);
+      reload = setTimeout(load, 500);
+    };
+
+    events.onerror = () => {
+      // the stream is only closed for good when it was refused, usually
+      // because the access token expired
+      if (events.readyState === EventSource.CLOSED) {
+        events = null;
+        setTimeout(() => refresh().then(load), 5000);
+      }
+    };
+  }
+
+  function unsubscribe() {
+    if (events) {
+      events.close();
+      events = null;
+    }
+  }
+
+  root.addEventListener("click", async (e) => {
+    const button = e.target.closest("button[data-option]");
+
+    if (!button) {
+      return;
+    }
+
+    root.querySelectorAll("button[data-option]").forEach((b) => (b.disabled = true));
+
+    const res = await call("/vote", {
+      method: "POST",
+      headers: jsonHeaders,
+      body: JSON.stringify({ poll_id: pollId, option_id: button.dataset.option }),
+    });
+
+    const error = res.ok ? null : await message(res, "Your vote could not be saved.");
+
+    await load();
+
+    if (error) {
+      root.append(notice(error));
+    }
+  });
+
+  form.addEventListener("submit", async (e) => {
+    e.preventDefault();
+    formError.textContent = "";
+
+    const data = new FormData(form);
+
+    const res = mfaToken
+      ? await fetch(api + "/auth/login/mfa", {
+          method: "POST",
+          headers: jsonHeaders,
+          body: JSON.stringify({ mfaToken, code: data.get("code") }),
+        })
+      : await fetch(api + "/auth/login", {
+          method: "POST",
+          headers: jsonHeaders,
+          body: JSON.stringify({ email: data.get("email"), password: data.get("password") }),
+        });
+
+    const body = await res.json().catch(() => ({}));
+
+    if (!res.ok) {
+      formError.textContent = body.message || "Sign in failed.";
+      return;
+    }
+
+    if (body.mfaRequired) {
+      mfaToken = body.data.mfaToken;
+      form.elements.code.hidden = false;
+      form.elements.code.required = true;
+      form.elements.code.focus();
+      return;
+    }
+
+    mfaToken = null;
+    form.elements.code.hidden = true;
+    form.elements.code.required = false;
+    form.reset();
+    saveTokens(body.data);
+    load();
+  });
+
+  signOut.addEventListener("click", () => {
+    clearTokens();
+    showSignIn();
+  });
+
+  load();
+})();
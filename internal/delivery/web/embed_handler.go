@@ -0,0 +1,190 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+//go:embed embed
+var embedFiles embed.FS
+
+var embedTemplates = template.Must(template.ParseFS(embedFiles, "embed/*.html"))
+
+// EmbedAssets holds the widget's script and stylesheet.
+var EmbedAssets, _ = fs.Sub(embedFiles, "embed/assets")
+
+type embedHandler struct {
+	pollservice    application.PollService
+	embedservice   application.EmbedService
+	publicURL      string
+	appURL         string
+	frameAncestors string
+}
+
+func NewEmbedHandler(pollservice application.PollService, embedservice application.EmbedService, publicURL string, appURL string, frameAncestors string) *embedHandler {
+	return &embedHandler{
+		pollservice:    pollservice,
+		embedservice:   embedservice,
+		publicURL:      publicURL,
+		appURL:         appURL,
+		frameAncestors: frameAncestors,
+	}
+}
+
+func (h *embedHandler) OEmbed(c fiber.Ctx) error {
+
+	oembedRequest := dto.OEmbedRequest{
+		URL:       c.Query("url"),
+		Format:    c.Query("format", "json"),
+		MaxWidth:  fiber.Query[int](c, "maxwidth"),
+		MaxHeight: fiber.Query[int](c, "maxheight"),
+	}
+
+	if oembedRequest.URL == "" {
+		return c.Status(400).JSON(fiber.Map{"message": "url is required"})
+	}
+
+	response, err := h.embedservice.OEmbed(c.Context(), oembedRequest)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.UnsupportedEmbedFormatError):
+			return c.Status(501).JSON(fiber.Map{"message": err.Error()})
+		case errors.Is(err, utils.UnsupportedEmbedURLError), errors.Is(err, utils.PollNotFoundError):
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		default:
+			fmt.Println("error", err.Error())
+			return c.Status(500).JSON(fiber.Map{"message": "Failed to embed poll"})
+		}
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+
+	return c.JSON(response)
+}
+
+// GetWidget serves the page sites put in an iframe. It only holds the poll
+// id; the script signs the user in and loads the poll from GetWidgetPoll, so
+// nothing about the poll is sent before access is checked.
+func (h *embedHandler) GetWidget(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(404).SendString("Poll not found")
+	}
+
+	widgetURL := fmt.Sprintf("%s/embed/polls/%s", h.publicURL, pollID)
+
+	page := struct {
+		PollID    string
+		PollURL   string
+		OEmbedURL string
+	}{
+		PollID:    pollID.String(),
+		PollURL:   fmt.Sprintf("%s/polls/%s", h.appURL, pollID),
+		OEmbedURL: fmt.Sprintf("%s/api/v1/oembed?format=json&url=%s", h.publicURL, url.QueryEscape(widgetURL)),
+	}
+
+	c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; form-action 'none'; base-uri 'none'; frame-ancestors "+h.frameAncestors)
+
+	return h.render(c, "widget.html", page)
+}
+
+type widgetPoll struct {
+	*dto.PollViewResponse
+	Error   string
+	Open    bool
+	CanVote bool
+	Total   int
+	Options []widgetOption
+}
+
+type widgetOption struct {
+	ID      string
+	Name    string
+	Votes   int
+	Percent int
+}
+
+// GetWidgetPoll renders the poll as the signed in user sees it: the question,
+// vote buttons while they can still vote and the tally once the results
+// policy shows it.
+func (h *embedHandler) GetWidgetPoll(c fiber.Ctx) error {
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": utils.PollNotFoundError.Error()})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	poll, err := h.pollservice.GetPoll(ctx, pollID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.MembersOnlyPollError):
+			c.Status(403)
+		case errors.Is(err, utils.PollNotFoundError):
+			c.Status(404)
+		default:
+			fmt.Println("error", err.Error())
+			return c.Status(500).JSON(fiber.Map{"message": "Failed to load poll"})
+		}
+		return h.render(c, "poll.html", widgetPoll{Error: err.Error()})
+	}
+
+	view := widgetPoll{
+		PollViewResponse: poll,
+		Open:             poll.ExpiresAt.After(time.Now()),
+	}
+
+	view.CanVote = view.Open && !poll.Voted
+
+	for _, option := range poll.Options {
+		view.Total += option.Votes
+	}
+
+	for _, option := range poll.Options {
+
+		row := widgetOption{ID: option.ID, Name: option.Name, Votes: option.Votes}
+
+		if view.Total > 0 {
+			row.Percent = int(math.Round(100 * float64(row.Votes) / float64(view.Total)))
+		}
+
+		view.Options = append(view.Options, row)
+	}
+
+	return h.render(c, "poll.html", view)
+}
+
+func (h *embedHandler) render(c fiber.Ctx, name string, data any) error {
+
+	var page bytes.Buffer
+
+	if err := embedTemplates.ExecuteTemplate(&page, name, data); err != nil {
+		fmt.Println("error rendering", name, err.Error())
+		return c.Status(500).SendString("Failed to render poll")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+
+	return c.Send(page.Bytes())
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type notificationHandler struct {
+	notificationservice application.NotificationService
+	validator           utils.XValidator
+}
+
+func NewNotificationHandler(notificationservice application.NotificationService, validator utils.XValidator) *notificationHandler {
+	return &notificationHandler{
+		notificationservice: notificationservice,
+		validator:           validator,
+	}
+}
+
+func (h *notificationHandler) GetPreferences(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.notificationservice.GetPreferences(ctx)
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.NotificationPreferenceResponse]{Message: "Notification preferences retrieved successfully", Data: response})
+}
+
+func (h *notificationHandler) UpdatePreferences(c fiber.Ctx) error {
+
+	var updateRequest dto.UpdateNotificationPreferencesRequest
+
+	if err := c.Bind().Body(&updateRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(updateRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.notificationservice.UpdatePreferences(ctx, updateRequest)
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.NotificationPreferenceResponse]{Message: "Notification preferences updated successfully", Data: response})
+}
+
+func (h *notificationHandler) GetNotifications(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.notificationservice.GetNotifications(ctx, c.Query("unread") == "true")
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.NotificationResponse]{Message: "Notifications retrieved successfully", Data: response})
+}
+
+func (h *notificationHandler) CountUnread(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.notificationservice.CountUnread(ctx)
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.UnreadNotificationsResponse]{Message: "Unread notifications counted successfully", Data: response})
+}
+
+func (h *notificationHandler) MarkRead(c fiber.Ctx) error {
+
+	notificationID, err := uuid.Parse(c.Params("notificationID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid notification id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+
+	if err := h.notificationservice.MarkRead(ctx, notificationID); err != nil {
+		if errors.Is(err, utils.NotificationNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Notification marked as read"})
+}
+
+func (h *notificationHandler) MarkAllRead(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+
+	if err := h.notificationservice.MarkAllRead(ctx); err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Notifications marked as read"})
+}
+
+// Stream sends the user's new notifications as server-sent events.
+func (h *notificationHandler) Stream(b *utils.Broker) fiber.Handler {
+	return UserSseHandler(b)
+}
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+// oidcLoginCookie holds the key tying a login with an identity provider to
+// the browser that started it.
+const oidcLoginCookie = "oidc_login"
+
+type oidcHandler struct {
+	oidcservice application.OIDCService
+	validator   utils.XValidator
+}
+
+func NewOIDCHandler(oidcservice application.OIDCService, validator utils.XValidator) *oidcHandler {
+	return &oidcHandler{
+		oidcservice: oidcservice,
+		validator:   validator,
+	}
+}
+
+func (h *oidcHandler) StartLogin(c fiber.Ctx) error {
+
+	authURL, browserKey, err := h.oidcservice.StartLogin(c.RequestCtx(), c.Params("provider"))
+
+	if err != nil {
+		if errors.Is(err, utils.IdentityProviderNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		}
+		fmt.Println("error", err.Error())
+		return c.Status(502).JSON(fiber.Map{"message": "Failed to reach identity provider"})
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcLoginCookie,
+		Value:    browserKey,
+		Path:     "/",
+		MaxAge:   int((time.Minute * 10).Seconds()),
+		Secure:   c.Secure(),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect().To(authURL)
+}
+
+func (h *oidcHandler) Callback(c fiber.Ctx) error {
+
+	var callbackRequest dto.OIDCCallbackRequest
+
+	if err := c.Bind().Body(&callbackRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(callbackRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	response, err := h.oidcservice.HandleCallback(c.RequestCtx(), c.Params("provider"), callbackRequest, c.Cookies(oidcLoginCookie))
+
+	// the login is over either way
+	c.ClearCookie(oidcLoginCookie)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.IdentityProviderNotFoundError):
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		case errors.Is(err, utils.InvalidLoginStateError),
+			errors.Is(err, utils.IdentityNotVerifiedError),
+			errors.Is(err, utils.IdentityEmailRequiredError):
+			return c.Status(401).JSON(fiber.Map{"message": err.Error()})
+		case errors.Is(err, utils.UserExistsError):
+			return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+		default:
+			fmt.Println("error", err.Error())
+			return c.Status(401).JSON(fiber.Map{"message": "Login with identity provider failed"})
+		}
+	}
+
+	return c.JSON(response)
+}
@@ -0,0 +1,188 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type organizationHandler struct {
+	organizationservice application.OrganizationService
+	validator           utils.XValidator
+}
+
+func NewOrganizationHandler(organizationservice application.OrganizationService, validator utils.XValidator) *organizationHandler {
+	return &organizationHandler{
+		organizationservice: organizationservice,
+		validator:           validator,
+	}
+}
+
+func (h *organizationHandler) CreateOrganization(c fiber.Ctx) error {
+
+	var createRequest dto.CreateOrganizationRequest
+
+	if ok, err := h.bind(c, &createRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.organizationservice.CreateOrganization(ctx, createRequest)
+
+	if err != nil {
+		return organizationError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ApiResponse[*dto.OrganizationResponse]{Message: "Organization created successfully", Data: response})
+}
+
+func (h *organizationHandler) GetOrganizations(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.organizationservice.GetOrganizations(ctx)
+
+	if err != nil {
+		return organizationError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.OrganizationResponse]{Message: "Organizations retrieved successfully", Data: response})
+}
+
+func (h *organizationHandler) GetOrganization(c fiber.Ctx) error {
+
+	organizationID, err := uuid.Parse(c.Params("organizationID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid organization id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.organizationservice.GetOrganization(ctx, organizationID)
+
+	if err != nil {
+		return organizationError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.OrganizationResponse]{Message: "Organization retrieved successfully", Data: response})
+}
+
+func (h *organizationHandler) AddMember(c fiber.Ctx) error {
+
+	organizationID, err := uuid.Parse(c.Params("organizationID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid organization id"})
+	}
+
+	var addRequest dto.AddMemberRequest
+
+	if ok, err := h.bind(c, &addRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.organizationservice.AddMember(ctx, organizationID, addRequest)
+
+	if err != nil {
+		return organizationError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ApiResponse[*dto.OrganizationResponse]{Message: "Member added successfully", Data: response})
+}
+
+func (h *organizationHandler) UpdateMemberRole(c fiber.Ctx) error {
+
+	organizationID, memberID, ok, err := memberParams(c)
+
+	if !ok {
+		return err
+	}
+
+	var updateRequest dto.UpdateMemberRoleRequest
+
+	if ok, err := h.bind(c, &updateRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.organizationservice.UpdateMemberRole(ctx, organizationID, memberID, updateRequest)
+
+	if err != nil {
+		return organizationError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.OrganizationResponse]{Message: "Member role updated successfully", Data: response})
+}
+
+func (h *organizationHandler) RemoveMember(c fiber.Ctx) error {
+
+	organizationID, memberID, ok, err := memberParams(c)
+
+	if !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	if err := h.organizationservice.RemoveMember(ctx, organizationID, memberID); err != nil {
+		return organizationError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Member removed successfully"})
+}
+
+// memberParams parses the organization and member ids from the path. When it
+// returns false the error response has already been written.
+func memberParams(c fiber.Ctx) (uuid.UUID, uuid.UUID, bool, error) {
+
+	organizationID, err := uuid.Parse(c.Params("organizationID"))
+
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false, c.Status(400).JSON(fiber.Map{"message": "Invalid organization id"})
+	}
+
+	memberID, err := uuid.Parse(c.Params("userID"))
+
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false, c.Status(400).JSON(fiber.Map{"message": "Invalid user id"})
+	}
+
+	return organizationID, memberID, true, nil
+}
+
+// bind parses and validates the request body. When it returns false the
+// error response has already been written.
+func (h *organizationHandler) bind(c fiber.Ctx, request any) (bool, error) {
+
+	if err := c.Bind().Body(request); err != nil {
+		return false, c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(request); err != nil {
+		return false, c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return true, nil
+}
+
+func organizationError(c fiber.Ctx, err error) error {
+
+	switch {
+	case errors.Is(err, utils.OrganizationNotFoundError), errors.Is(err, utils.MemberNotFoundError), errors.Is(err, utils.UserNotFoundError):
+		return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.OrganizationPermissionDeniedError):
+		return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.MemberExistsError), errors.Is(err, utils.LastOwnerError):
+		return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+	default:
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+}
@@ -0,0 +1,197 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+const (
+	minChartWidth  = 200
+	maxChartWidth  = 2000
+	minChartHeight = 150
+	maxChartHeight = 2000
+)
+
+type pollChartHandler struct {
+	pollservice application.PollService
+	renderer    application.ChartRenderer
+}
+
+func NewPollChartHandler(pollservice application.PollService, renderer application.ChartRenderer) *pollChartHandler {
+	return &pollChartHandler{
+		pollservice: pollservice,
+		renderer:    renderer,
+	}
+}
+
+// GetChart renders the results the user may see as an SVG or PNG image.
+// Query params: type (bar, pie), format (svg, png), theme (light, dark),
+// width and height in pixels.
+func (h *pollChartHandler) GetChart(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	chart, format, err := chartParams(c)
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	poll, err := h.pollservice.GetPollView(ctx, pollID)
+
+	if err != nil {
+		if errors.Is(err, utils.PollAccessDeniedError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	chart.Title = poll.Title
+
+	for _, option := range poll.Options {
+		chart.Options = append(chart.Options, application.ChartOption{Name: option.Name, Votes: option.Votes})
+	}
+
+	// the image only changes with a new vote, so clients revalidate
+	// against the last vote time instead of downloading it again
+	etag := chartETag(poll, chart, format)
+
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	c.Set(fiber.HeaderETag, etag)
+
+	if poll.LastVoteAt != nil {
+		c.Set(fiber.HeaderLastModified, poll.LastVoteAt.UTC().Format(http.TimeFormat))
+	}
+
+	if chartNotModified(c, etag, poll.LastVoteAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	var image bytes.Buffer
+
+	if format == "png" {
+		c.Set(fiber.HeaderContentType, "image/png")
+		err = h.renderer.PNG(&image, chart)
+	} else {
+		c.Set(fiber.HeaderContentType, "image/svg+xml")
+		// keeps a browser from running anything if the SVG is opened
+		// directly
+		c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'")
+		err = h.renderer.SVG(&image, chart)
+	}
+
+	if err != nil {
+		fmt.Println("error rendering chart", pollID, err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Send(image.Bytes())
+}
+
+func chartParams(c fiber.Ctx) (application.Chart, string, error) {
+
+	chart := application.Chart{
+		Kind:   application.ChartKind(c.Query("type", string(application.ChartKindBar))),
+		Theme:  application.ChartTheme(c.Query("theme", string(application.ChartThemeLight))),
+		Width:  640,
+		Height: 360,
+	}
+
+	if chart.Kind != application.ChartKindBar && chart.Kind != application.ChartKindPie {
+		return chart, "", errors.New("type must be bar or pie")
+	}
+
+	if chart.Theme != application.ChartThemeLight && chart.Theme != application.ChartThemeDark {
+		return chart, "", errors.New("theme must be light or dark")
+	}
+
+	format := c.Query("format", "svg")
+
+	if format != "svg" && format != "png" {
+		return chart, "", errors.New("format must be svg or png")
+	}
+
+	for _, param := range []struct {
+		name     string
+		value    *int
+		min, max int
+	}{
+		{"width", &chart.Width, minChartWidth, maxChartWidth},
+		{"height", &chart.Height, minChartHeight, maxChartHeight},
+	} {
+
+		raw := c.Query(param.name)
+
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+
+		if err != nil || value < param.min || value > param.max {
+			return chart, "", fmt.Errorf("%s must be between %d and %d", param.name, param.min, param.max)
+		}
+
+		*param.value = value
+	}
+
+	return chart, format, nil
+}
+
+func chartETag(poll *dto.PollViewResponse, chart application.Chart, format string) string {
+
+	var key strings.Builder
+
+	fmt.Fprintf(&key, "%s|%s|%s|%s|%d|%d|%s", poll.ID, chart.Kind, chart.Theme, format, chart.Width, chart.Height, chart.Title)
+
+	if poll.LastVoteAt != nil {
+		fmt.Fprintf(&key, "|%d", poll.LastVoteAt.UnixNano())
+	}
+
+	for _, option := range chart.Options {
+		fmt.Fprintf(&key, "|%s=%d", option.Name, option.Votes)
+	}
+
+	sum := sha256.Sum256([]byte(key.String()))
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func chartNotModified(c fiber.Ctx, etag string, lastVoteAt *time.Time) bool {
+
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, candidate := range strings.Split(noneMatch, ",") {
+			if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastVoteAt == nil {
+		return false
+	}
+
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+
+	return err == nil && !lastVoteAt.Truncate(time.Second).After(since)
+}
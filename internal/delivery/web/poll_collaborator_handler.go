@@ -0,0 +1,159 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type pollCollaboratorHandler struct {
+	collaboratorservice application.PollCollaboratorService
+	validator           utils.XValidator
+}
+
+func NewPollCollaboratorHandler(collaboratorservice application.PollCollaboratorService, validator utils.XValidator) *pollCollaboratorHandler {
+	return &pollCollaboratorHandler{
+		collaboratorservice: collaboratorservice,
+		validator:           validator,
+	}
+}
+
+func (h *pollCollaboratorHandler) AddCollaborator(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	var addRequest dto.AddCollaboratorRequest
+
+	if ok, err := h.bind(c, &addRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.collaboratorservice.AddCollaborator(ctx, pollID, addRequest)
+
+	if err != nil {
+		return pollCollaboratorError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ApiResponse[[]dto.PollCollaboratorResponse]{Message: "Collaborator added successfully", Data: response})
+}
+
+func (h *pollCollaboratorHandler) GetCollaborators(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.collaboratorservice.GetCollaborators(ctx, pollID)
+
+	if err != nil {
+		return pollCollaboratorError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.PollCollaboratorResponse]{Message: "Collaborators retrieved successfully", Data: response})
+}
+
+func (h *pollCollaboratorHandler) UpdateCollaboratorRole(c fiber.Ctx) error {
+
+	pollID, collaboratorID, ok, err := collaboratorParams(c)
+
+	if !ok {
+		return err
+	}
+
+	var updateRequest dto.UpdateCollaboratorRoleRequest
+
+	if ok, err := h.bind(c, &updateRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.collaboratorservice.UpdateCollaboratorRole(ctx, pollID, collaboratorID, updateRequest)
+
+	if err != nil {
+		return pollCollaboratorError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.PollCollaboratorResponse]{Message: "Collaborator role updated successfully", Data: response})
+}
+
+func (h *pollCollaboratorHandler) RemoveCollaborator(c fiber.Ctx) error {
+
+	pollID, collaboratorID, ok, err := collaboratorParams(c)
+
+	if !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	if err := h.collaboratorservice.RemoveCollaborator(ctx, pollID, collaboratorID); err != nil {
+		return pollCollaboratorError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Collaborator removed successfully"})
+}
+
+// bind parses and validates the request body. When it returns false the
+// error response has already been written.
+func (h *pollCollaboratorHandler) bind(c fiber.Ctx, request any) (bool, error) {
+
+	if err := c.Bind().Body(request); err != nil {
+		return false, c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(request); err != nil {
+		return false, c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return true, nil
+}
+
+func collaboratorParams(c fiber.Ctx) (uuid.UUID, uuid.UUID, bool, error) {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false, c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	collaboratorID, err := uuid.Parse(c.Params("userID"))
+
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false, c.Status(400).JSON(fiber.Map{"message": "Invalid user id"})
+	}
+
+	return pollID, collaboratorID, true, nil
+}
+
+func pollCollaboratorError(c fiber.Ctx, err error) error {
+
+	switch {
+	case errors.Is(err, utils.PollPermissionDeniedError):
+		return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.CollaboratorNotFoundError), errors.Is(err, utils.UserNotFoundError), errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.CollaboratorExistsError):
+		return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.CreatorCollaboratorError):
+		return c.Status(422).JSON(fiber.Map{"message": err.Error()})
+	default:
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+}
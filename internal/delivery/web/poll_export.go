@@ -0,0 +1,303 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type resultsExporter struct {
+	contentType string
+	write       func(w io.Writer, poll *dto.PollViewResponse, ballots resultsBallots) error
+}
+
+var resultsExporters = map[string]resultsExporter{
+	"csv":  {"text/csv; charset=utf-8", writeResultsCSV},
+	"json": {fiber.MIMEApplicationJSONCharsetUTF8, writeResultsJSON},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", writeResultsXLSX},
+}
+
+// ExportResults downloads the results GetPollView shows the user as a
+// spreadsheet friendly file. The file is written while it is sent and the
+// ballots are read a batch at a time, so large polls are never held in
+// memory.
+func (h *pollhandler) ExportResults(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	format := c.Query("format", "csv")
+
+	exporter, ok := resultsExporters[format]
+
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"message": "Unsupported export format, use csv, json or xlsx"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	poll, err := h.pollservice.GetPollView(ctx, pollID)
+
+	if err != nil {
+		if errors.Is(err, utils.PollAccessDeniedError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, exporter.contentType)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Attachment(fmt.Sprintf("poll-%s-results.%s", poll.ID, format))
+
+	var ballots resultsBallots
+
+	if poll.VotersVisible {
+		ballots = h.pollBallots(ctx, poll)
+	}
+
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		if err := exporter.write(w, poll, ballots); err != nil {
+			fmt.Println("error exporting poll results", poll.ID, err.Error())
+			return
+		}
+		w.Flush()
+	})
+}
+
+type resultsTally struct {
+	OptionID string  `json:"option_id"`
+	Option   string  `json:"option"`
+	Votes    int     `json:"votes"`
+	Share    float64 `json:"share"`
+}
+
+type resultsBallot struct {
+	ID       string    `json:"id"`
+	OptionID string    `json:"option_id"`
+	Option   string    `json:"option"`
+	VoterID  string    `json:"voter_id"`
+	VotedAt  time.Time `json:"voted_at"`
+}
+
+// resultsBallots calls fn for every ballot of the poll.
+type resultsBallots func(fn func(resultsBallot) error) error
+
+func tallies(poll *dto.PollViewResponse) []resultsTally {
+
+	total := totalVotes(poll)
+
+	rows := make([]resultsTally, 0, len(poll.Options))
+
+	for _, option := range poll.Options {
+
+		row := resultsTally{
+			OptionID: option.ID,
+			Option:   option.Name,
+			Votes:    option.Votes,
+		}
+
+		if total > 0 {
+			row.Share = float64(row.Votes) / float64(total)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func totalVotes(poll *dto.PollViewResponse) int {
+
+	total := 0
+
+	for _, option := range poll.Options {
+		total += option.Votes
+	}
+
+	return total
+}
+
+// pollBallots lists the poll's ballots for users who may see who voted.
+func (h *pollhandler) pollBallots(ctx context.Context, poll *dto.PollViewResponse) resultsBallots {
+
+	names := make(map[string]string, len(poll.Options))
+
+	for _, option := range poll.Options {
+		names[option.ID] = option.Name
+	}
+
+	pollID := uuid.MustParse(poll.ID)
+
+	return func(fn func(resultsBallot) error) error {
+		return h.pollservice.EachPollVote(ctx, pollID, func(vote dto.Vote) error {
+			return fn(resultsBallot{
+				ID:       vote.ID,
+				OptionID: vote.OptionID,
+				Option:   names[vote.OptionID],
+				VoterID:  vote.UserID,
+				VotedAt:  vote.VotedAt.UTC(),
+			})
+		})
+	}
+}
+
+// hasBallots tells whether the export lists ballots: only users who may see
+// who voted get them, and only once someone has.
+func hasBallots(poll *dto.PollViewResponse, ballots resultsBallots) bool {
+	return ballots != nil && totalVotes(poll) > 0
+}
+
+func writeResultsCSV(w io.Writer, poll *dto.PollViewResponse, ballots resultsBallots) error {
+
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"option_id", "option", "votes", "share"})
+
+	for _, row := range tallies(poll) {
+		cw.Write([]string{row.OptionID, csvText(row.Option), strconv.Itoa(row.Votes), strconv.FormatFloat(row.Share, 'f', 4, 64)})
+	}
+
+	if hasBallots(poll, ballots) {
+
+		// a blank line separates the ballots so both tables paste cleanly
+		cw.Write(nil)
+		cw.Write([]string{"ballot_id", "option_id", "option", "voter_id", "voted_at"})
+
+		err := ballots(func(ballot resultsBallot) error {
+			return cw.Write([]string{ballot.ID, ballot.OptionID, csvText(ballot.Option), ballot.VoterID, ballot.VotedAt.Format(time.RFC3339)})
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// csvText keeps spreadsheets from evaluating user supplied text that starts
+// like a formula.
+func csvText(value string) string {
+
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+func writeResultsJSON(w io.Writer, poll *dto.PollViewResponse, ballots resultsBallots) error {
+
+	header, err := json.Marshal(fiber.Map{
+		"id":         poll.ID,
+		"title":      poll.Title,
+		"created_at": poll.CreatedAt,
+		"expires_at": poll.ExpiresAt,
+		"tallies":    tallies(poll),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// ballots are appended one at a time instead of marshalling them
+	// into a single slice first
+	if _, err := w.Write(header[:len(header)-1]); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, `,"ballots":[`); err != nil {
+		return err
+	}
+
+	first := true
+
+	if !hasBallots(poll, ballots) {
+		ballots = func(func(resultsBallot) error) error { return nil }
+	}
+
+	err = ballots(func(ballot resultsBallot) error {
+
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		first = false
+
+		data, err := json.Marshal(ballot)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]}\n")
+
+	return err
+}
+
+func writeResultsXLSX(w io.Writer, poll *dto.PollViewResponse, ballots resultsBallots) error {
+
+	x := utils.NewXLSXWriter(w)
+
+	if err := x.AddSheet("Results"); err != nil {
+		return err
+	}
+
+	if err := x.WriteRow("Option ID", "Option", "Votes", "Share"); err != nil {
+		return err
+	}
+
+	for _, row := range tallies(poll) {
+		if err := x.WriteRow(row.OptionID, row.Option, row.Votes, row.Share); err != nil {
+			return err
+		}
+	}
+
+	if hasBallots(poll, ballots) {
+
+		if err := x.AddSheet("Ballots"); err != nil {
+			return err
+		}
+
+		if err := x.WriteRow("Ballot ID", "Option ID", "Option", "Voter ID", "Voted at"); err != nil {
+			return err
+		}
+
+		err := ballots(func(ballot resultsBallot) error {
+			return x.WriteRow(ballot.ID, ballot.OptionID, ballot.Option, ballot.VoterID, ballot.VotedAt)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return x.Close()
+}
@@ -38,11 +38,20 @@ func (h *pollhandler) CreatePoll(c fiber.Ctx) error {
 	}
 
 	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
-	if err := h.pollservice.CreatePoll(ctx, &pollRequest); err != nil {
+	pollID, err := h.pollservice.CreatePoll(ctx, &pollRequest)
+
+	if err != nil {
+		if errors.Is(err, utils.OrganizationNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		} else if errors.Is(err, utils.OrganizationPermissionDeniedError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		} else if errors.Is(err, utils.InvalidMembersOnlyPollError) {
+			return c.Status(422).JSON(fiber.Map{"message": err.Error()})
+		}
 		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{"message": "Poll created successfully"})
+	return c.JSON(fiber.Map{"message": "Poll created successfully", "id": pollID})
 }
 
 func (h *pollhandler) DeletePoll(c fiber.Ctx) error {
@@ -51,6 +60,9 @@ func (h *pollhandler) DeletePoll(c fiber.Ctx) error {
 
 	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
 	if err := h.pollservice.DeletePoll(ctx, uuid.MustParse(pollID)); err != nil {
+		if errors.Is(err, utils.PollPermissionDeniedError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		}
 		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
 	}
 
@@ -74,6 +86,29 @@ func (h *pollhandler) GetPollView(c fiber.Ctx) error {
 	return c.JSON(response)
 }
 
+// GetPollVotes lists who voted, a page at a time. The next page is fetched
+// with ?cursor= set to the previous page's next_cursor.
+func (h *pollhandler) GetPollVotes(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.pollservice.GetPollVotes(ctx, pollID, c.Query("cursor"))
+
+	if err != nil {
+		if errors.Is(err, utils.VotersAccessDeniedError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(response)
+}
+
 func (h *pollhandler) GetPoll(c fiber.Ctx) error {
 
 	pollID := c.Params("pollID")
@@ -81,6 +116,9 @@ func (h *pollhandler) GetPoll(c fiber.Ctx) error {
 	response, err := h.pollservice.GetPoll(c.RequestCtx(), uuid.MustParse(pollID))
 
 	if err != nil {
+		if errors.Is(err, utils.MembersOnlyPollError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		}
 		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
 	}
 
@@ -98,3 +136,50 @@ func (h *pollhandler) GetAllPolls(c fiber.Ctx) error {
 
 	return c.JSON(polls)
 }
+
+func (h *pollhandler) GetOrganizationPolls(c fiber.Ctx) error {
+
+	organizationID, err := uuid.Parse(c.Params("organizationID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid organization id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	polls, err := h.pollservice.GetOrganizationPolls(ctx, organizationID)
+
+	if err != nil {
+		if errors.Is(err, utils.OrganizationNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.PollViewResponse]{Message: "Polls retrieved successfully", Data: polls})
+}
+
+// StreamResults sends the poll's new votes as server-sent events to users the
+// results policy lets see them.
+func (h *pollhandler) StreamResults(b *utils.Broker) fiber.Handler {
+
+	return func(c fiber.Ctx) error {
+
+		pollID, err := uuid.Parse(c.Params("pollID"))
+
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+		}
+
+		ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+		if err := h.pollservice.AuthorizeResultsStream(ctx, pollID); err != nil {
+			if errors.Is(err, utils.PollAccessDeniedError) {
+				return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+			}
+			return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+		}
+
+		return SseHandler(b, func(event utils.Event) bool {
+			return event.PollID == pollID.String()
+		})(c)
+	}
+}
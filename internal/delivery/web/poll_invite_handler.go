@@ -0,0 +1,111 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+	"gorm.io/gorm"
+)
+
+type pollInviteHandler struct {
+	inviteservice application.PollInviteService
+	validator     utils.XValidator
+}
+
+func NewPollInviteHandler(inviteservice application.PollInviteService, validator utils.XValidator) *pollInviteHandler {
+	return &pollInviteHandler{
+		inviteservice: inviteservice,
+		validator:     validator,
+	}
+}
+
+func (h *pollInviteHandler) CreateInvites(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	var createRequest dto.CreateInvitesRequest
+
+	if err := c.Bind().Body(&createRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(createRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.inviteservice.CreateInvites(ctx, pollID, createRequest)
+
+	if err != nil {
+		return pollInviteError(c, err)
+	}
+
+	return c.Status(201).JSON(dto.ApiResponse[*dto.PollInvitesResponse]{Message: "Invites sent successfully", Data: response})
+}
+
+func (h *pollInviteHandler) GetInvites(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.inviteservice.GetInvites(ctx, pollID)
+
+	if err != nil {
+		return pollInviteError(c, err)
+	}
+
+	return c.JSON(dto.ApiResponse[*dto.PollInvitesResponse]{Message: "Invites retrieved successfully", Data: response})
+}
+
+func (h *pollInviteHandler) RevokeInvite(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid poll id"})
+	}
+
+	inviteID, err := uuid.Parse(c.Params("inviteID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid invite id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	if err := h.inviteservice.RevokeInvite(ctx, pollID, inviteID); err != nil {
+		return pollInviteError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Invite revoked successfully"})
+}
+
+func pollInviteError(c fiber.Ctx, err error) error {
+
+	switch {
+	case errors.Is(err, utils.PollPermissionDeniedError):
+		return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.InviteNotFoundError), errors.Is(err, utils.UserNotFoundError), errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.EmptyInviteListError):
+		return c.Status(422).JSON(fiber.Map{"message": err.Error()})
+	default:
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+}
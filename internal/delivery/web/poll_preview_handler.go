@@ -0,0 +1,231 @@
+package web
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+//go:embed preview
+var previewFiles embed.FS
+
+var previewTemplate = template.Must(template.ParseFS(previewFiles, "preview/poll.html"))
+
+const (
+	previewImageWidth  = 1200
+	previewImageHeight = 630
+
+	// previewOptions is how many options the description lists by name
+	previewOptions = 6
+)
+
+// previewCrawlers are user agent fragments of the bots that unfurl links in
+// chat apps and social networks
+var previewCrawlers = []string{
+	"slackbot", "slack-imgproxy", "msteams", "skypeuripreview", "microsoftpreview",
+	"twitterbot", "facebookexternalhit", "facebot", "linkedinbot", "discordbot",
+	"telegrambot", "whatsapp", "mattermost", "redditbot", "embedly", "iframely",
+	"googlebot", "bingbot", "applebot",
+}
+
+type pollPreviewHandler struct {
+	pollservice application.PollService
+	renderer    application.ChartRenderer
+	publicURL   string
+	appURL      string
+}
+
+func NewPollPreviewHandler(pollservice application.PollService, renderer application.ChartRenderer, publicURL string, appURL string) *pollPreviewHandler {
+	return &pollPreviewHandler{
+		pollservice: pollservice,
+		renderer:    renderer,
+		publicURL:   publicURL,
+		appURL:      appURL,
+	}
+}
+
+type previewPage struct {
+	Title       string
+	Description string
+	URL         string
+	AppURL      string
+	ImageURL    string
+	ImageAlt    string
+	ImageWidth  int
+	ImageHeight int
+}
+
+// GetPreview answers a shared poll link. Link preview crawlers get a page of
+// Open Graph and Twitter card tags, people are sent on to the poll in the
+// app.
+func (h *pollPreviewHandler) GetPreview(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": utils.PollNotFoundError.Error()})
+	}
+
+	appURL := fmt.Sprintf("%s/polls/%s", h.appURL, pollID)
+
+	c.Set(fiber.HeaderVary, fiber.HeaderUserAgent)
+
+	if !isPreviewCrawler(c.Get(fiber.HeaderUserAgent)) {
+		return c.Redirect().Status(fiber.StatusFound).To(appURL)
+	}
+
+	page := previewPage{
+		Title:       "Jille poll",
+		Description: "Sign in to Jille to see this poll.",
+		URL:         fmt.Sprintf("%s/polls/%s", h.publicURL, pollID),
+		AppURL:      appURL,
+	}
+
+	// crawlers are never signed in, so only public polls are described
+	poll, err := h.pollservice.GetPoll(c.Context(), pollID)
+
+	switch {
+	case err == nil:
+		page.Title = poll.Title
+		page.Description = previewDescription(poll)
+		page.ImageURL = page.URL + "/preview.png"
+		page.ImageWidth = previewImageWidth
+		page.ImageHeight = previewImageHeight
+		page.ImageAlt = "Options of the poll " + poll.Title
+
+		if poll.ResultsVisible {
+			page.ImageAlt = "Results of the poll " + poll.Title
+		}
+	case errors.Is(err, utils.PollNotFoundError):
+		c.Status(404)
+	case !errors.Is(err, utils.PollSignInRequiredError):
+		fmt.Println("error previewing poll", pollID, err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": "Failed to load poll"})
+	}
+
+	var html bytes.Buffer
+
+	if err := previewTemplate.Execute(&html, page); err != nil {
+		fmt.Println("error rendering poll preview", pollID, err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": "Failed to load poll"})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+
+	return c.Send(html.Bytes())
+}
+
+// GetPreviewImage draws the chart shown with a shared public poll. The tally
+// is left out until the results policy publishes it to everyone.
+func (h *pollPreviewHandler) GetPreviewImage(c fiber.Ctx) error {
+
+	pollID, err := uuid.Parse(c.Params("pollID"))
+
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": utils.PollNotFoundError.Error()})
+	}
+
+	poll, err := h.pollservice.GetPoll(c.Context(), pollID)
+
+	if err != nil {
+		if errors.Is(err, utils.PollNotFoundError) || errors.Is(err, utils.PollSignInRequiredError) {
+			return c.Status(404).JSON(fiber.Map{"message": utils.PollNotFoundError.Error()})
+		}
+		fmt.Println("error previewing poll", pollID, err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": "Failed to load poll"})
+	}
+
+	chart := application.Chart{
+		Title:       poll.Title,
+		Kind:        application.ChartKindBar,
+		Theme:       application.ChartThemeLight,
+		Width:       previewImageWidth,
+		Height:      previewImageHeight,
+		HideResults: !poll.ResultsVisible,
+	}
+
+	for _, option := range poll.Options {
+		chart.Options = append(chart.Options, application.ChartOption{Name: option.Name, Votes: option.Votes})
+	}
+
+	var image bytes.Buffer
+
+	if err := h.renderer.PNG(&image, chart); err != nil {
+		fmt.Println("error rendering chart", pollID, err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	c.Set(fiber.HeaderContentType, "image/png")
+
+	return c.Send(image.Bytes())
+}
+
+func isPreviewCrawler(userAgent string) bool {
+
+	userAgent = strings.ToLower(userAgent)
+
+	for _, crawler := range previewCrawlers {
+		if strings.Contains(userAgent, crawler) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// previewDescription sums up the options, with their share of the votes once
+// the results are public, and when voting closes.
+func previewDescription(poll *dto.PollViewResponse) string {
+
+	total := 0
+
+	for _, option := range poll.Options {
+		total += option.Votes
+	}
+
+	var options []string
+
+	for i, option := range poll.Options {
+
+		if i == previewOptions {
+			options = append(options, fmt.Sprintf("%d more", len(poll.Options)-previewOptions))
+			break
+		}
+
+		if poll.ResultsVisible && total > 0 {
+			options = append(options, fmt.Sprintf("%s (%.0f%%)", option.Name, 100*float64(option.Votes)/float64(total)))
+		} else {
+			options = append(options, option.Name)
+		}
+	}
+
+	parts := []string{strings.Join(options, ", ")}
+
+	if poll.ResultsVisible {
+		if total == 1 {
+			parts = append(parts, "1 vote")
+		} else {
+			parts = append(parts, fmt.Sprintf("%d votes", total))
+		}
+	}
+
+	if poll.ExpiresAt.After(time.Now()) {
+		parts = append(parts, "Closes "+poll.ExpiresAt.UTC().Format("2 Jan 2006"))
+	} else {
+		parts = append(parts, "Closed")
+	}
+
+	return strings.Join(parts, " · ")
+}
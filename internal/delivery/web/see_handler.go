@@ -10,45 +10,75 @@ import (
 	"github.com/winnerx0/jille/internal/utils"
 )
 
-func SseHandler(b *utils.Broker) fiber.Handler {
+// SseHandler streams the broker's events to the client. When filter is set,
+// only events it accepts are sent.
+func SseHandler(b *utils.Broker, filter func(utils.Event) bool) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		c.Set("Content-Type", "text/event-stream")
-		c.Set("Cache-Control", "no-cache")
-		c.Set("Connection", "keep-alive")
 
 		client := make(chan utils.Event)
 		b.Add <- client
 
-		reader, writer := io.Pipe()
-
-		go func() {
-			defer func() {
-				b.Remove <- client
-				writer.Close()
-			}()
-
-			ticker := time.NewTicker(30 * time.Second)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case event, ok := <-client:
-					if !ok {
-						return
-					}
-					data, _ := json.Marshal(event)
-					if _, err := fmt.Fprintf(writer, "data: %s\n\n", data); err != nil {
-						return
-					}
-				case <-ticker.C:
-					// Heartbeat to detect client disconnect
-					if _, err := fmt.Fprintf(writer, ": keep-alive\n\n"); err != nil {
-						return
-					}
+		return stream(c, client, filter, func() {
+			b.Remove <- client
+		})
+	}
+}
+
+// UserSseHandler streams the events published for the signed in user.
+func UserSseHandler(b *utils.Broker) fiber.Handler {
+	return func(c fiber.Ctx) error {
+
+		client := utils.UserClient{
+			UserID: c.Locals("userID").(string),
+			Events: make(chan utils.Event),
+		}
+		b.AddUser <- client
+
+		return stream(c, client.Events, nil, func() {
+			b.RemoveUser <- client
+		})
+	}
+}
+
+// stream writes the client's events until it disconnects, then calls remove,
+// which must unregister and close the client.
+func stream(c fiber.Ctx, client chan utils.Event, filter func(utils.Event) bool, remove func()) error {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		defer func() {
+			remove()
+			writer.Close()
+		}()
+
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-client:
+				if !ok {
+					return
+				}
+				if filter != nil && !filter(event) {
+					continue
+				}
+				data, _ := json.Marshal(event)
+				if _, err := fmt.Fprintf(writer, "data: %s\n\n", data); err != nil {
+					return
+				}
+			case <-ticker.C:
+				// Heartbeat to detect client disconnect
+				if _, err := fmt.Fprintf(writer, ": keep-alive\n\n"); err != nil {
+					return
 				}
 			}
-		}()
+		}
+	}()
 
-		return c.SendStream(reader)
-	}
+	return c.SendStream(reader)
 }
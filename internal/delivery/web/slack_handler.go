@@ -0,0 +1,64 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type slackHandler struct {
+	slackservice application.SlackService
+}
+
+func NewSlackHandler(slackservice application.SlackService) *slackHandler {
+	return &slackHandler{
+		slackservice: slackservice,
+	}
+}
+
+// VerifySignature rejects requests that were not signed with the app's
+// Slack signing secret.
+func (h *slackHandler) VerifySignature(c fiber.Ctx) error {
+
+	if err := h.slackservice.VerifyRequest(c.Get("X-Slack-Request-Timestamp"), c.Get("X-Slack-Signature"), c.Body()); err != nil {
+		return c.Status(401).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Next()
+}
+
+// Command handles the /jille slash command. Slack shows whatever comes back
+// to the user, so failures are answered with a message rather than an error
+// status.
+func (h *slackHandler) Command(c fiber.Ctx) error {
+
+	message, err := h.slackservice.HandleCommand(c.Context(), c.Body())
+
+	if err != nil {
+		if errors.Is(err, utils.InvalidSlackRequestError) {
+			return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+		}
+		fmt.Println("error handling slack command", err.Error())
+		return c.JSON(dto.SlackMessage{ResponseType: "ephemeral", Text: "The poll could not be created, please try again."})
+	}
+
+	return c.JSON(message)
+}
+
+// Interaction handles clicks on the vote buttons of poll messages.
+func (h *slackHandler) Interaction(c fiber.Ctx) error {
+
+	if err := h.slackservice.HandleInteraction(c.Context(), c.Body()); err != nil {
+		if errors.Is(err, utils.InvalidSlackRequestError) {
+			return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+		}
+		fmt.Println("error handling slack interaction", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.SendStatus(200)
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type twoFactorHandler struct {
+	twofactorservice application.TwoFactorService
+	validator        utils.XValidator
+}
+
+func NewTwoFactorHandler(twofactorservice application.TwoFactorService, validator utils.XValidator) *twoFactorHandler {
+	return &twoFactorHandler{
+		twofactorservice: twofactorservice,
+		validator:        validator,
+	}
+}
+
+func (h *twoFactorHandler) Enroll(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.twofactorservice.Enroll(ctx)
+
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Scan the code with your authenticator app, then confirm it", "data": response})
+}
+
+func (h *twoFactorHandler) Confirm(c fiber.Ctx) error {
+
+	var codeRequest dto.TwoFactorCodeRequest
+
+	if ok, err := h.bind(c, &codeRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.twofactorservice.Confirm(ctx, codeRequest)
+
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(response)
+}
+
+func (h *twoFactorHandler) Disable(c fiber.Ctx) error {
+
+	var codeRequest dto.TwoFactorCodeRequest
+
+	if ok, err := h.bind(c, &codeRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	ctx = context.WithValue(ctx, "clientIP", c.IP())
+	if err := h.twofactorservice.Disable(ctx, codeRequest); err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Two-factor authentication disabled"})
+}
+
+func (h *twoFactorHandler) RegenerateRecoveryCodes(c fiber.Ctx) error {
+
+	var codeRequest dto.TwoFactorCodeRequest
+
+	if ok, err := h.bind(c, &codeRequest); !ok {
+		return err
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	ctx = context.WithValue(ctx, "clientIP", c.IP())
+	response, err := h.twofactorservice.RegenerateRecoveryCodes(ctx, codeRequest)
+
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(response)
+}
+
+// bind parses and validates the request body. When it returns false the
+// error response has already been written.
+func (h *twoFactorHandler) bind(c fiber.Ctx, codeRequest *dto.TwoFactorCodeRequest) (bool, error) {
+
+	if err := c.Bind().Body(codeRequest); err != nil {
+		return false, c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(*codeRequest); err != nil {
+		return false, c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return true, nil
+}
+
+func twoFactorError(c fiber.Ctx, err error) error {
+
+	switch {
+	case errors.Is(err, utils.InvalidTwoFactorCodeError), errors.Is(err, utils.InvalidMFATokenError):
+		return c.Status(401).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.TooManyAttemptsError):
+		return lockoutError(c, err)
+	case errors.Is(err, utils.TwoFactorAlreadyEnabledError):
+		return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, utils.TwoFactorNotEnabledError), errors.Is(err, utils.TwoFactorNotEnrolledError):
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	default:
+		fmt.Println("error", err.Error())
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+}
@@ -20,33 +20,29 @@ func NewVoteHandler(voteservice application.VoteService) *votehandler {
 	}
 }
 
-func (h *votehandler) VotePoll(b utils.Broker) fiber.Handler {
+func (h *votehandler) VotePoll(c fiber.Ctx) error {
+	var voteRequst dto.VoteRequest
 
-	return func(c fiber.Ctx) error {
-		var voteRequst dto.VoteRequest
-
-		if err := c.Bind().Body(&voteRequst); err != nil {
-			return c.Status(400).JSON(fiber.Map{"message": "Failed to parse body"})
-		}
-
-		ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
-		response, err := h.voteservice.VotePoll(ctx, voteRequst)
-
-		if err != nil {
-			if errors.Is(err, utils.PollExpiredError) {
-				return c.Status(400).JSON(fiber.Map{"message": err.Error()})
-			} else if errors.Is(err, utils.OptionNotFound) {
-				return c.Status(404).JSON(fiber.Map{"message": err.Error()})
-			} else {
-				return c.Status(500).JSON(fiber.Map{"message": err.Error()})
-			}
-		}
+	if err := c.Bind().Body(&voteRequst); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Failed to parse body"})
+	}
 
-		b.Events <- utils.Event{
-			Type:    "POLL_VOTE",
-			Payload: voteRequst,
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.voteservice.VotePoll(ctx, voteRequst)
+
+	if err != nil {
+		if errors.Is(err, utils.PollExpiredError) {
+			return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+		} else if errors.Is(err, utils.OptionNotFound) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		} else if errors.Is(err, utils.MembersOnlyPollError) || errors.Is(err, utils.PollNotInvitedError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		} else if errors.Is(err, utils.VoteAlreadyExistsError) {
+			return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+		} else {
+			return c.Status(500).JSON(fiber.Map{"message": err.Error()})
 		}
-
-		return c.JSON(response)
 	}
+
+	return c.JSON(response)
 }
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/winnerx0/jille/internal/application"
+	"github.com/winnerx0/jille/internal/common/dto"
+	"github.com/winnerx0/jille/internal/utils"
+)
+
+type webhookHandler struct {
+	webhookservice application.WebhookService
+	validator      utils.XValidator
+}
+
+func NewWebhookHandler(webhookservice application.WebhookService, validator utils.XValidator) *webhookHandler {
+	return &webhookHandler{
+		webhookservice: webhookservice,
+		validator:      validator,
+	}
+}
+
+func (h *webhookHandler) CreateWebhook(c fiber.Ctx) error {
+
+	var createRequest dto.CreateWebhookRequest
+
+	if err := c.Bind().Body(&createRequest); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if err := h.validator.Validate(createRequest); err != nil {
+		return c.Status(422).JSON(dto.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	response, err := h.webhookservice.CreateWebhook(ctx, createRequest)
+
+	if err != nil {
+		if errors.Is(err, utils.InvalidWebhookURLError) || errors.Is(err, utils.WebhookAddressNotAllowedError) {
+			return c.Status(422).JSON(fiber.Map{"message": err.Error()})
+		} else if errors.Is(err, utils.PollNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		} else if errors.Is(err, utils.PollPermissionDeniedError) {
+			return c.Status(403).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(201).JSON(dto.ApiResponse[*dto.WebhookResponse]{
+		Message: "Webhook created. Copy the secret now, it will not be shown again",
+		Data:    response,
+	})
+}
+
+func (h *webhookHandler) GetWebhooks(c fiber.Ctx) error {
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	webhooks, err := h.webhookservice.GetWebhooks(ctx)
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.WebhookResponse]{Message: "Webhooks retrieved successfully", Data: webhooks})
+}
+
+func (h *webhookHandler) DeleteWebhook(c fiber.Ctx) error {
+
+	webhookID, err := uuid.Parse(c.Params("webhookID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid webhook id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	if err := h.webhookservice.DeleteWebhook(ctx, webhookID); err != nil {
+		if errors.Is(err, utils.WebhookNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Webhook deleted successfully"})
+}
+
+func (h *webhookHandler) GetDeliveries(c fiber.Ctx) error {
+
+	webhookID, err := uuid.Parse(c.Params("webhookID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid webhook id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	deliveries, err := h.webhookservice.GetDeliveries(ctx, webhookID)
+
+	if err != nil {
+		if errors.Is(err, utils.WebhookNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(dto.ApiResponse[[]dto.WebhookDeliveryResponse]{Message: "Deliveries retrieved successfully", Data: deliveries})
+}
+
+func (h *webhookHandler) Redeliver(c fiber.Ctx) error {
+
+	webhookID, err := uuid.Parse(c.Params("webhookID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid webhook id"})
+	}
+
+	deliveryID, err := uuid.Parse(c.Params("deliveryID"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "Invalid delivery id"})
+	}
+
+	ctx := context.WithValue(c.Context(), "userID", c.Locals("userID"))
+	delivery, err := h.webhookservice.Redeliver(ctx, webhookID, deliveryID)
+
+	if err != nil {
+		if errors.Is(err, utils.WebhookNotFoundError) || errors.Is(err, utils.WebhookDeliveryNotFoundError) {
+			return c.Status(404).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(202).JSON(dto.ApiResponse[*dto.WebhookDeliveryResponse]{Message: "Delivery queued", Data: delivery})
+}
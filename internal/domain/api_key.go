@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ScopePollsRead  = "polls:read"
+	ScopePollsWrite = "polls:write"
+	ScopeVotesRead  = "votes:read"
+	ScopeVotesWrite = "votes:write"
+)
+
+var Scopes = []string{ScopePollsRead, ScopePollsWrite, ScopeVotesRead, ScopeVotesWrite}
+
+// APIKey is a long-lived credential a user mints for scripts. The key itself
+// is only shown once; Prefix is kept so users can tell their keys apart.
+type APIKey struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name       string    `gorm:"not null"`
+	Prefix     string    `gorm:"not null"`
+	KeyHash    string    `gorm:"not null;uniqueIndex:,where:deleted_at IS NULL"`
+	Scopes     []string  `gorm:"serializer:json;not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time      `gorm:"not null"`
+	UpdatedAt  time.Time      `gorm:"not null"`
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return
+}
@@ -7,13 +7,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// RefreshToken is a session. Only the SHA-256 of the token is stored; the
+// token itself is only ever handed to the client.
 type RefreshToken struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;"`
-	Token     string         `gorm:"not null"`
+	TokenHash string         `gorm:"not null;index"`
 	ExpiresAt time.Time      `gorm:"not null"`
-	UserID    uuid.UUID      `gorm:"type:uuid;not null"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null;index:idx_refresh_tokens_user,priority:1"`
 	Revoked   bool           `gorm:"not null;default:false"`
-	CreatedAt time.Time      `gorm:"not null"`
+	CreatedAt time.Time      `gorm:"not null;index:idx_refresh_tokens_user,priority:2"`
 	UpdatedAt time.Time      `gorm:"not null"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DataExportStatus string
+
+const (
+	DataExportPending    DataExportStatus = "pending"
+	DataExportProcessing DataExportStatus = "processing"
+	DataExportReady      DataExportStatus = "ready"
+	DataExportFailed     DataExportStatus = "failed"
+	DataExportExpired    DataExportStatus = "expired"
+)
+
+// DataExport is an archive of everything stored about a user. The archive is
+// built in the background and downloaded with a token, of which only the
+// SHA-256 hash is kept. A user has at most one export pending or processing.
+type DataExport struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey;"`
+	UserID      uuid.UUID        `gorm:"type:uuid;not null;index"`
+	Status      DataExportStatus `gorm:"not null"`
+	TokenHash   string           `gorm:"not null;uniqueIndex:,where:deleted_at IS NULL"`
+	FileName    string
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time     `gorm:"index"`
+	CreatedAt   time.Time      `gorm:"not null"`
+	UpdatedAt   time.Time      `gorm:"not null"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (e *DataExport) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// LoginThrottle tracks consecutive failed logins for a key such as an email
+// address or a client IP.
+type LoginThrottle struct {
+	Key           string    `gorm:"primaryKey"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   time.Time `gorm:"not null"`
+}
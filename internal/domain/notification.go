@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationKind string
+
+const (
+	// NotificationPollClosed tells creators their poll has closed, and
+	// voters in the app.
+	NotificationPollClosed NotificationKind = "poll_closed"
+	// NotificationResultsReady sends voters the final results.
+	NotificationResultsReady NotificationKind = "results_ready"
+	// NotificationPollInvited asks someone to vote in an invite-only poll.
+	NotificationPollInvited NotificationKind = "poll_invited"
+	// NotificationPollExpiring reminds invitees who have not voted yet.
+	NotificationPollExpiring NotificationKind = "poll_expiring"
+	// NotificationVoteReceived tells creators someone voted on their poll.
+	// It is only shown in the app.
+	NotificationVoteReceived NotificationKind = "vote_received"
+)
+
+// NotificationKinds are the kinds sent by email, which users can set a
+// delivery for.
+var NotificationKinds = []NotificationKind{
+	NotificationPollClosed,
+	NotificationResultsReady,
+	NotificationPollInvited,
+	NotificationPollExpiring,
+}
+
+// NotificationDelivery is how a user wants to hear about a kind of
+// notification.
+type NotificationDelivery string
+
+const (
+	NotificationInstant NotificationDelivery = "instant"
+	NotificationDigest  NotificationDelivery = "digest"
+	NotificationOff     NotificationDelivery = "off"
+)
+
+// DefaultDelivery applies until the user picks something else. Results go
+// into the daily digest, everything else that needs acting on is sent right
+// away.
+func (k NotificationKind) DefaultDelivery() NotificationDelivery {
+
+	if k == NotificationResultsReady {
+		return NotificationDigest
+	}
+
+	return NotificationInstant
+}
+
+type NotificationPreference struct {
+	UserID    uuid.UUID            `gorm:"type:uuid;primaryKey"`
+	Kind      NotificationKind     `gorm:"primaryKey"`
+	Delivery  NotificationDelivery `gorm:"not null"`
+	UpdatedAt time.Time            `gorm:"not null"`
+}
+
+type EmailNotificationStatus string
+
+const (
+	EmailNotificationPending EmailNotificationStatus = "pending"
+	EmailNotificationSent    EmailNotificationStatus = "sent"
+	EmailNotificationFailed  EmailNotificationStatus = "failed"
+)
+
+// EmailNotification is one rendered email waiting to be sent, either on its
+// own or, when Digest is set, together with the recipient's other digest
+// notifications. A recipient gets at most one per outbox event.
+type EmailNotification struct {
+	ID      uuid.UUID        `gorm:"type:uuid;primaryKey;"`
+	EventID uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_email_notification_event"`
+	Email   string           `gorm:"not null;uniqueIndex:idx_email_notification_event"`
+	UserID  *uuid.UUID       `gorm:"type:uuid;index"`
+	Kind    NotificationKind `gorm:"not null"`
+	PollID  uuid.UUID        `gorm:"type:uuid;not null"`
+	Subject string           `gorm:"not null"`
+	Body    string           `gorm:"not null"`
+	// Summary is the line the notification gets in a digest.
+	Summary   string                  `gorm:"not null"`
+	Digest    bool                    `gorm:"not null;default:false"`
+	Status    EmailNotificationStatus `gorm:"not null;index:idx_email_notification_due,priority:1"`
+	Attempts  int                     `gorm:"not null;default:0"`
+	SendAfter time.Time               `gorm:"not null;index:idx_email_notification_due,priority:2"`
+	SentAt    *time.Time
+	Error     string
+	CreatedAt time.Time `gorm:"not null;index"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (n *EmailNotification) BeforeCreate(tx *gorm.DB) (err error) {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return
+}
+
+// Notification is an entry in a user's in-app inbox.
+type Notification struct {
+	ID     uuid.UUID        `gorm:"type:uuid;primaryKey;"`
+	UserID uuid.UUID        `gorm:"type:uuid;not null;index:idx_notification_inbox,priority:1;index:idx_notification_unread,where:read_at IS NULL;uniqueIndex:idx_notification_event,priority:2"`
+	Kind   NotificationKind `gorm:"not null"`
+	PollID uuid.UUID        `gorm:"type:uuid;not null"`
+	// EventID is the outbox event the notification came from, so processing
+	// the event again does not add it twice.
+	EventID   *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_notification_event,priority:1"`
+	Message   string     `gorm:"not null"`
+	ReadAt    *time.Time `gorm:"index"`
+	CreatedAt time.Time  `gorm:"not null;index:idx_notification_inbox,priority:2"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) (err error) {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return
+}
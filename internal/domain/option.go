@@ -10,12 +10,16 @@ import (
 type Option struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;"`
 	Name      string         `gorm:"not null"`
-	PollID    uuid.UUID      `gorm:"not null"`
+	PollID    uuid.UUID      `gorm:"not null;index"`
 	CreatedAt time.Time      `gorm:"not null"`
 	UpdatedAt time.Time      `gorm:"not null"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	Votes []Vote `gorm:"foreignKey:OptionID;references:ID"`
+	// VoteCount and LastVoteAt are kept current by the database as votes
+	// are cast and removed; the votes themselves are never loaded with an
+	// option.
+	VoteCount  int        `gorm:"not null;default:0;<-:false"`
+	LastVoteAt *time.Time `gorm:"<-:false"`
 }
 
 func (o *Option) BeforeCreate(tx *gorm.DB) (err error) {
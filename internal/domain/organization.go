@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrganizationRole string
+
+const (
+	OrganizationRoleViewer OrganizationRole = "viewer"
+	OrganizationRoleEditor OrganizationRole = "editor"
+	OrganizationRoleOwner  OrganizationRole = "owner"
+)
+
+var organizationRoleRank = map[OrganizationRole]int{
+	OrganizationRoleViewer: 1,
+	OrganizationRoleEditor: 2,
+	OrganizationRoleOwner:  3,
+}
+
+// Allows reports whether the role grants at least the required role. Owners
+// can do everything editors can, and editors everything viewers can.
+func (r OrganizationRole) Allows(required OrganizationRole) bool {
+	return organizationRoleRank[r] >= organizationRoleRank[required] && organizationRoleRank[r] > 0
+}
+
+type Organization struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;"`
+	Name      string         `gorm:"not null"`
+	CreatedAt time.Time      `gorm:"not null"`
+	UpdatedAt time.Time      `gorm:"not null"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Members []OrganizationMember `gorm:"foreignKey:OrganizationID;references:ID;constraint:OnDelete:CASCADE"`
+	Polls   []Poll               `gorm:"foreignKey:OrganizationID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) (err error) {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return
+}
+
+type OrganizationMember struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primaryKey;"`
+	OrganizationID uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_organization_member"`
+	UserID         uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_organization_member;index"`
+	Role           OrganizationRole `gorm:"not null"`
+	CreatedAt      time.Time        `gorm:"not null"`
+	UpdatedAt      time.Time        `gorm:"not null"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (m *OrganizationMember) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
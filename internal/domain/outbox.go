@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OutboxEventType string
+
+const (
+	OutboxPollCreated  OutboxEventType = "poll.created"
+	OutboxPollDeleted  OutboxEventType = "poll.deleted"
+	OutboxVoteCast     OutboxEventType = "vote.cast"
+	OutboxPollClosed   OutboxEventType = "poll.closed"
+	OutboxPollInvited  OutboxEventType = "poll.invited"
+	OutboxPollExpiring OutboxEventType = "poll.expiring"
+)
+
+// OutboxConsumer names something the outbox relay hands events to.
+type OutboxConsumer string
+
+const (
+	// OutboxConsumerBroker pushes events to the streams open in the app.
+	OutboxConsumerBroker OutboxConsumer = "broker"
+	// OutboxConsumerWebhooks queues webhook deliveries.
+	OutboxConsumerWebhooks OutboxConsumer = "webhooks"
+	// OutboxConsumerNotifications queues emails and fills inboxes.
+	OutboxConsumerNotifications OutboxConsumer = "notifications"
+)
+
+// OutboxConsumers each get every event.
+var OutboxConsumers = []OutboxConsumer{
+	OutboxConsumerBroker,
+	OutboxConsumerWebhooks,
+	OutboxConsumerNotifications,
+}
+
+// OutboxEvent records a change in the same transaction as the change
+// itself, so what follows from it, such as notifications, only happens once
+// the change is committed.
+type OutboxEvent struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primaryKey;"`
+	Type      OutboxEventType `gorm:"not null"`
+	PollID    uuid.UUID       `gorm:"type:uuid;not null"`
+	Payload   string          `gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt time.Time       `gorm:"not null;index"`
+}
+
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
+
+// OutboxDelivery tracks one consumer's handling of an event. The relay hands
+// an event to a consumer until it is marked processed, so a consumer sees an
+// event again if the relay stops in between; consumers make handling it
+// twice have the effect of handling it once. An event the consumer keeps
+// failing is given up at FailedAt.
+type OutboxDelivery struct {
+	EventID     uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	Consumer    OutboxConsumer `gorm:"primaryKey;index:idx_outbox_delivery_due,priority:1,where:processed_at IS NULL"`
+	LockedUntil *time.Time
+	ProcessedAt *time.Time
+	Attempts    int    `gorm:"not null;default:0"`
+	LastError   string `gorm:"not null;default:''"`
+	FailedAt    *time.Time
+	CreatedAt   time.Time `gorm:"not null;index:idx_outbox_delivery_due,priority:2"`
+}
+
+// NewOutboxEvent returns an event about the poll carrying payload as JSON.
+func NewOutboxEvent(eventType OutboxEventType, pollID uuid.UUID, payload any) (OutboxEvent, error) {
+
+	data, err := json.Marshal(payload)
+
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return OutboxEvent{
+		ID:      uuid.New(),
+		Type:    eventType,
+		PollID:  pollID,
+		Payload: string(data),
+	}, nil
+}
+
+// PollInvitedPayload names the invite a poll.invited event is about.
+type PollInvitedPayload struct {
+	InviteID uuid.UUID  `json:"invite_id"`
+	Email    string     `json:"email"`
+	UserID   *uuid.UUID `json:"user_id,omitempty"`
+}
+
+// VoteCastPayload names the vote a vote.cast event is about.
+type VoteCastPayload struct {
+	OptionID uuid.UUID `json:"option_id"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+// PollDeletedPayload keeps what consumers need of a poll that is gone.
+type PollDeletedPayload struct {
+	UserID uuid.UUID `json:"user_id"`
+	Title  string    `json:"title"`
+}
+
+// DecodePayload reads the event's payload into v.
+func (e OutboxEvent) DecodePayload(v any) error {
+	return json.Unmarshal([]byte(e.Payload), v)
+}
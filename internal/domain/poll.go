@@ -7,15 +7,49 @@ import (
 	"gorm.io/gorm"
 )
 
+// ResultsVisibility decides when voters who do not run a poll may see its
+// results.
+type ResultsVisibility string
+
+const (
+	ResultsVisibilityAlways      ResultsVisibility = "always"
+	ResultsVisibilityAfterVote   ResultsVisibility = "after_vote"
+	ResultsVisibilityAfterClose  ResultsVisibility = "after_close"
+	ResultsVisibilityCreatorOnly ResultsVisibility = "creator_only"
+)
+
 type Poll struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;"`
 	Title     string         `gorm:"required;not null"`
-	Options   []Option       `gorm:"required;foreignKey:PollID;references:ID"`
-	CreatedAt time.Time      `gorm:"required;not null"`
+	Options   []Option       `gorm:"required;foreignKey:PollID;references:ID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time      `gorm:"required;not null;index:idx_polls_user,priority:2;index:idx_polls_organization_id,priority:2"`
 	UpdatedAt time.Time      `gorm:"required;not null"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
-	UserID    uuid.UUID      `gorm:"required;type:uuid;not null"`
-	ExpiresAt time.Time    
+	UserID    uuid.UUID      `gorm:"required;type:uuid;not null;index:idx_polls_user,priority:1"`
+	// OrganizationID is set when the poll belongs to an organization rather
+	// than only to its creator.
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index:idx_polls_organization_id,priority:1"`
+	// MembersOnly limits voting on an organization poll to its members.
+	MembersOnly bool `gorm:"not null;default:false"`
+	// InviteOnly limits voting to the users and addresses on the poll's
+	// invite list.
+	InviteOnly bool `gorm:"not null;default:false"`
+	// ResultsVisibility controls who else besides the people running the
+	// poll sees its results.
+	ResultsVisibility ResultsVisibility `gorm:"not null;default:'creator_only'"`
+	// ClosedAt is set once the poll has expired and its closing has been
+	// announced.
+	ClosedAt *time.Time `gorm:"index"`
+	// ExpiryRemindedAt is set once invitees have been reminded that the
+	// poll closes soon.
+	ExpiryRemindedAt *time.Time
+	ExpiresAt time.Time `gorm:"index:idx_polls_open,where:closed_at IS NULL AND deleted_at IS NULL"`
+}
+
+// Public reports whether visitors who are not signed in may see the poll.
+// Organization and invite-only polls are never public.
+func (p *Poll) Public() bool {
+	return p.OrganizationID == nil && !p.InviteOnly
 }
 
 func (p *Poll) BeforeCreate(tx *gorm.DB) (err error) {
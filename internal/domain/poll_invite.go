@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PollInvite puts an email address on the allowlist of an invite-only poll.
+// UserID is known when the invitee already had an account or once they vote.
+type PollInvite struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;"`
+	PollID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_poll_invite_email"`
+	Email     string     `gorm:"not null;uniqueIndex:idx_poll_invite_email"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index"`
+	InvitedBy uuid.UUID  `gorm:"type:uuid;not null"`
+	CreatedAt time.Time  `gorm:"not null"`
+	UpdatedAt time.Time  `gorm:"not null"`
+}
+
+func (i *PollInvite) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return
+}
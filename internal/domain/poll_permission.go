@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PollAction is something a user can do with a poll.
+type PollAction string
+
+const (
+	// PollActionParticipate covers seeing and voting in members-only polls.
+	PollActionParticipate PollAction = "participate"
+
+	// PollActionViewResults covers live results and the invite list.
+	PollActionViewResults PollAction = "view_results"
+
+	// PollActionManage covers deleting the poll and managing its invites
+	// and collaborators.
+	PollActionManage PollAction = "manage"
+)
+
+// PollRole is the role a collaborator holds on a single poll.
+type PollRole string
+
+const (
+	PollRoleResultsViewer PollRole = "results_viewer"
+	PollRoleCoOwner       PollRole = "co_owner"
+)
+
+var pollRoleActions = map[PollRole][]PollAction{
+	PollRoleResultsViewer: {PollActionViewResults},
+	PollRoleCoOwner:       {PollActionParticipate, PollActionViewResults, PollActionManage},
+}
+
+// Allows reports whether the role grants the action.
+func (r PollRole) Allows(action PollAction) bool {
+	for _, allowed := range pollRoleActions[r] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// PollPermission grants a user other than the creator a role on a poll.
+type PollPermission struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	PollID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_poll_permission"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_poll_permission;index"`
+	Role      PollRole  `gorm:"not null"`
+	GrantedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (p *PollPermission) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return
+}
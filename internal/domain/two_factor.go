@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TwoFactor holds a user's TOTP secret. It is created disabled on enrollment
+// and only enabled once the user proves their authenticator works.
+type TwoFactor struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	Secret       string    `gorm:"not null"`
+	Enabled      bool      `gorm:"not null;default:false"`
+	LastUsedStep int64     `gorm:"not null;default:0"`
+	EnabledAt    *time.Time
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (t *TwoFactor) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	CodeHash  string    `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
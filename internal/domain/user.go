@@ -8,18 +8,25 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;"`
-	Username  string         `gorm:"not null"`
-	Email     string         `gorm:"not null;unique"`
-	Password  string         `gorm:"not null"`
-	JoinedAt  time.Time      `gorm:"not null"`
-	CreatedAt time.Time      `gorm:"not null"`
-	UpdatedAt time.Time      `gorm:"not null"`
-	DeletedAt gorm.DeletedAt `gorm:"index"`
-
-	Polls         []Poll         `gorm:"foreignKey:UserID;references:ID"`
-	Votes         []Vote         `gorm:"foreignKey:UserID;references:ID"`
-	RefreshTokens []RefreshToken `gorm:"foreignKey:UserID;references:ID"`
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	Username       string    `gorm:"not null"`
+	Email          string    `gorm:"not null;uniqueIndex:idx_users_email,where:deleted_at IS NULL"`
+	Password       string    `gorm:"not null"`
+	EmailVerified  bool      `gorm:"not null;default:false"`
+	ProfilePicture string
+	// Passwordless accounts were created through an identity provider or
+	// from Slack and have no password the user knows. They confirm changes
+	// to the account by signing in through the provider again.
+	Passwordless     bool `gorm:"not null;default:false"`
+	ProviderSignInAt *time.Time
+	JoinedAt         time.Time      `gorm:"not null"`
+	CreatedAt        time.Time      `gorm:"not null"`
+	UpdatedAt        time.Time      `gorm:"not null"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+
+	Polls         []Poll         `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	Votes         []Vote         `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	RefreshTokens []RefreshToken `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -28,3 +35,16 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// AccountDeletionMode decides what happens to a deleted user's polls and votes.
+type AccountDeletionMode string
+
+const (
+	// AccountDeletionAnonymize keeps polls and votes but strips everything
+	// that identifies the user from the account.
+	AccountDeletionAnonymize AccountDeletionMode = "anonymize"
+
+	// AccountDeletionRemove soft-deletes the user's polls, their options and
+	// votes, and every vote the user cast.
+	AccountDeletionRemove AccountDeletionMode = "remove"
+)
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a User to an account at an external identity provider.
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Provider  string    `gorm:"not null;uniqueIndex:idx_provider_subject,where:deleted_at IS NULL"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_provider_subject,where:deleted_at IS NULL"`
+	Email     string
+	CreatedAt time.Time      `gorm:"not null"`
+	UpdatedAt time.Time      `gorm:"not null"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return
+}
+
+// OIDCLoginState holds what is needed to finish an authorization code flow
+// between redirecting to the provider and handling its callback.
+type OIDCLoginState struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	Provider     string    `gorm:"not null"`
+	StateHash    string    `gorm:"not null;uniqueIndex"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	BrowserHash  string    `gorm:"not null;default:''"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (s *OIDCLoginState) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return
+}
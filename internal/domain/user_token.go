@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailChange       TokenPurpose = "email_change"
+)
+
+// UserToken is a single-use, expiring token sent to a user by email. Only the
+// SHA-256 hash of the token is stored.
+type UserToken struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primaryKey;"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Purpose   TokenPurpose `gorm:"not null"`
+	TokenHash string       `gorm:"not null;uniqueIndex:,where:deleted_at IS NULL"`
+	// Payload carries purpose specific data, such as the new address of an
+	// email change.
+	Payload   string
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time      `gorm:"not null"`
+	UpdatedAt time.Time      `gorm:"not null"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (t *UserToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
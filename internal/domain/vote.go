@@ -8,11 +8,11 @@ import (
 )
 
 type Vote struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;"`
-	UserID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_user_poll"`
-	PollID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_user_poll"`
-	OptionID  uuid.UUID      `gorm:"type:uuid;not null"`
-	CreatedAt time.Time      `gorm:"not null"`
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;index:idx_votes_page,priority:3"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_user_poll,where:deleted_at IS NULL;index:idx_votes_user,priority:1"`
+	PollID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_user_poll,where:deleted_at IS NULL;index:idx_votes_option,priority:2;index:idx_votes_tally,priority:1,where:deleted_at IS NULL;index:idx_votes_page,priority:1,where:deleted_at IS NULL"`
+	OptionID  uuid.UUID      `gorm:"type:uuid;not null;index:idx_votes_option,priority:1;index:idx_votes_tally,priority:2"`
+	CreatedAt time.Time      `gorm:"not null;index:idx_votes_user,priority:2;index:idx_votes_tally,priority:3;index:idx_votes_page,priority:2"`
 	UpdatedAt time.Time      `gorm:"not null"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
@@ -23,3 +23,20 @@ func (v *Vote) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// Ballot is a vote that passed validation and waits to be recorded.
+// InviteID is the email-only invite the voter used, which is claimed for
+// their account when the vote is recorded.
+type Ballot struct {
+	PollID   uuid.UUID
+	OptionID uuid.UUID
+	UserID   uuid.UUID
+	InviteID *uuid.UUID
+}
+
+// VoteCursor is the last vote of a page. Votes are listed in the order they
+// were cast, and the next page starts after the cursor.
+type VoteCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookEventPollCreated      WebhookEvent = "poll.created"
+	WebhookEventVoteCast         WebhookEvent = "vote.cast"
+	WebhookEventPollClosed       WebhookEvent = "poll.closed"
+	WebhookEventResultsFinalized WebhookEvent = "poll.results_finalized"
+)
+
+// Webhook posts poll events to a URL of the user's. Without a PollID it
+// covers every poll the user creates. Secret signs each payload, so it is
+// kept as is rather than hashed.
+type Webhook struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null;index"`
+	PollID    *uuid.UUID     `gorm:"type:uuid;index"`
+	URL       string         `gorm:"not null"`
+	Secret    string         `gorm:"not null"`
+	Events    []string       `gorm:"serializer:json;not null"`
+	CreatedAt time.Time      `gorm:"not null"`
+	UpdatedAt time.Time      `gorm:"not null"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return
+}
+
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	return slices.Contains(w.Events, string(event))
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery queues an event for a webhook and logs how sending it
+// went. Pending deliveries are sent once NextAttemptAt has passed; every
+// delivery of the same event shares its EventID so receivers can drop
+// duplicates.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primaryKey;"`
+	WebhookID      uuid.UUID             `gorm:"type:uuid;not null;index:idx_webhook_deliveries_webhook_id,priority:1"`
+	EventID        uuid.UUID             `gorm:"type:uuid;not null"`
+	Event          WebhookEvent          `gorm:"not null"`
+	PollID         uuid.UUID             `gorm:"type:uuid;not null"`
+	Payload        string                `gorm:"type:jsonb;not null"`
+	Status         WebhookDeliveryStatus `gorm:"not null;index:idx_webhook_delivery_due,priority:1"`
+	Attempts       int                   `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time             `gorm:"not null;index:idx_webhook_delivery_due,priority:2"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	ResponseBody   string
+	Error          string
+	CreatedAt      time.Time `gorm:"not null;index;index:idx_webhook_deliveries_webhook_id,priority:2"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	UserExistsError    = errors.New("User with email already exists")
@@ -10,6 +13,71 @@ var (
 	PollExpiredError   = errors.New("Poll has expired")
 	OptionNotFound     = errors.New("Option not found in poll")
 	PollNotFoundError  = errors.New("Poll not found")
-	PollAccessDeniedError = errors.New("Only the creator can view the live votings")
+	PollAccessDeniedError = errors.New("The results of this poll are not visible to you")
+	PollPermissionDeniedError = errors.New("You do not have permission to manage this poll")
+	MembersOnlyPollError = errors.New("This poll is only open to members of its organization")
+	InvalidMembersOnlyPollError = errors.New("Only organization polls can be limited to members")
 	VoteAlreadyExistsError = errors.New("You have already voted")
+	TokenAlreadyUsedError = errors.New("Token has already been used")
+	InvalidPasswordError = errors.New("Invalid password")
+	ReauthenticationRequiredError = errors.New("Sign in with your identity provider again to confirm this change")
+	InvalidLoginStateError = errors.New("Login session is invalid or has expired")
+	IdentityProviderNotFoundError = errors.New("Identity provider not found")
+	IdentityNotVerifiedError = errors.New("Identity provider returned an invalid identity")
+	IdentityEmailRequiredError = errors.New("Identity provider did not return an email address")
+	InvalidTwoFactorCodeError = errors.New("Invalid two-factor authentication code")
+	TwoFactorAlreadyEnabledError = errors.New("Two-factor authentication is already enabled")
+	TwoFactorNotEnabledError = errors.New("Two-factor authentication is not enabled")
+	TwoFactorNotEnrolledError = errors.New("Start two-factor enrollment first")
+	InvalidMFATokenError = errors.New("Two-factor challenge is invalid or has expired")
+	InvalidCredentialsError = errors.New("Invalid email or password")
+	TooManyAttemptsError = errors.New("Too many failed login attempts, try again later")
+	APIKeyNotFoundError = errors.New("API key not found")
+	InvalidAPIKeyError = errors.New("Invalid API key provided")
+	InvalidAPIKeyExpiryError = errors.New("API key expiry must be in the future")
+	EmailUnchangedError = errors.New("New email must be different from the current one")
+	UnsupportedImageError = errors.New("Profile picture must be a PNG, JPEG, GIF or WebP image")
+	ImageTooLargeError = errors.New("Profile picture must be at most 2 MB")
+	DataExportNotFoundError = errors.New("Data export not found")
+	DataExportInProgressError = errors.New("A data export is already being prepared")
+	DataExportNotReadyError = errors.New("Data export is not ready yet")
+	DataExportExpiredError = errors.New("Download link has expired")
+	OrganizationNotFoundError = errors.New("Organization not found")
+	OrganizationPermissionDeniedError = errors.New("Your role in the organization does not allow this")
+	MemberExistsError = errors.New("User is already a member of the organization")
+	MemberNotFoundError = errors.New("User is not a member of the organization")
+	LastOwnerError = errors.New("An organization must keep at least one owner")
+	PollNotInvitedError = errors.New("You have not been invited to vote in this poll")
+	InviteNotFoundError = errors.New("Invite not found")
+	EmptyInviteListError = errors.New("Invite at least one email address or user")
+	CollaboratorExistsError = errors.New("User is already a collaborator on this poll")
+	CollaboratorNotFoundError = errors.New("User is not a collaborator on this poll")
+	CreatorCollaboratorError = errors.New("The poll creator already has full access")
+	UnsupportedEmbedURLError = errors.New("URL does not link to a poll")
+	UnsupportedEmbedFormatError = errors.New("Only the json oEmbed format is supported")
+	PollSignInRequiredError = errors.New("Sign in to see this poll")
+	WebhookNotFoundError = errors.New("Webhook not found")
+	WebhookDeliveryNotFoundError = errors.New("Webhook delivery not found")
+	InvalidWebhookURLError = errors.New("Webhook URL must be an http or https URL")
+	WebhookAddressNotAllowedError = errors.New("Webhook URL must point to a public address")
+	InvalidSlackSignatureError = errors.New("Invalid Slack request signature")
+	InvalidSlackRequestError = errors.New("Invalid Slack request")
+	NotificationNotFoundError = errors.New("Notification not found")
+	OutdatedSchemaError = errors.New("Database schema is not up to date")
+	VotersAccessDeniedError = errors.New("Who voted in this poll is not visible to you")
+	InvalidCursorError = errors.New("Invalid cursor")
 )
+
+// LockoutError is returned while a login is temporarily locked. It matches
+// TooManyAttemptsError with errors.Is.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return TooManyAttemptsError.Error()
+}
+
+func (e *LockoutError) Is(target error) bool {
+	return target == TooManyAttemptsError
+}
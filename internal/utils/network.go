@@ -0,0 +1,43 @@
+package utils
+
+import "net/netip"
+
+// nonPublicPrefixes are ranges not covered by the netip.Addr predicates
+// that still never lead to a public host.
+var nonPublicPrefixes = []netip.Prefix{
+	// "this network"
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// carrier-grade NAT
+	netip.MustParsePrefix("100.64.0.0/10"),
+	// IPv4 translated to IPv6
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// IsPublicAddr reports whether addr is reachable on the public internet,
+// as opposed to loopback, private and link-local addresses, which include
+// the cloud metadata services at 169.254.169.254 and fd00:ec2::254. Anything
+// the server fetches on a user's behalf must only connect to public ones.
+func IsPublicAddr(addr netip.Addr) bool {
+
+	addr = addr.Unmap()
+
+	if !addr.IsValid() ||
+		addr.IsUnspecified() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
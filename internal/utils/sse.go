@@ -1,8 +1,25 @@
 package utils
 
 type Event struct {
+	// ID is set on events that could be sent twice, so clients can drop
+	// the copy.
+	ID      string      `json:"id,omitempty"`
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload"`
+
+	// PollID names the poll the event belongs to so streams can be limited
+	// to a single poll.
+	PollID string `json:"-"`
+
+	// UserID makes the event private to one user. It only reaches that
+	// user's streams, never the poll streams.
+	UserID string `json:"-"`
+}
+
+// UserClient is a stream of the events published for one user.
+type UserClient struct {
+	UserID string
+	Events chan Event
 }
 
 type Broker struct {
@@ -10,6 +27,10 @@ type Broker struct {
 	Add     chan chan Event
 	Remove  chan chan Event
 	Events  chan Event
+
+	Users      map[string]map[chan Event]bool
+	AddUser    chan UserClient
+	RemoveUser chan UserClient
 }
 
 func NewBroker() *Broker {
@@ -18,9 +39,18 @@ func NewBroker() *Broker {
 		Add:     make(chan chan Event),
 		Remove:  make(chan chan Event),
 		Events:  make(chan Event),
+
+		Users:      make(map[string]map[chan Event]bool),
+		AddUser:    make(chan UserClient),
+		RemoveUser: make(chan UserClient),
 	}
 }
 
+// Publish hands the event to the broker for its clients.
+func (b *Broker) Publish(event Event) {
+	b.Events <- event
+}
+
 func (b *Broker) Start() {
 	go func() {
 		for {
@@ -32,7 +62,26 @@ func (b *Broker) Start() {
 				delete(b.Clients, c)
 				close(c)
 
+			case c := <-b.AddUser:
+				if b.Users[c.UserID] == nil {
+					b.Users[c.UserID] = make(map[chan Event]bool)
+				}
+				b.Users[c.UserID][c.Events] = true
+
+			case c := <-b.RemoveUser:
+				delete(b.Users[c.UserID], c.Events)
+				if len(b.Users[c.UserID]) == 0 {
+					delete(b.Users, c.UserID)
+				}
+				close(c.Events)
+
 			case event := <-b.Events:
+				if event.UserID != "" {
+					for c := range b.Users[event.UserID] {
+						c <- event
+					}
+					continue
+				}
 				for c := range b.Clients {
 					c <- event
 				}
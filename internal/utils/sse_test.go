@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(client chan Event) (Event, bool) {
+	select {
+	case event := <-client:
+		return event, true
+	case <-time.After(50 * time.Millisecond):
+		return Event{}, false
+	}
+}
+
+func TestBroker_UserEventsOnlyReachTheirUser(t *testing.T) {
+	b := NewBroker()
+	b.Start()
+
+	pollClient := make(chan Event, 1)
+	ada := UserClient{UserID: "ada", Events: make(chan Event, 1)}
+	bob := UserClient{UserID: "bob", Events: make(chan Event, 1)}
+
+	b.Add <- pollClient
+	b.AddUser <- ada
+	b.AddUser <- bob
+
+	b.Publish(Event{Type: "NOTIFICATION", PollID: "poll", UserID: "ada"})
+
+	event, ok := receive(ada.Events)
+	assert.True(t, ok)
+	assert.Equal(t, "NOTIFICATION", event.Type)
+
+	_, ok = receive(bob.Events)
+	assert.False(t, ok)
+	_, ok = receive(pollClient)
+	assert.False(t, ok)
+
+	b.Publish(Event{Type: "POLL_VOTE", PollID: "poll"})
+
+	_, ok = receive(pollClient)
+	assert.True(t, ok)
+	_, ok = receive(ada.Events)
+	assert.False(t, ok)
+
+	b.RemoveUser <- ada
+
+	_, ok = <-ada.Events
+	assert.False(t, ok)
+}
@@ -59,6 +59,9 @@ func (v XValidator) Validate(data any) error {
 
 		case "optionlistmax":
 			return fmt.Errorf("Options must be at most %s", err[0].Param())
+
+		case "oneof":
+			return fmt.Errorf("%s must be one of %s", err[0].StructField(), err[0].Param())
 		default:
 			return errors.New(err[0].Error())
 		}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XLSXWriter streams a minimal Office Open XML workbook. Rows are written
+// straight into the zip, so large sheets never have to be held in memory.
+// Cells are stored as inline strings or numbers; times are written as
+// RFC 3339 text.
+type XLSXWriter struct {
+	zw     *zip.Writer
+	sheet  io.Writer
+	sheets []string
+}
+
+func NewXLSXWriter(w io.Writer) *XLSXWriter {
+	return &XLSXWriter{zw: zip.NewWriter(w)}
+}
+
+// AddSheet finishes the current sheet and starts a new one. Names longer
+// than Excel's 31 character limit are cut.
+func (x *XLSXWriter) AddSheet(name string) error {
+
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+
+	x.sheets = append(x.sheets, name)
+
+	sheet, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+
+	if err != nil {
+		return err
+	}
+
+	x.sheet = sheet
+
+	_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	return err
+}
+
+// WriteRow appends a row to the current sheet. Cells may be strings,
+// integers, floats or times; anything else is formatted with %v.
+func (x *XLSXWriter) WriteRow(cells ...any) error {
+
+	if x.sheet == nil {
+		return fmt.Errorf("xlsx: WriteRow called before AddSheet")
+	}
+
+	var row strings.Builder
+
+	row.WriteString("<row>")
+
+	for _, cell := range cells {
+		switch value := cell.(type) {
+		case int:
+			row.WriteString(`<c t="n"><v>` + strconv.Itoa(value) + `</v></c>`)
+		case int64:
+			row.WriteString(`<c t="n"><v>` + strconv.FormatInt(value, 10) + `</v></c>`)
+		case float64:
+			row.WriteString(`<c t="n"><v>` + strconv.FormatFloat(value, 'f', -1, 64) + `</v></c>`)
+		case time.Time:
+			writeInlineString(&row, value.UTC().Format(time.RFC3339))
+		case string:
+			writeInlineString(&row, value)
+		default:
+			writeInlineString(&row, fmt.Sprint(value))
+		}
+	}
+
+	row.WriteString("</row>")
+
+	_, err := io.WriteString(x.sheet, row.String())
+
+	return err
+}
+
+// Close finishes the last sheet and writes the workbook parts that list the
+// sheets.
+func (x *XLSXWriter) Close() error {
+
+	if len(x.sheets) == 0 {
+		if err := x.AddSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+
+	var contentTypes, workbook, workbookRels strings.Builder
+
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+
+	workbookRels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i, name := range x.sheets {
+		id := i + 1
+
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, id)
+
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(name))
+		fmt.Fprintf(&workbook, `" sheetId="%d" r:id="rId%d"/>`, id, id)
+
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, id, id)
+	}
+
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`, len(x.sheets)+1)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="1"><font/></fonts><fills count="1"><fill/></fills><borders count="1"><border/></borders><cellStyleXfs count="1"><xf/></cellStyleXfs><cellXfs count="1"><xf/></cellXfs></styleSheet>`},
+	}
+
+	for _, part := range parts {
+
+		w, err := x.zw.Create(part.name)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	return x.zw.Close()
+}
+
+func (x *XLSXWriter) endSheet() error {
+
+	if x.sheet == nil {
+		return nil
+	}
+
+	_, err := io.WriteString(x.sheet, `</sheetData></worksheet>`)
+
+	x.sheet = nil
+
+	return err
+}
+
+func writeInlineString(row *strings.Builder, value string) {
+
+	row.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(row, []byte(value))
+	row.WriteString(`</t></is></c>`)
+}
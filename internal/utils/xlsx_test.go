@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	x := NewXLSXWriter(&buf)
+
+	assert.NoError(t, x.AddSheet("Results"))
+	assert.NoError(t, x.WriteRow("Option", "Votes"))
+	assert.NoError(t, x.WriteRow("Fish & <Chips>", 3))
+	assert.NoError(t, x.AddSheet("Ballots"))
+	assert.NoError(t, x.WriteRow(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), 0.5))
+	assert.NoError(t, x.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	files := map[string]string{}
+
+	for _, f := range archive.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		files[f.Name] = string(content)
+	}
+
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Results" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Ballots" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], "Fish &amp; &lt;Chips&gt;")
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c t="n"><v>3</v></c>`)
+	assert.True(t, strings.HasSuffix(files["xl/worksheets/sheet2.xml"], "</sheetData></worksheet>"))
+	assert.Contains(t, files["xl/worksheets/sheet2.xml"], "2026-01-02T03:04:05Z")
+}